@@ -0,0 +1,174 @@
+// Package capture implements reading and writing .ludcap files, which hold
+// Ludwieg packages captured from network sessions.
+//
+// A capture file starts with the magic bytes "LUDCAP", a version byte, and the
+// fingerprint of the schema used when the packages were captured. Records
+// follow until the end of the file, each one holding:
+//
+//   - Timestamp, in nanoseconds since the Unix epoch (int64)
+//   - Connection number, identifying the session the package belongs to (uint32)
+//   - Direction, 0x00 for client to server, or 0x01 for server to client
+//   - Length of the package (uint32)
+//   - Raw package bytes, as sent on the wire
+//
+// All integers are encoded in little-endian byte order.
+package capture
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+)
+
+// Magic holds the bytes present at the beginning of every capture file
+var Magic = []byte("LUDCAP")
+
+// Version holds the version of the capture format written by Writer
+const Version = 0x01
+
+// recordHeaderSize holds the size of the fields preceding record data
+const recordHeaderSize = 8 + 4 + 1 + 4
+
+// maxRecordSize limits the length of records accepted by Reader
+const maxRecordSize = 64 << 20
+
+// ErrInvalidFile indicates that a file does not start with a capture header
+var ErrInvalidFile = errors.New("not a Ludwieg capture file")
+
+// Direction indicates which side of a connection sent a package
+type Direction byte
+
+const (
+	// ClientToServer indicates a package sent by the client
+	ClientToServer Direction = 0x00
+
+	// ServerToClient indicates a package sent by the server
+	ServerToClient Direction = 0x01
+)
+
+func (d Direction) String() string {
+	switch d {
+	case ClientToServer:
+		return "client → server"
+	case ServerToClient:
+		return "server → client"
+	}
+	return fmt.Sprintf("unknown(0x%02x)", byte(d))
+}
+
+// Record holds a single captured package
+type Record struct {
+	// Time indicates when the package was captured
+	Time time.Time
+
+	// Connection identifies the session the package belongs to
+	Connection uint32
+
+	// Direction indicates which side of the connection sent the package
+	Direction Direction
+
+	// Data holds the raw bytes of the package
+	Data []byte
+}
+
+// IsCapture determines whether data starts with the capture magic bytes
+func IsCapture(data []byte) bool {
+	return bytes.HasPrefix(data, Magic)
+}
+
+// Writer writes records to a capture file. Writer is not safe for concurrent
+// use.
+type Writer struct {
+	w *bufio.Writer
+}
+
+// NewWriter writes the capture header to w, returning a Writer for its
+// records
+func NewWriter(w io.Writer, fingerprint [sha256.Size]byte) (*Writer, error) {
+	buf := bufio.NewWriter(w)
+	buf.Write(Magic)
+	buf.WriteByte(Version)
+	buf.Write(fingerprint[:])
+	if err := buf.Flush(); err != nil {
+		return nil, err
+	}
+	return &Writer{w: buf}, nil
+}
+
+// Write appends a record to the file. Records are flushed as soon as they are
+// written, so that interrupted captures remain readable.
+func (w *Writer) Write(r Record) error {
+	var header [recordHeaderSize]byte
+	binary.LittleEndian.PutUint64(header[0:], uint64(r.Time.UnixNano()))
+	binary.LittleEndian.PutUint32(header[8:], r.Connection)
+	header[12] = byte(r.Direction)
+	binary.LittleEndian.PutUint32(header[13:], uint32(len(r.Data)))
+	w.w.Write(header[:])
+	w.w.Write(r.Data)
+	return w.w.Flush()
+}
+
+// Reader reads records from a capture file
+type Reader struct {
+	r      *bufio.Reader
+	offset int64
+
+	// Fingerprint holds the fingerprint of the schema used when the file was
+	// captured
+	Fingerprint [sha256.Size]byte
+}
+
+// NewReader reads the capture header from r, returning a Reader for its
+// records. ErrInvalidFile is returned when r does not start with a capture
+// header.
+func NewReader(r io.Reader) (*Reader, error) {
+	reader := &Reader{r: bufio.NewReader(r)}
+	header := make([]byte, len(Magic)+1+sha256.Size)
+	if _, err := io.ReadFull(reader.r, header); err != nil {
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			return nil, ErrInvalidFile
+		}
+		return nil, err
+	}
+	if !IsCapture(header) {
+		return nil, ErrInvalidFile
+	}
+	if v := header[len(Magic)]; v != Version {
+		return nil, fmt.Errorf("unsupported capture version 0x%02x", v)
+	}
+	copy(reader.Fingerprint[:], header[len(Magic)+1:])
+	reader.offset = int64(len(header))
+	return reader, nil
+}
+
+// Next reads the next record from the file. io.EOF is returned after the last
+// record, and io.ErrUnexpectedEOF when the file ends in the middle of one.
+func (r *Reader) Next() (*Record, error) {
+	var header [recordHeaderSize]byte
+	if _, err := io.ReadFull(r.r, header[:]); err != nil {
+		return nil, err
+	}
+	size := binary.LittleEndian.Uint32(header[13:])
+	if size > maxRecordSize {
+		return nil, fmt.Errorf("offset %d: record length %d exceeds the limit of %d bytes", r.offset, size, maxRecordSize)
+	}
+	rec := &Record{
+		Time:       time.Unix(0, int64(binary.LittleEndian.Uint64(header[0:]))),
+		Connection: binary.LittleEndian.Uint32(header[8:]),
+		Direction:  Direction(header[12]),
+		Data:       make([]byte, size),
+	}
+	if _, err := io.ReadFull(r.r, rec.Data); err != nil {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return nil, err
+	}
+	r.offset += int64(recordHeaderSize) + int64(size)
+	return rec, nil
+}
@@ -3,6 +3,7 @@ package cmd
 import (
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 
 	log "github.com/sirupsen/logrus"
@@ -11,6 +12,34 @@ import (
 	"github.com/ludwieg/ludco/langs"
 )
 
+// compilers maps values accepted by --lang to their compilers
+var compilers = map[string]langs.Compiler{
+	"go":         langs.Go{},
+	"objc":       langs.ObjC{},
+	"swift":      langs.Swift{},
+	"java":       langs.Java{},
+	"kotlin":     langs.Kotlin{},
+	"typescript": langs.TypeScript{},
+	"python":     langs.Python{},
+	"rust":       langs.Rust{},
+	"csharp":     langs.CSharp{},
+	"c":          langs.C{},
+	"cpp":        langs.Cpp{},
+	"dart":       langs.Dart{},
+	"elixir":     langs.Elixir{},
+	"wireshark":  langs.Wireshark{},
+}
+
+// supportedLanguages lists values accepted by --lang, for use in messages
+func supportedLanguages() string {
+	names := make([]string, 0, len(compilers))
+	for name := range compilers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return strings.Join(names[:len(names)-1], ", ") + " and " + names[len(names)-1]
+}
+
 var Compile = cli.Command{
 	Name:    "compile",
 	Aliases: []string{"c"},
@@ -18,15 +47,15 @@ var Compile = cli.Command{
 	Flags: []cli.Flag{
 		cli.StringFlag{
 			Name:  "lang",
-			Usage: "Destination language. Currently supported languages are go, objc, and java",
+			Usage: "Destination language. Currently supported languages are " + supportedLanguages(),
 		},
 		cli.StringFlag{
 			Name:  "package",
-			Usage: "Package name to use when generating files. Required when compiling to Java or Go. When omitted, ludco uses the input folder's name",
+			Usage: "Package name to use when generating files, or namespace when compiling to C#, C++ or Elixir. Required when compiling to Java, Kotlin or Go. When omitted, ludco uses the input folder's name",
 		},
 		cli.StringFlag{
 			Name:  "prefix",
-			Usage: "Class prefix used by the Objective-C compiler",
+			Usage: "Class prefix used by the Objective-C and Swift compilers, or symbol prefix used by the C compiler",
 		},
 	},
 	Action: func(c *cli.Context) error {
@@ -51,8 +80,9 @@ var Compile = cli.Command{
 			log.Errorf("Error: You must define which language must be used as output")
 			return nil
 		}
-		if lang != "objc" && lang != "go" && lang != "java" {
-			log.Errorf("Error: Supported languages are go, objc and java")
+		compiler, ok := compilers[lang]
+		if !ok {
+			log.Errorf("Error: Supported languages are %s", supportedLanguages())
 			return nil
 		}
 
@@ -108,17 +138,6 @@ var Compile = cli.Command{
 			return nil
 		}
 
-		var compiler langs.Compiler
-
-		switch lang {
-		case "go":
-			compiler = langs.Go{}
-		case "objc":
-			compiler = langs.ObjC{}
-		case "java":
-			compiler = langs.Java{}
-		}
-
 		compiler.Compile(input, output, c.String("package"), c.String("prefix"), &allPackages)
 		return nil
 	},
@@ -0,0 +1,108 @@
+package cmd
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/logrusorgru/aurora"
+	log "github.com/sirupsen/logrus"
+	"github.com/urfave/cli"
+
+	"github.com/ludwieg/ludco/capture"
+	"github.com/ludwieg/ludco/codec"
+	"github.com/ludwieg/ludco/models"
+)
+
+// checkFingerprint warns when a capture was recorded using a schema different
+// from the loaded one
+func checkFingerprint(r *capture.Reader, packages models.PackageList) {
+	if r.Fingerprint != packages.Fingerprint() {
+		log.Warn("Capture was recorded using a different schema; packages may not be decoded correctly")
+	}
+}
+
+// recordTitle returns the title used when printing a captured package
+func recordTitle(rec *capture.Record) string {
+	return fmt.Sprintf("#%d %s %s", rec.Connection, aurora.Blue(rec.Direction), aurora.Gray(rec.Time.Format(time.RFC3339Nano)))
+}
+
+func decodeCapture(r *capture.Reader, packages models.PackageList, rep *reporter) {
+	checkFingerprint(r, packages)
+	for {
+		rec, err := r.Next()
+		if err == io.EOF {
+			return
+		} else if err != nil {
+			log.Errorf("Error reading capture: %s", err)
+			return
+		}
+		msg, _, err := codec.Decode(packages, rec.Data)
+		rep.report(recordTitle(rec), map[string]interface{}{
+			"time":       rec.Time.Format(time.RFC3339Nano),
+			"connection": rec.Connection,
+			"direction":  rec.Direction.String(),
+		}, msg, err)
+	}
+}
+
+func decodeStream(r io.Reader, packages models.PackageList, rep *reporter) {
+	err := codec.NewScanner(r, packages).Each(func(msg *codec.Message, offset int64, err error) {
+		rep.report(aurora.Gray(fmt.Sprintf("@%d", offset)).String(), map[string]interface{}{
+			"offset": offset,
+		}, msg, err)
+	})
+	if err != nil {
+		log.Errorf("Error reading input: %s", err)
+	}
+}
+
+var Decode = cli.Command{
+	Name:      "decode",
+	Usage:     "Decodes Ludwieg packages stored in a file",
+	ArgsUsage: "<input> <file>",
+	Flags: []cli.Flag{
+		cli.BoolFlag{
+			Name:  "json",
+			Usage: "Writes decoded packages to the stdout as JSON objects, one per line",
+		},
+	},
+	Action: func(c *cli.Context) error {
+		if len(c.Args()) != 2 {
+			log.Errorf("Error: decode requires an input directory and a file")
+			return nil
+		}
+		allPackages := loadProject(c.Args().First())
+		if allPackages == nil {
+			return nil
+		}
+
+		fd, err := os.Open(c.Args().Get(1))
+		if err != nil {
+			log.Errorf("Error: %s", err)
+			return nil
+		}
+		defer fd.Close()
+
+		rep := &reporter{}
+		if c.Bool("json") {
+			rep.json = json.NewEncoder(os.Stdout)
+		}
+
+		buf := bufio.NewReader(fd)
+		if head, _ := buf.Peek(len(capture.Magic)); capture.IsCapture(head) {
+			r, err := capture.NewReader(buf)
+			if err != nil {
+				log.Errorf("Error reading capture: %s", err)
+				return nil
+			}
+			decodeCapture(r, allPackages, rep)
+		} else {
+			decodeStream(buf, allPackages, rep)
+		}
+		return nil
+	},
+}
@@ -0,0 +1,66 @@
+package cmd
+
+import (
+	"path/filepath"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/urfave/cli"
+
+	"github.com/ludwieg/ludco/docs"
+)
+
+var Doc = cli.Command{
+	Name:      "doc",
+	Usage:     "Generates reference documentation of Ludwieg packages",
+	ArgsUsage: "<input> <output>",
+	Flags: []cli.Flag{
+		cli.StringFlag{
+			Name:  "format",
+			Usage: "Documentation format. Currently supported formats are html and markdown",
+		},
+	},
+	Action: func(c *cli.Context) error {
+		if c.NArg() != 2 {
+			log.Errorf("Please specify input and output paths. ludco doc --format <format> <input> <output>")
+			return nil
+		}
+
+		var renderer docs.Renderer
+		switch strings.ToLower(c.String("format")) {
+		case "":
+			log.Errorf("Error: You must define which format must be used as output")
+			return nil
+		case "html":
+			renderer = docs.HTML{}
+		case "markdown", "md":
+			renderer = docs.Markdown{}
+		default:
+			log.Errorf("Error: Supported formats are html and markdown")
+			return nil
+		}
+
+		output, err := filepath.Abs(c.Args()[1])
+		if err != nil {
+			log.Errorf("Error reading output path: %s", err)
+			return nil
+		}
+
+		allPackages := loadProject(c.Args()[0])
+		if allPackages == nil || !prepareOutput(output) {
+			return nil
+		}
+
+		data, err := renderer.Render(allPackages)
+		if err != nil {
+			log.Errorf("Error rendering documentation: %s", err)
+			return nil
+		}
+		if err := writeOutput(output, "index"+renderer.Extension(), data); err != nil {
+			return nil
+		}
+
+		log.Info("Succeeded")
+		return nil
+	},
+}
@@ -0,0 +1,76 @@
+package cmd
+
+import (
+	"path/filepath"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/urfave/cli"
+
+	"github.com/ludwieg/ludco/export"
+)
+
+var Export = cli.Command{
+	Name:      "export",
+	Usage:     "Exports Ludwieg packages as schemas of other formats",
+	ArgsUsage: "<input> <output>",
+	Flags: []cli.Flag{
+		cli.StringFlag{
+			Name:  "format",
+			Usage: "Schema format. Currently supported formats are proto and jsonschema",
+		},
+		cli.StringFlag{
+			Name:  "package",
+			Usage: "Package declared by exported Protocol Buffers schemas",
+		},
+	},
+	Action: func(c *cli.Context) error {
+		if c.NArg() != 2 {
+			log.Errorf("Please specify input and output paths. ludco export --format <format> <input> <output>")
+			return nil
+		}
+
+		var exporter export.Exporter
+		switch strings.ToLower(c.String("format")) {
+		case "":
+			log.Errorf("Error: You must define which format must be used as output")
+			return nil
+		case "proto":
+			exporter = export.Proto{Package: c.String("package")}
+		case "jsonschema":
+			if c.String("package") != "" {
+				log.Warn("Ignoring unnecessary --package option")
+			}
+			exporter = export.JSONSchema{}
+		default:
+			log.Errorf("Error: Supported formats are proto and jsonschema")
+			return nil
+		}
+
+		output, err := filepath.Abs(c.Args()[1])
+		if err != nil {
+			log.Errorf("Error reading output path: %s", err)
+			return nil
+		}
+
+		allPackages := loadProject(c.Args()[0])
+		if allPackages == nil || !prepareOutput(output) {
+			return nil
+		}
+
+		for i := range allPackages {
+			pkg := &allPackages[i]
+			data, err := exporter.Export(pkg)
+			if err != nil {
+				log.Errorf("Error exporting %s: %s", pkg.Name, err)
+				return nil
+			}
+			if err := writeOutput(output, pkg.Name+exporter.Extension(), data); err != nil {
+				return nil
+			}
+		}
+
+		log.Info("Succeeded")
+		return nil
+	},
+}
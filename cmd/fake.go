@@ -0,0 +1,141 @@
+package cmd
+
+import (
+	"bufio"
+	"encoding/binary"
+	"encoding/json"
+	"io"
+	"math/rand"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/logrusorgru/aurora"
+	log "github.com/sirupsen/logrus"
+	"github.com/urfave/cli"
+
+	"github.com/ludwieg/ludco/codec"
+	"github.com/ludwieg/ludco/instances"
+	"github.com/ludwieg/ludco/models"
+)
+
+// fakeWriter writes a generated message to the output using one of the
+// supported formats
+type fakeWriter func(w io.Writer, pkg *models.Package, messageID byte, obj codec.Object) error
+
+var fakeWriters = map[string]fakeWriter{
+	"wire": func(w io.Writer, pkg *models.Package, messageID byte, obj codec.Object) error {
+		data, err := codec.Encode(pkg, messageID, obj)
+		if err != nil {
+			return err
+		}
+		_, err = w.Write(data)
+		return err
+	},
+	"stream": func(w io.Writer, pkg *models.Package, messageID byte, obj codec.Object) error {
+		data, err := codec.Encode(pkg, messageID, obj)
+		if err != nil {
+			return err
+		}
+		var length [4]byte
+		binary.BigEndian.PutUint32(length[:], uint32(len(data)))
+		if _, err = w.Write(length[:]); err != nil {
+			return err
+		}
+		_, err = w.Write(data)
+		return err
+	},
+	"json": func(w io.Writer, pkg *models.Package, messageID byte, obj codec.Object) error {
+		msg, err := jsonMessage(pkg, messageID, obj)
+		if err != nil {
+			return err
+		}
+		data, err := json.Marshal(msg)
+		if err != nil {
+			return err
+		}
+		_, err = w.Write(append(data, '\n'))
+		return err
+	},
+}
+
+var Fake = cli.Command{
+	Name:      "fake",
+	Usage:     "Generates random instances of Ludwieg packages",
+	ArgsUsage: "<input>",
+	Flags: []cli.Flag{
+		cli.StringFlag{
+			Name:  "package",
+			Usage: "Name or identifier of the package to generate. When omitted, packages are picked randomly",
+		},
+		cli.IntFlag{
+			Name:  "count",
+			Value: 1,
+			Usage: "Amount of instances to generate",
+		},
+		cli.Int64Flag{
+			Name:  "seed",
+			Usage: "Seed used by the random generator. When omitted, a seed is chosen and logged",
+		},
+		cli.StringFlag{
+			Name:  "format",
+			Value: "wire",
+			Usage: "Output format. Supported formats are wire (concatenated messages), stream (messages prefixed by their length as a big-endian uint32), and json (one object per line)",
+		},
+		cli.StringFlag{
+			Name:  "output",
+			Usage: "Path of the output file. When omitted, instances are written to the stdout",
+		},
+	},
+	Action: func(c *cli.Context) error {
+		allPackages := loadProject(c.Args().First())
+		if allPackages == nil {
+			return nil
+		}
+
+		write, ok := fakeWriters[strings.ToLower(c.String("format"))]
+		if !ok {
+			log.Errorf("Error: Supported formats are wire, stream and json")
+			return nil
+		}
+
+		candidates := allPackages
+		if name := c.String("package"); name != "" {
+			pkg := findPackage(allPackages, name)
+			if pkg == nil {
+				log.Errorf("Error: Unknown package %s", name)
+				return nil
+			}
+			candidates = models.PackageList{*pkg}
+		}
+
+		seed := c.Int64("seed")
+		if !c.IsSet("seed") {
+			seed = time.Now().UnixNano()
+			log.Infof("Using seed %s", aurora.Magenta(seed))
+		}
+		rnd := rand.New(rand.NewSource(seed))
+
+		var out io.Writer = os.Stdout
+		if path := c.String("output"); path != "" {
+			fd, err := os.Create(path)
+			if err != nil {
+				log.Errorf("Error creating output file: %s", err)
+				return nil
+			}
+			defer fd.Close()
+			out = fd
+		}
+		buf := bufio.NewWriter(out)
+		defer buf.Flush()
+
+		for i := 0; i < c.Int("count"); i++ {
+			pkg := &candidates[rnd.Intn(len(candidates))]
+			if err := write(buf, pkg, byte(i), instances.Random(pkg, rnd)); err != nil {
+				log.Errorf("Error writing instance of %s: %s", pkg.Name, err)
+				return nil
+			}
+		}
+		return nil
+	},
+}
@@ -0,0 +1,56 @@
+package cmd
+
+import (
+	"os"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/urfave/cli"
+
+	"github.com/ludwieg/ludco/graph"
+)
+
+var Graph = cli.Command{
+	Name:      "graph",
+	Usage:     "Prints a graph of Ludwieg packages and their structures",
+	ArgsUsage: "<input>",
+	Flags: []cli.Flag{
+		cli.StringFlag{
+			Name:  "format",
+			Usage: "Graph format. Currently supported formats are dot and mermaid",
+		},
+	},
+	Action: func(c *cli.Context) error {
+		if c.NArg() != 1 {
+			log.Errorf("Please specify project path. ludco graph --format <format> <path>")
+			return nil
+		}
+
+		var renderer graph.Renderer
+		switch strings.ToLower(c.String("format")) {
+		case "":
+			log.Errorf("Error: You must define which format must be used as output")
+			return nil
+		case "dot":
+			renderer = graph.DOT{}
+		case "mermaid":
+			renderer = graph.Mermaid{}
+		default:
+			log.Errorf("Error: Supported formats are dot and mermaid")
+			return nil
+		}
+
+		allPackages := loadProject(c.Args().First())
+		if allPackages == nil {
+			return nil
+		}
+
+		data, err := renderer.Render(allPackages)
+		if err != nil {
+			log.Errorf("Error rendering graph: %s", err)
+			return nil
+		}
+		os.Stdout.Write(data)
+		return nil
+	},
+}
@@ -0,0 +1,94 @@
+package cmd
+
+import (
+	"path/filepath"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/urfave/cli"
+
+	"github.com/ludwieg/ludco/importer"
+)
+
+var Import = cli.Command{
+	Name:  "import",
+	Usage: "Imports schemas of other formats as Ludwieg packages",
+	Subcommands: []cli.Command{
+		{
+			Name:      "proto",
+			Usage:     "Imports Protocol Buffers schemas",
+			ArgsUsage: "<input.proto>... <output>",
+			Action: func(c *cli.Context) error {
+				return runImport(c, importer.Proto{}, "ludco import proto <input.proto>... <output>")
+			},
+		},
+		{
+			Name:      "json",
+			Usage:     "Infers packages from JSON Schema documents or sample JSON documents",
+			ArgsUsage: "<input.json>... <output>",
+			Flags: []cli.Flag{
+				cli.StringFlag{
+					Name:  "name",
+					Usage: "Name of the package inferred from samples. Defaults to the name of the first sample file",
+				},
+			},
+			Action: func(c *cli.Context) error {
+				return runImport(c, importer.JSON{Name: c.String("name")}, "ludco import json <input.json>... <output>")
+			},
+		},
+	},
+}
+
+// runImport imports all input files provided to a subcommand, writing a
+// definition file for each resulting package to the output directory
+func runImport(c *cli.Context, imp importer.Importer, usage string) error {
+	if c.NArg() < 2 {
+		log.Errorf("Please specify input and output paths. %s", usage)
+		return nil
+	}
+	inputs := c.Args()[:c.NArg()-1]
+	output, err := filepath.Abs(c.Args()[c.NArg()-1])
+	if err != nil {
+		log.Errorf("Error reading output path: %s", err)
+		return nil
+	}
+
+	packages, warnings, err := imp.Import(inputs)
+	if err != nil {
+		log.Errorf("Error importing: %s", err)
+		return nil
+	}
+	for _, w := range warnings {
+		log.Warn(w)
+	}
+	if !prepareOutput(output) {
+		return nil
+	}
+
+	var names []string
+	for _, i := range inputs {
+		names = append(names, filepath.Base(i))
+	}
+	var written []string
+	for i := range packages {
+		pkg := &packages[i]
+		data := importer.Format(pkg, "Imported from "+strings.Join(names, ", "))
+		if err := writeOutput(output, pkg.Name+".lud", data); err != nil {
+			return nil
+		}
+		written = append(written, filepath.Join(output, pkg.Name+".lud"))
+	}
+
+	// Imported definitions are loaded back, so problems surface before
+	// they are compiled
+	if ProcessFiles(written) == nil {
+		return nil
+	}
+
+	if len(warnings) > 0 {
+		log.Warnf("Succeeded with %d warnings. Please review the definitions above.", len(warnings))
+		return nil
+	}
+	log.Info("Succeeded")
+	return nil
+}
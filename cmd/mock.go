@@ -0,0 +1,218 @@
+package cmd
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/rand"
+	"net"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/logrusorgru/aurora"
+	log "github.com/sirupsen/logrus"
+	"github.com/urfave/cli"
+
+	"github.com/ludwieg/ludco/codec"
+	"github.com/ludwieg/ludco/instances"
+	"github.com/ludwieg/ludco/models"
+)
+
+// mockRule determines how the mock server replies to a given package
+type mockRule struct {
+	// Request holds the name or identifier of the package being replied, or
+	// "*" to match any package
+	Request string `json:"request"`
+
+	// Response holds the name or identifier of the package sent as reply.
+	// When empty, no reply is sent.
+	Response string `json:"response"`
+
+	// Template holds values for the response, using the same representation
+	// of JSON outputs
+	Template map[string]interface{} `json:"template"`
+
+	// Random determines whether fields absent from the template are filled
+	// with random values
+	Random bool `json:"random"`
+
+	request  *models.Package
+	response *models.Package
+	template codec.Object
+}
+
+type mockRules struct {
+	Rules []*mockRule `json:"rules"`
+}
+
+type mockServer struct {
+	packages models.PackageList
+	rules    []*mockRule
+
+	rndLock sync.Mutex
+	rnd     *rand.Rand
+}
+
+func loadMockRules(path string, packages models.PackageList) ([]*mockRule, error) {
+	fd, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer fd.Close()
+
+	var rules mockRules
+	decoder := json.NewDecoder(fd)
+	decoder.UseNumber()
+	if err := decoder.Decode(&rules); err != nil {
+		return nil, err
+	}
+
+	for i, r := range rules.Rules {
+		if r.Request != "*" {
+			if r.request = findPackage(packages, r.Request); r.request == nil {
+				return nil, fmt.Errorf("rule %d: unknown request package %s", i, r.Request)
+			}
+		}
+		if r.Response == "" {
+			if r.Template != nil || r.Random {
+				return nil, fmt.Errorf("rule %d: template and random require a response package", i)
+			}
+			continue
+		}
+		if r.response = findPackage(packages, r.Response); r.response == nil {
+			return nil, fmt.Errorf("rule %d: unknown response package %s", i, r.Response)
+		}
+		if r.template, err = codec.FromJSON(r.response, r.Template); err != nil {
+			return nil, fmt.Errorf("rule %d: %s", i, err)
+		}
+	}
+	return rules.Rules, nil
+}
+
+// reply builds the response to a message, returning nil when no reply must be
+// sent
+func (m *mockServer) reply(msg *codec.Message) ([]byte, error) {
+	var rule *mockRule
+	for _, r := range m.rules {
+		if r.request == nil || r.request.Identifier == msg.Package.Identifier {
+			rule = r
+			break
+		}
+	}
+	if rule == nil || rule.response == nil {
+		return nil, nil
+	}
+
+	obj := codec.Object{}
+	if rule.Random {
+		m.rndLock.Lock()
+		obj = instances.Random(rule.response, m.rnd)
+		m.rndLock.Unlock()
+	}
+	for k, v := range rule.template {
+		obj[k] = v
+	}
+	return codec.Encode(rule.response, msg.MessageID, obj)
+}
+
+func (m *mockServer) serve(conn net.Conn) {
+	defer conn.Close()
+	logger := log.WithField("client", conn.RemoteAddr())
+	logger.Info("Connection accepted")
+	reader := bufio.NewReader(conn)
+
+	for {
+		frame, err := codec.ReadFrame(reader)
+		if err == io.EOF {
+			logger.Info("Connection closed")
+			return
+		} else if err != nil {
+			logger.Errorf("Error reading message: %s", err)
+			return
+		}
+
+		msg, _, err := codec.Decode(m.packages, frame)
+		if err != nil {
+			logger.Errorf("Error decoding message: %s", err)
+			continue
+		}
+		printMessage(aurora.Blue("→").String(), msg)
+
+		response, err := m.reply(msg)
+		if err != nil {
+			logger.Errorf("Error building response to %s: %s", msg.Package.Name, err)
+			continue
+		}
+		if response == nil {
+			continue
+		}
+		if _, err := conn.Write(response); err != nil {
+			logger.Errorf("Error writing response: %s", err)
+			return
+		}
+		if sent, _, err := codec.Decode(m.packages, response); err == nil {
+			printMessage(aurora.Green("←").String(), sent)
+		}
+	}
+}
+
+var Mock = cli.Command{
+	Name:      "mock",
+	Usage:     "Runs a local server that replies to Ludwieg packages",
+	ArgsUsage: "<input>",
+	Flags: []cli.Flag{
+		cli.StringFlag{
+			Name:  "listen",
+			Value: ":9000",
+			Usage: "Address to listen for connections",
+		},
+		cli.StringFlag{
+			Name:  "rules",
+			Usage: "Path to a JSON file describing how to reply to received packages. When omitted, packages are only logged",
+		},
+		cli.Int64Flag{
+			Name:  "seed",
+			Usage: "Seed used when generating random responses",
+		},
+	},
+	Action: func(c *cli.Context) error {
+		allPackages := loadProject(c.Args().First())
+		if allPackages == nil {
+			return nil
+		}
+
+		server := &mockServer{packages: allPackages}
+		if path := c.String("rules"); path != "" {
+			rules, err := loadMockRules(path, allPackages)
+			if err != nil {
+				log.Errorf("Error loading rules: %s", err)
+				return nil
+			}
+			server.rules = rules
+		}
+		seed := c.Int64("seed")
+		if !c.IsSet("seed") {
+			seed = time.Now().UnixNano()
+		}
+		server.rnd = rand.New(rand.NewSource(seed))
+
+		listener, err := net.Listen("tcp", c.String("listen"))
+		if err != nil {
+			log.Errorf("Error: %s", err)
+			return nil
+		}
+		defer listener.Close()
+		log.Infof("Listening on %s", aurora.Magenta(listener.Addr()))
+
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				log.Errorf("Error accepting connection: %s", err)
+				return nil
+			}
+			go server.serve(conn)
+		}
+	},
+}
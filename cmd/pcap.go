@@ -0,0 +1,237 @@
+package cmd
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/logrusorgru/aurora"
+	log "github.com/sirupsen/logrus"
+	"github.com/urfave/cli"
+
+	"github.com/ludwieg/ludco/codec"
+	"github.com/ludwieg/ludco/models"
+	"github.com/ludwieg/ludco/pcap"
+)
+
+// pcapStream holds data of a flow not yet decoded
+type pcapStream struct {
+	buf []byte
+
+	// offset holds the position of buf on the stream
+	offset int64
+
+	// skipped holds the amount of bytes discarded while looking for the next
+	// message
+	skipped int
+}
+
+// pcapDecoder splits reassembled flows into messages
+type pcapDecoder struct {
+	reporter
+	packages models.PackageList
+	streams  map[string]*pcapStream
+	last     time.Time
+}
+
+func (d *pcapDecoder) stream(f pcap.Flow) *pcapStream {
+	s, ok := d.streams[f.String()]
+	if !ok {
+		s = &pcapStream{}
+		d.streams[f.String()] = s
+	}
+	return s
+}
+
+func (d *pcapDecoder) fail(f pcap.Flow, t time.Time, offset int64, reason string) {
+	d.emit(f, t, offset, nil, &codec.DecodeError{Offset: int(offset), Reason: reason})
+}
+
+func (d *pcapDecoder) emit(f pcap.Flow, t time.Time, offset int64, msg *codec.Message, err error) {
+	title := fmt.Sprintf("%s %s %s", aurora.Gray(t.Format(time.RFC3339Nano)), aurora.Blue(f), aurora.Gray(fmt.Sprintf("@%d", offset)))
+	d.report(title, map[string]interface{}{
+		"time":        t.Format(time.RFC3339Nano),
+		"source":      f.Src.String(),
+		"destination": f.Dst.String(),
+		"offset":      offset,
+	}, msg, err)
+}
+
+// flushSkipped reports bytes discarded while looking for a message
+func (d *pcapDecoder) flushSkipped(f pcap.Flow, s *pcapStream) {
+	if s.skipped == 0 {
+		return
+	}
+	d.fail(f, d.last, s.offset-int64(s.skipped), fmt.Sprintf("skipped %d bytes not belonging to a message", s.skipped))
+	s.skipped = 0
+}
+
+// discard removes n bytes from the beginning of the buffer
+func (s *pcapStream) discard(n int) {
+	s.buf = s.buf[n:]
+	s.offset += int64(n)
+}
+
+func (d *pcapDecoder) Data(f pcap.Flow, data []byte, t time.Time) {
+	d.last = t
+	s := d.stream(f)
+	s.buf = append(s.buf, data...)
+
+	for len(s.buf) > 0 {
+		if idx := bytes.Index(s.buf, codec.Magic); idx != 0 {
+			if idx < 0 {
+				// Keep bytes that may start a magic sequence split among
+				// segments
+				idx = len(s.buf) - (len(codec.Magic) - 1)
+				if idx <= 0 {
+					return
+				}
+			}
+			s.skipped += idx
+			s.discard(idx)
+			continue
+		}
+		d.flushSkipped(f, s)
+
+		msg, n, err := codec.Decode(d.packages, s.buf)
+		if err == codec.ErrShortBuffer {
+			// Messages declaring sizes beyond the limit would otherwise
+			// buffer the rest of the flow
+			if h, _, herr := codec.ParseHeader(s.buf); herr == nil && h.Size > codec.MaxMessageSize {
+				d.fail(f, t, s.offset, fmt.Sprintf("payload size %d exceeds the limit of %d bytes", h.Size, codec.MaxMessageSize))
+				s.discard(len(codec.Magic))
+				continue
+			}
+			return
+		}
+		if n == 0 {
+			// Invalid header; look for the next message
+			d.emit(f, t, s.offset, nil, offsetError(err, s.offset))
+			s.discard(len(codec.Magic))
+			continue
+		}
+		d.emit(f, t, s.offset, msg, offsetError(err, s.offset))
+		s.discard(n)
+	}
+}
+
+func (d *pcapDecoder) Gap(f pcap.Flow, n int) {
+	s := d.stream(f)
+	d.flushSkipped(f, s)
+	if len(s.buf) > 0 {
+		d.fail(f, d.last, s.offset, fmt.Sprintf("message interrupted by %d bytes missing from the capture", n))
+		s.discard(len(s.buf))
+	} else {
+		d.fail(f, d.last, s.offset, fmt.Sprintf("%d bytes missing from the capture", n))
+	}
+	s.offset += int64(n)
+}
+
+func (d *pcapDecoder) Reset(f pcap.Flow, n int) {
+	log.Warnf("%s: too much data captured out of order; skipping %d bytes", f, n)
+	d.Gap(f, n)
+}
+
+func (d *pcapDecoder) Close(f pcap.Flow) {
+	s := d.stream(f)
+	d.flushSkipped(f, s)
+	if len(s.buf) > 0 {
+		if bytes.HasPrefix(s.buf, codec.Magic) {
+			d.fail(f, d.last, s.offset, "stream ends in the middle of a message")
+		} else {
+			s.skipped += len(s.buf)
+			s.discard(len(s.buf))
+			d.flushSkipped(f, s)
+		}
+	}
+	delete(d.streams, f.String())
+}
+
+// offsetError converts offsets of decode errors to be relative to the stream
+func offsetError(err error, offset int64) error {
+	if e, ok := err.(*codec.DecodeError); ok {
+		e.Offset += int(offset)
+	}
+	return err
+}
+
+var Pcap = cli.Command{
+	Name:      "pcap",
+	Usage:     "Decodes Ludwieg packages exchanged on TCP connections captured in a pcap file",
+	ArgsUsage: "<file> <input>",
+	Flags: []cli.Flag{
+		cli.IntFlag{
+			Name:  "port",
+			Value: 9000,
+			Usage: "TCP port used by the server",
+		},
+		cli.BoolFlag{
+			Name:  "json",
+			Usage: "Writes decoded packages to the stdout as JSON objects, one per line",
+		},
+	},
+	Action: func(c *cli.Context) error {
+		if len(c.Args()) != 2 {
+			log.Errorf("Error: pcap requires a pcap file and an input directory")
+			return nil
+		}
+		allPackages := loadProject(c.Args().Get(1))
+		if allPackages == nil {
+			return nil
+		}
+
+		fd, err := os.Open(c.Args().First())
+		if err != nil {
+			log.Errorf("Error: %s", err)
+			return nil
+		}
+		defer fd.Close()
+		r, err := pcap.NewReader(bufio.NewReader(fd))
+		if err != nil {
+			log.Errorf("Error: %s", err)
+			return nil
+		}
+
+		d := &pcapDecoder{packages: allPackages, streams: map[string]*pcapStream{}}
+		if c.Bool("json") {
+			d.json = json.NewEncoder(os.Stdout)
+		}
+		assembler := pcap.NewAssembler(d)
+		port := uint16(c.Int("port"))
+
+		truncated := 0
+		for {
+			p, err := r.Next()
+			if err == io.EOF {
+				break
+			} else if err != nil {
+				log.Errorf("Error reading pcap file: %s", err)
+				break
+			}
+			seg, err := pcap.ParseSegment(r.LinkType, p.Data)
+			if err == pcap.ErrNotTCP {
+				continue
+			} else if err != nil {
+				log.Errorf("Error: %s", err)
+				return nil
+			}
+			if seg.Src.Port != port && seg.Dst.Port != port {
+				continue
+			}
+			if p.Truncated {
+				truncated++
+			}
+			assembler.Add(seg, p.Time)
+		}
+		assembler.Flush()
+
+		if truncated > 0 {
+			log.Warnf("%d packets were truncated by the capture; increase its snapshot length to decode them completely", truncated)
+		}
+		return nil
+	},
+}
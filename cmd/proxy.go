@@ -0,0 +1,172 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/logrusorgru/aurora"
+	log "github.com/sirupsen/logrus"
+	"github.com/urfave/cli"
+
+	"github.com/ludwieg/ludco/capture"
+	"github.com/ludwieg/ludco/codec"
+	"github.com/ludwieg/ludco/models"
+)
+
+type proxy struct {
+	reporter
+	packages models.PackageList
+	upstream string
+
+	// capture receives every package forwarded, when recording
+	capture     *capture.Writer
+	captureLock sync.Mutex
+}
+
+// record writes a forwarded package to the capture file, if any
+func (p *proxy) record(conn int, direction capture.Direction, data []byte) {
+	if p.capture == nil || data == nil {
+		return
+	}
+	p.captureLock.Lock()
+	defer p.captureLock.Unlock()
+	err := p.capture.Write(capture.Record{
+		Time:       time.Now(),
+		Connection: uint32(conn),
+		Direction:  direction,
+		Data:       data,
+	})
+	if err != nil {
+		log.WithField("connection", conn).Errorf("Error writing capture: %s", err)
+	}
+}
+
+// forward copies src to dst, decoding every message passing through it
+func (p *proxy) forward(conn int, direction capture.Direction, src, dst net.Conn) {
+	scanner := codec.NewScanner(io.TeeReader(src, dst), p.packages)
+	err := scanner.Each(func(msg *codec.Message, offset int64, err error) {
+		p.record(conn, direction, scanner.Bytes())
+		title := fmt.Sprintf("#%d %s %s", conn, aurora.Blue(direction), aurora.Gray(fmt.Sprintf("@%d", offset)))
+		p.report(title, map[string]interface{}{
+			"time":       time.Now().Format(time.RFC3339Nano),
+			"connection": conn,
+			"direction":  direction.String(),
+			"offset":     offset,
+		}, msg, err)
+	})
+	if err != nil {
+		// Connection failures interrupt both directions
+		log.WithField("connection", conn).Errorf("%s: %s", direction, err)
+		src.Close()
+		dst.Close()
+		return
+	}
+
+	// Propagate the end of the stream, allowing the other direction to
+	// finish
+	if tcp, ok := dst.(*net.TCPConn); ok {
+		tcp.CloseWrite()
+	} else {
+		dst.Close()
+	}
+}
+
+func (p *proxy) serve(id int, client net.Conn) {
+	defer client.Close()
+	logger := log.WithField("connection", id)
+	logger.Infof("Connection accepted from %s", client.RemoteAddr())
+
+	upstream, err := net.Dial("tcp", p.upstream)
+	if err != nil {
+		logger.Errorf("Error connecting to upstream: %s", err)
+		return
+	}
+	defer upstream.Close()
+
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		p.forward(id, capture.ClientToServer, client, upstream)
+	}()
+	go func() {
+		defer wg.Done()
+		p.forward(id, capture.ServerToClient, upstream, client)
+	}()
+	wg.Wait()
+	logger.Info("Connection closed")
+}
+
+// listen accepts connections on the address provided through --listen,
+// forwarding them until the listener fails
+func (p *proxy) listen(c *cli.Context) {
+	listener, err := net.Listen("tcp", c.String("listen"))
+	if err != nil {
+		log.Errorf("Error: %s", err)
+		return
+	}
+	defer listener.Close()
+	log.Infof("Forwarding %s to %s", aurora.Magenta(listener.Addr()), aurora.Magenta(p.upstream))
+
+	for id := 1; ; id++ {
+		conn, err := listener.Accept()
+		if err != nil {
+			log.Errorf("Error accepting connection: %s", err)
+			return
+		}
+		go p.serve(id, conn)
+	}
+}
+
+// newProxy validates flags shared by commands forwarding connections,
+// returning nil when the project cannot be used
+func newProxy(c *cli.Context) *proxy {
+	if c.String("upstream") == "" {
+		log.Errorf("Error: --upstream is required")
+		return nil
+	}
+	allPackages := loadProject(c.Args().First())
+	if allPackages == nil {
+		return nil
+	}
+
+	p := &proxy{packages: allPackages, upstream: c.String("upstream")}
+	if c.Bool("json") {
+		p.json = json.NewEncoder(os.Stdout)
+	}
+	return p
+}
+
+var proxyFlags = []cli.Flag{
+	cli.StringFlag{
+		Name:  "listen",
+		Value: ":9001",
+		Usage: "Address to listen for connections",
+	},
+	cli.StringFlag{
+		Name:  "upstream",
+		Usage: "Address of the server receiving forwarded connections, as host:port",
+	},
+	cli.BoolFlag{
+		Name:  "json",
+		Usage: "Writes decoded packages to the stdout as JSON objects, one per line",
+	},
+}
+
+var Proxy = cli.Command{
+	Name:      "proxy",
+	Usage:     "Forwards TCP connections to a server, decoding Ludwieg packages exchanged through them",
+	ArgsUsage: "<input>",
+	Flags:     proxyFlags,
+	Action: func(c *cli.Context) error {
+		if p := newProxy(c); p != nil {
+			p.listen(c)
+		}
+		return nil
+	},
+}
@@ -0,0 +1,44 @@
+package cmd
+
+import (
+	"os"
+
+	"github.com/logrusorgru/aurora"
+	log "github.com/sirupsen/logrus"
+	"github.com/urfave/cli"
+
+	"github.com/ludwieg/ludco/capture"
+)
+
+var Record = cli.Command{
+	Name:      "record",
+	Usage:     "Forwards TCP connections to a server, recording Ludwieg packages exchanged through them",
+	ArgsUsage: "<input>",
+	Flags: append([]cli.Flag{
+		cli.StringFlag{
+			Name:  "output",
+			Value: "session.ludcap",
+			Usage: "Path of the capture file",
+		},
+	}, proxyFlags...),
+	Action: func(c *cli.Context) error {
+		p := newProxy(c)
+		if p == nil {
+			return nil
+		}
+
+		fd, err := os.Create(c.String("output"))
+		if err != nil {
+			log.Errorf("Error creating capture file: %s", err)
+			return nil
+		}
+		defer fd.Close()
+		if p.capture, err = capture.NewWriter(fd, p.packages.Fingerprint()); err != nil {
+			log.Errorf("Error writing capture file: %s", err)
+			return nil
+		}
+		log.Infof("Recording to %s", aurora.Magenta(c.String("output")))
+		p.listen(c)
+		return nil
+	},
+}
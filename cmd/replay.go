@@ -0,0 +1,160 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"time"
+
+	"github.com/logrusorgru/aurora"
+	log "github.com/sirupsen/logrus"
+	"github.com/urfave/cli"
+
+	"github.com/ludwieg/ludco/capture"
+	"github.com/ludwieg/ludco/codec"
+	"github.com/ludwieg/ludco/models"
+)
+
+// replayConn holds a connection opened to replay a captured session
+type replayConn struct {
+	net.Conn
+	done chan struct{}
+}
+
+// receive decodes responses sent by the target until it closes the connection
+func (r *replayConn) receive(id uint32, packages models.PackageList, rep *reporter) {
+	defer close(r.done)
+	codec.NewScanner(r, packages).Each(func(msg *codec.Message, offset int64, err error) {
+		rep.report(fmt.Sprintf("#%d %s %s", id, aurora.Green(capture.ServerToClient), aurora.Gray(fmt.Sprintf("@%d", offset))), map[string]interface{}{
+			"time":       time.Now().Format(time.RFC3339Nano),
+			"connection": id,
+			"direction":  capture.ServerToClient.String(),
+			"offset":     offset,
+		}, msg, err)
+	})
+}
+
+var Replay = cli.Command{
+	Name:      "replay",
+	Usage:     "Sends packages recorded in a capture file to a server",
+	ArgsUsage: "<input> <capture>",
+	Flags: []cli.Flag{
+		cli.StringFlag{
+			Name:  "target",
+			Usage: "Address of the server receiving packages, as host:port",
+		},
+		cli.BoolFlag{
+			Name:  "realtime",
+			Usage: "Preserves the interval between recorded packages",
+		},
+		cli.DurationFlag{
+			Name:  "wait",
+			Value: 2 * time.Second,
+			Usage: "Time to wait for responses after all packages are sent",
+		},
+		cli.BoolFlag{
+			Name:  "json",
+			Usage: "Writes packages sent and received to the stdout as JSON objects, one per line",
+		},
+	},
+	Action: func(c *cli.Context) error {
+		if len(c.Args()) != 2 {
+			log.Errorf("Error: replay requires an input directory and a capture file")
+			return nil
+		}
+		if c.String("target") == "" {
+			log.Errorf("Error: --target is required")
+			return nil
+		}
+		allPackages := loadProject(c.Args().First())
+		if allPackages == nil {
+			return nil
+		}
+
+		fd, err := os.Open(c.Args().Get(1))
+		if err != nil {
+			log.Errorf("Error: %s", err)
+			return nil
+		}
+		defer fd.Close()
+		r, err := capture.NewReader(fd)
+		if err != nil {
+			log.Errorf("Error reading capture: %s", err)
+			return nil
+		}
+		checkFingerprint(r, allPackages)
+
+		rep := &reporter{}
+		if c.Bool("json") {
+			rep.json = json.NewEncoder(os.Stdout)
+		}
+
+		// Connections are opened as sessions appear on the capture, and
+		// closed after all packages are sent
+		conns := map[uint32]*replayConn{}
+		order := []*replayConn{}
+		defer func() {
+			for _, conn := range order {
+				if tcp, ok := conn.Conn.(*net.TCPConn); ok {
+					tcp.CloseWrite()
+				}
+			}
+			timeout := time.After(c.Duration("wait"))
+			for _, conn := range order {
+				select {
+				case <-conn.done:
+				case <-timeout:
+				}
+				conn.Close()
+			}
+		}()
+
+		var last time.Time
+		sent := 0
+		for {
+			rec, err := r.Next()
+			if err == io.EOF {
+				break
+			} else if err != nil {
+				log.Errorf("Error reading capture: %s", err)
+				return nil
+			}
+			if rec.Direction != capture.ClientToServer {
+				continue
+			}
+			if c.Bool("realtime") && !last.IsZero() {
+				time.Sleep(rec.Time.Sub(last))
+			}
+			last = rec.Time
+
+			conn, ok := conns[rec.Connection]
+			if !ok {
+				nc, err := net.Dial("tcp", c.String("target"))
+				if err != nil {
+					log.Errorf("Error connecting to target: %s", err)
+					return nil
+				}
+				conn = &replayConn{Conn: nc, done: make(chan struct{})}
+				conns[rec.Connection] = conn
+				order = append(order, conn)
+				go conn.receive(rec.Connection, allPackages, rep)
+			}
+
+			if _, err := conn.Write(rec.Data); err != nil {
+				log.WithField("connection", rec.Connection).Errorf("Error sending package: %s", err)
+				return nil
+			}
+			sent++
+			msg, _, err := codec.Decode(allPackages, rec.Data)
+			rep.report(fmt.Sprintf("#%d %s", rec.Connection, aurora.Blue(capture.ClientToServer)), map[string]interface{}{
+				"time":       time.Now().Format(time.RFC3339Nano),
+				"connection": rec.Connection,
+				"direction":  capture.ClientToServer.String(),
+			}, msg, err)
+		}
+		log.Infof("Sent %d packages over %d connections", sent, len(order))
+		return nil
+	},
+}
@@ -0,0 +1,153 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+
+	"github.com/logrusorgru/aurora"
+	log "github.com/sirupsen/logrus"
+	"github.com/urfave/cli"
+
+	"github.com/ludwieg/ludco/codec"
+	"github.com/ludwieg/ludco/models"
+)
+
+// testExample encodes an example, compares it against its golden file, and
+// decodes it back, returning a description of the first problem found
+func testExample(packages models.PackageList, pkg *models.Package, e models.Example, golden string, update bool) string {
+	obj, err := codec.FromExample(pkg, e)
+	if err != nil {
+		return err.Error()
+	}
+	data, err := codec.Encode(pkg, 0, obj)
+	if err != nil {
+		return fmt.Sprintf("encoding failed: %s", err)
+	}
+
+	if update {
+		if err := ioutil.WriteFile(golden, data, 0644); err != nil {
+			return fmt.Sprintf("writing %s failed: %s", filepath.Base(golden), err)
+		}
+	} else if expected, err := ioutil.ReadFile(golden); err == nil {
+		if !bytes.Equal(expected, data) {
+			offset := 0
+			for offset < len(data) && offset < len(expected) && data[offset] == expected[offset] {
+				offset++
+			}
+			return fmt.Sprintf("encoding differs from %s at offset %d", filepath.Base(golden), offset)
+		}
+	} else if !os.IsNotExist(err) {
+		return fmt.Sprintf("reading %s failed: %s", filepath.Base(golden), err)
+	}
+
+	msg, n, err := codec.Decode(packages, data)
+	if err != nil {
+		return fmt.Sprintf("decoding failed: %s", err)
+	}
+	if n != len(data) {
+		return fmt.Sprintf("decoding consumed %d out of %d bytes", n, len(data))
+	}
+	original, err := codec.ToJSON(pkg, obj)
+	if err != nil {
+		return err.Error()
+	}
+	decoded, err := codec.ToJSON(msg.Package, msg.Value)
+	if err != nil {
+		return err.Error()
+	}
+	if path := firstDifference(original, decoded, pkg.Name); path != "" {
+		return fmt.Sprintf("decoded value differs at %s", path)
+	}
+	return ""
+}
+
+// firstDifference compares two values produced by codec.ToJSON, returning the
+// path of the first difference found, or an empty string
+func firstDifference(a, b interface{}, path string) string {
+	switch av := a.(type) {
+	case map[string]interface{}:
+		bv, ok := b.(map[string]interface{})
+		if !ok || len(av) != len(bv) {
+			return path
+		}
+		keys := []string{}
+		for k := range av {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			if p := firstDifference(av[k], bv[k], path+"."+k); p != "" {
+				return p
+			}
+		}
+		return ""
+	case []interface{}:
+		bv, ok := b.([]interface{})
+		if !ok || len(av) != len(bv) {
+			return path
+		}
+		for i := range av {
+			if p := firstDifference(av[i], bv[i], fmt.Sprintf("%s[%d]", path, i)); p != "" {
+				return p
+			}
+		}
+		return ""
+	}
+	if !reflect.DeepEqual(a, b) {
+		return path
+	}
+	return ""
+}
+
+var Test = cli.Command{
+	Name:      "test",
+	Aliases:   []string{"t"},
+	Usage:     "Verifies examples declared on Ludwieg packages",
+	ArgsUsage: "<input>",
+	Flags: []cli.Flag{
+		cli.BoolFlag{
+			Name:  "update",
+			Usage: "Writes the golden file of every example, replacing existing ones",
+		},
+	},
+	Action: func(c *cli.Context) error {
+		input := c.Args().First()
+		allPackages := loadProject(input)
+		if allPackages == nil {
+			return nil
+		}
+
+		passed, failed := 0, 0
+		fmt.Println()
+		for i := range allPackages {
+			pkg := &allPackages[i]
+			for _, e := range pkg.Examples {
+				name := fmt.Sprintf("%s/%s", pkg.Name, e.Name)
+				golden := filepath.Join(input, fmt.Sprintf("%s.%s.bin", pkg.Name, e.Name))
+				if problem := testExample(allPackages, pkg, e, golden, c.Bool("update")); problem != "" {
+					fmt.Printf("%s  %s: %s\n", aurora.Red("FAIL"), name, problem)
+					failed++
+				} else {
+					fmt.Printf("%s  %s\n", aurora.Green("PASS"), name)
+					passed++
+				}
+			}
+		}
+
+		if passed+failed == 0 {
+			log.Warn("No examples were found.")
+			return nil
+		}
+		fmt.Println()
+		log.Infof("%d passed, %d failed", passed, failed)
+		if failed > 0 {
+			os.Exit(1)
+		}
+		return nil
+	},
+}
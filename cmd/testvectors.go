@@ -0,0 +1,104 @@
+package cmd
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/urfave/cli"
+
+	"github.com/ludwieg/ludco/codec"
+	"github.com/ludwieg/ludco/instances"
+)
+
+type vectorManifest struct {
+	ProtocolVersion byte          `json:"protocol_version"`
+	Vectors         []vectorEntry `json:"vectors"`
+}
+
+type vectorEntry struct {
+	Package    string                 `json:"package"`
+	Identifier string                 `json:"id"`
+	Name       string                 `json:"name"`
+	MessageID  byte                   `json:"message_id"`
+	File       string                 `json:"file"`
+	Hex        string                 `json:"hex"`
+	Value      map[string]interface{} `json:"value"`
+}
+
+var TestVectors = cli.Command{
+	Name:      "testvectors",
+	Aliases:   []string{"tv"},
+	Usage:     "Generates conformance test vectors for a Ludwieg project",
+	ArgsUsage: "<input> <output>",
+	Action: func(c *cli.Context) error {
+		if c.NArg() != 2 {
+			log.Errorf("Please specify input and output paths. ludco testvectors <input> <output>")
+			return nil
+		}
+		output, err := filepath.Abs(c.Args()[1])
+		if err != nil {
+			log.Errorf("Error reading output path: %s", err)
+			return nil
+		}
+
+		allPackages := loadProject(c.Args()[0])
+		if allPackages == nil || !prepareOutput(output) {
+			return nil
+		}
+
+		manifest := vectorManifest{
+			ProtocolVersion: codec.ProtocolVersion,
+			Vectors:         []vectorEntry{},
+		}
+
+		for _, pkg := range allPackages {
+			if err := os.MkdirAll(filepath.Join(output, pkg.Name), 0700); err != nil {
+				log.Errorf("Error: %s", err)
+				return nil
+			}
+
+			for i, vector := range instances.Vectors(&pkg) {
+				messageID := byte(i + 1)
+				data, err := codec.Encode(&pkg, messageID, vector.Value)
+				if err != nil {
+					log.Errorf("BUG: Error encoding vector %s of %s: %s", vector.Name, pkg.Name, err)
+					return nil
+				}
+				value, err := codec.ToJSON(&pkg, vector.Value)
+				if err != nil {
+					log.Errorf("BUG: Error converting vector %s of %s: %s", vector.Name, pkg.Name, err)
+					return nil
+				}
+
+				file := filepath.Join(pkg.Name, vector.Name+".bin")
+				if err := writeOutput(output, file, data); err != nil {
+					return nil
+				}
+				manifest.Vectors = append(manifest.Vectors, vectorEntry{
+					Package:    pkg.Name,
+					Identifier: pkg.Identifier,
+					Name:       vector.Name,
+					MessageID:  messageID,
+					File:       filepath.ToSlash(file),
+					Hex:        hex.EncodeToString(data),
+					Value:      value,
+				})
+			}
+		}
+
+		data, err := json.MarshalIndent(manifest, "", "  ")
+		if err != nil {
+			log.Errorf("BUG: Error encoding manifest: %s", err)
+			return nil
+		}
+		if err := writeOutput(output, "manifest.json", append(data, '\n')); err != nil {
+			return nil
+		}
+
+		log.Infof("Generated %d vectors for %d packages", len(manifest.Vectors), len(allPackages))
+		return nil
+	},
+}
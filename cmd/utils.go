@@ -1,10 +1,14 @@
 package cmd
 
 import (
+	"fmt"
+	"io/ioutil"
 	"os"
 	"path/filepath"
 	"sort"
+	"strings"
 
+	"github.com/logrusorgru/aurora"
 	log "github.com/sirupsen/logrus"
 
 	"github.com/ludwieg/ludco/models"
@@ -49,7 +53,15 @@ func ProcessFiles(toProcess []string) models.PackageList {
 						logger.Errorf("Unexpected error analysing %s (%s): %s", pkg.Name, f, err)
 					}
 				}()
-				allPackages = append(allPackages, *models.ConvertASTPackage(pkg))
+				converted := models.ConvertASTPackage(pkg)
+				if errs := validation.ValidateExamples(converted); len(errs) > 0 {
+					logger.Warn("Found problems validating examples:")
+					for _, err := range errs {
+						logger.Errorf("error: %s", err)
+					}
+					return nil
+				}
+				allPackages = append(allPackages, *converted)
 			}
 		}
 	}
@@ -68,3 +80,76 @@ func ProcessFiles(toProcess []string) models.PackageList {
 
 	return allPackages
 }
+
+// loadProject enumerates and processes all definition files found on the
+// input directory. Problems are printed to the stdout, and nil is returned.
+func loadProject(input string) models.PackageList {
+	if input == "" {
+		log.Errorf("Please specify the project path.")
+		return nil
+	}
+	stat, err := os.Stat(input)
+	if err != nil {
+		if os.IsNotExist(err) {
+			log.Errorf("Input path %s does not exist.", input)
+		} else {
+			log.Errorf("Error: %s", err)
+		}
+		return nil
+	}
+	if !stat.IsDir() {
+		log.Errorf("Input path is not a directory.")
+		return nil
+	}
+
+	glob, err := filepath.Glob(input + "/*.lud")
+	if err != nil {
+		log.Errorf("Error enumerating files: %s", err)
+		return nil
+	}
+	return ProcessFiles(glob)
+}
+
+// prepareOutput ensures the output path exists and is a directory, creating it
+// when needed. Problems are printed to the stdout, and false is returned.
+func prepareOutput(output string) bool {
+	stat, err := os.Stat(output)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			log.Errorf("Error: %s", err)
+			return false
+		}
+		if err = os.MkdirAll(output, 0700); err != nil {
+			log.Errorf("Error: %s", err)
+			return false
+		}
+		return true
+	}
+	if !stat.IsDir() {
+		log.Errorf("%s already exists and is not a directory.", output)
+		return false
+	}
+	return true
+}
+
+// writeOutput writes a file relative to the output directory, logging its
+// progress. Failures are logged and returned.
+func writeOutput(output, name string, contents []byte) error {
+	log.Infof("Writing %s", aurora.Magenta(fmt.Sprintf("%s/%s", filepath.Base(output), filepath.ToSlash(name))))
+	err := ioutil.WriteFile(filepath.Join(output, name), contents, 0644)
+	if err != nil {
+		log.Errorf("Operation failed: %s", err)
+	}
+	return err
+}
+
+// findPackage looks up a package by its name or identifier, returning nil when
+// it cannot be found
+func findPackage(packages models.PackageList, name string) *models.Package {
+	for i := range packages {
+		if packages[i].Name == name || strings.EqualFold(packages[i].Identifier, name) {
+			return &packages[i]
+		}
+	}
+	return nil
+}
@@ -0,0 +1,169 @@
+package cmd
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sync"
+
+	"github.com/disiqueira/gotree"
+	"github.com/logrusorgru/aurora"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/ludwieg/ludco/codec"
+	"github.com/ludwieg/ludco/models"
+)
+
+// maxBlobPreview limits how many bytes of a blob are printed
+const maxBlobPreview = 32
+
+// printLock serializes trees printed by concurrent connections
+var printLock sync.Mutex
+
+// printMessage prints a decoded message using the same notation of the show
+// command
+func printMessage(title string, msg *codec.Message) {
+	var tree gotree.GTStructure
+	tree.Name = fmt.Sprintf("%s %s (%s) message %d", title, aurora.Bold(msg.Package.Name), aurora.Gray(msg.Package.Identifier), msg.MessageID)
+	valueFields(&tree, msg.Package.Scope(), msg.Package.Fields, msg.Value)
+	if len(tree.Items) == 0 {
+		var empty gotree.GTStructure
+		empty.Name = aurora.Gray("(Empty Package)").String()
+		tree.Items = append(tree.Items, empty)
+	}
+
+	printLock.Lock()
+	defer printLock.Unlock()
+	gotree.PrintTree(tree)
+	fmt.Println()
+}
+
+func valueFields(tree *gotree.GTStructure, s *models.Scope, fields []models.Field, obj codec.Object) {
+	for i, f := range fields {
+		var item gotree.GTStructure
+		item.Name = fmt.Sprintf("%s %s", aurora.Gray(fmt.Sprintf("[%d]", i)), f.Name)
+		v := obj[f.Name]
+
+		if f.IsArray() {
+			if items, ok := v.([]interface{}); ok {
+				item.Name += aurora.Gray(fmt.Sprintf(" (%d items)", len(items))).String()
+				for idx, el := range items {
+					var child gotree.GTStructure
+					child.Name = aurora.Gray(fmt.Sprintf("[%d]", idx)).String()
+					valueSingle(&child, s, f.Type, el)
+					item.Items = append(item.Items, child)
+				}
+			} else {
+				item.Name += ": " + describeValue(v)
+			}
+		} else {
+			valueSingle(&item, s, f.Type, v)
+		}
+
+		if f.HasAttribute(models.AttributeDeprecated) {
+			item.Name += fmt.Sprintf(" %s", aurora.Red("[Deprecated]"))
+		}
+		tree.Items = append(tree.Items, item)
+	}
+}
+
+func valueSingle(item *gotree.GTStructure, s *models.Scope, t models.Type, v interface{}) {
+	obj, isObject := v.(codec.Object)
+	if t.Source != models.SourceUser || !isObject {
+		item.Name += ": " + describeValue(v)
+		return
+	}
+	item.Name += " " + aurora.Green("@"+t.CustomType).String()
+	if str, inner, ok := s.Resolve(t.CustomType); ok {
+		valueFields(item, inner, str.Fields, obj)
+	}
+}
+
+// describeValue returns a human-readable representation of a native value
+func describeValue(v interface{}) string {
+	switch i := v.(type) {
+	case nil:
+		return aurora.Gray("(empty)").String()
+	case string:
+		return aurora.Brown(fmt.Sprintf("%q", i)).String()
+	case []byte:
+		preview := i
+		if len(preview) > maxBlobPreview {
+			preview = preview[:maxBlobPreview]
+		}
+		desc := "0x" + hex.EncodeToString(preview)
+		if len(preview) < len(i) {
+			desc += "..."
+		}
+		return aurora.Cyan(desc).String() + aurora.Gray(fmt.Sprintf(" (%d bytes)", len(i))).String()
+	case codec.UUID:
+		return aurora.Cyan(i.String()).String()
+	case []interface{}:
+		desc := "["
+		for idx, item := range i {
+			if idx > 0 {
+				desc += ", "
+			}
+			desc += describeValue(item)
+		}
+		return desc + "]" + aurora.Gray(" (array)").String()
+	}
+	desc := aurora.Cyan(fmt.Sprint(v)).String()
+	if t, ok := codec.AnyType(v); ok {
+		desc += aurora.Gray(fmt.Sprintf(" (%s)", t)).String()
+	}
+	return desc
+}
+
+// jsonMessage returns the representation of a message used by JSON outputs
+func jsonMessage(pkg *models.Package, messageID byte, obj codec.Object) (map[string]interface{}, error) {
+	value, err := codec.ToJSON(pkg, obj)
+	if err != nil {
+		return nil, err
+	}
+	return map[string]interface{}{
+		"package":    pkg.Name,
+		"id":         pkg.Identifier,
+		"message_id": messageID,
+		"value":      value,
+	}, nil
+}
+
+// reporter prints decoded messages either as trees or as JSON objects, one
+// per line
+type reporter struct {
+	json *json.Encoder
+}
+
+// report prints a decoded message, or the failure found decoding it. title
+// is used by trees, while context holds information included in JSON
+// objects, such as where the message was found.
+func (r *reporter) report(title string, context map[string]interface{}, msg *codec.Message, err error) {
+	if r.json == nil {
+		if err != nil {
+			log.Errorf("%s: Error decoding message: %s", title, err)
+			return
+		}
+		printMessage(title, msg)
+		return
+	}
+
+	entry := map[string]interface{}{}
+	if err == nil {
+		if entry, err = jsonMessage(msg.Package, msg.MessageID, msg.Value); err != nil {
+			entry = map[string]interface{}{}
+		}
+	}
+	if err != nil {
+		entry["error"] = err.Error()
+	}
+	for k, v := range context {
+		entry[k] = v
+	}
+
+	printLock.Lock()
+	defer printLock.Unlock()
+	if err := r.json.Encode(entry); err != nil {
+		log.Errorf("Error writing output: %s", err)
+	}
+}
@@ -0,0 +1,422 @@
+package codec
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/ludwieg/ludco/models"
+	"github.com/ludwieg/ludco/parser"
+)
+
+const testSource = `package sample {
+    id 0x05
+
+    uint8       small
+    uint32      medium
+    uint64      large
+    double      real
+    string      text
+    blob        raw
+    bool        flag
+    uuid        ident
+    dynint      counter
+    any         value
+    string[*]   names
+    @item[2]    items
+
+    struct item {
+        string      label
+        @item[*]    children
+    }
+}
+`
+
+var testUUID = UUID{0x32, 0x32, 0xEE, 0x42, 0xC2, 0xF2, 0x4B, 0xAF, 0x84, 0x13, 0x18, 0x33, 0x5B, 0x4D, 0x56, 0x40}
+
+func testPackage(t *testing.T) *models.Package {
+	out, err := parser.Parse("sample.lud", []byte(testSource))
+	if err != nil {
+		t.Fatalf("parsing test package: %s", err)
+	}
+	return models.ConvertASTPackage(out.([]interface{})[0].(parser.Package))
+}
+
+// complete returns obj holding nil for every field absent from it, as
+// decoded objects do
+func complete(fields []string, obj Object) Object {
+	result := Object{}
+	for _, f := range fields {
+		result[f] = obj[f]
+	}
+	return result
+}
+
+var packageFields = []string{"small", "medium", "large", "real", "text", "raw", "flag", "ident", "counter", "value", "names", "items"}
+var itemFields = []string{"label", "children"}
+
+func TestSizeWidths(t *testing.T) {
+	tests := []struct {
+		value    uint64
+		expected []byte
+	}{
+		{0, []byte{0x01, 0x00}},
+		{math.MaxUint8, []byte{0x01, 0xFF}},
+		{math.MaxUint8 + 1, []byte{0x02, 0x00, 0x01}},
+		{math.MaxUint16, []byte{0x02, 0xFF, 0xFF}},
+		{math.MaxUint16 + 1, []byte{0x04, 0x00, 0x00, 0x01, 0x00}},
+		{math.MaxUint32, []byte{0x04, 0xFF, 0xFF, 0xFF, 0xFF}},
+		{math.MaxUint32 + 1, []byte{0x08, 0x00, 0x00, 0x00, 0x00, 0x01, 0x00, 0x00, 0x00}},
+		{math.MaxUint64, []byte{0x08, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF}},
+	}
+	for _, tt := range tests {
+		encoded := appendSize(nil, tt.value)
+		if !bytes.Equal(encoded, tt.expected) {
+			t.Errorf("appendSize(%d) = % x, expected % x", tt.value, encoded, tt.expected)
+			continue
+		}
+		v, n, err := readSize(encoded)
+		if err != nil || v != tt.value || n != len(encoded) {
+			t.Errorf("readSize(% x) = %d, %d, %v", encoded, v, n, err)
+		}
+	}
+}
+
+func TestReadSizeErrors(t *testing.T) {
+	tests := []struct {
+		data  []byte
+		short bool
+	}{
+		{[]byte{}, true},
+		{[]byte{0x02, 0x00}, true},
+		{[]byte{0x08, 0x00, 0x00, 0x00}, true},
+		{[]byte{0x00}, false},
+		{[]byte{0x03, 0x00, 0x00, 0x00}, false},
+	}
+	for _, tt := range tests {
+		_, _, err := readSize(tt.data)
+		if err == nil {
+			t.Errorf("readSize(% x) succeeded", tt.data)
+		} else if (err == ErrShortBuffer) != tt.short {
+			t.Errorf("readSize(% x) = %v", tt.data, err)
+		}
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	p := testPackage(t)
+	tests := []struct {
+		name string
+		obj  Object
+	}{
+		{"empty", Object{}},
+		{"natives", Object{
+			"small":   uint8(27),
+			"medium":  uint32(math.MaxUint32),
+			"large":   uint64(math.MaxUint64),
+			"real":    30.2,
+			"text":    "Ludwieg: ação, 日本語, 🚀",
+			"raw":     []byte{0x27, 0x24, 0x50},
+			"flag":    true,
+			"ident":   testUUID,
+			"counter": DynInt(math.MaxUint16 + 1),
+		}},
+		{"zero", Object{
+			"small":   uint8(0),
+			"flag":    false,
+			"text":    "",
+			"raw":     []byte{},
+			"counter": DynInt(0),
+		}},
+		{"long", Object{
+			"text": strings.Repeat("L", math.MaxUint8+1),
+			"raw":  bytes.Repeat([]byte{0xFF}, math.MaxUint16+1),
+		}},
+		{"any_native", Object{"value": "Stringy!"}},
+		{"any_array", Object{"value": []interface{}{uint8(1), "two", nil, []interface{}{DynInt(3)}}}},
+		{"any_empty_array", Object{"value": []interface{}{}}},
+		{"arrays", Object{
+			"names": []interface{}{"a", nil, "c"},
+			"items": []interface{}{
+				complete(itemFields, Object{"label": "first"}),
+				nil,
+			},
+		}},
+		{"empty_arrays", Object{
+			"names": []interface{}{},
+			"items": []interface{}{},
+		}},
+		{"nested", Object{
+			"items": []interface{}{
+				complete(itemFields, Object{
+					"label": "parent",
+					"children": []interface{}{
+						complete(itemFields, Object{"label": "child", "children": []interface{}{}}),
+					},
+				}),
+			},
+		}},
+	}
+
+	for _, tt := range tests {
+		data, err := Encode(p, 0x2A, tt.obj)
+		if err != nil {
+			t.Errorf("%s: encoding: %s", tt.name, err)
+			continue
+		}
+		msg, n, err := Decode(models.PackageList{*p}, data)
+		if err != nil {
+			t.Errorf("%s: decoding: %s", tt.name, err)
+			continue
+		}
+		if n != len(data) {
+			t.Errorf("%s: decoded %d bytes out of %d", tt.name, n, len(data))
+		}
+		if msg.MessageID != 0x2A || msg.PackageID != 0x05 {
+			t.Errorf("%s: unexpected header %+v", tt.name, msg.Header)
+		}
+		expected := complete(packageFields, tt.obj)
+		if !reflect.DeepEqual(msg.Value, expected) {
+			t.Errorf("%s: decoded %#v, expected %#v", tt.name, msg.Value, expected)
+		}
+	}
+}
+
+func TestEmptyFlags(t *testing.T) {
+	p := testPackage(t)
+	data, err := Encode(p, 0x01, Object{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []byte{
+		0x27, 0x24, 0x50, 0x01, 0x01, 0x05, 0x01, 0x0C,
+		0x81, 0x82, 0x83, 0x84, 0x85, 0x86, 0x87, 0x88, 0x8C, 0x89, 0x8A, 0x8A,
+	}
+	if !bytes.Equal(data, expected) {
+		t.Errorf("encoded % x, expected % x", data, expected)
+	}
+
+	// An empty value held by an any field is flagged within it
+	data, err = Encode(p, 0x01, Object{"value": []interface{}{nil}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	anyArray := []byte{0x09, 0x0A, 0x01, 0x03, 0x01, 0x01, 0x89}
+	if !bytes.Contains(data, anyArray) {
+		t.Errorf("encoded % x, expected it to contain % x", data, anyArray)
+	}
+}
+
+func TestDecodeCompatibility(t *testing.T) {
+	p := testPackage(t)
+	tests := []struct {
+		name     string
+		payload  []byte
+		expected Object
+	}{
+		{"missing_trailing", []byte{0x01, 0x1B}, Object{"small": uint8(27)}},
+		{"unknown_trailing", []byte{
+			0x81, 0x82, 0x83, 0x84, 0x85, 0x86, 0x87, 0x88, 0x8C, 0x89, 0x8A, 0x8A,
+			0x05, 0x01, 0x02, 'h', 'i',
+			0x0B, 0x01, 0x02, 0x01, 0x00,
+		}, Object{}},
+	}
+	for _, tt := range tests {
+		data := append([]byte{0x27, 0x24, 0x50, 0x01, 0x01, 0x05}, appendSize(nil, uint64(len(tt.payload)))...)
+		data = append(data, tt.payload...)
+		msg, _, err := Decode(models.PackageList{*p}, data)
+		if err != nil {
+			t.Errorf("%s: %s", tt.name, err)
+			continue
+		}
+		expected := complete(packageFields, tt.expected)
+		if !reflect.DeepEqual(msg.Value, expected) {
+			t.Errorf("%s: decoded %#v, expected %#v", tt.name, msg.Value, expected)
+		}
+	}
+}
+
+func TestDecodeErrors(t *testing.T) {
+	p := testPackage(t)
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{"magic", []byte{0x27, 0x24, 0x51, 0x01, 0x01, 0x05, 0x01, 0x00}},
+		{"version", []byte{0x27, 0x24, 0x50, 0x02, 0x01, 0x05, 0x01, 0x00}},
+		{"package", []byte{0x27, 0x24, 0x50, 0x01, 0x01, 0x06, 0x01, 0x00}},
+		{"type", []byte{0x27, 0x24, 0x50, 0x01, 0x01, 0x05, 0x01, 0x02, 0x02, 0x00}},
+		{"bool", []byte{0x27, 0x24, 0x50, 0x01, 0x01, 0x05, 0x01, 0x08, 0x81, 0x82, 0x83, 0x84, 0x85, 0x86, 0x07, 0x02}},
+		{"boundaries", []byte{0x27, 0x24, 0x50, 0x01, 0x01, 0x05, 0x01, 0x08, 0x81, 0x82, 0x83, 0x84, 0x05, 0x01, 0x09, 'a'}},
+	}
+	for _, tt := range tests {
+		if _, _, err := Decode(models.PackageList{*p}, tt.data); err == nil || err == ErrShortBuffer {
+			t.Errorf("%s: expected a decode error, found %v", tt.name, err)
+		}
+	}
+
+	if _, _, err := Decode(models.PackageList{*p}, []byte{0x27, 0x24, 0x50, 0x01, 0x01, 0x05, 0x01, 0x02, 0x81}); err != ErrShortBuffer {
+		t.Errorf("truncated message: expected ErrShortBuffer, found %v", err)
+	}
+}
+
+func TestEncodeErrors(t *testing.T) {
+	p := testPackage(t)
+	tests := []struct {
+		name string
+		obj  Object
+		err  string
+	}{
+		{"unknown", Object{"missing": uint8(1)}, "unknown field `missing'"},
+		{"type", Object{"small": 1}, "sample.small: expected uint8"},
+		{"array", Object{"names": "a"}, "sample.names: expected array"},
+		{"size", Object{"items": []interface{}{nil, nil, nil}}, "array holds 3 items, but its size is 2"},
+		{"any", Object{"value": Object{}}, "cannot be held by an any field"},
+	}
+	for _, tt := range tests {
+		_, err := Encode(p, 0x01, tt.obj)
+		if err == nil || !strings.Contains(err.Error(), tt.err) {
+			t.Errorf("%s: expected error containing %q, found %v", tt.name, tt.err, err)
+		}
+	}
+}
+
+func TestFromJSON(t *testing.T) {
+	p := testPackage(t)
+	tests := []struct {
+		name     string
+		json     string
+		expected Object
+		err      string
+	}{
+		{"natives", `{"small": 27, "medium": "28", "large": "18446744073709551615", "real": 30.2,
+			"text": "hi", "raw": "JyRQ", "flag": true, "ident": "3232EE42-C2F2-4BAF-8413-18335B4D5640",
+			"counter": 65536}`, Object{
+			"small":   uint8(27),
+			"medium":  uint32(28),
+			"large":   uint64(math.MaxUint64),
+			"real":    30.2,
+			"text":    "hi",
+			"raw":     []byte{0x27, 0x24, 0x50},
+			"flag":    true,
+			"ident":   testUUID,
+			"counter": DynInt(65536),
+		}, ""},
+		{"nulls", `{"small": null, "items": null}`, Object{"small": nil, "items": nil}, ""},
+		{"any_tagged", `{"value": {"type": "uint32", "value": 7}}`, Object{"value": uint32(7)}, ""},
+		{"any_plain", `{"value": [1, 2.5, "x", null, [true]]}`, Object{
+			"value": []interface{}{DynInt(1), 2.5, "x", nil, []interface{}{true}},
+		}, ""},
+		{"structs", `{"items": [{"label": "a", "children": []}, null]}`, Object{
+			"items": []interface{}{Object{"label": "a", "children": []interface{}{}}, nil},
+		}, ""},
+		{"unknown", `{"missing": 1}`, nil, "unknown field `missing'"},
+		{"overflow", `{"small": 256}`, nil, "sample.small"},
+		{"negative", `{"counter": -1}`, nil, "sample.counter"},
+		{"kind", `{"text": 1}`, nil, "expected string, found number"},
+		{"base64", `{"raw": "!"}`, nil, "invalid base64 value"},
+		{"uuid", `{"ident": "nope"}`, nil, "invalid uuid"},
+		{"any_object", `{"value": {"a": 1}}`, nil, "must be objects containing"},
+		{"any_struct", `{"value": {"type": "struct", "value": {}}}`, nil, "cannot be held by an any field"},
+		{"struct_kind", `{"items": [1]}`, nil, "expected struct item, found number"},
+	}
+	for _, tt := range tests {
+		dec := json.NewDecoder(strings.NewReader(tt.json))
+		dec.UseNumber()
+		var data map[string]interface{}
+		if err := dec.Decode(&data); err != nil {
+			t.Fatalf("%s: %s", tt.name, err)
+		}
+		obj, err := FromJSON(p, data)
+		if tt.err != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Errorf("%s: expected error containing %q, found %v", tt.name, tt.err, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: %s", tt.name, err)
+			continue
+		}
+		if !reflect.DeepEqual(obj, tt.expected) {
+			t.Errorf("%s: converted %#v, expected %#v", tt.name, obj, tt.expected)
+		}
+	}
+}
+
+func TestJSONRoundTrip(t *testing.T) {
+	p := testPackage(t)
+	obj := complete(packageFields, Object{
+		"large":   uint64(math.MaxUint64),
+		"raw":     []byte{0x00, 0xFF},
+		"ident":   testUUID,
+		"counter": DynInt(math.MaxUint64),
+		"value":   []interface{}{uint64(1), []byte{0x01}, nil},
+		"items":   []interface{}{complete(itemFields, Object{"label": "a"})},
+	})
+	j, err := ToJSON(p, obj)
+	if err != nil {
+		t.Fatal(err)
+	}
+	raw, err := json.Marshal(j)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.UseNumber()
+	var data map[string]interface{}
+	if err := dec.Decode(&data); err != nil {
+		t.Fatal(err)
+	}
+	back, err := FromJSON(p, data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(back, obj) {
+		t.Errorf("converted %#v, expected %#v", back, obj)
+	}
+}
+
+func TestScannerEach(t *testing.T) {
+	p := testPackage(t)
+	first, _ := Encode(p, 0x01, Object{"small": uint8(1)})
+	second, _ := Encode(p, 0x02, Object{"small": uint8(2)})
+
+	var stream []byte
+	stream = append(stream, 0x00, 0x00)
+	stream = append(stream, first...)
+	stream = append(stream, second[:len(second)-1]...)
+
+	type result struct {
+		offset int64
+		id     byte
+		err    string
+	}
+	var results []result
+	err := NewScanner(bytes.NewReader(stream), models.PackageList{*p}).Each(func(msg *Message, offset int64, err error) {
+		r := result{offset: offset}
+		if msg != nil {
+			r.id = msg.MessageID
+		}
+		if err != nil {
+			r.err = err.Error()
+		}
+		results = append(results, r)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []result{
+		{0, 0, "offset 0: skipped 2 bytes not belonging to a message"},
+		{2, 0x01, ""},
+		{int64(2 + len(first)), 0, fmt.Sprintf("offset %d: stream ends in the middle of a message", 2+len(first))},
+	}
+	if !reflect.DeepEqual(results, expected) {
+		t.Errorf("scanned %+v, expected %+v", results, expected)
+	}
+}
@@ -0,0 +1,412 @@
+package codec
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/ludwieg/ludco/models"
+)
+
+// ErrShortBuffer indicates that data ends before the message being decoded
+var ErrShortBuffer = errors.New("unexpected end of message")
+
+// Header holds information present on the beginning of every message
+type Header struct {
+	// MessageID holds the identifier chosen by the sender of the message
+	MessageID byte
+
+	// PackageID holds the identifier of the package carried by the message
+	PackageID byte
+
+	// Size holds the size of the message payload, in bytes
+	Size uint64
+}
+
+// Message holds a decoded message
+type Message struct {
+	Header
+
+	// Package holds the definition of the package carried by the message
+	Package *models.Package
+
+	// Value holds the values carried by the message
+	Value Object
+}
+
+// DecodeError describes a failure decoding a message
+type DecodeError struct {
+	// Offset indicates where the failure happened, relative to the beginning
+	// of the message
+	Offset int
+
+	// Path identifies the value being decoded, such as package.field[1]
+	Path string
+
+	// Reason describes the failure
+	Reason string
+}
+
+func (e *DecodeError) Error() string {
+	if e.Path == "" {
+		return fmt.Sprintf("offset %d: %s", e.Offset, e.Reason)
+	}
+	return fmt.Sprintf("offset %d: %s: %s", e.Offset, e.Path, e.Reason)
+}
+
+// ParseHeader parses the header of a message from the beginning of data,
+// returning it along with its length. ErrShortBuffer is returned when data
+// does not contain a complete header.
+func ParseHeader(data []byte) (Header, int, error) {
+	var h Header
+	for i := range Magic {
+		if len(data) <= i {
+			return h, 0, ErrShortBuffer
+		}
+		if data[i] != Magic[i] {
+			return h, 0, &DecodeError{Offset: i, Reason: "invalid magic bytes"}
+		}
+	}
+	if len(data) < len(Magic)+3 {
+		return h, 0, ErrShortBuffer
+	}
+	if v := data[len(Magic)]; v != ProtocolVersion {
+		return h, 0, &DecodeError{Offset: len(Magic), Reason: fmt.Sprintf("unsupported protocol version 0x%02x", v)}
+	}
+	h.MessageID = data[len(Magic)+1]
+	h.PackageID = data[len(Magic)+2]
+
+	size, n, err := readSize(data[len(Magic)+3:])
+	if err == ErrShortBuffer {
+		return h, 0, err
+	} else if err != nil {
+		return h, 0, &DecodeError{Offset: len(Magic) + 3, Reason: err.Error()}
+	}
+	h.Size = size
+	return h, len(Magic) + 3 + n, nil
+}
+
+// Decode decodes a message from the beginning of data using the provided
+// packages, returning it along with the amount of bytes it spans.
+// ErrShortBuffer is returned when data does not contain a complete message.
+// Other failures are reported through a *DecodeError.
+func Decode(packages models.PackageList, data []byte) (*Message, int, error) {
+	h, n, err := ParseHeader(data)
+	if err != nil {
+		return nil, 0, err
+	}
+	if uint64(len(data)-n) < h.Size {
+		return nil, 0, ErrShortBuffer
+	}
+	end := n + int(h.Size)
+
+	var pkg *models.Package
+	for i := range packages {
+		if packages[i].RawIdentifier() == h.PackageID {
+			pkg = &packages[i]
+			break
+		}
+	}
+	if pkg == nil {
+		return nil, end, &DecodeError{Offset: len(Magic) + 2, Reason: fmt.Sprintf("unknown package identifier 0x%02x", h.PackageID)}
+	}
+
+	d := decoder{data: data[:end], pos: n}
+	obj, err := d.fields(pkg.Scope(), pkg.Fields, end, pkg.Name)
+	if err != nil {
+		return nil, end, err
+	}
+	return &Message{Header: h, Package: pkg, Value: obj}, end, nil
+}
+
+type decoder struct {
+	data []byte
+	pos  int
+}
+
+func (d *decoder) fail(path, format string, args ...interface{}) error {
+	return &DecodeError{Offset: d.pos, Path: path, Reason: fmt.Sprintf(format, args...)}
+}
+
+func (d *decoder) take(n uint64, path string) ([]byte, error) {
+	if uint64(len(d.data)-d.pos) < n {
+		return nil, d.fail(path, "value exceeds message boundaries")
+	}
+	v := d.data[d.pos : d.pos+int(n)]
+	d.pos += int(n)
+	return v, nil
+}
+
+func (d *decoder) size(path string) (uint64, error) {
+	v, n, err := readSize(d.data[d.pos:])
+	if err == ErrShortBuffer {
+		return 0, d.fail(path, "value exceeds message boundaries")
+	} else if err != nil {
+		return 0, d.fail(path, "%s", err)
+	}
+	d.pos += n
+	return v, nil
+}
+
+// body reads the size of a container, returning the offset where it ends
+func (d *decoder) body(path string) (int, error) {
+	size, err := d.size(path)
+	if err != nil {
+		return 0, err
+	}
+	if uint64(len(d.data)-d.pos) < size {
+		return 0, d.fail(path, "value exceeds message boundaries")
+	}
+	return d.pos + int(size), nil
+}
+
+// typeByte reads the type byte of a value, ensuring it matches the expected
+// type. It returns whether the value is empty.
+func (d *decoder) typeByte(expected ProtocolType, path string) (bool, error) {
+	t, err := d.take(1, path)
+	if err != nil {
+		return false, err
+	}
+	if found := ProtocolType(t[0] & typeMask); found != expected {
+		d.pos--
+		return false, d.fail(path, "expected %s, found %s", expected, found)
+	}
+	return t[0]&FlagEmpty != 0, nil
+}
+
+func (d *decoder) fields(s *models.Scope, fields []models.Field, end int, path string) (Object, error) {
+	obj := Object{}
+	for _, f := range fields {
+		if d.pos >= end {
+			// Values missing at the end of a body are considered empty
+			obj[f.Name] = nil
+			continue
+		}
+		v, err := d.field(s, f, path+"."+f.Name)
+		if err != nil {
+			return nil, err
+		}
+		obj[f.Name] = v
+	}
+	for d.pos < end {
+		// Values beyond the last known field belong to newer definitions
+		if err := d.skip(path); err != nil {
+			return nil, err
+		}
+	}
+	if d.pos != end {
+		return nil, d.fail(path, "value exceeds its enclosing body")
+	}
+	return obj, nil
+}
+
+func (d *decoder) field(s *models.Scope, f models.Field, path string) (interface{}, error) {
+	if !f.IsArray() {
+		return d.single(s, f.Type, path)
+	}
+
+	empty, err := d.typeByte(TypeArray, path)
+	if err != nil || empty {
+		return nil, err
+	}
+	end, err := d.body(path)
+	if err != nil {
+		return nil, err
+	}
+	count, err := d.size(path)
+	if err != nil {
+		return nil, err
+	}
+	if count > uint64(end-d.pos) {
+		return nil, d.fail(path, "array count %d exceeds its body", count)
+	}
+	items := make([]interface{}, count)
+	for i := range items {
+		items[i], err = d.single(s, f.Type, fmt.Sprintf("%s[%d]", path, i))
+		if err != nil {
+			return nil, err
+		}
+	}
+	if d.pos != end {
+		return nil, d.fail(path, "array body size mismatch")
+	}
+	return items, nil
+}
+
+func (d *decoder) single(s *models.Scope, t models.Type, path string) (interface{}, error) {
+	if t.Source == models.SourceNative {
+		pt := ProtocolTypeFor(t.NativeType)
+		empty, err := d.typeByte(pt, path)
+		if err != nil || empty {
+			return nil, err
+		}
+		return d.native(pt, path)
+	}
+
+	empty, err := d.typeByte(TypeStruct, path)
+	if err != nil || empty {
+		return nil, err
+	}
+	str, inner, ok := s.Resolve(t.CustomType)
+	if !ok {
+		return nil, d.fail(path, "unknown type `%s'", t.CustomType)
+	}
+	end, err := d.body(path)
+	if err != nil {
+		return nil, err
+	}
+	return d.fields(inner, str.Fields, end, path)
+}
+
+// native reads the body of a native value whose type byte has already been
+// read
+func (d *decoder) native(t ProtocolType, path string) (interface{}, error) {
+	switch t {
+	case TypeUint8:
+		b, err := d.take(1, path)
+		if err != nil {
+			return nil, err
+		}
+		return b[0], nil
+	case TypeBool:
+		b, err := d.take(1, path)
+		if err != nil {
+			return nil, err
+		}
+		if b[0] > 1 {
+			d.pos--
+			return nil, d.fail(path, "invalid bool value 0x%02x", b[0])
+		}
+		return b[0] == 1, nil
+	case TypeUint32:
+		b, err := d.take(4, path)
+		if err != nil {
+			return nil, err
+		}
+		return binary.LittleEndian.Uint32(b), nil
+	case TypeUint64:
+		b, err := d.take(8, path)
+		if err != nil {
+			return nil, err
+		}
+		return binary.LittleEndian.Uint64(b), nil
+	case TypeDouble:
+		b, err := d.take(8, path)
+		if err != nil {
+			return nil, err
+		}
+		return math.Float64frombits(binary.LittleEndian.Uint64(b)), nil
+	case TypeString, TypeBlob:
+		size, err := d.size(path)
+		if err != nil {
+			return nil, err
+		}
+		b, err := d.take(size, path)
+		if err != nil {
+			return nil, err
+		}
+		if t == TypeString {
+			return string(b), nil
+		}
+		return append([]byte{}, b...), nil
+	case TypeUUID:
+		b, err := d.take(16, path)
+		if err != nil {
+			return nil, err
+		}
+		var u UUID
+		copy(u[:], b)
+		return u, nil
+	case TypeDynInt:
+		v, err := d.size(path)
+		if err != nil {
+			return nil, err
+		}
+		return DynInt(v), nil
+	case TypeAny:
+		return d.any(path)
+	}
+	return nil, d.fail(path, "unexpected type %s", t)
+}
+
+// any reads the value held by an any field
+func (d *decoder) any(path string) (interface{}, error) {
+	b, err := d.take(1, path)
+	if err != nil {
+		return nil, err
+	}
+	t := ProtocolType(b[0] & typeMask)
+	if b[0]&FlagEmpty != 0 {
+		return nil, nil
+	}
+
+	switch t {
+	case TypeAny, TypeStruct:
+		d.pos--
+		return nil, d.fail(path, "type %s cannot be held by an any field", t)
+	case TypeArray:
+		end, err := d.body(path)
+		if err != nil {
+			return nil, err
+		}
+		count, err := d.size(path)
+		if err != nil {
+			return nil, err
+		}
+		if count > uint64(end-d.pos) {
+			return nil, d.fail(path, "array count %d exceeds its body", count)
+		}
+		items := make([]interface{}, count)
+		for i := range items {
+			p := fmt.Sprintf("%s[%d]", path, i)
+			empty, err := d.typeByte(TypeAny, p)
+			if err != nil {
+				return nil, err
+			}
+			if !empty {
+				if items[i], err = d.any(p); err != nil {
+					return nil, err
+				}
+			}
+		}
+		if d.pos != end {
+			return nil, d.fail(path, "array body size mismatch")
+		}
+		return items, nil
+	}
+	if _, known := protocolTypeNames[t]; !known {
+		d.pos--
+		return nil, d.fail(path, "unknown type 0x%02x", byte(t))
+	}
+	return d.native(t, path)
+}
+
+// skip reads and discards a value of any type
+func (d *decoder) skip(path string) error {
+	b, err := d.take(1, path)
+	if err != nil {
+		return err
+	}
+	if b[0]&FlagEmpty != 0 {
+		return nil
+	}
+	switch t := ProtocolType(b[0] & typeMask); t {
+	case TypeAny:
+		return d.skip(path)
+	case TypeArray, TypeStruct:
+		end, err := d.body(path)
+		if err != nil {
+			return err
+		}
+		d.pos = end
+		return nil
+	default:
+		if _, known := protocolTypeNames[t]; !known {
+			d.pos--
+			return d.fail(path, "unknown type 0x%02x", byte(t))
+		}
+		_, err := d.native(t, path)
+		return err
+	}
+}
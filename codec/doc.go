@@ -0,0 +1,37 @@
+// Package codec implements the Ludwieg wire format driven directly by a
+// `models.PackageList`, without requiring generated sources. It is used by
+// ludco facilities that need to produce or inspect binary messages.
+//
+// Every message is framed as follows:
+//
+//	0x27 0x24 0x50   magic bytes
+//	0x01             protocol version
+//	message id       one byte, chosen by the sender
+//	package id       one byte, the package identifier
+//	size             dynamic size of the payload, in bytes
+//	payload          one value per package field, in declaration order
+//
+// Each value starts with a type byte holding a ProtocolType. When FlagEmpty is
+// set on that byte, the value is empty and no body follows. Otherwise, the
+// body depends on the type:
+//
+//	uint8, bool      1 byte
+//	uint32           4 bytes, little-endian
+//	uint64, double   8 bytes, little-endian
+//	uuid             16 bytes
+//	dynint           dynamic size holding the integer value
+//	string, blob     dynamic size followed by the raw bytes
+//	any              a complete value (type byte and body)
+//	array            dynamic size of the remaining body, dynamic element
+//	                 count, then one complete value per element
+//	struct           dynamic size of the remaining body, then one value per
+//	                 struct field, in declaration order
+//
+// A dynamic size is a width byte (0x01, 0x02, 0x04, or 0x08) followed by an
+// unsigned little-endian integer of that many bytes. Encoders always use the
+// smallest width able to hold the value.
+//
+// Decoders ignore values beyond the last known field of a package or struct,
+// and consider missing trailing fields empty. This allows fields to be
+// appended to a package without breaking older peers.
+package codec
@@ -0,0 +1,238 @@
+package codec
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+
+	"github.com/ludwieg/ludco/models"
+)
+
+// ProtocolVersion is the version written on the header of every message
+const ProtocolVersion byte = 0x01
+
+// Magic holds the bytes every message starts with
+var Magic = []byte{0x27, 0x24, 0x50}
+
+// Encode serializes an instance of a package into a framed message using the
+// provided message identifier
+func Encode(p *models.Package, messageID byte, obj Object) ([]byte, error) {
+	payload, err := encodeFields(nil, p.Scope(), p.Fields, obj, p.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	buf := append([]byte{}, Magic...)
+	buf = append(buf, ProtocolVersion, messageID, p.RawIdentifier())
+	buf = appendSize(buf, uint64(len(payload)))
+	return append(buf, payload...), nil
+}
+
+func encodeFields(buf []byte, s *models.Scope, fields []models.Field, obj Object, path string) ([]byte, error) {
+	known := map[string]bool{}
+	for _, f := range fields {
+		known[f.Name] = true
+	}
+	var unknown []string
+	for k := range obj {
+		if !known[k] {
+			unknown = append(unknown, k)
+		}
+	}
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+		return nil, fmt.Errorf("%s: unknown field `%s'", path, unknown[0])
+	}
+
+	var err error
+	for _, f := range fields {
+		buf, err = encodeField(buf, s, f, obj[f.Name], path+"."+f.Name)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return buf, nil
+}
+
+func encodeField(buf []byte, s *models.Scope, f models.Field, v interface{}, path string) ([]byte, error) {
+	if !f.IsArray() {
+		return encodeSingle(buf, s, f.Type, v, path)
+	}
+
+	if v == nil {
+		return append(buf, byte(TypeArray)|FlagEmpty), nil
+	}
+	items, ok := v.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("%s: expected array, found %T", path, v)
+	}
+	if f.Size != "*" {
+		max, _ := strconv.Atoi(f.Size)
+		if len(items) > max {
+			return nil, fmt.Errorf("%s: array holds %d items, but its size is %d", path, len(items), max)
+		}
+	}
+
+	body := appendSize(nil, uint64(len(items)))
+	var err error
+	for i, item := range items {
+		body, err = encodeSingle(body, s, f.Type, item, fmt.Sprintf("%s[%d]", path, i))
+		if err != nil {
+			return nil, err
+		}
+	}
+	buf = append(buf, byte(TypeArray))
+	buf = appendSize(buf, uint64(len(body)))
+	return append(buf, body...), nil
+}
+
+func encodeSingle(buf []byte, s *models.Scope, t models.Type, v interface{}, path string) ([]byte, error) {
+	if t.Source == models.SourceNative {
+		return encodeNative(buf, t.NativeType, v, path)
+	}
+
+	if v == nil {
+		return append(buf, byte(TypeStruct)|FlagEmpty), nil
+	}
+	obj, ok := v.(Object)
+	if !ok {
+		return nil, fmt.Errorf("%s: expected struct %s, found %T", path, t.CustomType, v)
+	}
+	str, inner, ok := s.Resolve(t.CustomType)
+	if !ok {
+		return nil, fmt.Errorf("%s: unknown type `%s'", path, t.CustomType)
+	}
+	body, err := encodeFields(nil, inner, str.Fields, obj, path)
+	if err != nil {
+		return nil, err
+	}
+	buf = append(buf, byte(TypeStruct))
+	buf = appendSize(buf, uint64(len(body)))
+	return append(buf, body...), nil
+}
+
+func encodeNative(buf []byte, t models.NativeType, v interface{}, path string) ([]byte, error) {
+	pt := ProtocolTypeFor(t)
+	if v == nil {
+		return append(buf, byte(pt)|FlagEmpty), nil
+	}
+	if pt == TypeAny {
+		return encodeAny(append(buf, byte(TypeAny)), v, path)
+	}
+
+	var ok bool
+	buf = append(buf, byte(pt))
+	switch pt {
+	case TypeUint8:
+		var i uint8
+		if i, ok = v.(uint8); ok {
+			buf = append(buf, i)
+		}
+	case TypeBool:
+		var b bool
+		if b, ok = v.(bool); ok {
+			if b {
+				buf = append(buf, 0x01)
+			} else {
+				buf = append(buf, 0x00)
+			}
+		}
+	case TypeUint32:
+		var i uint32
+		if i, ok = v.(uint32); ok {
+			buf = append(buf, 0, 0, 0, 0)
+			binary.LittleEndian.PutUint32(buf[len(buf)-4:], i)
+		}
+	case TypeUint64:
+		var i uint64
+		if i, ok = v.(uint64); ok {
+			buf = append(buf, 0, 0, 0, 0, 0, 0, 0, 0)
+			binary.LittleEndian.PutUint64(buf[len(buf)-8:], i)
+		}
+	case TypeDouble:
+		var d float64
+		if d, ok = v.(float64); ok {
+			buf = append(buf, 0, 0, 0, 0, 0, 0, 0, 0)
+			binary.LittleEndian.PutUint64(buf[len(buf)-8:], math.Float64bits(d))
+		}
+	case TypeString:
+		var str string
+		if str, ok = v.(string); ok {
+			buf = appendSize(buf, uint64(len(str)))
+			buf = append(buf, str...)
+		}
+	case TypeBlob:
+		var b []byte
+		if b, ok = v.([]byte); ok {
+			buf = appendSize(buf, uint64(len(b)))
+			buf = append(buf, b...)
+		}
+	case TypeUUID:
+		var u UUID
+		if u, ok = v.(UUID); ok {
+			buf = append(buf, u[:]...)
+		}
+	case TypeDynInt:
+		var i DynInt
+		if i, ok = v.(DynInt); ok {
+			buf = appendSize(buf, uint64(i))
+		}
+	}
+
+	if !ok {
+		return nil, fmt.Errorf("%s: expected %s, found %T", path, t, v)
+	}
+	return buf, nil
+}
+
+// encodeAny writes a complete value for v, inferring its type from the Go
+// type holding it
+func encodeAny(buf []byte, v interface{}, path string) ([]byte, error) {
+	if items, ok := v.([]interface{}); ok {
+		body := appendSize(nil, uint64(len(items)))
+		var err error
+		for i, item := range items {
+			body, err = encodeNative(body, models.TypeAny, item, fmt.Sprintf("%s[%d]", path, i))
+			if err != nil {
+				return nil, err
+			}
+		}
+		buf = append(buf, byte(TypeArray))
+		buf = appendSize(buf, uint64(len(body)))
+		return append(buf, body...), nil
+	}
+
+	t, ok := AnyType(v)
+	if !ok {
+		return nil, fmt.Errorf("%s: type %T cannot be held by an any field", path, v)
+	}
+	return encodeNative(buf, t, v, path)
+}
+
+// AnyType returns the native type used to encode v when it is held by an any
+// field. Arrays are not native types, and must be checked by the caller.
+func AnyType(v interface{}) (models.NativeType, bool) {
+	switch v.(type) {
+	case uint8:
+		return models.TypeUint8, true
+	case bool:
+		return models.TypeBool, true
+	case uint32:
+		return models.TypeUint32, true
+	case uint64:
+		return models.TypeUint64, true
+	case float64:
+		return models.TypeDouble, true
+	case string:
+		return models.TypeString, true
+	case []byte:
+		return models.TypeBlob, true
+	case UUID:
+		return models.TypeUUID, true
+	case DynInt:
+		return models.TypeDynInt, true
+	}
+	return "", false
+}
@@ -0,0 +1,324 @@
+package codec
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/ludwieg/ludco/models"
+)
+
+// ToJSON converts an instance of a package into a value suitable for use with
+// encoding/json. Empty values are represented by null, uint64 and dynint
+// values by decimal strings (avoiding precision loss on consumers using
+// IEEE-754 numbers), blobs by base64 strings, and values held by any fields by
+// an object containing their `type' and `value'.
+func ToJSON(p *models.Package, obj Object) (map[string]interface{}, error) {
+	return jsonFields(p.Scope(), p.Fields, obj, p.Name)
+}
+
+func jsonFields(s *models.Scope, fields []models.Field, obj Object, path string) (map[string]interface{}, error) {
+	result := map[string]interface{}{}
+	for _, f := range fields {
+		v, err := jsonField(s, f, obj[f.Name], path+"."+f.Name)
+		if err != nil {
+			return nil, err
+		}
+		result[f.Name] = v
+	}
+	return result, nil
+}
+
+func jsonField(s *models.Scope, f models.Field, v interface{}, path string) (interface{}, error) {
+	if !f.IsArray() || v == nil {
+		return jsonSingle(s, f.Type, v, path)
+	}
+	items, ok := v.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("%s: expected array, found %T", path, v)
+	}
+	result := make([]interface{}, len(items))
+	for i, item := range items {
+		j, err := jsonSingle(s, f.Type, item, fmt.Sprintf("%s[%d]", path, i))
+		if err != nil {
+			return nil, err
+		}
+		result[i] = j
+	}
+	return result, nil
+}
+
+func jsonSingle(s *models.Scope, t models.Type, v interface{}, path string) (interface{}, error) {
+	if v == nil {
+		return nil, nil
+	}
+	if t.Source == models.SourceNative {
+		return jsonNative(t.NativeType, v, path)
+	}
+	obj, ok := v.(Object)
+	if !ok {
+		return nil, fmt.Errorf("%s: expected struct %s, found %T", path, t.CustomType, v)
+	}
+	str, inner, ok := s.Resolve(t.CustomType)
+	if !ok {
+		return nil, fmt.Errorf("%s: unknown type `%s'", path, t.CustomType)
+	}
+	return jsonFields(inner, str.Fields, obj, path)
+}
+
+func jsonNative(t models.NativeType, v interface{}, path string) (interface{}, error) {
+	if t == models.TypeAny {
+		return jsonAny(v, path)
+	}
+	if at, ok := AnyType(v); !ok || ProtocolTypeFor(at) != ProtocolTypeFor(t) {
+		return nil, fmt.Errorf("%s: expected %s, found %T", path, t, v)
+	}
+
+	switch i := v.(type) {
+	case uint64:
+		return strconv.FormatUint(i, 10), nil
+	case DynInt:
+		return strconv.FormatUint(uint64(i), 10), nil
+	case []byte:
+		return base64.StdEncoding.EncodeToString(i), nil
+	case UUID:
+		return i.String(), nil
+	}
+	return v, nil
+}
+
+func jsonAny(v interface{}, path string) (interface{}, error) {
+	if v == nil {
+		return nil, nil
+	}
+	if items, ok := v.([]interface{}); ok {
+		result := make([]interface{}, len(items))
+		for i, item := range items {
+			j, err := jsonAny(item, fmt.Sprintf("%s[%d]", path, i))
+			if err != nil {
+				return nil, err
+			}
+			result[i] = j
+		}
+		return map[string]interface{}{"type": "array", "value": result}, nil
+	}
+
+	t, ok := AnyType(v)
+	if !ok {
+		return nil, fmt.Errorf("%s: type %T cannot be held by an any field", path, v)
+	}
+	j, err := jsonNative(t, v, path)
+	if err != nil {
+		return nil, err
+	}
+	return map[string]interface{}{"type": string(t), "value": j}, nil
+}
+
+// FromJSON converts a value produced by encoding/json into an instance of a
+// package, accepting the representation produced by ToJSON. Integers may also
+// be provided as numbers, preferably decoded as json.Number to avoid precision
+// loss. Values held by any fields may also be provided as plain JSON values,
+// in which case integers are held as dynint, other numbers as double, and
+// arrays as arrays of any.
+func FromJSON(p *models.Package, data map[string]interface{}) (Object, error) {
+	return fromJSONFields(p.Scope(), p.Fields, data, p.Name)
+}
+
+func fromJSONFields(s *models.Scope, fields []models.Field, data map[string]interface{}, path string) (Object, error) {
+	byName := map[string]models.Field{}
+	for _, f := range fields {
+		byName[f.Name] = f
+	}
+	keys := []string{}
+	for k := range data {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	obj := Object{}
+	for _, k := range keys {
+		f, ok := byName[k]
+		if !ok {
+			return nil, fmt.Errorf("%s: unknown field `%s'", path, k)
+		}
+		v, err := fromJSONField(s, f, data[k], path+"."+k)
+		if err != nil {
+			return nil, err
+		}
+		obj[k] = v
+	}
+	return obj, nil
+}
+
+func fromJSONField(s *models.Scope, f models.Field, v interface{}, path string) (interface{}, error) {
+	if !f.IsArray() || v == nil {
+		return fromJSONSingle(s, f.Type, v, path)
+	}
+	items, ok := v.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("%s: expected array, found %s", path, jsonKind(v))
+	}
+	result := make([]interface{}, len(items))
+	for i, item := range items {
+		j, err := fromJSONSingle(s, f.Type, item, fmt.Sprintf("%s[%d]", path, i))
+		if err != nil {
+			return nil, err
+		}
+		result[i] = j
+	}
+	return result, nil
+}
+
+func fromJSONSingle(s *models.Scope, t models.Type, v interface{}, path string) (interface{}, error) {
+	if v == nil {
+		return nil, nil
+	}
+	if t.Source == models.SourceNative {
+		return fromJSONNative(t.NativeType, v, path)
+	}
+	data, ok := v.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("%s: expected struct %s, found %s", path, t.CustomType, jsonKind(v))
+	}
+	str, inner, ok := s.Resolve(t.CustomType)
+	if !ok {
+		return nil, fmt.Errorf("%s: unknown type `%s'", path, t.CustomType)
+	}
+	return fromJSONFields(inner, str.Fields, data, path)
+}
+
+func fromJSONNative(t models.NativeType, v interface{}, path string) (interface{}, error) {
+	invalid := func() error {
+		return fmt.Errorf("%s: expected %s, found %s", path, t, jsonKind(v))
+	}
+
+	switch t {
+	case models.TypeUint8, models.TypeByte, models.TypeUint32, models.TypeUint64, models.TypeDynInt:
+		var text string
+		switch n := v.(type) {
+		case json.Number:
+			text = n.String()
+		case string:
+			text = n
+		case float64:
+			text = strconv.FormatFloat(n, 'f', -1, 64)
+		default:
+			return nil, invalid()
+		}
+		lit := models.Literal{Kind: models.LiteralNumber, Value: text}
+		return literalNative(t, lit, path)
+	case models.TypeDouble:
+		switch n := v.(type) {
+		case json.Number:
+			return n.Float64()
+		case float64:
+			return n, nil
+		}
+	case models.TypeString:
+		if str, ok := v.(string); ok {
+			return str, nil
+		}
+	case models.TypeBlob:
+		if str, ok := v.(string); ok {
+			b, err := base64.StdEncoding.DecodeString(str)
+			if err != nil {
+				return nil, fmt.Errorf("%s: invalid base64 value", path)
+			}
+			return b, nil
+		}
+	case models.TypeBool:
+		if b, ok := v.(bool); ok {
+			return b, nil
+		}
+	case models.TypeUUID:
+		if str, ok := v.(string); ok {
+			u, err := ParseUUID(str)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %s", path, err)
+			}
+			return u, nil
+		}
+	case models.TypeAny:
+		return fromJSONAny(v, path)
+	}
+	return nil, invalid()
+}
+
+func fromJSONAny(v interface{}, path string) (interface{}, error) {
+	switch i := v.(type) {
+	case nil:
+		return nil, nil
+	case bool:
+		return i, nil
+	case string:
+		return i, nil
+	case json.Number, float64:
+		text := fmt.Sprint(i)
+		if strings.ContainsAny(text, ".eE-") {
+			return fromJSONNative(models.TypeDouble, v, path)
+		}
+		return fromJSONNative(models.TypeDynInt, v, path)
+	case []interface{}:
+		items := make([]interface{}, len(i))
+		for idx, item := range i {
+			j, err := fromJSONAny(item, fmt.Sprintf("%s[%d]", path, idx))
+			if err != nil {
+				return nil, err
+			}
+			items[idx] = j
+		}
+		return items, nil
+	case map[string]interface{}:
+		t, _ := i["type"].(string)
+		value, hasValue := i["value"]
+		if len(i) != 2 || t == "" || !hasValue {
+			return nil, fmt.Errorf("%s: values held by any fields must be objects containing `type' and `value'", path)
+		}
+		if t == "array" {
+			if _, ok := value.([]interface{}); !ok {
+				return nil, fmt.Errorf("%s: expected array, found %s", path, jsonKind(value))
+			}
+			return fromJSONAny(value, path)
+		}
+		for _, at := range anyNativeTypes {
+			if string(at) == t {
+				return fromJSONNative(at, value, path)
+			}
+		}
+		return nil, fmt.Errorf("%s: type %s cannot be held by an any field", path, t)
+	}
+	return nil, fmt.Errorf("%s: unexpected %s", path, jsonKind(v))
+}
+
+var anyNativeTypes = []models.NativeType{
+	models.TypeUint8,
+	models.TypeUint32,
+	models.TypeUint64,
+	models.TypeDouble,
+	models.TypeString,
+	models.TypeBlob,
+	models.TypeBool,
+	models.TypeUUID,
+	models.TypeDynInt,
+}
+
+func jsonKind(v interface{}) string {
+	switch v.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case string:
+		return "string"
+	case json.Number, float64:
+		return "number"
+	case []interface{}:
+		return "array"
+	case map[string]interface{}:
+		return "object"
+	}
+	return fmt.Sprintf("%T", v)
+}
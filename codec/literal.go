@@ -0,0 +1,203 @@
+package codec
+
+import (
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/ludwieg/ludco/models"
+)
+
+// FromExample converts an example declared on a package into an Object,
+// checking its values against the package fields
+func FromExample(p *models.Package, e models.Example) (Object, error) {
+	return literalFields(p.Scope(), p.Fields, e.Fields, p.Name)
+}
+
+func literalFields(s *models.Scope, fields []models.Field, assignments []models.Assignment, path string) (Object, error) {
+	byName := map[string]models.Field{}
+	for _, f := range fields {
+		byName[f.Name] = f
+	}
+
+	obj := Object{}
+	for _, a := range assignments {
+		f, ok := byName[a.Name]
+		if !ok {
+			return nil, fmt.Errorf("%s: unknown field `%s'", path, a.Name)
+		}
+		if _, exists := obj[a.Name]; exists {
+			return nil, fmt.Errorf("%s: duplicated assignment for `%s'", path, a.Name)
+		}
+		v, err := literalField(s, f, a.Value, path+"."+a.Name)
+		if err != nil {
+			return nil, err
+		}
+		obj[a.Name] = v
+	}
+	return obj, nil
+}
+
+func literalField(s *models.Scope, f models.Field, lit models.Literal, path string) (interface{}, error) {
+	if !f.IsArray() || lit.Kind == models.LiteralNull {
+		return literalSingle(s, f.Type, lit, path)
+	}
+	if lit.Kind != models.LiteralList {
+		return nil, fmt.Errorf("%s: expected list, found %s", path, lit.Kind)
+	}
+	if f.Size != "*" {
+		max, _ := strconv.Atoi(f.Size)
+		if len(lit.Items) > max {
+			return nil, fmt.Errorf("%s: list holds %d items, but array size is %d", path, len(lit.Items), max)
+		}
+	}
+	items := make([]interface{}, len(lit.Items))
+	for i, item := range lit.Items {
+		v, err := literalSingle(s, f.Type, item, fmt.Sprintf("%s[%d]", path, i))
+		if err != nil {
+			return nil, err
+		}
+		items[i] = v
+	}
+	return items, nil
+}
+
+func literalSingle(s *models.Scope, t models.Type, lit models.Literal, path string) (interface{}, error) {
+	if lit.Kind == models.LiteralNull {
+		return nil, nil
+	}
+	if t.Source == models.SourceNative {
+		return literalNative(t.NativeType, lit, path)
+	}
+	if lit.Kind != models.LiteralStruct {
+		return nil, fmt.Errorf("%s: expected struct %s, found %s", path, t.CustomType, lit.Kind)
+	}
+	str, inner, ok := s.Resolve(t.CustomType)
+	if !ok {
+		return nil, fmt.Errorf("%s: unknown type `%s'", path, t.CustomType)
+	}
+	return literalFields(inner, str.Fields, lit.Fields, path)
+}
+
+func literalNative(t models.NativeType, lit models.Literal, path string) (interface{}, error) {
+	invalid := func() error {
+		return fmt.Errorf("%s: %s literal cannot be used as %s", path, lit.Kind, t)
+	}
+
+	switch t {
+	case models.TypeUint8, models.TypeByte, models.TypeUint32, models.TypeUint64, models.TypeDynInt:
+		bits := map[models.NativeType]int{
+			models.TypeUint8:  8,
+			models.TypeByte:   8,
+			models.TypeUint32: 32,
+			models.TypeUint64: 64,
+			models.TypeDynInt: 64,
+		}[t]
+		var v uint64
+		var err error
+		switch lit.Kind {
+		case models.LiteralNumber:
+			v, err = strconv.ParseUint(lit.Value, 10, bits)
+		case models.LiteralHex:
+			v, err = strconv.ParseUint(lit.Value[2:], 16, bits)
+		default:
+			return nil, invalid()
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%s: %s is not a valid %s", path, lit.Value, t)
+		}
+		switch t {
+		case models.TypeUint8, models.TypeByte:
+			return uint8(v), nil
+		case models.TypeUint32:
+			return uint32(v), nil
+		case models.TypeUint64:
+			return v, nil
+		}
+		return DynInt(v), nil
+
+	case models.TypeDouble:
+		if lit.Kind != models.LiteralNumber {
+			return nil, invalid()
+		}
+		v, err := strconv.ParseFloat(lit.Value, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %s is not a valid %s", path, lit.Value, t)
+		}
+		return v, nil
+
+	case models.TypeString:
+		if lit.Kind != models.LiteralString {
+			return nil, invalid()
+		}
+		return lit.Value, nil
+
+	case models.TypeBlob:
+		switch lit.Kind {
+		case models.LiteralString:
+			return []byte(lit.Value), nil
+		case models.LiteralHex:
+			v, err := hex.DecodeString(lit.Value[2:])
+			if err != nil {
+				return nil, fmt.Errorf("%s: %s is not a valid blob", path, lit.Value)
+			}
+			return v, nil
+		}
+		return nil, invalid()
+
+	case models.TypeBool:
+		if lit.Kind != models.LiteralBool {
+			return nil, invalid()
+		}
+		return lit.Value == "true", nil
+
+	case models.TypeUUID:
+		if lit.Kind != models.LiteralUUID && lit.Kind != models.LiteralString {
+			return nil, invalid()
+		}
+		v, err := ParseUUID(lit.Value)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %s", path, err)
+		}
+		return v, nil
+
+	case models.TypeAny:
+		return literalAny(lit, path)
+	}
+	return nil, invalid()
+}
+
+// literalAny infers the type of a value held by an any field from its
+// literal: integers are held as dynint, other numbers as double, and hex
+// literals as blob.
+func literalAny(lit models.Literal, path string) (interface{}, error) {
+	switch lit.Kind {
+	case models.LiteralNull:
+		return nil, nil
+	case models.LiteralNumber:
+		if strings.ContainsAny(lit.Value, ".eE-") {
+			return literalNative(models.TypeDouble, lit, path)
+		}
+		return literalNative(models.TypeDynInt, lit, path)
+	case models.LiteralHex:
+		return literalNative(models.TypeBlob, lit, path)
+	case models.LiteralString:
+		return literalNative(models.TypeString, lit, path)
+	case models.LiteralBool:
+		return literalNative(models.TypeBool, lit, path)
+	case models.LiteralUUID:
+		return literalNative(models.TypeUUID, lit, path)
+	case models.LiteralList:
+		items := make([]interface{}, len(lit.Items))
+		for i, item := range lit.Items {
+			v, err := literalAny(item, fmt.Sprintf("%s[%d]", path, i))
+			if err != nil {
+				return nil, err
+			}
+			items[i] = v
+		}
+		return items, nil
+	}
+	return nil, fmt.Errorf("%s: %s literal cannot be held by an any field", path, lit.Kind)
+}
@@ -0,0 +1,126 @@
+package codec
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+
+	"github.com/ludwieg/ludco/models"
+)
+
+// Scanner decodes consecutive messages from a stream, such as a TCP
+// connection. Unlike Decode, offsets reported by a Scanner are relative to the
+// beginning of the stream, and invalid data is skipped until the next message
+// is found.
+type Scanner struct {
+	r        *bufio.Reader
+	packages models.PackageList
+	offset   int64
+	frame    []byte
+}
+
+// NewScanner returns a Scanner reading from r and decoding messages using the
+// provided packages
+func NewScanner(r io.Reader, packages models.PackageList) *Scanner {
+	return &Scanner{r: bufio.NewReader(r), packages: packages}
+}
+
+// Offset returns the amount of bytes consumed from the stream
+func (s *Scanner) Offset() int64 {
+	return s.offset
+}
+
+// Bytes returns the raw bytes of the message last read by Next, even when it
+// could not be decoded. nil is returned when Next could not read a complete
+// message.
+func (s *Scanner) Bytes() []byte {
+	return s.frame
+}
+
+// scannerReader accounts bytes read by ReadFrame on the stream offset
+type scannerReader struct {
+	s *Scanner
+}
+
+func (r scannerReader) Read(p []byte) (int, error) {
+	n, err := r.s.r.Read(p)
+	r.s.offset += int64(n)
+	return n, err
+}
+
+// Next decodes the next message on the stream, returning it along with its
+// offset. io.EOF is returned when the stream ends, and io.ErrUnexpectedEOF
+// when it ends in the middle of a message. Invalid data and messages that
+// cannot be decoded are reported through a *DecodeError, after which Next
+// may be called again to continue from the following message.
+func (s *Scanner) Next() (*Message, int64, error) {
+	s.frame = nil
+	start := s.offset
+	if err := s.sync(); err != nil {
+		return nil, start, err
+	}
+	start = s.offset
+
+	frame, err := ReadFrame(scannerReader{s})
+	if err != nil {
+		if e, ok := err.(*DecodeError); ok {
+			e.Offset += int(start)
+		}
+		return nil, start, unexpectedEOF(err)
+	}
+	s.frame = frame
+
+	msg, _, err := Decode(s.packages, frame)
+	if e, ok := err.(*DecodeError); ok {
+		e.Offset += int(start)
+	}
+	return msg, start, err
+}
+
+// sync discards bytes until the stream is positioned on a magic sequence,
+// reporting discarded bytes through a *DecodeError
+func (s *Scanner) sync() error {
+	start := s.offset
+	for {
+		b, err := s.r.Peek(len(Magic))
+		if bytes.Equal(b, Magic) {
+			break
+		}
+		if err != nil && len(b) == 0 {
+			if s.offset == start {
+				return err
+			}
+			break
+		}
+		s.r.Discard(1)
+		s.offset++
+	}
+	if skipped := s.offset - start; skipped > 0 {
+		return &DecodeError{Offset: int(start), Reason: fmt.Sprintf("skipped %d bytes not belonging to a message", skipped)}
+	}
+	return nil
+}
+
+// Each calls fn for every message on the stream, until it ends. Messages that
+// cannot be decoded are passed to fn along with a *DecodeError, including a
+// message truncated by the end of the stream, which is always the last one.
+// Failures reading the stream interrupt it and are returned, while nil is
+// returned when the stream ends.
+func (s *Scanner) Each(fn func(msg *Message, offset int64, err error)) error {
+	for {
+		msg, offset, err := s.Next()
+		truncated := err == io.ErrUnexpectedEOF
+		if err == io.EOF {
+			return nil
+		} else if truncated {
+			err = &DecodeError{Offset: int(offset), Reason: "stream ends in the middle of a message"}
+		} else if _, ok := err.(*DecodeError); err != nil && !ok {
+			return err
+		}
+		fn(msg, offset, err)
+		if truncated {
+			return nil
+		}
+	}
+}
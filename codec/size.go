@@ -0,0 +1,51 @@
+package codec
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+)
+
+// appendSize appends a dynamic size to buf, using the smallest width able to
+// hold v
+func appendSize(buf []byte, v uint64) []byte {
+	switch {
+	case v <= math.MaxUint8:
+		return append(buf, 0x01, byte(v))
+	case v <= math.MaxUint16:
+		buf = append(buf, 0x02, 0, 0)
+		binary.LittleEndian.PutUint16(buf[len(buf)-2:], uint16(v))
+	case v <= math.MaxUint32:
+		buf = append(buf, 0x04, 0, 0, 0, 0)
+		binary.LittleEndian.PutUint32(buf[len(buf)-4:], uint32(v))
+	default:
+		buf = append(buf, 0x08, 0, 0, 0, 0, 0, 0, 0, 0)
+		binary.LittleEndian.PutUint64(buf[len(buf)-8:], v)
+	}
+	return buf
+}
+
+// readSize reads a dynamic size from the beginning of data, returning its
+// value and the amount of bytes consumed
+func readSize(data []byte) (uint64, int, error) {
+	if len(data) < 1 {
+		return 0, 0, ErrShortBuffer
+	}
+	width := int(data[0])
+	if width != 1 && width != 2 && width != 4 && width != 8 {
+		return 0, 0, fmt.Errorf("invalid size width 0x%02x", data[0])
+	}
+	if len(data) < 1+width {
+		return 0, 0, ErrShortBuffer
+	}
+	raw := data[1 : 1+width]
+	switch width {
+	case 1:
+		return uint64(raw[0]), 2, nil
+	case 2:
+		return uint64(binary.LittleEndian.Uint16(raw)), 3, nil
+	case 4:
+		return uint64(binary.LittleEndian.Uint32(raw)), 5, nil
+	}
+	return binary.LittleEndian.Uint64(raw), 9, nil
+}
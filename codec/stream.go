@@ -0,0 +1,53 @@
+package codec
+
+import (
+	"fmt"
+	"io"
+)
+
+// MaxMessageSize limits the payload size accepted by ReadFrame, preventing
+// corrupted headers from causing large allocations
+const MaxMessageSize = 64 << 20
+
+// ReadFrame reads a complete message from r, returning its raw bytes without
+// decoding its payload. io.EOF is returned when r ends before a new message
+// starts, and io.ErrUnexpectedEOF when it ends in the middle of one. Invalid
+// headers are reported through a *DecodeError.
+func ReadFrame(r io.Reader) ([]byte, error) {
+	// Magic, version, message id, package id, and size width
+	buf := make([]byte, len(Magic)+4)
+	if _, err := io.ReadFull(r, buf); err != nil {
+		return nil, err
+	}
+	width := int(buf[len(buf)-1])
+	if width != 1 && width != 2 && width != 4 && width != 8 {
+		// ParseHeader reports the invalid width, unless the header is
+		// invalid before it
+		_, _, err := ParseHeader(buf)
+		return nil, err
+	}
+	buf = append(buf, make([]byte, width)...)
+	if _, err := io.ReadFull(r, buf[len(buf)-width:]); err != nil {
+		return nil, unexpectedEOF(err)
+	}
+
+	h, n, err := ParseHeader(buf)
+	if err != nil {
+		return nil, err
+	}
+	if h.Size > MaxMessageSize {
+		return nil, &DecodeError{Offset: n - width - 1, Reason: fmt.Sprintf("payload size %d exceeds the limit of %d bytes", h.Size, MaxMessageSize)}
+	}
+	buf = append(buf, make([]byte, h.Size)...)
+	if _, err := io.ReadFull(r, buf[n:]); err != nil {
+		return nil, unexpectedEOF(err)
+	}
+	return buf, nil
+}
+
+func unexpectedEOF(err error) error {
+	if err == io.EOF {
+		return io.ErrUnexpectedEOF
+	}
+	return err
+}
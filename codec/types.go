@@ -0,0 +1,108 @@
+package codec
+
+import (
+	"fmt"
+
+	"github.com/ludwieg/ludco/models"
+)
+
+// ProtocolType identifies the type of a value on the wire. It is always
+// written as the first byte of an encoded value.
+type ProtocolType byte
+
+const (
+	// TypeUint8 is used by uint8 and byte fields
+	TypeUint8 ProtocolType = 0x01
+
+	// TypeUint32 represents a 32-bit unsigned integer
+	TypeUint32 ProtocolType = 0x02
+
+	// TypeUint64 represents a 64-bit unsigned integer
+	TypeUint64 ProtocolType = 0x03
+
+	// TypeDouble represents an IEEE-754 64-bit floating-point number
+	TypeDouble ProtocolType = 0x04
+
+	// TypeString represents an UTF-8 encoded string
+	TypeString ProtocolType = 0x05
+
+	// TypeBlob represents a Binary Large OBject
+	TypeBlob ProtocolType = 0x06
+
+	// TypeBool represents a true/false value
+	TypeBool ProtocolType = 0x07
+
+	// TypeUUID represents a 16-byte UUID
+	TypeUUID ProtocolType = 0x08
+
+	// TypeAny wraps another value carrying its own type
+	TypeAny ProtocolType = 0x09
+
+	// TypeArray represents an array of values
+	TypeArray ProtocolType = 0x0A
+
+	// TypeStruct represents an user-defined structure
+	TypeStruct ProtocolType = 0x0B
+
+	// TypeDynInt represents an integer encoded using the smallest possible
+	// width
+	TypeDynInt ProtocolType = 0x0C
+)
+
+const (
+	// FlagEmpty is set on the type byte of values that are not present.
+	// Empty values have no body.
+	FlagEmpty byte = 0x80
+
+	typeMask byte = 0x7F
+)
+
+var protocolTypeNames = map[ProtocolType]string{
+	TypeUint8:  "uint8",
+	TypeUint32: "uint32",
+	TypeUint64: "uint64",
+	TypeDouble: "double",
+	TypeString: "string",
+	TypeBlob:   "blob",
+	TypeBool:   "bool",
+	TypeUUID:   "uuid",
+	TypeAny:    "any",
+	TypeArray:  "array",
+	TypeStruct: "struct",
+	TypeDynInt: "dynint",
+}
+
+func (t ProtocolType) String() string {
+	if n, ok := protocolTypeNames[t]; ok {
+		return n
+	}
+	return fmt.Sprintf("unknown(0x%02x)", byte(t))
+}
+
+// ProtocolTypeFor returns the ProtocolType used to encode values of a given
+// NativeType
+func ProtocolTypeFor(t models.NativeType) ProtocolType {
+	switch t {
+	case models.TypeUint8, models.TypeByte:
+		return TypeUint8
+	case models.TypeUint32:
+		return TypeUint32
+	case models.TypeUint64:
+		return TypeUint64
+	case models.TypeDouble:
+		return TypeDouble
+	case models.TypeString:
+		return TypeString
+	case models.TypeBlob:
+		return TypeBlob
+	case models.TypeBool:
+		return TypeBool
+	case models.TypeUUID:
+		return TypeUUID
+	case models.TypeAny:
+		return TypeAny
+	case models.TypeDynInt:
+		return TypeDynInt
+	}
+	panic(fmt.Errorf("BUG: no protocol type for native type %#v", t))
+}
@@ -0,0 +1,50 @@
+package codec
+
+import (
+	"encoding/hex"
+	"fmt"
+	"strings"
+)
+
+// Object holds values of a package or struct instance, keyed by field name.
+// Fields absent from the map, or holding nil, are encoded as empty values.
+//
+// Values are represented by the following Go types:
+//
+//	uint8, byte    uint8
+//	bool           bool
+//	uint32         uint32
+//	uint64         uint64
+//	double         float64
+//	string         string
+//	blob           []byte
+//	uuid           UUID
+//	dynint         DynInt
+//	any            any of the types above, or []interface{} for arrays
+//	arrays         []interface{}
+//	structs        Object
+type Object map[string]interface{}
+
+// DynInt holds the value of a dynint field
+type DynInt uint64
+
+// UUID holds the raw 16 bytes of an uuid field
+type UUID [16]byte
+
+// String returns the canonical textual representation of the UUID
+func (u UUID) String() string {
+	h := hex.EncodeToString(u[:])
+	return strings.Join([]string{h[0:8], h[8:12], h[12:16], h[16:20], h[20:32]}, "-")
+}
+
+// ParseUUID parses a UUID in its canonical textual representation. Dashes are
+// optional.
+func ParseUUID(s string) (UUID, error) {
+	var u UUID
+	raw, err := hex.DecodeString(strings.Replace(s, "-", "", -1))
+	if err != nil || len(raw) != len(u) {
+		return u, fmt.Errorf("invalid uuid `%s'", s)
+	}
+	copy(u[:], raw)
+	return u, nil
+}
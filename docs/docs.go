@@ -0,0 +1,201 @@
+// Package docs renders reference documentation of Ludwieg packages.
+package docs
+
+import (
+	"fmt"
+
+	"github.com/ludwieg/ludco/models"
+)
+
+// Renderer produces a single document describing all packages of a project
+type Renderer interface {
+	Extension() string
+	Render(packages models.PackageList) ([]byte, error)
+}
+
+// document holds packages prepared for rendering, with anchors resolved
+type document struct {
+	Packages []docPackage
+}
+
+type docPackage struct {
+	Name       string
+	Identifier string
+	Anchor     string
+	Doc        string
+	Fields     []docField
+	Structs    []docStruct
+}
+
+type docStruct struct {
+	// Path holds the name of the structure qualified by its parents
+	Path         string
+	Name         string
+	Anchor       string
+	Doc          string
+	Parent       string
+	ParentAnchor string
+	Fields       []docField
+	UsedBy       []docReference
+}
+
+type docField struct {
+	Position   int
+	Name       string
+	Type       string
+	TypeAnchor string
+	Size       string
+	Deprecated bool
+	Doc        string
+}
+
+// docReference points to a field referencing a structure
+type docReference struct {
+	Name   string
+	Anchor string
+}
+
+// TypeName returns the type of a field as written on definition files
+func (f docField) TypeName() string {
+	name := f.Type
+	if f.TypeAnchor != "" {
+		name = "@" + name
+	}
+	if f.Size != "" {
+		name += "[" + f.Size + "]"
+	}
+	return name
+}
+
+// docBuilder converts packages into a document
+type docBuilder struct {
+	anchors map[*models.Struct]string
+	structs map[*models.Struct]*docStruct
+}
+
+func newDocument(packages models.PackageList) (*document, error) {
+	b := docBuilder{
+		anchors: map[*models.Struct]string{},
+		structs: map[*models.Struct]*docStruct{},
+	}
+	doc := &document{}
+	for i := range packages {
+		p := &packages[i]
+		anchor := "package-" + p.Name
+		b.registerStructs(anchor, p.Structs)
+		doc.Packages = append(doc.Packages, docPackage{
+			Name:       p.Name,
+			Identifier: p.Identifier,
+			Anchor:     anchor,
+			Doc:        p.Doc,
+		})
+	}
+
+	// Fields are converted once all anchors are known, so references to
+	// structures declared after them can be linked
+	for i := range packages {
+		p := &packages[i]
+		dp := &doc.Packages[i]
+		fields, err := b.fields(p.Scope(), p.Fields, docReference{Name: p.Name, Anchor: dp.Anchor})
+		if err != nil {
+			return nil, fmt.Errorf("package %s: %s", p.Name, err)
+		}
+		dp.Fields = fields
+		if err := b.collectStructs(p.Scope(), p.Structs, p.Name, dp.Anchor); err != nil {
+			return nil, fmt.Errorf("package %s: %s", p.Name, err)
+		}
+	}
+	for i := range packages {
+		doc.Packages[i].Structs = b.flatten(packages[i].Structs)
+	}
+	return doc, nil
+}
+
+// registerStructs assigns anchors to structures, qualified by their parents
+// as names are only unique within a scope
+func (b *docBuilder) registerStructs(prefix string, sArr []models.Struct) {
+	for i := range sArr {
+		anchor := prefix + "-" + sArr[i].Name
+		b.anchors[&sArr[i]] = anchor
+		b.registerStructs(anchor, sArr[i].Structs)
+	}
+}
+
+// collectStructs converts structures declared by a package or structure,
+// identified by its path
+func (b *docBuilder) collectStructs(scope *models.Scope, sArr []models.Struct, parent, parentAnchor string) error {
+	for i := range sArr {
+		s := &sArr[i]
+		ds := b.structs[s]
+		if ds == nil {
+			ds = &docStruct{}
+			b.structs[s] = ds
+		}
+		ds.Path = parent + "." + s.Name
+		ds.Name = s.Name
+		ds.Anchor = b.anchors[s]
+		ds.Doc = s.Doc
+		ds.Parent = parent
+		ds.ParentAnchor = parentAnchor
+
+		inner := &models.Scope{Structs: s.Structs, Parent: scope}
+		fields, err := b.fields(inner, s.Fields, docReference{Name: ds.Path, Anchor: ds.Anchor})
+		if err != nil {
+			return err
+		}
+		ds.Fields = fields
+		if err := b.collectStructs(inner, s.Structs, ds.Path, ds.Anchor); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// flatten lists structures of a package, parents first
+func (b *docBuilder) flatten(sArr []models.Struct) []docStruct {
+	var result []docStruct
+	for i := range sArr {
+		result = append(result, *b.structs[&sArr[i]])
+		result = append(result, b.flatten(sArr[i].Structs)...)
+	}
+	return result
+}
+
+// fields converts fields of a package or structure, registering references to
+// structures used by them
+func (b *docBuilder) fields(scope *models.Scope, fArr []models.Field, owner docReference) ([]docField, error) {
+	var result []docField
+	for i, f := range fArr {
+		df := docField{
+			Position:   i,
+			Name:       f.Name,
+			Deprecated: f.HasAttribute(models.AttributeDeprecated),
+			Doc:        f.Doc,
+		}
+		if f.IsArray() {
+			df.Size = f.Size
+		}
+		if f.Type.Source == models.SourceUser {
+			s, _, ok := scope.Resolve(f.Type.CustomType)
+			if !ok {
+				return nil, fmt.Errorf("cannot resolve structure %s referenced by %s", f.Type.CustomType, f.Name)
+			}
+			df.Type = f.Type.CustomType
+			df.TypeAnchor = b.anchors[s]
+
+			ds := b.structs[s]
+			if ds == nil {
+				ds = &docStruct{}
+				b.structs[s] = ds
+			}
+			ds.UsedBy = append(ds.UsedBy, docReference{
+				Name:   owner.Name + "." + f.Name,
+				Anchor: owner.Anchor,
+			})
+		} else {
+			df.Type = string(f.Type.NativeType)
+		}
+		result = append(result, df)
+	}
+	return result, nil
+}
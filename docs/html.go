@@ -0,0 +1,98 @@
+package docs
+
+import (
+	"bytes"
+	"html/template"
+
+	"github.com/ludwieg/ludco/models"
+)
+
+// HTML renders documentation as a single, self-contained HTML page
+type HTML struct{}
+
+func (h HTML) Extension() string {
+	return ".html"
+}
+
+func (h HTML) Render(packages models.PackageList) ([]byte, error) {
+	doc, err := newDocument(packages)
+	if err != nil {
+		return nil, err
+	}
+	tpl, err := template.New("html").Parse(htmlTemplate)
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	if err := tpl.Execute(&buf, doc); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+const htmlTemplate = `<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>Protocol reference</title>
+<style>
+body { font-family: -apple-system, "Segoe UI", Helvetica, Arial, sans-serif; margin: 2em auto; max-width: 60em; padding: 0 1em; color: #24292e; }
+code { font-family: SFMono-Regular, Menlo, Consolas, monospace; font-size: 90%; }
+table { border-collapse: collapse; margin: 1em 0; width: 100%; }
+th, td { border: 1px solid #d0d7de; padding: 0.3em 0.6em; text-align: left; vertical-align: top; }
+th { background: #f6f8fa; }
+td.number { text-align: right; width: 5em; }
+tr.deprecated td { color: #8c959f; }
+tr.deprecated td code { text-decoration: line-through; }
+.deprecated-label { color: #cf222e; font-weight: bold; }
+.doc { white-space: pre-wrap; }
+section.package { border-top: 2px solid #d0d7de; margin-top: 2em; }
+</style>
+</head>
+<body>
+<h1>Protocol reference</h1>
+
+<h2>Packages</h2>
+<table>
+<tr><th>Identifier</th><th>Package</th><th>Fields</th><th>Structures</th></tr>
+{{- range .Packages}}
+<tr><td><code>{{.Identifier}}</code></td><td><a href="#{{.Anchor}}">{{.Name}}</a></td><td class="number">{{len .Fields}}</td><td class="number">{{len .Structs}}</td></tr>
+{{- end}}
+</table>
+{{range .Packages}}
+<section class="package" id="{{.Anchor}}">
+<h2>{{.Name}}</h2>
+<p>Identifier: <code>{{.Identifier}}</code></p>
+{{- if .Doc}}
+<p class="doc">{{.Doc}}</p>
+{{- end}}
+{{template "fields" .Fields}}
+{{- range .Structs}}
+
+<section class="struct" id="{{.Anchor}}">
+<h3>{{.Path}}</h3>
+<p>Declared by <a href="#{{.ParentAnchor}}">{{.Parent}}</a>.
+{{- if .UsedBy}} Used by {{range $i, $r := .UsedBy}}{{if $i}}, {{end}}<a href="#{{$r.Anchor}}">{{$r.Name}}</a>{{end}}.{{end}}</p>
+{{- if .Doc}}
+<p class="doc">{{.Doc}}</p>
+{{- end}}
+{{template "fields" .Fields}}
+</section>
+{{- end}}
+</section>
+{{end}}
+</body>
+</html>
+{{- define "fields"}}
+{{- if .}}
+<table>
+<tr><th>Position</th><th>Field</th><th>Type</th><th>Description</th></tr>
+{{- range .}}
+<tr{{if .Deprecated}} class="deprecated"{{end}}><td class="number">{{.Position}}</td><td><code>{{.Name}}</code></td><td>{{if .TypeAnchor}}<a href="#{{.TypeAnchor}}"><code>{{.TypeName}}</code></a>{{else}}<code>{{.TypeName}}</code>{{end}}</td><td>{{if .Deprecated}}<span class="deprecated-label">Deprecated.</span>{{if .Doc}} {{end}}{{end}}{{if .Doc}}<span class="doc">{{.Doc}}</span>{{end}}</td></tr>
+{{- end}}
+</table>
+{{- else}}
+<p>No fields are declared.</p>
+{{- end}}
+{{- end}}
+`
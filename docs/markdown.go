@@ -0,0 +1,88 @@
+package docs
+
+import (
+	"bytes"
+	"strings"
+	"text/template"
+
+	"github.com/ludwieg/ludco/models"
+)
+
+// Markdown renders documentation as a single Markdown document. Anchors are
+// declared through HTML elements, so links work regardless of how headings
+// are converted by renderers.
+type Markdown struct{}
+
+var markdownFuncs = template.FuncMap{
+	// cell escapes text for use within table cells
+	"cell": func(text string) string {
+		text = strings.Replace(text, "|", "\\|", -1)
+		return strings.Join(strings.Fields(text), " ")
+	},
+}
+
+func (m Markdown) Extension() string {
+	return ".md"
+}
+
+func (m Markdown) Render(packages models.PackageList) ([]byte, error) {
+	doc, err := newDocument(packages)
+	if err != nil {
+		return nil, err
+	}
+	tpl, err := template.New("markdown").Funcs(markdownFuncs).Parse(markdownTemplate)
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	if err := tpl.Execute(&buf, doc); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+const markdownTemplate = `# Protocol reference
+
+## Packages
+
+| Identifier | Package | Fields | Structures |
+|------------|---------|-------:|-----------:|
+{{- range .Packages}}
+| ` + "`{{.Identifier}}`" + ` | [{{.Name}}](#{{.Anchor}}) | {{len .Fields}} | {{len .Structs}} |
+{{- end}}
+{{range .Packages}}
+<a id="{{.Anchor}}"></a>
+## {{.Name}}
+
+Identifier: ` + "`{{.Identifier}}`" + `
+{{- if .Doc}}
+
+{{.Doc}}
+{{- end}}
+{{template "fields" .Fields}}
+{{- range .Structs}}
+
+<a id="{{.Anchor}}"></a>
+### {{.Path}}
+
+Declared by [{{.Parent}}](#{{.ParentAnchor}}).
+{{- if .UsedBy}} Used by {{range $i, $r := .UsedBy}}{{if $i}}, {{end}}[{{$r.Name}}](#{{$r.Anchor}}){{end}}.{{end}}
+{{- if .Doc}}
+
+{{.Doc}}
+{{- end}}
+{{template "fields" .Fields}}
+{{- end}}
+{{end}}
+{{- define "fields"}}
+{{- if .}}
+| Position | Field | Type | Description |
+|---------:|-------|------|-------------|
+{{- range .}}
+| {{.Position}} | ` + "`{{.Name}}`" + ` | {{if .TypeAnchor}}[` + "`{{.TypeName}}`" + `](#{{.TypeAnchor}}){{else}}` + "`{{.TypeName}}`" + `{{end}} | {{if .Deprecated}}**Deprecated.**{{if .Doc}} {{end}}{{end}}{{cell .Doc}} |
+{{- end}}
+{{- else}}
+No fields are declared.
+{{- end}}
+{{- end}}
+`
@@ -0,0 +1,14 @@
+// Package export converts Ludwieg packages into schemas of other formats, so
+// they can be shared with tools and teams that do not use Ludwieg.
+package export
+
+import "github.com/ludwieg/ludco/models"
+
+// Exporter converts packages into documents of another schema format
+type Exporter interface {
+	// Extension returns the file extension used by exported documents
+	Extension() string
+
+	// Export returns the document describing a package
+	Export(pkg *models.Package) ([]byte, error)
+}
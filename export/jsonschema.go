@@ -0,0 +1,246 @@
+package export
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	"github.com/ludwieg/ludco/models"
+)
+
+// JSONSchema exports packages as JSON Schema (draft 2020-12) documents
+// describing their JSON representation, as produced by codec.ToJSON and held
+// by the `value` of messages printed by `ludco decode --json`. Structures are
+// declared under `$defs`, and every value may be null.
+type JSONSchema struct{}
+
+// jsonSchemaAny holds the definition name of values held by any fields. Dots
+// cannot be used by structure names, so it never collides with them.
+const jsonSchemaAny = "ludwieg.any"
+
+// jsonObject holds members of a JSON object, preserving their order
+type jsonObject []jsonMember
+
+type jsonMember struct {
+	key   string
+	value interface{}
+}
+
+func (o jsonObject) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteString("{")
+	for i, m := range o {
+		if i > 0 {
+			buf.WriteString(",")
+		}
+		key, _ := json.Marshal(m.key)
+		value, err := json.Marshal(m.value)
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(key)
+		buf.WriteString(":")
+		buf.Write(value)
+	}
+	buf.WriteString("}")
+	return buf.Bytes(), nil
+}
+
+func (j JSONSchema) Extension() string {
+	return ".schema.json"
+}
+
+func (j JSONSchema) Export(pkg *models.Package) ([]byte, error) {
+	defs := map[*models.Struct]string{}
+	jsonSchemaRegisterStructs(defs, "", pkg.Structs)
+
+	var defList jsonObject
+	usesAny := false
+	properties, err := j.properties(defs, pkg.Scope(), pkg.Fields, &usesAny)
+	if err != nil {
+		return nil, err
+	}
+	if err := j.definitions(&defList, defs, pkg.Scope(), pkg.Structs, &usesAny); err != nil {
+		return nil, err
+	}
+	if usesAny {
+		defList = append(defList, jsonMember{jsonSchemaAny, jsonSchemaAnyDefinition()})
+	}
+
+	doc := jsonObject{
+		{"$schema", "https://json-schema.org/draft/2020-12/schema"},
+		{"title", pkg.Name},
+		{"description", fmt.Sprintf("Ludwieg package %s, identified by %s", pkg.Name, pkg.Identifier)},
+		{"type", "object"},
+		{"properties", properties},
+		{"additionalProperties", false},
+	}
+	if len(defList) > 0 {
+		doc = append(doc, jsonMember{"$defs", defList})
+	}
+
+	data, err := json.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(data, '\n'), nil
+}
+
+// jsonSchemaRegisterStructs assigns definition names to structures. Nested
+// structures are qualified by their parents, as names are only unique within
+// a scope.
+func jsonSchemaRegisterStructs(defs map[*models.Struct]string, prefix string, sArr []models.Struct) {
+	for i := range sArr {
+		name := prefix + sArr[i].Name
+		defs[&sArr[i]] = name
+		jsonSchemaRegisterStructs(defs, name+".", sArr[i].Structs)
+	}
+}
+
+// definitions appends definitions of structures, along with structures
+// declared by them
+func (j JSONSchema) definitions(out *jsonObject, defs map[*models.Struct]string, scope *models.Scope, sArr []models.Struct, usesAny *bool) error {
+	for i := range sArr {
+		s := &sArr[i]
+		inner := &models.Scope{Structs: s.Structs, Parent: scope}
+		properties, err := j.properties(defs, inner, s.Fields, usesAny)
+		if err != nil {
+			return err
+		}
+		*out = append(*out, jsonMember{defs[s], jsonObject{
+			{"type", "object"},
+			{"properties", properties},
+			{"additionalProperties", false},
+		}})
+		if err := j.definitions(out, defs, inner, s.Structs, usesAny); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (j JSONSchema) properties(defs map[*models.Struct]string, scope *models.Scope, fArr []models.Field, usesAny *bool) (jsonObject, error) {
+	properties := jsonObject{}
+	for _, f := range fArr {
+		schema, err := j.valueSchema(defs, scope, &f)
+		if err != nil {
+			return nil, err
+		}
+		if f.Type.NativeType == models.TypeAny {
+			*usesAny = true
+		}
+
+		if f.IsArray() {
+			array := jsonObject{
+				{"type", []string{"array", "null"}},
+				{"items", schema},
+			}
+			if f.Size != "*" {
+				size, _ := strconv.Atoi(f.Size)
+				array = append(array, jsonMember{"minItems", size}, jsonMember{"maxItems", size})
+			}
+			schema = array
+		}
+		if f.HasAttribute(models.AttributeDeprecated) {
+			schema = append(schema, jsonMember{"deprecated", true})
+		}
+		properties = append(properties, jsonMember{f.Name, schema})
+	}
+	return properties, nil
+}
+
+// valueSchema returns the schema of a single value held by a field
+func (j JSONSchema) valueSchema(defs map[*models.Struct]string, scope *models.Scope, f *models.Field) (jsonObject, error) {
+	if f.Type.Source == models.SourceUser {
+		s, _, ok := scope.Resolve(f.Type.CustomType)
+		if !ok {
+			return nil, fmt.Errorf("cannot resolve structure %s referenced by %s", f.Type.CustomType, f.Name)
+		}
+		return jsonSchemaNullable(jsonObject{{"$ref", "#/$defs/" + defs[s]}}), nil
+	}
+	if f.Type.NativeType == models.TypeAny {
+		return jsonObject{{"$ref", "#/$defs/" + jsonSchemaAny}}, nil
+	}
+	schema, ok := jsonSchemaNative(f.Type.NativeType)
+	if !ok {
+		return nil, fmt.Errorf("cannot export type %s of %s", f.Type.NativeType, f.Name)
+	}
+	return schema, nil
+}
+
+// jsonSchemaNullable allows a schema to also match null
+func jsonSchemaNullable(schema jsonObject) jsonObject {
+	return jsonObject{{"anyOf", []interface{}{schema, jsonObject{{"type", "null"}}}}}
+}
+
+// jsonSchemaNative returns the schema of a native value. Nulls are accepted,
+// as every value may be empty. 64-bit integers are represented by decimal
+// strings, avoiding precision loss, though numbers are also accepted.
+func jsonSchemaNative(t models.NativeType) (jsonObject, bool) {
+	switch t {
+	case models.TypeUint8, models.TypeByte:
+		return jsonObject{{"type", []string{"integer", "null"}}, {"minimum", 0}, {"maximum", 255}}, true
+	case models.TypeUint32:
+		return jsonObject{{"type", []string{"integer", "null"}}, {"minimum", 0}, {"maximum", uint64(1<<32 - 1)}}, true
+	case models.TypeUint64, models.TypeDynInt:
+		return jsonObject{
+			{"type", []string{"string", "integer", "null"}},
+			{"pattern", "^[0-9]+$"},
+			{"minimum", 0},
+			{"maximum", uint64(1<<64 - 1)},
+		}, true
+	case models.TypeDouble:
+		return jsonObject{{"type", []string{"number", "null"}}}, true
+	case models.TypeString:
+		return jsonObject{{"type", []string{"string", "null"}}}, true
+	case models.TypeBlob:
+		return jsonObject{{"type", []string{"string", "null"}}, {"contentEncoding", "base64"}}, true
+	case models.TypeBool:
+		return jsonObject{{"type", []string{"boolean", "null"}}}, true
+	case models.TypeUUID:
+		return jsonObject{{"type", []string{"string", "null"}}, {"format", "uuid"}}, true
+	}
+	return nil, false
+}
+
+// jsonSchemaAnyDefinition returns the definition of values held by any
+// fields, represented by objects containing their type and value
+func jsonSchemaAnyDefinition() jsonObject {
+	var variants []interface{}
+	for _, t := range []models.NativeType{
+		models.TypeUint8,
+		models.TypeUint32,
+		models.TypeUint64,
+		models.TypeDouble,
+		models.TypeString,
+		models.TypeBlob,
+		models.TypeBool,
+		models.TypeUUID,
+		models.TypeDynInt,
+	} {
+		value, _ := jsonSchemaNative(t)
+		variants = append(variants, jsonSchemaAnyVariant(string(t), value))
+	}
+	variants = append(variants, jsonSchemaAnyVariant("array", jsonObject{
+		{"type", "array"},
+		{"items", jsonObject{{"$ref", "#/$defs/" + jsonSchemaAny}}},
+	}))
+
+	return jsonObject{
+		{"description", "Value held by an any field, along with its type"},
+		{"oneOf", append(variants, jsonObject{{"type", "null"}})},
+	}
+}
+
+func jsonSchemaAnyVariant(t string, value jsonObject) jsonObject {
+	return jsonObject{
+		{"type", "object"},
+		{"properties", jsonObject{
+			{"type", jsonObject{{"const", t}}},
+			{"value", value},
+		}},
+		{"required", []string{"type", "value"}},
+		{"additionalProperties", false},
+	}
+}
@@ -0,0 +1,38 @@
+package export
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestJSONSchemaArrays(t *testing.T) {
+	pkg := testPackage(t, `package sample {
+    id 0x01
+
+    uint32[3]   numbers
+    string[*]   names
+}
+`)
+	out, err := JSONSchema{}.Export(pkg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var doc struct {
+		Properties map[string]map[string]interface{} `json:"properties"`
+	}
+	if err := json.Unmarshal(out, &doc); err != nil {
+		t.Fatal(err)
+	}
+
+	numbers := doc.Properties["numbers"]
+	if numbers["minItems"] != 3.0 || numbers["maxItems"] != 3.0 {
+		t.Errorf("numbers bounded by minItems %v and maxItems %v, expected 3", numbers["minItems"], numbers["maxItems"])
+	}
+	names := doc.Properties["names"]
+	if _, ok := names["minItems"]; ok {
+		t.Errorf("names bounded by minItems %v, expected no bound", names["minItems"])
+	}
+	if _, ok := names["maxItems"]; ok {
+		t.Errorf("names bounded by maxItems %v, expected no bound", names["maxItems"])
+	}
+}
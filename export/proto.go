@@ -0,0 +1,167 @@
+package export
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"github.com/ludwieg/ludco/models"
+)
+
+// Proto exports packages as Protocol Buffers (proto3) schemas. Each package
+// becomes a message, and its structures become nested messages. Field
+// numbers follow the order fields are declared on Ludwieg.
+type Proto struct {
+	// Package holds the protobuf package declared by documents, if any
+	Package string
+}
+
+// protoTypes maps native types to their protobuf counterparts. Types without
+// an exact counterpart are widened.
+var protoTypes = map[models.NativeType]string{
+	models.TypeUint8:  "uint32",
+	models.TypeByte:   "uint32",
+	models.TypeUint32: "uint32",
+	models.TypeUint64: "uint64",
+	models.TypeDouble: "double",
+	models.TypeString: "string",
+	models.TypeBlob:   "bytes",
+	models.TypeBool:   "bool",
+	models.TypeUUID:   "bytes",
+	models.TypeAny:    ".google.protobuf.Any",
+	models.TypeDynInt: "uint64",
+}
+
+func (p Proto) Extension() string {
+	return ".proto"
+}
+
+func (p Proto) Export(pkg *models.Package) ([]byte, error) {
+	name := protoMessageName(pkg.Name)
+	qualified := "." + name
+	if p.Package != "" {
+		qualified = "." + p.Package + qualified
+	}
+	messages := map[*models.Struct]string{}
+	protoRegisterStructs(messages, qualified, pkg.Structs)
+
+	var body bytes.Buffer
+	usesAny := false
+	err := p.writeMessage(&body, messages, pkg.Scope(), name, pkg.Fields, pkg.Structs, 0, &usesAny)
+	if err != nil {
+		return nil, err
+	}
+
+	var out bytes.Buffer
+	out.WriteString("// WARNING: Automatically generated by ludco. DO NOT EDIT.\n\n")
+	out.WriteString("syntax = \"proto3\";\n\n")
+	if p.Package != "" {
+		fmt.Fprintf(&out, "package %s;\n\n", p.Package)
+	}
+	if usesAny {
+		out.WriteString("import \"google/protobuf/any.proto\";\n\n")
+	}
+	fmt.Fprintf(&out, "// Ludwieg package %s, identified by %s\n", pkg.Name, pkg.Identifier)
+	out.Write(body.Bytes())
+	return out.Bytes(), nil
+}
+
+// protoMessageName converts a Ludwieg identifier into a message name
+func protoMessageName(name string) string {
+	var parts []string
+	for _, s := range strings.Split(name, "_") {
+		if s != "" {
+			parts = append(parts, strings.ToUpper(s[:1])+s[1:])
+		}
+	}
+	return strings.Join(parts, "")
+}
+
+// protoRegisterStructs assigns fully qualified message names to structures.
+// Names start with a dot, followed by the protobuf package and the package
+// message, so references never resolve to fields or messages of inner
+// scopes sharing their names.
+func protoRegisterStructs(messages map[*models.Struct]string, prefix string, sArr []models.Struct) {
+	for i := range sArr {
+		name := prefix + "." + protoMessageName(sArr[i].Name)
+		messages[&sArr[i]] = name
+		protoRegisterStructs(messages, name, sArr[i].Structs)
+	}
+}
+
+// writeMessage writes the message of a package or structure, along with
+// messages of structures declared by it
+func (p Proto) writeMessage(out *bytes.Buffer, messages map[*models.Struct]string, scope *models.Scope, name string, fArr []models.Field, sArr []models.Struct, depth int, usesAny *bool) error {
+	indent := strings.Repeat("  ", depth)
+	fmt.Fprintf(out, "%smessage %s {\n", indent, name)
+
+	for i, f := range fArr {
+		t, note, err := p.fieldType(messages, scope, &f)
+		if err != nil {
+			return err
+		}
+		if f.Type.NativeType == models.TypeAny {
+			*usesAny = true
+		}
+
+		label := ""
+		switch {
+		case f.IsArray():
+			label = "repeated "
+			if f.Size != "*" {
+				note = strings.TrimPrefix(note+", at most "+f.Size+" items", ", ")
+			}
+		case f.Type.Source == models.SourceNative && f.Type.NativeType != models.TypeAny:
+			// Scalars keep track of presence, as Ludwieg values may be empty
+			label = "optional "
+		}
+
+		options := ""
+		if f.HasAttribute(models.AttributeDeprecated) {
+			options = " [deprecated = true]"
+		}
+		if note != "" {
+			note = " // " + note
+		}
+		fmt.Fprintf(out, "%s  %s%s %s = %d%s;%s\n", indent, label, t, f.Name, i+1, options, note)
+	}
+
+	for i := range sArr {
+		s := &sArr[i]
+		if len(fArr) > 0 || i > 0 {
+			out.WriteString("\n")
+		}
+		inner := &models.Scope{Structs: s.Structs, Parent: scope}
+		if err := p.writeMessage(out, messages, inner, protoMessageName(s.Name), s.Fields, s.Structs, depth+1, usesAny); err != nil {
+			return err
+		}
+	}
+
+	fmt.Fprintf(out, "%s}\n", indent)
+	return nil
+}
+
+// fieldType returns the protobuf type of a field, along with a note
+// describing the original type when it had to be widened
+func (p Proto) fieldType(messages map[*models.Struct]string, scope *models.Scope, f *models.Field) (string, string, error) {
+	if f.Type.Source == models.SourceUser {
+		s, _, ok := scope.Resolve(f.Type.CustomType)
+		if !ok {
+			return "", "", fmt.Errorf("cannot resolve structure %s referenced by %s", f.Type.CustomType, f.Name)
+		}
+		return messages[s], "", nil
+	}
+
+	t, ok := protoTypes[f.Type.NativeType]
+	if !ok {
+		return "", "", fmt.Errorf("cannot export type %s of %s", f.Type.NativeType, f.Name)
+	}
+	note := ""
+	switch f.Type.NativeType {
+	case models.TypeUint8, models.TypeByte, models.TypeDynInt:
+		note = string(f.Type.NativeType)
+	case models.TypeUUID:
+		note = "uuid, 16 bytes"
+	}
+	return t, note, nil
+}
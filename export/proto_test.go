@@ -0,0 +1,58 @@
+package export
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/ludwieg/ludco/models"
+	"github.com/ludwieg/ludco/parser"
+)
+
+// testPackage parses a definition file declaring a single package
+func testPackage(t *testing.T, src string) *models.Package {
+	out, err := parser.Parse("test.lud", []byte(src))
+	if err != nil {
+		t.Fatalf("parsing test package: %s", err)
+	}
+	return models.ConvertASTPackage(out.([]interface{})[0].(parser.Package))
+}
+
+func TestProtoQualifiedNames(t *testing.T) {
+	pkg := testPackage(t, `package node {
+    id 0x01
+
+    @node       root
+    any         value
+
+    struct node {
+        @node       parent
+    }
+}
+`)
+	tests := []struct {
+		pkg      string
+		expected []string
+	}{
+		{"", []string{
+			"  .Node.Node root = 1;",
+			"  .google.protobuf.Any value = 2;",
+			"    .Node.Node parent = 1;",
+		}},
+		{"acme.protocol", []string{
+			"package acme.protocol;",
+			"  .acme.protocol.Node.Node root = 1;",
+			"    .acme.protocol.Node.Node parent = 1;",
+		}},
+	}
+	for _, tt := range tests {
+		out, err := Proto{Package: tt.pkg}.Export(pkg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, line := range tt.expected {
+			if !strings.Contains(string(out), line+"\n") {
+				t.Errorf("package %q: output lacks %q:\n%s", tt.pkg, line, out)
+			}
+		}
+	}
+}
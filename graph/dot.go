@@ -0,0 +1,77 @@
+package graph
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/ludwieg/ludco/models"
+)
+
+// DOT renders graphs in the Graphviz DOT language. Packages are drawn as
+// clusters, and fields are listed within their nodes.
+type DOT struct{}
+
+func (d DOT) Render(packages models.PackageList) ([]byte, error) {
+	g, err := newGraph(packages)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString("digraph ludwieg {\n")
+	buf.WriteString("\tgraph [rankdir=LR, fontname=\"Helvetica\"];\n")
+	buf.WriteString("\tnode [shape=plaintext, fontname=\"Helvetica\"];\n")
+	buf.WriteString("\tedge [fontname=\"Helvetica\", fontsize=10];\n")
+
+	for i, c := range g.Clusters {
+		fmt.Fprintf(&buf, "\n\tsubgraph cluster_%d {\n", i)
+		fmt.Fprintf(&buf, "\t\tlabel=%q;\n", c.title())
+		for _, n := range c.Nodes {
+			fmt.Fprintf(&buf, "\t\t%s [label=<%s>];\n", n.ID, d.label(n))
+		}
+		buf.WriteString("\t}\n")
+	}
+
+	if len(g.Edges) > 0 {
+		buf.WriteString("\n")
+	}
+	for _, e := range g.Edges {
+		fmt.Fprintf(&buf, "\t%s -> %s [%s];\n", e.From, e.To, d.attributes(e))
+	}
+	buf.WriteString("}\n")
+	return buf.Bytes(), nil
+}
+
+// label returns an HTML-like label listing the fields of a node
+func (d DOT) label(n *node) string {
+	color := structColor
+	if n.Package {
+		color = packageColor
+	}
+	var buf bytes.Buffer
+	buf.WriteString(`<TABLE BORDER="0" CELLBORDER="1" CELLSPACING="0">`)
+	fmt.Fprintf(&buf, `<TR><TD BGCOLOR="%s"><B>%s</B></TD></TR>`, color, n.Name)
+	for _, f := range n.Fields {
+		text := f.Type + " " + f.Name
+		if f.Deprecated {
+			text = fmt.Sprintf(`<FONT COLOR="%s"><S>%s</S></FONT>`, deprecatedColor, text)
+		}
+		fmt.Fprintf(&buf, `<TR><TD ALIGN="LEFT">%s</TD></TR>`, text)
+	}
+	buf.WriteString("</TABLE>")
+	return buf.String()
+}
+
+func (d DOT) attributes(e edge) string {
+	if e.Nested {
+		return "style=dotted, arrowhead=none"
+	}
+	attrs := fmt.Sprintf("label=%q", e.Label)
+	if e.Array {
+		attrs += ", arrowhead=crow"
+	}
+	if e.Deprecated {
+		attrs += fmt.Sprintf(", color=%q, fontcolor=%q, style=dashed", deprecatedColor, deprecatedColor)
+	}
+	return attrs
+}
@@ -0,0 +1,169 @@
+// Package graph renders the relationships between Ludwieg packages and
+// structures as graph descriptions.
+package graph
+
+import (
+	"fmt"
+
+	"github.com/ludwieg/ludco/models"
+)
+
+// Renderer produces a single graph describing all packages of a project
+type Renderer interface {
+	Render(packages models.PackageList) ([]byte, error)
+}
+
+// Colors used to distinguish packages, structures, and deprecated fields
+const (
+	packageColor    = "#dde7f3"
+	structColor     = "#f6f8fa"
+	deprecatedColor = "#cf222e"
+)
+
+// graph holds nodes grouped by package, and edges between them
+type graph struct {
+	Clusters []cluster
+	Edges    []edge
+}
+
+// cluster groups the nodes declared by a package, the package itself first
+type cluster struct {
+	Name       string
+	Identifier string
+	Nodes      []*node
+}
+
+type node struct {
+	ID      string
+	Name    string
+	Package bool
+	Fields  []field
+}
+
+type field struct {
+	Name       string
+	Type       string
+	Deprecated bool
+}
+
+// edge represents either a field referencing a structure, or a package or
+// structure declaring a nested structure
+type edge struct {
+	From       string
+	To         string
+	Label      string
+	Array      bool
+	Deprecated bool
+	Nested     bool
+}
+
+// graphBuilder converts packages into a graph
+type graphBuilder struct {
+	ids   map[*models.Struct]string
+	count int
+	graph graph
+}
+
+func newGraph(packages models.PackageList) (*graph, error) {
+	b := graphBuilder{ids: map[*models.Struct]string{}}
+	ids := make([]string, len(packages))
+	for i := range packages {
+		ids[i] = b.nextID()
+		b.registerStructs(packages[i].Structs)
+	}
+
+	// Edges are created once all identifiers are known, so references to
+	// structures declared after them can be resolved
+	for i := range packages {
+		p := &packages[i]
+		root := &node{ID: ids[i], Name: p.Name, Package: true}
+		c := cluster{Name: p.Name, Identifier: p.Identifier, Nodes: []*node{root}}
+		if err := b.fields(p.Scope(), root, p.Fields); err != nil {
+			return nil, fmt.Errorf("package %s: %s", p.Name, err)
+		}
+		if err := b.structs(p.Scope(), &c, root.ID, p.Structs); err != nil {
+			return nil, fmt.Errorf("package %s: %s", p.Name, err)
+		}
+		b.graph.Clusters = append(b.graph.Clusters, c)
+	}
+	return &b.graph, nil
+}
+
+func (b *graphBuilder) nextID() string {
+	id := fmt.Sprintf("n%d", b.count)
+	b.count++
+	return id
+}
+
+// registerStructs assigns identifiers to structures, as names are only unique
+// within a scope
+func (b *graphBuilder) registerStructs(sArr []models.Struct) {
+	for i := range sArr {
+		b.ids[&sArr[i]] = b.nextID()
+		b.registerStructs(sArr[i].Structs)
+	}
+}
+
+// structs adds structures declared by a package or structure to its cluster,
+// walking them in the same order as `ludco show`
+func (b *graphBuilder) structs(scope *models.Scope, c *cluster, parent string, sArr []models.Struct) error {
+	for i := range sArr {
+		s := &sArr[i]
+		n := &node{ID: b.ids[s], Name: s.Name}
+		c.Nodes = append(c.Nodes, n)
+		b.graph.Edges = append(b.graph.Edges, edge{From: parent, To: n.ID, Nested: true})
+
+		inner := &models.Scope{Structs: s.Structs, Parent: scope}
+		if err := b.fields(inner, n, s.Fields); err != nil {
+			return err
+		}
+		if err := b.structs(inner, c, n.ID, s.Structs); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// fields lists fields of a node, adding edges for references to structures
+func (b *graphBuilder) fields(scope *models.Scope, n *node, fArr []models.Field) error {
+	for _, f := range fArr {
+		fd := field{
+			Name:       f.Name,
+			Deprecated: f.HasAttribute(models.AttributeDeprecated),
+		}
+		if f.Type.Source == models.SourceUser {
+			fd.Type = "@" + f.Type.CustomType
+		} else {
+			fd.Type = string(f.Type.NativeType)
+		}
+		if f.IsArray() {
+			fd.Type += "[" + f.Size + "]"
+		}
+		n.Fields = append(n.Fields, fd)
+
+		if f.Type.Source != models.SourceUser {
+			continue
+		}
+		s, _, ok := scope.Resolve(f.Type.CustomType)
+		if !ok {
+			return fmt.Errorf("cannot resolve structure %s referenced by %s", f.Type.CustomType, f.Name)
+		}
+		label := f.Name
+		if f.IsArray() {
+			label += " [" + f.Size + "]"
+		}
+		b.graph.Edges = append(b.graph.Edges, edge{
+			From:       n.ID,
+			To:         b.ids[s],
+			Label:      label,
+			Array:      f.IsArray(),
+			Deprecated: fd.Deprecated,
+		})
+	}
+	return nil
+}
+
+// title returns the caption of a cluster
+func (c cluster) title() string {
+	return fmt.Sprintf("%s (%s)", c.Name, c.Identifier)
+}
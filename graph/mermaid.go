@@ -0,0 +1,80 @@
+package graph
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"github.com/ludwieg/ludco/models"
+)
+
+// Mermaid renders graphs as Mermaid flowcharts. Packages are drawn as
+// subgraphs, and fields are listed within their nodes.
+type Mermaid struct{}
+
+func (m Mermaid) Render(packages models.PackageList) ([]byte, error) {
+	g, err := newGraph(packages)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	var pkgNodes, structNodes []string
+	buf.WriteString("flowchart LR\n")
+	for i, c := range g.Clusters {
+		fmt.Fprintf(&buf, "    subgraph c%d [%q]\n", i, c.title())
+		for _, n := range c.Nodes {
+			fmt.Fprintf(&buf, "        %s[\"%s\"]\n", n.ID, m.label(n))
+			if n.Package {
+				pkgNodes = append(pkgNodes, n.ID)
+			} else {
+				structNodes = append(structNodes, n.ID)
+			}
+		}
+		buf.WriteString("    end\n")
+	}
+
+	// Links are styled by their position, as Mermaid does not support
+	// styling them individually
+	var deprecated []string
+	for i, e := range g.Edges {
+		switch {
+		case e.Nested:
+			fmt.Fprintf(&buf, "    %s -.- %s\n", e.From, e.To)
+		case e.Array:
+			fmt.Fprintf(&buf, "    %s ==>|%q| %s\n", e.From, e.Label, e.To)
+		default:
+			fmt.Fprintf(&buf, "    %s -->|%q| %s\n", e.From, e.Label, e.To)
+		}
+		if e.Deprecated {
+			deprecated = append(deprecated, fmt.Sprint(i))
+		}
+	}
+	if len(deprecated) > 0 {
+		fmt.Fprintf(&buf, "    linkStyle %s stroke:%s,color:%s,stroke-dasharray:4\n",
+			strings.Join(deprecated, ","), deprecatedColor, deprecatedColor)
+	}
+
+	fmt.Fprintf(&buf, "    classDef package fill:%s\n", packageColor)
+	fmt.Fprintf(&buf, "    classDef structure fill:%s\n", structColor)
+	if len(pkgNodes) > 0 {
+		fmt.Fprintf(&buf, "    class %s package\n", strings.Join(pkgNodes, ","))
+	}
+	if len(structNodes) > 0 {
+		fmt.Fprintf(&buf, "    class %s structure\n", strings.Join(structNodes, ","))
+	}
+	return buf.Bytes(), nil
+}
+
+// label returns the contents of a node, listing its fields
+func (m Mermaid) label(n *node) string {
+	lines := []string{"<b>" + n.Name + "</b>"}
+	for _, f := range n.Fields {
+		text := f.Type + " " + f.Name
+		if f.Deprecated {
+			text = fmt.Sprintf("<s>%s</s> <i>deprecated</i>", text)
+		}
+		lines = append(lines, text)
+	}
+	return strings.Join(lines, "<br/>")
+}
@@ -0,0 +1,114 @@
+// Package importer converts schemas of other formats into Ludwieg packages,
+// and formats packages as definition files.
+package importer
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/ludwieg/ludco/models"
+)
+
+// Importer reads schemas of another format and returns the equivalent
+// packages, along with warnings about constructs that could not be
+// represented exactly
+type Importer interface {
+	Import(paths []string) (models.PackageList, []string, error)
+}
+
+// Format returns the definition file of a package. Comment lines are written
+// before the package declaration.
+func Format(pkg *models.Package, comments ...string) []byte {
+	var out bytes.Buffer
+	for _, c := range comments {
+		fmt.Fprintf(&out, "// %s\n", c)
+	}
+	fmt.Fprintf(&out, "package %s {\n", pkg.Name)
+	fmt.Fprintf(&out, "    id %s\n", pkg.Identifier)
+	if len(pkg.Fields) > 0 {
+		out.WriteString("\n")
+	}
+	formatContents(&out, pkg.Fields, pkg.Structs, 1)
+	out.WriteString("}\n")
+	return out.Bytes()
+}
+
+// formatContents writes fields and structures of a package or structure,
+// aligning fields in columns
+func formatContents(out *bytes.Buffer, fArr []models.Field, sArr []models.Struct, depth int) {
+	indent := strings.Repeat("    ", depth)
+	types := make([]string, len(fArr))
+	typeWidth, nameWidth := 0, 0
+	for i, f := range fArr {
+		t := string(f.Type.NativeType)
+		if f.Type.Source == models.SourceUser {
+			t = "@" + f.Type.CustomType
+		}
+		if f.IsArray() {
+			t += "[" + f.Size + "]"
+		}
+		types[i] = t
+		if len(t) > typeWidth {
+			typeWidth = len(t)
+		}
+		if len(f.Name) > nameWidth {
+			nameWidth = len(f.Name)
+		}
+	}
+
+	for i, f := range fArr {
+		line := fmt.Sprintf("%s%-*s  %s", indent, typeWidth, types[i], f.Name)
+		for _, a := range f.Attributes {
+			line = fmt.Sprintf("%-*s  !%s", len(indent)+typeWidth+2+nameWidth, line, a)
+		}
+		out.WriteString(line + "\n")
+	}
+
+	for i, s := range sArr {
+		if len(fArr) > 0 || i > 0 {
+			out.WriteString("\n")
+		}
+		fmt.Fprintf(out, "%sstruct %s {\n", indent, s.Name)
+		formatContents(out, s.Fields, s.Structs, depth+1)
+		fmt.Fprintf(out, "%s}\n", indent)
+	}
+}
+
+// digitNames holds spelled digits, as identifiers cannot contain numbers
+var digitNames = []string{"zero", "one", "two", "three", "four", "five", "six", "seven", "eight", "nine"}
+
+// identifier converts a name into a valid Ludwieg identifier, splitting
+// camel-cased words and spelling digits
+func identifier(name string) string {
+	var parts []string
+	var current []rune
+	flush := func() {
+		if len(current) > 0 {
+			parts = append(parts, string(current))
+			current = nil
+		}
+	}
+
+	runes := []rune(name)
+	for i, r := range runes {
+		switch {
+		case r >= '0' && r <= '9':
+			flush()
+			parts = append(parts, digitNames[r-'0'])
+		case unicode.IsUpper(r):
+			// Starts a new word, unless it continues an acronym
+			if i > 0 && (unicode.IsLower(runes[i-1]) || (unicode.IsUpper(runes[i-1]) && i+1 < len(runes) && unicode.IsLower(runes[i+1]))) {
+				flush()
+			}
+			current = append(current, unicode.ToLower(r))
+		case r >= 'a' && r <= 'z':
+			current = append(current, r)
+		default:
+			flush()
+		}
+	}
+	flush()
+	return strings.Join(parts, "_")
+}
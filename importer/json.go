@@ -0,0 +1,758 @@
+package importer
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/ludwieg/ludco/models"
+)
+
+// JSON infers packages from JSON Schema documents, or from sample JSON
+// documents. Each schema becomes a package, while all samples are merged into
+// a single package. Identifiers are assigned sequentially.
+type JSON struct {
+	// Name holds the name of the package inferred from samples. When empty,
+	// the name of the first sample file is used.
+	Name string
+}
+
+// jsonSchemaAnyDef holds the definition name used by exported schemas for
+// values of any fields
+const jsonSchemaAnyDef = "ludwieg.any"
+
+var jsonUUIDPattern = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+
+// jsonObject holds members of a JSON object, preserving their order
+type jsonObject struct {
+	keys   []string
+	values map[string]interface{}
+}
+
+func (o *jsonObject) get(key string) (interface{}, bool) {
+	v, ok := o.values[key]
+	return v, ok
+}
+
+func (o *jsonObject) object(key string) *jsonObject {
+	v, _ := o.values[key].(*jsonObject)
+	return v
+}
+
+func (o *jsonObject) str(key string) string {
+	v, _ := o.values[key].(string)
+	return v
+}
+
+func (o *jsonObject) number(key string) (float64, bool) {
+	n, ok := o.values[key].(json.Number)
+	if !ok {
+		return 0, false
+	}
+	v, err := n.Float64()
+	return v, err == nil
+}
+
+// jsonRead reads a value from a decoder, preserving the order of object
+// members. Numbers are read as json.Number.
+func jsonRead(dec *json.Decoder) (interface{}, error) {
+	t, err := dec.Token()
+	if err != nil {
+		return nil, err
+	}
+	switch t {
+	case json.Delim('{'):
+		obj := &jsonObject{values: map[string]interface{}{}}
+		for dec.More() {
+			k, err := dec.Token()
+			if err != nil {
+				return nil, jsonUnexpectedEOF(err)
+			}
+			key := k.(string)
+			v, err := jsonRead(dec)
+			if err != nil {
+				return nil, jsonUnexpectedEOF(err)
+			}
+			if _, ok := obj.values[key]; !ok {
+				obj.keys = append(obj.keys, key)
+			}
+			obj.values[key] = v
+		}
+		_, err = dec.Token()
+		return obj, jsonUnexpectedEOF(err)
+	case json.Delim('['):
+		items := []interface{}{}
+		for dec.More() {
+			v, err := jsonRead(dec)
+			if err != nil {
+				return nil, jsonUnexpectedEOF(err)
+			}
+			items = append(items, v)
+		}
+		_, err = dec.Token()
+		return items, jsonUnexpectedEOF(err)
+	}
+	return t, nil
+}
+
+// jsonUnexpectedEOF reports the end of input found within a value
+func jsonUnexpectedEOF(err error) error {
+	if err == io.EOF {
+		return io.ErrUnexpectedEOF
+	}
+	return err
+}
+
+// jsonReadFile reads all documents held by a file, such as JSON lines
+func jsonReadFile(path string) ([]interface{}, error) {
+	fd, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer fd.Close()
+
+	dec := json.NewDecoder(fd)
+	dec.UseNumber()
+	var docs []interface{}
+	for {
+		v, err := jsonRead(dec)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%s: %s", filepath.Base(path), err)
+		}
+		docs = append(docs, v)
+	}
+	return docs, nil
+}
+
+// jsonIsSchema determines whether a document looks like a JSON Schema
+func jsonIsSchema(doc interface{}) bool {
+	obj, ok := doc.(*jsonObject)
+	if !ok {
+		return false
+	}
+	if _, ok := obj.get("$schema"); ok {
+		return true
+	}
+	return obj.str("type") == "object" && obj.object("properties") != nil
+}
+
+func (j JSON) Import(paths []string) (models.PackageList, []string, error) {
+	c := jsonConverter{}
+	packages := models.PackageList{}
+	names := map[string]string{}
+	add := func(pkg *models.Package, file string) error {
+		if other, ok := names[pkg.Name]; ok {
+			return fmt.Errorf("%s and %s would both be imported as package %s", other, file, pkg.Name)
+		}
+		if len(packages) == 0xff {
+			return fmt.Errorf("too many packages; at most 255 packages can be identified")
+		}
+		names[pkg.Name] = file
+		pkg.Identifier = fmt.Sprintf("0x%02x", len(packages)+1)
+		packages = append(packages, *pkg)
+		return nil
+	}
+
+	var samples []*jsonObject
+	sampleName := j.Name
+	for _, path := range paths {
+		file := filepath.Base(path)
+		docs, err := jsonReadFile(path)
+		if err != nil {
+			return nil, nil, err
+		}
+		if len(docs) == 1 && jsonIsSchema(docs[0]) {
+			pkg, err := c.schemaPackage(docs[0].(*jsonObject), file)
+			if err != nil {
+				return nil, nil, err
+			}
+			if err := add(pkg, file); err != nil {
+				return nil, nil, err
+			}
+			continue
+		}
+
+		for i, doc := range docs {
+			obj, ok := doc.(*jsonObject)
+			if !ok {
+				return nil, nil, fmt.Errorf("%s: document %d is not an object", file, i+1)
+			}
+			samples = append(samples, obj)
+		}
+		if sampleName == "" {
+			sampleName = strings.SplitN(file, ".", 2)[0]
+		}
+	}
+
+	if len(samples) > 0 {
+		name := c.rename(sampleName, "package "+sampleName)
+		if name == "" {
+			return nil, nil, fmt.Errorf("%s cannot be used as a package name", sampleName)
+		}
+		shape := &jsonShape{}
+		for _, s := range samples {
+			shape.observe(s)
+		}
+		pkg := &models.Package{Name: name}
+		pkg.Fields = c.sampleFields(shape, &pkg.Structs, name)
+		if err := add(pkg, "samples"); err != nil {
+			return nil, nil, err
+		}
+	}
+	if len(packages) == 0 {
+		return nil, nil, fmt.Errorf("no schemas or samples were provided")
+	}
+	return packages, c.warnings, nil
+}
+
+// jsonConverter converts schemas and samples into packages
+type jsonConverter struct {
+	warnings []string
+}
+
+func (c *jsonConverter) warn(msg string, args ...interface{}) {
+	c.warnings = append(c.warnings, fmt.Sprintf(msg, args...))
+}
+
+// rename converts a key into an identifier, reporting keys that had to be
+// changed beyond their casing
+func (c *jsonConverter) rename(key, context string) string {
+	id := identifier(key)
+	if strings.Replace(id, "_", "", -1) != strings.ToLower(strings.Replace(key, "_", "", -1)) {
+		c.warn("%s renamed to %s, as identifiers may only contain lowercase letters and underscores", context, id)
+	}
+	return id
+}
+
+// jsonStructName returns an unused structure name for values of a field.
+// Items of arrays are named after the singular form of the field.
+func jsonStructName(structs []models.Struct, name string, item bool) string {
+	used := map[string]bool{}
+	for _, s := range structs {
+		used[s.Name] = true
+	}
+	if item {
+		switch {
+		case strings.HasSuffix(name, "ies") && len(name) > 3:
+			name = name[:len(name)-3] + "y"
+		case strings.HasSuffix(name, "s") && !strings.HasSuffix(name, "ss") && len(name) > 1:
+			name = name[:len(name)-1]
+		}
+	}
+	for used[name] {
+		name += "_value"
+	}
+	return name
+}
+
+// jsonField appends a field to a list, reporting names used more than once
+func (c *jsonConverter) jsonField(fields []models.Field, f models.Field, context string) []models.Field {
+	for _, other := range fields {
+		if other.Name == f.Name {
+			c.warn("%s would be imported as %s, which is already used, and was skipped", context, f.Name)
+			return fields
+		}
+	}
+	return append(fields, f)
+}
+
+// jsonNative returns a field holding a native type
+func jsonNative(t models.NativeType) models.Field {
+	return models.Field{
+		ObjectType: models.ObjectTypeField,
+		Type:       models.Type{Source: models.SourceNative, NativeType: t},
+	}
+}
+
+// jsonIntegerType returns the narrowest type holding integers up to a
+// maximum value
+func jsonIntegerType(max float64) models.NativeType {
+	switch {
+	case max <= math.MaxUint8:
+		return models.TypeUint8
+	case max <= math.MaxUint32:
+		return models.TypeUint32
+	}
+	return models.TypeUint64
+}
+
+// ------
+// Samples
+
+// jsonShape accumulates values observed on a position of sample documents
+type jsonShape struct {
+	null, boolean, number, str, array, object bool
+
+	// Numbers
+	fraction bool
+	negative bool
+	max      float64
+
+	// Strings
+	uuid bool
+
+	// Arrays
+	items *jsonShape
+
+	// Objects
+	keys   []string
+	fields map[string]*jsonShape
+}
+
+func (s *jsonShape) observe(v interface{}) {
+	switch i := v.(type) {
+	case nil:
+		s.null = true
+	case bool:
+		s.boolean = true
+	case json.Number:
+		s.number = true
+		f, _ := i.Float64()
+		if _, err := strconv.ParseUint(i.String(), 10, 64); err != nil {
+			if f < 0 && f == math.Trunc(f) {
+				s.negative = true
+			} else {
+				s.fraction = true
+			}
+		}
+		if f > s.max {
+			s.max = f
+		}
+	case string:
+		match := jsonUUIDPattern.MatchString(i)
+		s.uuid = match && (s.uuid || !s.str)
+		s.str = true
+	case []interface{}:
+		s.array = true
+		if s.items == nil {
+			s.items = &jsonShape{}
+		}
+		for _, item := range i {
+			s.items.observe(item)
+		}
+	case *jsonObject:
+		s.object = true
+		if s.fields == nil {
+			s.fields = map[string]*jsonShape{}
+		}
+		for _, k := range i.keys {
+			f, ok := s.fields[k]
+			if !ok {
+				f = &jsonShape{}
+				s.fields[k] = f
+				s.keys = append(s.keys, k)
+			}
+			f.observe(i.values[k])
+		}
+	}
+}
+
+// empty determines whether only null values were observed
+func (s *jsonShape) empty() bool {
+	return !s.boolean && !s.number && !s.str && !s.array && !s.object
+}
+
+// kinds returns the amount of kinds of values observed, ignoring nulls
+func (s *jsonShape) kinds() int {
+	n := 0
+	for _, k := range []bool{s.boolean, s.number, s.str, s.array, s.object} {
+		if k {
+			n++
+		}
+	}
+	return n
+}
+
+func (c *jsonConverter) sampleFields(shape *jsonShape, structs *[]models.Struct, context string) []models.Field {
+	var fields []models.Field
+	for _, k := range shape.keys {
+		fieldContext := context + "." + k
+		name := c.rename(k, fieldContext)
+		if name == "" {
+			c.warn("%s cannot be named as a Ludwieg field and was skipped", fieldContext)
+			continue
+		}
+		f := c.sampleField(shape.fields[k], name, false, structs, fieldContext)
+		f.Name = name
+		fields = c.jsonField(fields, f, fieldContext)
+	}
+	return fields
+}
+
+// sampleField infers the field holding values observed on a position
+func (c *jsonConverter) sampleField(shape *jsonShape, name string, item bool, structs *[]models.Struct, context string) models.Field {
+	switch {
+	case shape.empty():
+		c.warn("%s only holds null values, and was imported as any", context)
+		return jsonNative(models.TypeAny)
+	case shape.kinds() > 1:
+		c.warn("%s holds values of different kinds, and was imported as any", context)
+		return jsonNative(models.TypeAny)
+	case shape.boolean:
+		return jsonNative(models.TypeBool)
+	case shape.number:
+		if shape.negative && !shape.fraction {
+			c.warn("%s holds negative integers, which cannot be represented, and was imported as double", context)
+		}
+		if shape.negative || shape.fraction {
+			return jsonNative(models.TypeDouble)
+		}
+		return jsonNative(jsonIntegerType(shape.max))
+	case shape.str:
+		if shape.uuid {
+			return jsonNative(models.TypeUUID)
+		}
+		return jsonNative(models.TypeString)
+	case shape.array:
+		if shape.items.empty() {
+			c.warn("%s only holds empty arrays, and was imported as any[*]", context)
+			return models.Field{ObjectType: models.ObjectTypeArray, Type: jsonNative(models.TypeAny).Type, Size: "*"}
+		}
+		f := c.sampleField(shape.items, name, true, structs, context+"[]")
+		if f.IsArray() {
+			c.warn("%s holds nested arrays, which cannot be represented, and was imported as any[*]", context)
+			f = jsonNative(models.TypeAny)
+		}
+		f.ObjectType = models.ObjectTypeArray
+		f.Size = "*"
+		return f
+	}
+
+	s := models.Struct{Name: jsonStructName(*structs, name, item)}
+	s.Fields = c.sampleFields(shape, &s.Structs, context)
+	if len(s.Fields) == 0 {
+		// Structures cannot be empty
+		c.warn("%s only holds objects without fields that can be represented, and was imported as any", context)
+		return jsonNative(models.TypeAny)
+	}
+	*structs = append(*structs, s)
+	return models.Field{
+		ObjectType: models.ObjectTypeField,
+		Type:       models.Type{Source: models.SourceUser, CustomType: s.Name},
+	}
+}
+
+// ------
+// Schemas
+
+// jsonSchemaContext holds definitions of a schema being converted
+type jsonSchemaContext struct {
+	file    string
+	defs    *jsonObject
+	names   map[string]string
+	structs *[]models.Struct
+}
+
+func (c *jsonConverter) schemaPackage(doc *jsonObject, file string) (*models.Package, error) {
+	name := doc.str("title")
+	if name == "" {
+		name = strings.SplitN(file, ".", 2)[0]
+	}
+	pkgName := c.rename(name, file+": package "+name)
+	if pkgName == "" {
+		return nil, fmt.Errorf("%s: %s cannot be used as a package name", file, name)
+	}
+	if doc.object("properties") == nil {
+		return nil, fmt.Errorf("%s: the root schema must describe an object with properties", file)
+	}
+
+	pkg := &models.Package{Name: pkgName}
+	ctx := &jsonSchemaContext{file: file, names: map[string]string{}, structs: &pkg.Structs}
+	if ctx.defs = doc.object("$defs"); ctx.defs == nil {
+		ctx.defs = doc.object("definitions")
+	}
+	pkg.Fields = c.schemaFields(ctx, doc, &pkg.Structs, pkgName)
+	return pkg, nil
+}
+
+func (c *jsonConverter) schemaFields(ctx *jsonSchemaContext, schema *jsonObject, structs *[]models.Struct, context string) []models.Field {
+	properties := schema.object("properties")
+	var fields []models.Field
+	for _, k := range properties.keys {
+		fieldContext := ctx.file + ": " + context + "." + k
+		name := c.rename(k, fieldContext)
+		if name == "" {
+			c.warn("%s cannot be named as a Ludwieg field and was skipped", fieldContext)
+			continue
+		}
+		prop, _ := properties.values[k].(*jsonObject)
+		if prop == nil {
+			prop = &jsonObject{values: map[string]interface{}{}}
+		}
+		f := c.schemaField(ctx, prop, name, false, structs, fieldContext)
+		f.Name = name
+		if jsonDeprecated(prop) {
+			f.Attributes = []models.Attribute{models.AttributeDeprecated}
+		}
+		fields = c.jsonField(fields, f, fieldContext)
+	}
+	return fields
+}
+
+// jsonDeprecated determines whether a schema, or the non-null variant it
+// wraps, is deprecated
+func jsonDeprecated(schema *jsonObject) bool {
+	if v, _ := schema.values["deprecated"].(bool); v {
+		return true
+	}
+	if inner := jsonUnwrapNullable(schema); inner != schema {
+		return jsonDeprecated(inner)
+	}
+	return false
+}
+
+// jsonUnwrapNullable returns the variant of an anyOf or oneOf schema that
+// also accepts null. Other schemas are returned unchanged.
+func jsonUnwrapNullable(schema *jsonObject) *jsonObject {
+	for _, key := range []string{"anyOf", "oneOf"} {
+		variants, ok := schema.values[key].([]interface{})
+		if !ok {
+			continue
+		}
+		var remaining []*jsonObject
+		for _, v := range variants {
+			obj, _ := v.(*jsonObject)
+			if obj == nil || obj.str("type") == "null" {
+				continue
+			}
+			remaining = append(remaining, obj)
+		}
+		if len(remaining) == 1 {
+			return remaining[0]
+		}
+	}
+	return schema
+}
+
+// jsonSchemaTypes returns types accepted by a schema, ignoring null
+func jsonSchemaTypes(schema *jsonObject) []string {
+	var types []string
+	switch t := schema.values["type"].(type) {
+	case string:
+		types = []string{t}
+	case []interface{}:
+		for _, i := range t {
+			if s, ok := i.(string); ok {
+				types = append(types, s)
+			}
+		}
+	}
+	var result []string
+	for _, t := range types {
+		if t != "null" {
+			result = append(result, t)
+		}
+	}
+	return result
+}
+
+// schemaField converts the schema of a field. Values are accepted or rejected
+// by schemas, so only keywords describing their type are considered.
+func (c *jsonConverter) schemaField(ctx *jsonSchemaContext, schema *jsonObject, name string, item bool, structs *[]models.Struct, context string) models.Field {
+	schema = jsonUnwrapNullable(schema)
+	if ref := schema.str("$ref"); ref != "" {
+		return c.schemaReference(ctx, ref, context)
+	}
+	if _, ok := schema.values["anyOf"]; ok {
+		c.warn("%s accepts values of different schemas, and was imported as any", context)
+		return jsonNative(models.TypeAny)
+	}
+	if _, ok := schema.values["oneOf"]; ok {
+		c.warn("%s accepts values of different schemas, and was imported as any", context)
+		return jsonNative(models.TypeAny)
+	}
+
+	types := jsonSchemaTypes(schema)
+	if len(types) == 0 {
+		if values, ok := schema.values["enum"].([]interface{}); ok {
+			// Enumerations are inferred as samples
+			shape := &jsonShape{}
+			for _, v := range values {
+				shape.observe(v)
+			}
+			return c.sampleField(shape, name, item, structs, context)
+		}
+		switch {
+		case schema.object("properties") != nil:
+			types = []string{"object"}
+		case schema.values["items"] != nil:
+			types = []string{"array"}
+		}
+	}
+
+	kind := ""
+	switch {
+	case len(types) == 1:
+		kind = types[0]
+	case len(types) == 2 && jsonContains(types, "integer") && jsonContains(types, "string"):
+		// 64-bit integers may be represented by decimal strings
+		kind = "integer"
+	case len(types) == 2 && jsonContains(types, "integer") && jsonContains(types, "number"):
+		kind = "number"
+	case len(types) == 0:
+		c.warn("%s does not define its type, and was imported as any", context)
+		return jsonNative(models.TypeAny)
+	default:
+		c.warn("%s accepts values of different types, and was imported as any", context)
+		return jsonNative(models.TypeAny)
+	}
+
+	switch kind {
+	case "boolean":
+		return jsonNative(models.TypeBool)
+	case "number":
+		return jsonNative(models.TypeDouble)
+	case "integer":
+		if min, ok := schema.number("minimum"); ok && min < 0 {
+			c.warn("%s accepts negative integers, which cannot be represented, and was imported as double", context)
+			return jsonNative(models.TypeDouble)
+		}
+		if max, ok := schema.number("maximum"); ok {
+			return jsonNative(jsonIntegerType(max))
+		}
+		return jsonNative(models.TypeDynInt)
+	case "string":
+		switch {
+		case schema.str("format") == "uuid":
+			return jsonNative(models.TypeUUID)
+		case schema.str("contentEncoding") == "base64", schema.str("format") == "byte":
+			return jsonNative(models.TypeBlob)
+		}
+		return jsonNative(models.TypeString)
+	case "array":
+		items := schema.object("items")
+		if items == nil {
+			c.warn("%s does not define its items, and was imported as any[*]", context)
+			return models.Field{ObjectType: models.ObjectTypeArray, Type: jsonNative(models.TypeAny).Type, Size: "*"}
+		}
+		f := c.schemaField(ctx, items, name, true, structs, context+"[]")
+		if f.IsArray() {
+			c.warn("%s holds nested arrays, which cannot be represented, and was imported as any[*]", context)
+			f = jsonNative(models.TypeAny)
+		}
+		f.ObjectType = models.ObjectTypeArray
+		f.Size = "*"
+		if max, ok := schema.number("maxItems"); ok && max >= 1 && max < math.MaxUint32-1 {
+			f.Size = strconv.Itoa(int(max))
+		}
+		return f
+	case "object":
+		if schema.object("properties") == nil {
+			c.warn("%s does not define its properties, and was imported as any", context)
+			return jsonNative(models.TypeAny)
+		}
+		// Reserve a slot, as definitions referenced by the structure may be
+		// declared alongside it
+		sName := jsonStructName(*structs, name, item)
+		idx := len(*structs)
+		*structs = append(*structs, models.Struct{Name: sName})
+		var inner []models.Struct
+		fields := c.schemaFields(ctx, schema, &inner, context)
+		if len(fields) == 0 {
+			*structs = append((*structs)[:idx], (*structs)[idx+1:]...)
+			c.warn("%s does not define properties that can be represented, and was imported as any", context)
+			return jsonNative(models.TypeAny)
+		}
+		(*structs)[idx].Fields = fields
+		(*structs)[idx].Structs = inner
+		return models.Field{
+			ObjectType: models.ObjectTypeField,
+			Type:       models.Type{Source: models.SourceUser, CustomType: sName},
+		}
+	}
+	c.warn("%s has unknown type %s, and was imported as any", context, kind)
+	return jsonNative(models.TypeAny)
+}
+
+// schemaReference converts a reference to a definition. Definitions of
+// objects become structures of the package, converted on their first use.
+func (c *jsonConverter) schemaReference(ctx *jsonSchemaContext, ref, context string) models.Field {
+	var key string
+	for _, prefix := range []string{"#/$defs/", "#/definitions/"} {
+		if strings.HasPrefix(ref, prefix) {
+			key = strings.Replace(strings.Replace(ref[len(prefix):], "~1", "/", -1), "~0", "~", -1)
+		}
+	}
+	var def *jsonObject
+	if key != "" && ctx.defs != nil {
+		def = ctx.defs.object(key)
+	}
+	if key == jsonSchemaAnyDef {
+		return jsonNative(models.TypeAny)
+	}
+	if def == nil {
+		c.warn("%s references unsupported definition %s, and was imported as any", context, ref)
+		return jsonNative(models.TypeAny)
+	}
+
+	isObject := def.object("properties") != nil
+	if types := jsonSchemaTypes(def); len(types) > 0 && (len(types) > 1 || types[0] != "object") {
+		isObject = false
+	}
+	if !isObject {
+		return c.schemaField(ctx, def, identifier(key), false, ctx.structs, context)
+	}
+
+	if name, ok := ctx.names[key]; ok {
+		if name == "" {
+			// Definitions without properties are held by any fields
+			return jsonNative(models.TypeAny)
+		}
+		return models.Field{
+			ObjectType: models.ObjectTypeField,
+			Type:       models.Type{Source: models.SourceUser, CustomType: name},
+		}
+	}
+
+	// Definitions qualified by dots are named after their last component,
+	// unless it is already used
+	parts := strings.Split(key, ".")
+	name := identifier(parts[len(parts)-1])
+	for _, s := range *ctx.structs {
+		if s.Name == name {
+			name = identifier(key)
+		}
+	}
+	name = jsonStructName(*ctx.structs, name, false)
+	if name == "" {
+		c.warn("%s references definition %s, which cannot be named, and was imported as any", context, key)
+		return jsonNative(models.TypeAny)
+	}
+	ctx.names[key] = name
+
+	// Reserve a slot, as definitions may reference themselves
+	idx := len(*ctx.structs)
+	*ctx.structs = append(*ctx.structs, models.Struct{Name: name})
+	var inner []models.Struct
+	fields := c.schemaFields(ctx, def, &inner, key)
+	if len(fields) == 0 {
+		*ctx.structs = append((*ctx.structs)[:idx], (*ctx.structs)[idx+1:]...)
+		ctx.names[key] = ""
+		c.warn("%s references definition %s, which does not define properties that can be represented, and was imported as any", context, key)
+		return jsonNative(models.TypeAny)
+	}
+	(*ctx.structs)[idx].Fields = fields
+	(*ctx.structs)[idx].Structs = inner
+	return models.Field{
+		ObjectType: models.ObjectTypeField,
+		Type:       models.Type{Source: models.SourceUser, CustomType: name},
+	}
+}
+
+func jsonContains(list []string, value string) bool {
+	for _, v := range list {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
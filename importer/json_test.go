@@ -0,0 +1,56 @@
+package importer
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ludwieg/ludco/models"
+	"github.com/ludwieg/ludco/parser"
+)
+
+func TestJSONEmptyObjects(t *testing.T) {
+	files := map[string]string{
+		"sample.json": `{"id": 1, "empty": {}, "nested": {"inner": {}, "name": "x"}, "list": [{}, {}]}`,
+		"order.json": `{"$schema": "https://json-schema.org/draft/2020-12/schema", "title": "order", "type": "object",
+			"properties": {"meta": {"type": "object", "properties": {}}, "tag": {"$ref": "#/$defs/tag"}},
+			"$defs": {"tag": {"type": "object", "properties": {}}}}`,
+	}
+	dir, err := ioutil.TempDir("", "ludco-json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	var paths []string
+	for name, contents := range files {
+		path := filepath.Join(dir, name)
+		if err := ioutil.WriteFile(path, []byte(contents), 0644); err != nil {
+			t.Fatal(err)
+		}
+		paths = append(paths, path)
+	}
+
+	packages, _, err := JSON{Name: "sample"}.Import(paths)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := map[string]bool{"empty": true, "inner": true, "list": true, "meta": true, "tag": true}
+	var check func(fArr []models.Field, sArr []models.Struct)
+	check = func(fArr []models.Field, sArr []models.Struct) {
+		for _, f := range fArr {
+			if expected[f.Name] && f.Type.NativeType != models.TypeAny {
+				t.Errorf("field %s imported as %v, expected any", f.Name, f.Type)
+			}
+		}
+		for _, s := range sArr {
+			check(s.Fields, s.Structs)
+		}
+	}
+	for i := range packages {
+		check(packages[i].Fields, packages[i].Structs)
+		if _, err := parser.Parse(packages[i].Name+".lud", Format(&packages[i])); err != nil {
+			t.Errorf("%s: formatted package cannot be parsed: %s", packages[i].Name, err)
+		}
+	}
+}
@@ -0,0 +1,454 @@
+package importer
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/ludwieg/ludco/models"
+)
+
+// Proto imports Protocol Buffers (proto2 and proto3) schemas. Top-level
+// messages that are not referenced by other messages become packages, and
+// messages referenced by them become structures. Messages may define their
+// package identifier through `option (ludwieg.id) = 0x05;`; remaining packages
+// are numbered sequentially.
+type Proto struct{}
+
+// protoIDOption holds the name of the message option defining identifiers
+const protoIDOption = "(ludwieg.id)"
+
+// protoScalars maps protobuf scalar types to native types
+var protoScalars = map[string]models.NativeType{
+	"uint32":   models.TypeUint32,
+	"fixed32":  models.TypeUint32,
+	"uint64":   models.TypeUint64,
+	"fixed64":  models.TypeUint64,
+	"double":   models.TypeDouble,
+	"float":    models.TypeDouble,
+	"string":   models.TypeString,
+	"bytes":    models.TypeBlob,
+	"bool":     models.TypeBool,
+	"int32":    "",
+	"int64":    "",
+	"sint32":   "",
+	"sint64":   "",
+	"sfixed32": "",
+	"sfixed64": "",
+}
+
+// protoWellKnown maps well-known types to native types. Wrappers are
+// represented by their values, as every Ludwieg field may be empty.
+var protoWellKnown = map[string]models.NativeType{
+	".google.protobuf.Any":         models.TypeAny,
+	".google.protobuf.DoubleValue": models.TypeDouble,
+	".google.protobuf.FloatValue":  models.TypeDouble,
+	".google.protobuf.UInt64Value": models.TypeUint64,
+	".google.protobuf.UInt32Value": models.TypeUint32,
+	".google.protobuf.BoolValue":   models.TypeBool,
+	".google.protobuf.StringValue": models.TypeString,
+	".google.protobuf.BytesValue":  models.TypeBlob,
+}
+
+type protoField struct {
+	name       string
+	kind       string
+	number     int
+	line       int
+	repeated   bool
+	deprecated bool
+	oneof      string
+	isMap      bool
+	isGroup    bool
+}
+
+type protoMessage struct {
+	name     string
+	fullName string
+	file     string
+	id       string
+	fields   []protoField
+	messages []*protoMessage
+}
+
+type protoFile struct {
+	path     string
+	pkg      string
+	imports  []string
+	messages []*protoMessage
+	enums    []string
+	warnings []string
+}
+
+func (p Proto) Import(paths []string) (models.PackageList, []string, error) {
+	files := map[string]*protoFile{}
+	var inputs []*protoFile
+	for _, path := range paths {
+		f, err := protoLoad(path, files)
+		if err != nil {
+			return nil, nil, err
+		}
+		inputs = append(inputs, f)
+	}
+
+	c := protoConverter{
+		messages: map[string]*protoMessage{},
+		enums:    map[string]bool{},
+		reported: map[string]bool{},
+	}
+	var names []string
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		f := files[name]
+		for _, w := range f.warnings {
+			c.report(w)
+		}
+		for _, e := range f.enums {
+			c.enums[e] = true
+		}
+		var register func(mArr []*protoMessage)
+		register = func(mArr []*protoMessage) {
+			for _, m := range mArr {
+				c.messages[m.fullName] = m
+				register(m.messages)
+			}
+		}
+		register(f.messages)
+	}
+
+	// Messages referenced by others are only imported as structures, unless
+	// they explicitly define an identifier
+	referenced := map[*protoMessage]bool{}
+	for _, m := range c.messages {
+		for _, f := range m.fields {
+			if r := c.resolve(m, f.kind); r != nil && r != m {
+				referenced[r] = true
+			}
+		}
+	}
+	var roots []*protoMessage
+	for _, f := range inputs {
+		for _, m := range f.messages {
+			if m.id == "" && referenced[m] {
+				continue
+			}
+			roots = append(roots, m)
+		}
+	}
+	if len(roots) == 0 {
+		return nil, nil, fmt.Errorf("no messages could be imported as packages")
+	}
+
+	ids, err := protoAssignIdentifiers(roots)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	packages := models.PackageList{}
+	packageNames := map[string]string{}
+	for i, m := range roots {
+		name := c.rename(m.name, m.fullName)
+		if name == "" {
+			return nil, nil, fmt.Errorf("%s: message %s cannot be named as a package", m.file, m.fullName[1:])
+		}
+		if other, ok := packageNames[name]; ok {
+			return nil, nil, fmt.Errorf("messages %s and %s would both be imported as package %s", other, m.fullName[1:], name)
+		}
+		packageNames[name] = m.fullName[1:]
+
+		pkg, err := c.convertPackage(m, name, ids[i])
+		if err != nil {
+			return nil, nil, err
+		}
+		packages = append(packages, *pkg)
+	}
+	return packages, c.warnings, nil
+}
+
+// protoLoad parses a file along with files imported by it, registering them by
+// their absolute path
+func protoLoad(path string, files map[string]*protoFile) (*protoFile, error) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return nil, err
+	}
+	if f, ok := files[abs]; ok {
+		return f, nil
+	}
+	data, err := ioutil.ReadFile(abs)
+	if err != nil {
+		return nil, err
+	}
+	f, err := protoParse(filepath.Base(path), string(data))
+	if err != nil {
+		return nil, err
+	}
+	files[abs] = f
+
+	for _, imp := range f.imports {
+		target := filepath.Join(filepath.Dir(abs), filepath.FromSlash(imp))
+		if _, err := os.Stat(target); err != nil {
+			if !strings.HasPrefix(imp, "google/protobuf/") {
+				f.warnings = append(f.warnings, fmt.Sprintf("%s: cannot find imported file %s; types declared by it will be skipped", f.path, imp))
+			}
+			continue
+		}
+		if _, err := protoLoad(target, files); err != nil {
+			return nil, err
+		}
+	}
+	return f, nil
+}
+
+// protoAssignIdentifiers returns identifiers of messages imported as packages,
+// honouring identifiers defined through options
+func protoAssignIdentifiers(roots []*protoMessage) ([]string, error) {
+	ids := make([]string, len(roots))
+	used := map[uint64]string{}
+	for i, m := range roots {
+		if m.id == "" {
+			continue
+		}
+		v, err := strconv.ParseUint(m.id, 0, 64)
+		if err != nil || v > 0xff {
+			return nil, fmt.Errorf("%s: invalid identifier %s for message %s", m.file, m.id, m.name)
+		}
+		if other, ok := used[v]; ok {
+			return nil, fmt.Errorf("%s: messages %s and %s share identifier 0x%02x", m.file, other, m.name, v)
+		}
+		used[v] = m.name
+		ids[i] = fmt.Sprintf("0x%02x", v)
+	}
+
+	next := uint64(1)
+	for i := range roots {
+		if ids[i] != "" {
+			continue
+		}
+		for used[next] != "" {
+			next++
+		}
+		if next > 0xff {
+			return nil, fmt.Errorf("too many messages; at most 255 packages can be identified")
+		}
+		used[next] = roots[i].name
+		ids[i] = fmt.Sprintf("0x%02x", next)
+	}
+	return ids, nil
+}
+
+// protoConverter converts parsed messages into packages
+type protoConverter struct {
+	messages map[string]*protoMessage
+	enums    map[string]bool
+	warnings []string
+
+	// reported holds warnings already emitted, as messages referenced by
+	// several packages are converted once for each of them
+	reported map[string]bool
+}
+
+// report appends a warning, unless it has already been emitted
+func (c *protoConverter) report(w string) {
+	if !c.reported[w] {
+		c.reported[w] = true
+		c.warnings = append(c.warnings, w)
+	}
+}
+
+func (c *protoConverter) warn(m *protoMessage, line int, msg string, args ...interface{}) {
+	c.report(fmt.Sprintf("%s:%d: %s", m.file, line, fmt.Sprintf(msg, args...)))
+}
+
+// rename converts a protobuf name into an identifier, reporting names that
+// had to be changed beyond their casing
+func (c *protoConverter) rename(name, context string) string {
+	id := identifier(name)
+	if strings.Replace(id, "_", "", -1) != strings.ToLower(strings.Replace(name, "_", "", -1)) {
+		c.report(fmt.Sprintf("%s renamed to %s, as identifiers may only contain lowercase letters and underscores", strings.TrimPrefix(context, "."), id))
+	}
+	return id
+}
+
+// lookup returns the full name of a type referenced from a scope, following
+// protobuf's scoping rules
+func (c *protoConverter) lookup(scope, kind string) string {
+	if strings.HasPrefix(kind, ".") {
+		scope, kind = "", kind[1:]
+	}
+	for {
+		candidate := scope + "." + kind
+		if c.known(candidate) {
+			return candidate
+		}
+		if scope == "" {
+			return ""
+		}
+		scope = scope[:strings.LastIndex(scope, ".")]
+	}
+}
+
+// known determines whether a fully-qualified type has been declared
+func (c *protoConverter) known(name string) bool {
+	return c.messages[name] != nil || c.enums[name] || protoWellKnown[name] != ""
+}
+
+// resolve returns the message referenced by a type, or nil
+func (c *protoConverter) resolve(scope *protoMessage, kind string) *protoMessage {
+	return c.messages[c.lookup(scope.fullName, kind)]
+}
+
+func (c *protoConverter) convertPackage(m *protoMessage, name, id string) (*models.Package, error) {
+	s := protoStructs{names: map[*protoMessage]string{}, used: map[string]bool{}}
+	fields, err := c.convertFields(m, &s)
+	if err != nil {
+		return nil, err
+	}
+	return &models.Package{
+		Name:       name,
+		Identifier: id,
+		Fields:     fields,
+		Structs:    s.structs,
+	}, nil
+}
+
+// protoStructs collects structures of a package. Referenced messages are
+// declared at the package level, so they can be reached from any structure.
+type protoStructs struct {
+	names   map[*protoMessage]string
+	used    map[string]bool
+	structs []models.Struct
+}
+
+// structFor returns the name of the structure representing a message,
+// converting it on its first use. Structures cannot be empty, so an empty name
+// is returned for messages without fields that can be represented.
+func (c *protoConverter) structFor(m *protoMessage, s *protoStructs) (string, error) {
+	if name, ok := s.names[m]; ok {
+		return name, nil
+	}
+
+	// Names are qualified by enclosing messages when they collide
+	parts := strings.Split(strings.TrimPrefix(m.fullName, "."), ".")
+	name := c.rename(m.name, m.fullName)
+	for i := len(parts) - 2; i >= 0 && s.used[name]; i-- {
+		name = strings.Trim(identifier(parts[i])+"_"+name, "_")
+	}
+	if s.used[name] || name == "" {
+		return "", fmt.Errorf("%s: cannot find a unique structure name for message %s", m.file, m.fullName[1:])
+	}
+	s.names[m] = name
+	s.used[name] = true
+
+	// Reserve a slot, as fields may reference structures recursively
+	idx := len(s.structs)
+	s.structs = append(s.structs, models.Struct{Name: name})
+	fields, err := c.convertFields(m, s)
+	if err != nil {
+		return "", err
+	}
+	if len(fields) == 0 {
+		s.structs = append(s.structs[:idx], s.structs[idx+1:]...)
+		s.names[m] = ""
+		delete(s.used, name)
+		return "", nil
+	}
+	s.structs[idx].Fields = fields
+	return name, nil
+}
+
+func (c *protoConverter) convertFields(m *protoMessage, s *protoStructs) ([]models.Field, error) {
+	sorted := append([]protoField{}, m.fields...)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].number < sorted[j].number })
+
+	var result []models.Field
+	names := map[string]string{}
+	oneofs := map[string]bool{}
+	for _, f := range sorted {
+		context := m.name + "." + f.name
+		if f.isMap {
+			c.warn(m, f.line, "map field %s cannot be represented and was skipped", context)
+			continue
+		}
+		if f.isGroup {
+			c.warn(m, f.line, "group %s cannot be represented and was skipped", context)
+			continue
+		}
+		if f.oneof != "" && !oneofs[f.oneof] {
+			oneofs[f.oneof] = true
+			c.warn(m, f.line, "oneof %s.%s cannot be represented; its fields were imported as independent fields", m.name, f.oneof)
+		}
+
+		t, err := c.convertType(m, &f, s)
+		if err != nil {
+			return nil, err
+		}
+		if t == nil {
+			continue
+		}
+
+		name := c.rename(f.name, m.fullName+"."+f.name)
+		if name == "" {
+			return nil, fmt.Errorf("%s:%d: field %s cannot be named as a Ludwieg field", m.file, f.line, context)
+		}
+		if other, ok := names[name]; ok {
+			return nil, fmt.Errorf("%s:%d: fields %s and %s of %s would both be imported as %s", m.file, f.line, other, f.name, m.name, name)
+		}
+		names[name] = f.name
+
+		field := models.Field{Name: name, Type: *t}
+		if f.repeated {
+			field.ObjectType = models.ObjectTypeArray
+			field.Size = "*"
+		} else {
+			field.ObjectType = models.ObjectTypeField
+		}
+		if f.deprecated {
+			field.Attributes = []models.Attribute{models.AttributeDeprecated}
+		}
+		result = append(result, field)
+	}
+	return result, nil
+}
+
+// convertType returns the type of a field. Types that cannot be represented
+// are reported, and nil is returned.
+func (c *protoConverter) convertType(m *protoMessage, f *protoField, s *protoStructs) (*models.Type, error) {
+	context := m.name + "." + f.name
+	if native, ok := protoScalars[f.kind]; ok {
+		if native == "" {
+			c.warn(m, f.line, "field %s uses signed type %s, which cannot be represented, and was skipped", context, f.kind)
+			return nil, nil
+		}
+		return &models.Type{Source: models.SourceNative, NativeType: native}, nil
+	}
+
+	full := c.lookup(m.fullName, f.kind)
+	switch {
+	case full == "":
+		c.warn(m, f.line, "field %s references unknown type %s and was skipped", context, f.kind)
+		return nil, nil
+	case protoWellKnown[full] != "":
+		return &models.Type{Source: models.SourceNative, NativeType: protoWellKnown[full]}, nil
+	case c.enums[full]:
+		c.warn(m, f.line, "field %s uses enum %s, which was imported as uint32", context, f.kind)
+		return &models.Type{Source: models.SourceNative, NativeType: models.TypeUint32}, nil
+	}
+
+	name, err := c.structFor(c.messages[full], s)
+	if err != nil {
+		return nil, err
+	}
+	if name == "" {
+		c.warn(m, f.line, "field %s references message %s, which has no fields that can be represented, and was imported as any", context, f.kind)
+		return &models.Type{Source: models.SourceNative, NativeType: models.TypeAny}, nil
+	}
+	return &models.Type{Source: models.SourceUser, CustomType: name}, nil
+}
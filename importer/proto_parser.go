@@ -0,0 +1,491 @@
+package importer
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+type protoToken struct {
+	text string
+	line int
+
+	// quoted indicates whether the token is a string literal, in which case
+	// text holds its unquoted value
+	quoted bool
+}
+
+// protoTokenize splits a protobuf source into tokens, discarding comments
+func protoTokenize(file, src string) ([]protoToken, error) {
+	var tokens []protoToken
+	line := 1
+	for i := 0; i < len(src); {
+		ch := src[i]
+		switch {
+		case ch == '\n':
+			line++
+			i++
+		case ch == ' ' || ch == '\t' || ch == '\r' || ch == '\f' || ch == '\v':
+			i++
+		case strings.HasPrefix(src[i:], "//"):
+			for i < len(src) && src[i] != '\n' {
+				i++
+			}
+		case strings.HasPrefix(src[i:], "/*"):
+			end := strings.Index(src[i+2:], "*/")
+			if end < 0 {
+				return nil, fmt.Errorf("%s:%d: unterminated comment", file, line)
+			}
+			line += strings.Count(src[i:i+2+end], "\n")
+			i += end + 4
+		case ch == '"' || ch == '\'':
+			start := i
+			i++
+			for i < len(src) && src[i] != ch {
+				if src[i] == '\\' {
+					i++
+				}
+				if i < len(src) && src[i] == '\n' {
+					return nil, fmt.Errorf("%s:%d: unterminated string", file, line)
+				}
+				i++
+			}
+			if i >= len(src) {
+				return nil, fmt.Errorf("%s:%d: unterminated string", file, line)
+			}
+			i++
+			raw := src[start:i]
+			if ch == '\'' {
+				raw = "\"" + strings.Replace(raw[1:len(raw)-1], "\"", "\\\"", -1) + "\""
+			}
+			value, err := strconv.Unquote(raw)
+			if err != nil {
+				value = raw[1 : len(raw)-1]
+			}
+			tokens = append(tokens, protoToken{text: value, line: line, quoted: true})
+		case isProtoWord(ch) || (ch == '.' && i+1 < len(src) && isProtoWord(src[i+1])):
+			start := i
+			for i < len(src) && (isProtoWord(src[i]) || src[i] == '.') {
+				i++
+			}
+			tokens = append(tokens, protoToken{text: src[start:i], line: line})
+		default:
+			tokens = append(tokens, protoToken{text: string(ch), line: line})
+			i++
+		}
+	}
+	return tokens, nil
+}
+
+func isProtoWord(ch byte) bool {
+	return ch == '_' || (ch >= 'a' && ch <= 'z') || (ch >= 'A' && ch <= 'Z') || (ch >= '0' && ch <= '9')
+}
+
+// protoParser reads declarations from tokens of a protobuf source
+type protoParser struct {
+	file   string
+	tokens []protoToken
+	pos    int
+	result *protoFile
+}
+
+// protoParse parses a protobuf source, returning its messages and enums
+func protoParse(file, src string) (*protoFile, error) {
+	tokens, err := protoTokenize(file, src)
+	if err != nil {
+		return nil, err
+	}
+	p := protoParser{file: file, tokens: tokens, result: &protoFile{path: file}}
+	if err := p.parseFile(); err != nil {
+		return nil, err
+	}
+	return p.result, nil
+}
+
+func (p *protoParser) line() int {
+	if p.pos < len(p.tokens) {
+		return p.tokens[p.pos].line
+	}
+	if len(p.tokens) > 0 {
+		return p.tokens[len(p.tokens)-1].line
+	}
+	return 1
+}
+
+func (p *protoParser) errorf(msg string, args ...interface{}) error {
+	return fmt.Errorf("%s:%d: %s", p.file, p.line(), fmt.Sprintf(msg, args...))
+}
+
+func (p *protoParser) warnf(msg string, args ...interface{}) {
+	p.result.warnings = append(p.result.warnings, fmt.Sprintf("%s:%d: %s", p.file, p.line(), fmt.Sprintf(msg, args...)))
+}
+
+// peek returns the text of the next token, or an empty string at the end of
+// the source
+func (p *protoParser) peek() string {
+	if p.pos < len(p.tokens) && !p.tokens[p.pos].quoted {
+		return p.tokens[p.pos].text
+	}
+	return ""
+}
+
+func (p *protoParser) next() (protoToken, error) {
+	if p.pos >= len(p.tokens) {
+		return protoToken{}, p.errorf("unexpected end of file")
+	}
+	p.pos++
+	return p.tokens[p.pos-1], nil
+}
+
+func (p *protoParser) expect(text string) error {
+	t, err := p.next()
+	if err != nil {
+		return err
+	}
+	if t.quoted || t.text != text {
+		p.pos--
+		return p.errorf("expected `%s', found `%s'", text, t.text)
+	}
+	return nil
+}
+
+func (p *protoParser) name() (string, error) {
+	t, err := p.next()
+	if err != nil {
+		return "", err
+	}
+	if t.quoted || !(isProtoWord(t.text[0]) || t.text[0] == '.') {
+		p.pos--
+		return "", p.errorf("expected identifier, found `%s'", t.text)
+	}
+	return t.text, nil
+}
+
+func (p *protoParser) str() (string, error) {
+	t, err := p.next()
+	if err != nil {
+		return "", err
+	}
+	if !t.quoted {
+		p.pos--
+		return "", p.errorf("expected string, found `%s'", t.text)
+	}
+	return t.text, nil
+}
+
+// skipStatement skips tokens up to the end of the current statement,
+// including nested blocks
+func (p *protoParser) skipStatement() error {
+	depth := 0
+	for {
+		t, err := p.next()
+		if err != nil {
+			return err
+		}
+		if t.quoted {
+			continue
+		}
+		switch t.text {
+		case "{":
+			depth++
+		case "}":
+			depth--
+			if depth == 0 {
+				if p.peek() == ";" {
+					p.pos++
+				}
+				return nil
+			}
+		case ";":
+			if depth == 0 {
+				return nil
+			}
+		}
+	}
+}
+
+func (p *protoParser) parseFile() error {
+	for p.pos < len(p.tokens) {
+		switch p.peek() {
+		case ";":
+			p.pos++
+		case "syntax", "edition":
+			p.pos++
+			if err := p.expect("="); err != nil {
+				return err
+			}
+			if _, err := p.str(); err != nil {
+				return err
+			}
+			if err := p.expect(";"); err != nil {
+				return err
+			}
+		case "package":
+			p.pos++
+			pkg, err := p.name()
+			if err != nil {
+				return err
+			}
+			p.result.pkg = pkg
+			if err := p.expect(";"); err != nil {
+				return err
+			}
+		case "import":
+			p.pos++
+			if p.peek() == "public" || p.peek() == "weak" {
+				p.pos++
+			}
+			path, err := p.str()
+			if err != nil {
+				return err
+			}
+			p.result.imports = append(p.result.imports, path)
+			if err := p.expect(";"); err != nil {
+				return err
+			}
+		case "option", "service":
+			if err := p.skipStatement(); err != nil {
+				return err
+			}
+		case "extend":
+			p.warnf("extensions cannot be represented and were skipped")
+			if err := p.skipStatement(); err != nil {
+				return err
+			}
+		case "message":
+			m, err := p.parseMessage(p.scope())
+			if err != nil {
+				return err
+			}
+			p.result.messages = append(p.result.messages, m)
+		case "enum":
+			if err := p.parseEnum(p.scope()); err != nil {
+				return err
+			}
+		default:
+			return p.errorf("unexpected `%s'", p.tokens[p.pos].text)
+		}
+	}
+	return nil
+}
+
+// scope returns the fully-qualified name of the file package
+func (p *protoParser) scope() string {
+	if p.result.pkg == "" {
+		return ""
+	}
+	return "." + p.result.pkg
+}
+
+func (p *protoParser) parseEnum(scope string) error {
+	p.pos++
+	name, err := p.name()
+	if err != nil {
+		return err
+	}
+	p.result.enums = append(p.result.enums, scope+"."+name)
+	return p.skipStatement()
+}
+
+func (p *protoParser) parseMessage(scope string) (*protoMessage, error) {
+	p.pos++
+	name, err := p.name()
+	if err != nil {
+		return nil, err
+	}
+	m := &protoMessage{name: name, fullName: scope + "." + name, file: p.file}
+	if err := p.expect("{"); err != nil {
+		return nil, err
+	}
+	if err := p.parseBody(m, ""); err != nil {
+		return nil, err
+	}
+	if p.peek() == ";" {
+		p.pos++
+	}
+	return m, nil
+}
+
+// parseBody parses declarations of a message or oneof up to its closing
+// brace. Fields declared by oneofs are tagged with its name.
+func (p *protoParser) parseBody(m *protoMessage, oneof string) error {
+	for {
+		switch p.peek() {
+		case "}":
+			p.pos++
+			return nil
+		case ";":
+			p.pos++
+		case "":
+			if p.pos >= len(p.tokens) {
+				return p.errorf("unexpected end of file")
+			}
+			return p.errorf("unexpected string")
+		case "message":
+			inner, err := p.parseMessage(m.fullName)
+			if err != nil {
+				return err
+			}
+			m.messages = append(m.messages, inner)
+		case "enum":
+			if err := p.parseEnum(m.fullName); err != nil {
+				return err
+			}
+		case "option":
+			if err := p.parseOption(m); err != nil {
+				return err
+			}
+		case "reserved", "extensions":
+			if err := p.skipStatement(); err != nil {
+				return err
+			}
+		case "extend":
+			p.warnf("extensions cannot be represented and were skipped")
+			if err := p.skipStatement(); err != nil {
+				return err
+			}
+		case "oneof":
+			p.pos++
+			name, err := p.name()
+			if err != nil {
+				return err
+			}
+			if err := p.expect("{"); err != nil {
+				return err
+			}
+			if err := p.parseBody(m, name); err != nil {
+				return err
+			}
+		default:
+			f, err := p.parseField()
+			if err != nil {
+				return err
+			}
+			f.oneof = oneof
+			m.fields = append(m.fields, *f)
+		}
+	}
+}
+
+// parseOption parses an option statement of a message, looking for its
+// package identifier
+func (p *protoParser) parseOption(m *protoMessage) error {
+	start := p.pos
+	p.pos++
+	var name []string
+	for p.peek() != "=" && p.peek() != ";" && p.pos < len(p.tokens) {
+		name = append(name, p.tokens[p.pos].text)
+		p.pos++
+	}
+	if strings.Join(name, "") != protoIDOption {
+		p.pos = start
+		return p.skipStatement()
+	}
+	if err := p.expect("="); err != nil {
+		return err
+	}
+	value, err := p.name()
+	if err != nil {
+		return err
+	}
+	m.id = value
+	return p.expect(";")
+}
+
+func (p *protoParser) parseField() (*protoField, error) {
+	f := &protoField{line: p.line()}
+	switch p.peek() {
+	case "repeated":
+		f.repeated = true
+		p.pos++
+	case "optional", "required":
+		p.pos++
+	}
+
+	if p.peek() == "map" && p.pos+1 < len(p.tokens) && p.tokens[p.pos+1].text == "<" {
+		f.isMap = true
+		for p.peek() != ">" {
+			if _, err := p.next(); err != nil {
+				return nil, err
+			}
+		}
+		p.pos++
+	} else {
+		kind, err := p.name()
+		if err != nil {
+			return nil, err
+		}
+		f.kind = kind
+		f.isGroup = kind == "group"
+	}
+
+	name, err := p.name()
+	if err != nil {
+		return nil, err
+	}
+	f.name = name
+	if err := p.expect("="); err != nil {
+		return nil, err
+	}
+	number, err := p.name()
+	if err != nil {
+		return nil, err
+	}
+	n, err := strconv.ParseInt(number, 0, 32)
+	if err != nil {
+		return nil, p.errorf("invalid field number %s", number)
+	}
+	f.number = int(n)
+
+	if p.peek() == "[" {
+		if err := p.parseFieldOptions(f); err != nil {
+			return nil, err
+		}
+	}
+	if f.isGroup {
+		// Groups declare their message inline
+		return f, p.skipStatement()
+	}
+	return f, p.expect(";")
+}
+
+// parseFieldOptions parses options of a field, looking for deprecations
+func (p *protoParser) parseFieldOptions(f *protoField) error {
+	p.pos++
+	for {
+		var name []string
+		for p.peek() != "=" {
+			t, err := p.next()
+			if err != nil {
+				return err
+			}
+			name = append(name, t.text)
+		}
+		p.pos++
+
+		// Values may be aggregates, so everything up to the next option is
+		// consumed
+		var value []string
+		depth := 0
+		for depth > 0 || (p.peek() != "," && p.peek() != "]") {
+			t, err := p.next()
+			if err != nil {
+				return err
+			}
+			if !t.quoted && t.text == "{" {
+				depth++
+			} else if !t.quoted && t.text == "}" {
+				depth--
+			}
+			value = append(value, t.text)
+		}
+		if strings.Join(name, "") == "deprecated" && strings.Join(value, "") == "true" {
+			f.deprecated = true
+		}
+
+		t, _ := p.next()
+		if t.text == "]" {
+			return nil
+		}
+	}
+}
@@ -0,0 +1,71 @@
+package importer
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ludwieg/ludco/models"
+)
+
+func TestProtoEmptyMessages(t *testing.T) {
+	src := `syntax = "proto3";
+
+message Empty {}
+
+message Shared {
+  map<string, string> labels = 1;
+  Empty nothing = 2;
+  string name = 3;
+}
+
+message First {
+  Shared shared = 1;
+  Empty marker = 2;
+}
+
+message Second {
+  Shared shared = 1;
+}
+`
+	dir, err := ioutil.TempDir("", "ludco-proto")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "sample.proto")
+	if err := ioutil.WriteFile(path, []byte(src), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	packages, warnings, err := Proto{}.Import([]string{path})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(packages) != 2 {
+		t.Fatalf("imported %d packages, expected 2", len(packages))
+	}
+	for _, p := range packages {
+		if len(p.Structs) != 1 || p.Structs[0].Name != "shared" {
+			t.Errorf("%s: structures %v, expected only shared", p.Name, p.Structs)
+		}
+		for _, f := range append(p.Fields, p.Structs[0].Fields...) {
+			if (f.Name == "marker" || f.Name == "nothing") && f.Type.NativeType != models.TypeAny {
+				t.Errorf("%s: field %s imported as %v, expected any", p.Name, f.Name, f.Type)
+			}
+		}
+	}
+
+	seen := map[string]bool{}
+	for _, w := range warnings {
+		if seen[w] {
+			t.Errorf("warning reported more than once: %s", w)
+		}
+		seen[w] = true
+	}
+	if len(warnings) != 3 {
+		t.Errorf("warnings:\n%s\nexpected 3", strings.Join(warnings, "\n"))
+	}
+}
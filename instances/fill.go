@@ -0,0 +1,86 @@
+// Package instances generates instances of packages described by a
+// `models.PackageList`, for use on conformance and load tests.
+package instances
+
+import (
+	"github.com/ludwieg/ludco/codec"
+	"github.com/ludwieg/ludco/models"
+)
+
+// maxDepth limits how deep structures are populated, since structures may
+// reference themselves
+const maxDepth = 8
+
+// filler populates objects using a set of strategies for each kind of value
+type filler struct {
+	// native returns a value for a field of the given native type, or nil
+	native func(t models.NativeType) interface{}
+
+	// length returns the amount of items for an array field. Negative values
+	// leave the field empty.
+	length func(f models.Field) int
+
+	// structs determines whether fields of user types are populated
+	structs bool
+}
+
+func (fl filler) fill(s *models.Scope, fields []models.Field, depth int) codec.Object {
+	obj := codec.Object{}
+	for _, f := range fields {
+		obj[f.Name] = fl.field(s, f, depth)
+	}
+	return obj
+}
+
+func (fl filler) field(s *models.Scope, f models.Field, depth int) interface{} {
+	if !f.IsArray() {
+		return fl.single(s, f.Type, depth)
+	}
+	n := fl.length(f)
+	if n < 0 {
+		return nil
+	}
+	items := make([]interface{}, n)
+	for i := range items {
+		items[i] = fl.single(s, f.Type, depth)
+	}
+	return items
+}
+
+func (fl filler) single(s *models.Scope, t models.Type, depth int) interface{} {
+	if t.Source == models.SourceNative {
+		return fl.native(t.NativeType)
+	}
+	if !fl.structs || depth >= maxDepth {
+		return nil
+	}
+	str, inner, ok := s.Resolve(t.CustomType)
+	if !ok {
+		return nil
+	}
+	return fl.fill(inner, str.Fields, depth+1)
+}
+
+// hasField determines whether the package, or any structure reachable from
+// it, contains a field satisfying fn
+func hasField(p *models.Package, fn func(f models.Field) bool) bool {
+	return scopeHasField(p.Scope(), p.Fields, fn, 0)
+}
+
+func scopeHasField(s *models.Scope, fields []models.Field, fn func(f models.Field) bool, depth int) bool {
+	if depth >= maxDepth {
+		return false
+	}
+	for _, f := range fields {
+		if fn(f) {
+			return true
+		}
+		if f.Type.Source != models.SourceUser {
+			continue
+		}
+		if str, inner, ok := s.Resolve(f.Type.CustomType); ok && scopeHasField(inner, str.Fields, fn, depth+1) {
+			return true
+		}
+	}
+	return false
+}
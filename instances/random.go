@@ -0,0 +1,120 @@
+package instances
+
+import (
+	"math"
+	"math/rand"
+	"strconv"
+	"strings"
+
+	"github.com/ludwieg/ludco/codec"
+	"github.com/ludwieg/ludco/models"
+)
+
+const (
+	// emptyChance determines how often random values are left empty
+	emptyChance = 0.1
+
+	// maxRandomItems limits the amount of items on arrays with arbitrary
+	// sizes
+	maxRandomItems = 8
+
+	// maxRandomLength limits the length of strings and blobs
+	maxRandomLength = 48
+)
+
+var words = strings.Fields(`lorem ipsum dolor sit amet consectetur adipiscing
+	elit sed do eiusmod tempor incididunt ut labore et dolore magna aliqua ação
+	straße ωμέγα 日本語 🚀`)
+
+var anyTypes = []models.NativeType{
+	models.TypeUint8,
+	models.TypeUint32,
+	models.TypeUint64,
+	models.TypeDouble,
+	models.TypeString,
+	models.TypeBlob,
+	models.TypeBool,
+	models.TypeUUID,
+	models.TypeDynInt,
+}
+
+// Random generates an instance of a package using values drawn from rnd.
+// Values are occasionally left empty, arrays with fixed sizes hold exactly
+// that many items, and integers are spread across all encoding widths.
+func Random(p *models.Package, rnd *rand.Rand) codec.Object {
+	fl := filler{
+		native: func(t models.NativeType) interface{} {
+			if rnd.Float64() < emptyChance {
+				return nil
+			}
+			return randomValue(t, rnd)
+		},
+		length: func(f models.Field) int {
+			if rnd.Float64() < emptyChance {
+				return -1
+			}
+			if f.Size != "*" {
+				size, _ := strconv.Atoi(f.Size)
+				return size
+			}
+			return rnd.Intn(maxRandomItems + 1)
+		},
+		structs: true,
+	}
+	return fl.fill(p.Scope(), p.Fields, 0)
+}
+
+// randomUint returns an integer with up to the given amount of bits, picking
+// its width first so that small and large values are equally frequent
+func randomUint(rnd *rand.Rand, bits uint) uint64 {
+	width := uint(rnd.Intn(int(bits/8))+1) * 8
+	if width == 64 {
+		return rnd.Uint64()
+	}
+	return rnd.Uint64() & (1<<width - 1)
+}
+
+func randomValue(t models.NativeType, rnd *rand.Rand) interface{} {
+	switch t {
+	case models.TypeUint8, models.TypeByte:
+		return uint8(rnd.Intn(math.MaxUint8 + 1))
+	case models.TypeBool:
+		return rnd.Intn(2) == 1
+	case models.TypeUint32:
+		return uint32(randomUint(rnd, 32))
+	case models.TypeUint64:
+		return randomUint(rnd, 64)
+	case models.TypeDouble:
+		return (rnd.Float64() - 0.5) * math.Pow(10, float64(rnd.Intn(12)))
+	case models.TypeString:
+		n := rnd.Intn(maxRandomLength/4 + 1)
+		arr := make([]string, n)
+		for i := range arr {
+			arr[i] = words[rnd.Intn(len(words))]
+		}
+		return strings.Join(arr, " ")
+	case models.TypeBlob:
+		blob := make([]byte, rnd.Intn(maxRandomLength+1))
+		rnd.Read(blob)
+		return blob
+	case models.TypeUUID:
+		var u codec.UUID
+		rnd.Read(u[:])
+		// Version 4, RFC 4122 variant
+		u[6] = u[6]&0x0F | 0x40
+		u[8] = u[8]&0x3F | 0x80
+		return u
+	case models.TypeDynInt:
+		return codec.DynInt(randomUint(rnd, 64))
+	case models.TypeAny:
+		if rnd.Intn(len(anyTypes)+1) == 0 {
+			items := make([]interface{}, rnd.Intn(maxRandomItems+1))
+			for i := range items {
+				items[i] = randomValue(anyTypes[rnd.Intn(len(anyTypes))], rnd)
+			}
+			return items
+		}
+		return randomValue(anyTypes[rnd.Intn(len(anyTypes))], rnd)
+	}
+	return nil
+}
@@ -0,0 +1,272 @@
+package instances
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/ludwieg/ludco/codec"
+	"github.com/ludwieg/ludco/models"
+)
+
+// Vector represents a named instance of a package, used to assert that
+// different runtimes produce identical encodings
+type Vector struct {
+	Name  string
+	Value codec.Object
+}
+
+// DynIntBoundaries holds values on both sides of each dynint width boundary
+var DynIntBoundaries = []uint64{
+	0,
+	math.MaxUint8,
+	math.MaxUint8 + 1,
+	math.MaxUint16,
+	math.MaxUint16 + 1,
+	math.MaxUint32,
+	math.MaxUint32 + 1,
+	math.MaxUint64,
+}
+
+// unicodeSample mixes two, three, and four-byte UTF-8 sequences
+const unicodeSample = "Ludwieg: ação, Straße, Ωμέγα, 日本語, 🚀👍🏽"
+
+// longSize crosses the one-byte dynamic size boundary
+const longSize = math.MaxUint8 + 1
+
+var anySamples = []struct {
+	name  string
+	value interface{}
+}{
+	{"uint8", uint8(27)},
+	{"uint32", uint32(28)},
+	{"uint64", uint64(29)},
+	{"double", 30.2},
+	{"string", "Stringy!"},
+	{"blob", []byte{0x27, 0x24, 0x50}},
+	{"bool", true},
+	{"uuid", codec.UUID{0x32, 0x32, 0xEE, 0x42, 0xC2, 0xF2, 0x4B, 0xAF, 0x84, 0x13, 0x18, 0x33, 0x5B, 0x4D, 0x56, 0x40}},
+	{"dynint", codec.DynInt(math.MaxUint16 + 1)},
+	{"array", []interface{}{uint8(1), "two", nil, []interface{}{codec.DynInt(3)}}},
+	{"empty_array", []interface{}{}},
+}
+
+// Vectors deterministically generates instances of a package exercising its
+// edge cases, such as empty values, zero and maximum values, dynint width
+// boundaries, empty arrays, empty structures, and non-ASCII strings. Vectors
+// exercising field types absent from the package are omitted.
+func Vectors(p *models.Package) []Vector {
+	s := p.Scope()
+	build := func(name string, fl filler) Vector {
+		return Vector{Name: name, Value: fl.fill(s, p.Fields, 0)}
+	}
+	vectors := []Vector{
+		{Name: "empty", Value: codec.Object{}},
+		build("zero", filler{native: zeroValue, length: fixedLength(0), structs: true}),
+		build("typical", typicalFiller()),
+		build("maximum", filler{native: maximumValue, length: fixedLength(2), structs: true}),
+	}
+
+	if hasField(p, func(f models.Field) bool { return f.IsArray() }) {
+		fl := typicalFiller()
+		fl.native = func(models.NativeType) interface{} { return nil }
+		vectors = append(vectors, build("empty_items", fl))
+	}
+
+	if hasField(p, func(f models.Field) bool { return f.Type.Source == models.SourceUser }) {
+		fl := typicalFiller()
+		fl.structs = false
+		vectors = append(vectors, build("empty_structs", fl))
+	}
+
+	if hasField(p, isNative(models.TypeDynInt)) {
+		for _, b := range DynIntBoundaries {
+			fl := typicalFiller()
+			typical := fl.native
+			value := codec.DynInt(b)
+			fl.native = func(t models.NativeType) interface{} {
+				if t == models.TypeDynInt {
+					return value
+				}
+				return typical(t)
+			}
+			vectors = append(vectors, build("dynint_"+strconv.FormatUint(b, 10), fl))
+		}
+	}
+
+	if hasField(p, isNative(models.TypeString)) {
+		fl := typicalFiller()
+		typical := fl.native
+		fl.native = func(t models.NativeType) interface{} {
+			if t == models.TypeString {
+				return unicodeSample
+			}
+			return typical(t)
+		}
+		vectors = append(vectors, build("unicode", fl))
+	}
+
+	if hasField(p, isNative(models.TypeString, models.TypeBlob)) {
+		fl := typicalFiller()
+		typical := fl.native
+		fl.native = func(t models.NativeType) interface{} {
+			switch t {
+			case models.TypeString:
+				return strings.Repeat("L", longSize)
+			case models.TypeBlob:
+				blob := make([]byte, longSize)
+				for i := range blob {
+					blob[i] = byte(i)
+				}
+				return blob
+			}
+			return typical(t)
+		}
+		vectors = append(vectors, build("long_values", fl))
+	}
+
+	if hasField(p, isNative(models.TypeAny)) {
+		for _, sample := range anySamples {
+			fl := typicalFiller()
+			typical := fl.native
+			value := sample.value
+			fl.native = func(t models.NativeType) interface{} {
+				if t == models.TypeAny {
+					return value
+				}
+				return typical(t)
+			}
+			vectors = append(vectors, build("any_"+sample.name, fl))
+		}
+	}
+
+	return vectors
+}
+
+func isNative(types ...models.NativeType) func(f models.Field) bool {
+	return func(f models.Field) bool {
+		if f.Type.Source != models.SourceNative {
+			return false
+		}
+		for _, t := range types {
+			if f.Type.NativeType == t {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// fixedLength returns a length strategy using n items, capped by the size of
+// fixed arrays. Sizes are hints rather than exact lengths, so filling large
+// fixed arrays completely would only yield huge vectors.
+func fixedLength(n int) func(f models.Field) int {
+	return func(f models.Field) int {
+		if f.Size == "*" {
+			return n
+		}
+		if size, _ := strconv.Atoi(f.Size); size < n {
+			return size
+		}
+		return n
+	}
+}
+
+// typicalFiller populates every field with a distinct value, so that fields
+// swapped by a runtime yield different encodings
+func typicalFiller() filler {
+	n := 0
+	return filler{
+		native: func(t models.NativeType) interface{} {
+			n++
+			return typicalValue(t, n)
+		},
+		length:  fixedLength(2),
+		structs: true,
+	}
+}
+
+func typicalValue(t models.NativeType, n int) interface{} {
+	switch t {
+	case models.TypeUint8, models.TypeByte:
+		return uint8(n)
+	case models.TypeBool:
+		return n%2 == 1
+	case models.TypeUint32:
+		return uint32(n)<<16 | uint32(n)
+	case models.TypeUint64:
+		return uint64(n)<<32 | uint64(n)
+	case models.TypeDouble:
+		return float64(n) + 0.25
+	case models.TypeString:
+		return fmt.Sprintf("value %d", n)
+	case models.TypeBlob:
+		return []byte{0x27, 0x24, 0x50, byte(n)}
+	case models.TypeUUID:
+		u := codec.UUID{0x32, 0x32, 0xEE, 0x42, 0xC2, 0xF2, 0x4B, 0xAF, 0x84, 0x13, 0x18, 0x33, 0x5B, 0x4D, 0x56, 0x40}
+		u[15] = byte(n)
+		return u
+	case models.TypeDynInt:
+		return codec.DynInt(n * 1000)
+	case models.TypeAny:
+		return anySamples[n%len(anySamples)].value
+	}
+	return nil
+}
+
+func zeroValue(t models.NativeType) interface{} {
+	switch t {
+	case models.TypeUint8, models.TypeByte:
+		return uint8(0)
+	case models.TypeBool:
+		return false
+	case models.TypeUint32:
+		return uint32(0)
+	case models.TypeUint64:
+		return uint64(0)
+	case models.TypeDouble:
+		return float64(0)
+	case models.TypeString:
+		return ""
+	case models.TypeBlob:
+		return []byte{}
+	case models.TypeUUID:
+		return codec.UUID{}
+	case models.TypeDynInt:
+		return codec.DynInt(0)
+	case models.TypeAny:
+		return uint8(0)
+	}
+	return nil
+}
+
+func maximumValue(t models.NativeType) interface{} {
+	switch t {
+	case models.TypeUint8, models.TypeByte:
+		return uint8(math.MaxUint8)
+	case models.TypeBool:
+		return true
+	case models.TypeUint32:
+		return uint32(math.MaxUint32)
+	case models.TypeUint64:
+		return uint64(math.MaxUint64)
+	case models.TypeDouble:
+		return math.MaxFloat64
+	case models.TypeString:
+		return strings.Repeat("M", longSize)
+	case models.TypeBlob:
+		return []byte(strings.Repeat("\xff", longSize))
+	case models.TypeUUID:
+		var u codec.UUID
+		for i := range u {
+			u[i] = 0xFF
+		}
+		return u
+	case models.TypeDynInt:
+		return codec.DynInt(math.MaxUint64)
+	case models.TypeAny:
+		return uint64(math.MaxUint64)
+	}
+	return nil
+}
@@ -0,0 +1,38 @@
+package instances
+
+import (
+	"testing"
+
+	"github.com/ludwieg/ludco/models"
+	"github.com/ludwieg/ludco/parser"
+)
+
+func TestVectorsCapFixedArrays(t *testing.T) {
+	src := `package sample {
+    id 0x05
+
+    uint8[100000]   large
+    uint8[1]        single
+    @item[*]        items
+
+    struct item {
+        string[3]   names
+    }
+}
+`
+	out, err := parser.Parse("sample.lud", []byte(src))
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := models.ConvertASTPackage(out.([]interface{})[0].(parser.Package))
+
+	limits := map[string]int{"large": 2, "single": 1, "items": 2}
+	for _, v := range Vectors(p) {
+		for name, limit := range limits {
+			items, _ := v.Value[name].([]interface{})
+			if len(items) > limit {
+				t.Errorf("%s: %s holds %d items, expected at most %d", v.Name, name, len(items), limit)
+			}
+		}
+	}
+}
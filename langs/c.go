@@ -0,0 +1,405 @@
+package langs
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/alecthomas/chroma/quick"
+	"github.com/logrusorgru/aurora"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/ludwieg/ludco/codec"
+	"github.com/ludwieg/ludco/models"
+)
+
+// cKeywords lists reserved words that cannot be used as member names, along
+// with members declared by generated structures
+var cKeywords = map[string]bool{
+	"auto": true, "bool": true, "break": true, "case": true, "char": true,
+	"const": true, "continue": true, "default": true, "do": true, "double": true,
+	"else": true, "enum": true, "extern": true, "false": true, "float": true,
+	"for": true, "goto": true, "if": true, "inline": true, "int": true,
+	"long": true, "register": true, "restrict": true, "return": true,
+	"short": true, "signed": true, "sizeof": true, "static": true,
+	"struct": true, "switch": true, "true": true, "typedef": true, "union": true,
+	"unsigned": true, "void": true, "volatile": true, "while": true,
+	"present": true,
+}
+
+type C struct {
+	prefix string
+	out    string
+
+	// names maps structures to the base name of their generated type and
+	// functions
+	names map[*models.Struct]string
+
+	// references maps structures to structures held by their fields, which
+	// are used to order declarations and to detect recursive types
+	references map[*models.Struct][]*models.Struct
+}
+
+func (c C) Compile(in, out, pkgName, prefix string, packages *models.PackageList) {
+	log.Infof("Initialising %s compiler", aurora.Cyan("C"))
+	if pkgName != "" {
+		log.Warn("Ignoring unnecessary --package option")
+	}
+	c.prefix = strings.ToLower(prefix)
+	c.out = out
+	c.names = map[*models.Struct]string{}
+	c.references = map[*models.Struct][]*models.Struct{}
+
+	for i := range *packages {
+		c.checkName(&(*packages)[i])
+	}
+
+	var magic []string
+	for _, b := range codec.Magic {
+		magic = append(magic, fmt.Sprintf("0x%02X", b))
+	}
+	c.output("ludwieg_config.h", []byte(cConfig))
+	c.output("ludwieg.h", processTemplate("cRuntimeHeader", cRuntimeHeader, templateData{
+		"magicLength": len(codec.Magic),
+		"version":     fmt.Sprintf("0x%02X", codec.ProtocolVersion),
+	}))
+	c.output("ludwieg.c", processTemplate("cRuntimeSource", cRuntimeSource, templateData{
+		"magic": strings.Join(magic, ", "),
+	}))
+
+	for i := range *packages {
+		c.writePackage(&(*packages)[i])
+	}
+	c.writeMessages(packages)
+
+	log.Info("Succeeded")
+	fmt.Println()
+	fmt.Println(c.integrationInstructions())
+}
+
+func (c C) output(name string, contents []byte) {
+	log.Infof("Writing %s/%s", aurora.Magenta(filepath.Base(c.out)), aurora.Magenta(name))
+	err := ioutil.WriteFile(filepath.Join(c.out, name), contents, 0644)
+	if err != nil {
+		log.Errorf("Operation failed: %s", err)
+		os.Exit(1)
+	}
+}
+
+// baseName returns the name prepended to types and functions of a package
+func (c C) baseName(p *models.Package) string {
+	if c.prefix == "" {
+		return p.Name
+	}
+	return c.prefix + "_" + p.Name
+}
+
+// messagesName returns the name of the message type and its functions
+func (c C) messagesName() string {
+	if c.prefix == "" {
+		return "ludwieg"
+	}
+	return c.prefix
+}
+
+// checkName rejects packages whose files or symbols would clash with the
+// ones of the runtime, which are all named after ludwieg, or with the
+// messages files
+func (c C) checkName(p *models.Package) {
+	name := c.baseName(p)
+	if name == "ludwieg" || strings.HasPrefix(name, "ludwieg_") || name == c.messagesName()+"_messages" {
+		log.Errorf("Package %s would be written as %s, which clashes with the runtime or messages files. Rename it or use a different --prefix", aurora.Magenta(p.Name), aurora.Magenta(name))
+		os.Exit(1)
+	}
+}
+
+func (c C) writePackage(p *models.Package) {
+	name := c.baseName(p)
+	var all []*models.Struct
+	c.registerStructs(name, p.Scope(), p.Structs, &all)
+
+	for _, s := range all {
+		if c.reaches(s, s, map[*models.Struct]bool{}, true) {
+			log.Errorf("Structure %s of package %s is recursive, and cannot be represented without heap allocation", aurora.Magenta(s.Name), aurora.Magenta(p.Name))
+			os.Exit(1)
+		}
+	}
+
+	// Structures are declared after the ones they hold
+	var ordered []*models.Struct
+	declared := map[*models.Struct]bool{}
+	for _, s := range all {
+		c.order(s, declared, &ordered)
+	}
+
+	scopes := map[*models.Struct]*models.Scope{}
+	c.collectScopes(p.Scope(), p.Structs, scopes)
+
+	var types, prototypes, functions []string
+	for _, s := range ordered {
+		sName := c.names[s]
+		fields := []string{"    bool present;\n"}
+		read, write := c.generateFields(scopes[s], s.Fields, sName, &fields)
+		types = append(types, string(processTemplate("cStructType", cStructType, templateData{
+			"name":   sName,
+			"fields": strings.Join(fields, ""),
+		})))
+		prototypes = append(prototypes,
+			fmt.Sprintf("static ludwieg_error %s_read(ludwieg_reader *r, void *out);\n", sName),
+			fmt.Sprintf("static ludwieg_error %s_write(ludwieg_writer *w, const void *in);\n", sName))
+		functions = append(functions, c.fieldFunctions(sName, read, write))
+		functions = append(functions, string(processTemplate("cStructFunctions", cStructFunctions, templateData{
+			"name": sName,
+		})))
+	}
+
+	var fields []string
+	read, write := c.generateFields(p.Scope(), p.Fields, name, &fields)
+	if len(fields) == 0 {
+		fields = append(fields, "    uint8_t reserved; /* C forbids empty structures */\n")
+	}
+	types = append(types, string(processTemplate("cStructType", cStructType, templateData{
+		"name":   name,
+		"fields": strings.Join(fields, ""),
+	})))
+	functions = append(functions, c.fieldFunctions(name, read, write))
+
+	if len(prototypes) > 0 {
+		prototypes = append([]string{"\n"}, prototypes...)
+	}
+	c.output(name+".h", processTemplate("cPackageHeader", cPackageHeader, templateData{
+		"guard":   strings.ToUpper(name) + "_H",
+		"idMacro": strings.ToUpper(name) + "_ID",
+		"id":      p.Identifier,
+		"name":    name,
+		"types":   strings.Join(types, ""),
+	}))
+	c.output(name+".c", processTemplate("cPackageSource", cPackageSource, templateData{
+		"file":       name,
+		"name":       name,
+		"prototypes": strings.Join(prototypes, ""),
+		"functions":  strings.Join(functions, ""),
+	}))
+}
+
+// registerStructs assigns base names to structures, and collects their
+// references and the structures themselves
+func (c C) registerStructs(prefix string, scope *models.Scope, sArr []models.Struct, all *[]*models.Struct) {
+	for i := range sArr {
+		s := &sArr[i]
+		name := prefix + "_" + s.Name
+		c.names[s] = name
+		*all = append(*all, s)
+
+		inner := &models.Scope{Structs: s.Structs, Parent: scope}
+		for _, f := range s.Fields {
+			if f.Type.Source != models.SourceUser {
+				continue
+			}
+			if ref, _, ok := inner.Resolve(f.Type.CustomType); ok {
+				c.references[s] = append(c.references[s], ref)
+			}
+		}
+		c.registerStructs(name, inner, s.Structs, all)
+	}
+}
+
+func (c C) collectScopes(scope *models.Scope, sArr []models.Struct, scopes map[*models.Struct]*models.Scope) {
+	for i := range sArr {
+		s := &sArr[i]
+		inner := &models.Scope{Structs: s.Structs, Parent: scope}
+		scopes[s] = inner
+		c.collectScopes(inner, s.Structs, scopes)
+	}
+}
+
+// reaches determines whether a value of from may hold a value of to. first
+// indicates from itself is not yet considered a match.
+func (c C) reaches(from, to *models.Struct, visited map[*models.Struct]bool, first bool) bool {
+	if from == to && !first {
+		return true
+	}
+	if visited[from] {
+		return false
+	}
+	visited[from] = true
+	for _, ref := range c.references[from] {
+		if c.reaches(ref, to, visited, false) {
+			return true
+		}
+	}
+	return false
+}
+
+func (c C) order(s *models.Struct, declared map[*models.Struct]bool, ordered *[]*models.Struct) {
+	if declared[s] {
+		return
+	}
+	declared[s] = true
+	for _, ref := range c.references[s] {
+		c.order(ref, declared, ordered)
+	}
+	*ordered = append(*ordered, s)
+}
+
+func (c C) fieldFunctions(name string, read, write []string) string {
+	if len(read) == 0 {
+		read = []string{"    (void)r;\n", "    (void)out;\n"}
+		write = []string{"    (void)w;\n", "    (void)in;\n"}
+	} else {
+		read = append([]string{fmt.Sprintf("    %s_t *v = out;\n", name)}, read...)
+		write = append([]string{fmt.Sprintf("    const %s_t *v = in;\n", name)}, write...)
+	}
+	return string(processTemplate("cFieldFunctions", cFieldFunctions, templateData{
+		"name":  name,
+		"read":  strings.Join(read, ""),
+		"write": strings.Join(write, ""),
+	}))
+}
+
+// cKind returns the suffix of the value type, reader and writer handling a
+// native type
+func cKind(t models.NativeType) string {
+	switch t {
+	case models.TypeUint8, models.TypeByte:
+		return "uint8"
+	case models.TypeUint32:
+		return "uint32"
+	case models.TypeUint64:
+		return "uint64"
+	case models.TypeDouble:
+		return "double"
+	case models.TypeString:
+		return "string"
+	case models.TypeBlob:
+		return "blob"
+	case models.TypeBool:
+		return "bool"
+	case models.TypeUUID:
+		return "uuid"
+	case models.TypeAny:
+		return "any"
+	case models.TypeDynInt:
+		return "dynint"
+	}
+	log.Fatalf("BUG: Cannot coerce unknown type %#v to native type.", t)
+	return ""
+}
+
+func cIdentifier(name string) string {
+	if cKeywords[name] {
+		return name + "_"
+	}
+	return name
+}
+
+// generateFields appends member declarations of fields to decls, returning
+// statements decoding and encoding them
+func (c C) generateFields(scope *models.Scope, fArr []models.Field, owner string, decls *[]string) ([]string, []string) {
+	var read, write []string
+	for _, f := range fArr {
+		name := cIdentifier(f.Name)
+
+		var t, reader, writer string
+		if f.Type.Source == models.SourceUser {
+			s, _, ok := scope.Resolve(f.Type.CustomType)
+			if !ok {
+				log.Errorf("BUG: Cannot resolve structure %s referenced by %s", f.Type.CustomType, f.Name)
+				os.Exit(1)
+			}
+			t = c.names[s] + "_t"
+			reader = c.names[s] + "_read"
+			writer = c.names[s] + "_write"
+		} else {
+			kind := cKind(f.Type.NativeType)
+			t = "ludwieg_" + kind
+			reader = "ludwieg_read_" + kind
+			writer = "ludwieg_write_" + kind
+		}
+
+		var decl string
+		if f.IsArray() {
+			size := "LUDWIEG_MAX_ARRAY_LENGTH"
+			if f.Size != "*" {
+				size = f.Size
+			}
+			decl = string(processTemplate("cArrayField", cArrayField, templateData{
+				"type": t,
+				"size": size,
+				"name": name,
+			}))
+			read = append(read, fmt.Sprintf("    LUDWIEG_TRY(LUDWIEG_READ_ARRAY(r, v->%s, %s));\n", name, reader))
+			write = append(write, fmt.Sprintf("    LUDWIEG_TRY(LUDWIEG_WRITE_ARRAY(w, v->%s, %s));\n", name, writer))
+		} else {
+			decl = fmt.Sprintf("%s %s;", t, name)
+			read = append(read, fmt.Sprintf("    LUDWIEG_TRY(%s(r, &v->%s));\n", reader, name))
+			write = append(write, fmt.Sprintf("    LUDWIEG_TRY(%s(w, &v->%s));\n", writer, name))
+		}
+		if f.HasAttribute(models.AttributeDeprecated) {
+			decl = "/* Deprecated */\n" + decl
+		}
+		*decls = append(*decls, indent(decl)+"\n")
+	}
+	return read, write
+}
+
+func (c C) writeMessages(pList *models.PackageList) {
+	prefix := c.messagesName()
+	file := prefix + "_messages"
+
+	var includes, members, encoders, decoders []string
+	for i := range *pList {
+		p := &(*pList)[i]
+		name := c.baseName(p)
+		id := strings.ToUpper(name) + "_ID"
+		member := cIdentifier(p.Name)
+		includes = append(includes, fmt.Sprintf("#include \"%s.h\"", name))
+		members = append(members, fmt.Sprintf("        %s_t %s;\n", name, member))
+		encoders = append(encoders, fmt.Sprintf("    case %s:\n        return %s_encode(w, &in->package.%s);\n", id, name, member))
+		decoders = append(decoders, fmt.Sprintf("    case %s:\n        return %s_decode(&payload, &out->package.%s);\n", id, name, member))
+	}
+	sort.Strings(includes)
+
+	c.output(file+".h", processTemplate("cMessagesHeader", cMessagesHeader, templateData{
+		"guard":    strings.ToUpper(file) + "_H",
+		"prefix":   prefix,
+		"includes": strings.Join(includes, "\n"),
+		"members":  strings.Join(members, ""),
+	}))
+	c.output(file+".c", processTemplate("cMessagesSource", cMessagesSource, templateData{
+		"file":     file,
+		"prefix":   prefix,
+		"encoders": strings.Join(encoders, ""),
+		"decoders": strings.Join(decoders, ""),
+	}))
+}
+
+func (c C) formatCode(code string) string {
+	var buf bytes.Buffer
+	err := quick.Highlight(&buf, code, "c", "terminal", "pygments")
+	if err != nil {
+		log.Fatalf("BUG: Error processing C source: %s", err)
+	}
+
+	return string(buf.Bytes())
+}
+
+func (c C) integrationInstructions() string {
+	prefix := c.messagesName()
+	usage := strings.Join([]string{
+		prefix + "_message message;",
+		"     size_t used;",
+		"     ludwieg_error err = " + prefix + "_decode_message(&message, data, length, &used);",
+	}, "\n")
+
+	return string(processTemplate("cIntegration", cIntegrationSteps, templateData{
+		"config":   aurora.Magenta("ludwieg_config.h"),
+		"messages": aurora.Magenta(prefix + "_messages.h"),
+		"cFlags":   c.formatCode("cc -std=c99 -DLUDWIEG_MAX_STRING_LENGTH=128 -c *.c"),
+		"cUsage":   c.formatCode(usage),
+	}))
+}
@@ -0,0 +1,947 @@
+package langs
+
+const cConfig = `/* WARNING: Automatically generated by ludco. DO NOT EDIT.
+ *
+ * Capacities of dynamic values. Generated structures do not use heap
+ * allocation, so strings, blobs and arrays without a fixed size hold up to the
+ * amount of items defined here. Values exceeding them are rejected with
+ * LUDWIEG_ERR_CAPACITY. Each of them may be overridden through compiler flags,
+ * e.g. -DLUDWIEG_MAX_STRING_LENGTH=128.
+ */
+
+#ifndef LUDWIEG_CONFIG_H
+#define LUDWIEG_CONFIG_H
+
+/* Maximum length of strings, in bytes, excluding the NUL terminator */
+#ifndef LUDWIEG_MAX_STRING_LENGTH
+#define LUDWIEG_MAX_STRING_LENGTH 64
+#endif
+
+/* Maximum length of blobs, in bytes */
+#ifndef LUDWIEG_MAX_BLOB_LENGTH
+#define LUDWIEG_MAX_BLOB_LENGTH 64
+#endif
+
+/* Maximum length of strings, blobs and arrays held by any fields, in bytes */
+#ifndef LUDWIEG_MAX_ANY_LENGTH
+#define LUDWIEG_MAX_ANY_LENGTH 64
+#endif
+
+/* Maximum amount of items of arrays declared with a dynamic size */
+#ifndef LUDWIEG_MAX_ARRAY_LENGTH
+#define LUDWIEG_MAX_ARRAY_LENGTH 8
+#endif
+
+#endif
+`
+
+const cRuntimeHeader = `/* WARNING: Automatically generated by ludco. DO NOT EDIT.
+ *
+ * Runtime used by generated packages to encode and decode values using the
+ * Ludwieg wire format. Values are read from and written to buffers provided
+ * by the caller; no heap allocation is performed.
+ */
+
+#ifndef LUDWIEG_H
+#define LUDWIEG_H
+
+#include <stdbool.h>
+#include <stddef.h>
+#include <stdint.h>
+
+#include "ludwieg_config.h"
+
+#define LUDWIEG_MAGIC_LENGTH {{.magicLength}}
+#define LUDWIEG_PROTOCOL_VERSION {{.version}}
+
+extern const uint8_t ludwieg_magic[LUDWIEG_MAGIC_LENGTH];
+
+typedef enum {
+    LUDWIEG_TYPE_UINT8 = 0x01,
+    LUDWIEG_TYPE_UINT32 = 0x02,
+    LUDWIEG_TYPE_UINT64 = 0x03,
+    LUDWIEG_TYPE_DOUBLE = 0x04,
+    LUDWIEG_TYPE_STRING = 0x05,
+    LUDWIEG_TYPE_BLOB = 0x06,
+    LUDWIEG_TYPE_BOOL = 0x07,
+    LUDWIEG_TYPE_UUID = 0x08,
+    LUDWIEG_TYPE_ANY = 0x09,
+    LUDWIEG_TYPE_ARRAY = 0x0A,
+    LUDWIEG_TYPE_STRUCT = 0x0B,
+    LUDWIEG_TYPE_DYNINT = 0x0C
+} ludwieg_type;
+
+typedef enum {
+    LUDWIEG_OK = 0,
+    /* Data does not contain a complete message yet */
+    LUDWIEG_ERR_SHORT_BUFFER,
+    LUDWIEG_ERR_INVALID_MAGIC,
+    LUDWIEG_ERR_UNSUPPORTED_VERSION,
+    LUDWIEG_ERR_UNKNOWN_PACKAGE,
+    LUDWIEG_ERR_INVALID_SIZE,
+    LUDWIEG_ERR_UNEXPECTED_TYPE,
+    /* A value exceeds the boundaries of its message */
+    LUDWIEG_ERR_BOUNDARIES,
+    /* A value exceeds the capacity of its field */
+    LUDWIEG_ERR_CAPACITY,
+    /* The output buffer cannot hold the encoded message */
+    LUDWIEG_ERR_BUFFER_FULL
+} ludwieg_error;
+
+const char *ludwieg_error_string(ludwieg_error err);
+
+/* LUDWIEG_TRY returns from the calling function when expr fails */
+#define LUDWIEG_TRY(expr) do { \
+        ludwieg_error ludwieg_err_ = (expr); \
+        if (ludwieg_err_ != LUDWIEG_OK) { \
+            return ludwieg_err_; \
+        } \
+    } while (0)
+
+/* Values are held along with a flag indicating whether they are present */
+typedef struct { bool present; uint8_t value; } ludwieg_uint8;
+typedef struct { bool present; uint32_t value; } ludwieg_uint32;
+typedef struct { bool present; uint64_t value; } ludwieg_uint64;
+typedef struct { bool present; double value; } ludwieg_double;
+typedef struct { bool present; size_t length; char value[LUDWIEG_MAX_STRING_LENGTH + 1]; } ludwieg_string;
+typedef struct { bool present; size_t length; uint8_t value[LUDWIEG_MAX_BLOB_LENGTH]; } ludwieg_blob;
+typedef struct { bool present; bool value; } ludwieg_bool;
+typedef struct { bool present; uint8_t value[16]; } ludwieg_uuid;
+typedef struct { bool present; uint64_t value; } ludwieg_dynint;
+
+/* ludwieg_any holds values of any fields along with their type. Strings and
+ * blobs are stored on data, while other values are stored on value. Arrays are
+ * stored on data as their encoded body: a size holding the amount of items,
+ * followed by the items themselves, which can be read through
+ * ludwieg_read_size and ludwieg_read_any. */
+typedef struct {
+    bool present;
+    ludwieg_type type;
+    union {
+        uint8_t u8;
+        uint32_t u32;
+        uint64_t u64;
+        double f64;
+        bool boolean;
+        uint8_t uuid[16];
+    } value;
+    size_t length;
+    uint8_t data[LUDWIEG_MAX_ANY_LENGTH + 1];
+} ludwieg_any;
+
+typedef struct {
+    const uint8_t *data;
+    size_t position;
+    size_t limit;
+} ludwieg_reader;
+
+/* Writers initialised with a NULL buffer only measure encoded values */
+typedef struct {
+    uint8_t *data;
+    size_t length;
+    size_t capacity;
+} ludwieg_writer;
+
+typedef ludwieg_error (*ludwieg_read_fn)(ludwieg_reader *r, void *out);
+typedef ludwieg_error (*ludwieg_write_fn)(ludwieg_writer *w, const void *in);
+
+void ludwieg_reader_init(ludwieg_reader *r, const uint8_t *data, size_t length);
+void ludwieg_writer_init(ludwieg_writer *w, uint8_t *buffer, size_t capacity);
+
+ludwieg_error ludwieg_read_size(ludwieg_reader *r, uint64_t *out);
+ludwieg_error ludwieg_write_size(ludwieg_writer *w, uint64_t value);
+
+/* Readers and writers below take pointers to the value types named after
+ * them, so they can also be used as array elements. */
+ludwieg_error ludwieg_read_uint8(ludwieg_reader *r, void *out);
+ludwieg_error ludwieg_read_uint32(ludwieg_reader *r, void *out);
+ludwieg_error ludwieg_read_uint64(ludwieg_reader *r, void *out);
+ludwieg_error ludwieg_read_double(ludwieg_reader *r, void *out);
+ludwieg_error ludwieg_read_string(ludwieg_reader *r, void *out);
+ludwieg_error ludwieg_read_blob(ludwieg_reader *r, void *out);
+ludwieg_error ludwieg_read_bool(ludwieg_reader *r, void *out);
+ludwieg_error ludwieg_read_uuid(ludwieg_reader *r, void *out);
+ludwieg_error ludwieg_read_dynint(ludwieg_reader *r, void *out);
+ludwieg_error ludwieg_read_any(ludwieg_reader *r, void *out);
+ludwieg_error ludwieg_read_array(ludwieg_reader *r, bool *present, size_t *count, void *items, size_t stride, size_t capacity, ludwieg_read_fn element);
+ludwieg_error ludwieg_read_struct(ludwieg_reader *r, bool *present, void *out, ludwieg_read_fn fields);
+
+ludwieg_error ludwieg_write_uint8(ludwieg_writer *w, const void *in);
+ludwieg_error ludwieg_write_uint32(ludwieg_writer *w, const void *in);
+ludwieg_error ludwieg_write_uint64(ludwieg_writer *w, const void *in);
+ludwieg_error ludwieg_write_double(ludwieg_writer *w, const void *in);
+ludwieg_error ludwieg_write_string(ludwieg_writer *w, const void *in);
+ludwieg_error ludwieg_write_blob(ludwieg_writer *w, const void *in);
+ludwieg_error ludwieg_write_bool(ludwieg_writer *w, const void *in);
+ludwieg_error ludwieg_write_uuid(ludwieg_writer *w, const void *in);
+ludwieg_error ludwieg_write_dynint(ludwieg_writer *w, const void *in);
+ludwieg_error ludwieg_write_any(ludwieg_writer *w, const void *in);
+ludwieg_error ludwieg_write_array(ludwieg_writer *w, bool present, size_t count, const void *items, size_t stride, size_t capacity, ludwieg_write_fn element);
+ludwieg_error ludwieg_write_struct(ludwieg_writer *w, bool present, const void *in, ludwieg_write_fn fields);
+
+/* Arrays are held as structures with present, count and items members */
+#define LUDWIEG_READ_ARRAY(r, field, element) \
+    ludwieg_read_array((r), &(field).present, &(field).count, (field).items, \
+        sizeof((field).items[0]), sizeof((field).items) / sizeof((field).items[0]), (element))
+#define LUDWIEG_WRITE_ARRAY(w, field, element) \
+    ludwieg_write_array((w), (field).present, (field).count, (field).items, \
+        sizeof((field).items[0]), sizeof((field).items) / sizeof((field).items[0]), (element))
+
+/* ludwieg_read_header validates the header of the message at the beginning
+ * of data, initialising payload to read its contents. used receives the
+ * length of the whole message. */
+ludwieg_error ludwieg_read_header(const uint8_t *data, size_t length, uint8_t *message_id, uint8_t *package_id, ludwieg_reader *payload, size_t *used);
+ludwieg_error ludwieg_write_header(ludwieg_writer *w, uint8_t message_id, uint8_t package_id, uint64_t payload_length);
+
+#endif
+`
+
+const cRuntimeSource = `/* WARNING: Automatically generated by ludco. DO NOT EDIT. */
+
+#include <string.h>
+
+#include "ludwieg.h"
+
+#define LUDWIEG_FLAG_EMPTY 0x80
+
+const uint8_t ludwieg_magic[LUDWIEG_MAGIC_LENGTH] = { {{.magic}} };
+
+const char *ludwieg_error_string(ludwieg_error err)
+{
+    switch (err) {
+    case LUDWIEG_OK: return "success";
+    case LUDWIEG_ERR_SHORT_BUFFER: return "buffer does not contain a complete message";
+    case LUDWIEG_ERR_INVALID_MAGIC: return "invalid magic";
+    case LUDWIEG_ERR_UNSUPPORTED_VERSION: return "unsupported protocol version";
+    case LUDWIEG_ERR_UNKNOWN_PACKAGE: return "unknown package";
+    case LUDWIEG_ERR_INVALID_SIZE: return "invalid size width";
+    case LUDWIEG_ERR_UNEXPECTED_TYPE: return "unexpected type";
+    case LUDWIEG_ERR_BOUNDARIES: return "value exceeds message boundaries";
+    case LUDWIEG_ERR_CAPACITY: return "value exceeds field capacity";
+    case LUDWIEG_ERR_BUFFER_FULL: return "output buffer is full";
+    }
+    return "unknown error";
+}
+
+void ludwieg_reader_init(ludwieg_reader *r, const uint8_t *data, size_t length)
+{
+    r->data = data;
+    r->position = 0;
+    r->limit = length;
+}
+
+void ludwieg_writer_init(ludwieg_writer *w, uint8_t *buffer, size_t capacity)
+{
+    w->data = buffer;
+    w->length = 0;
+    w->capacity = capacity;
+}
+
+static uint64_t little_endian(const uint8_t *p, size_t n)
+{
+    uint64_t v = 0;
+    size_t i;
+    for (i = n; i > 0; i--) {
+        v = (v << 8) | p[i - 1];
+    }
+    return v;
+}
+
+static ludwieg_error take(ludwieg_reader *r, uint64_t n, const uint8_t **out)
+{
+    if (n > r->limit - r->position) {
+        return LUDWIEG_ERR_BOUNDARIES;
+    }
+    *out = r->data + r->position;
+    r->position += (size_t)n;
+    return LUDWIEG_OK;
+}
+
+ludwieg_error ludwieg_read_size(ludwieg_reader *r, uint64_t *out)
+{
+    const uint8_t *p;
+    size_t width;
+    LUDWIEG_TRY(take(r, 1, &p));
+    width = p[0];
+    if (width != 1 && width != 2 && width != 4 && width != 8) {
+        return LUDWIEG_ERR_INVALID_SIZE;
+    }
+    LUDWIEG_TRY(take(r, width, &p));
+    *out = little_endian(p, width);
+    return LUDWIEG_OK;
+}
+
+/* begin reads the type byte of a value, clearing present when the value is
+ * empty. Missing trailing values are also considered empty. */
+static ludwieg_error begin(ludwieg_reader *r, ludwieg_type t, bool *present)
+{
+    const uint8_t *p;
+    *present = false;
+    if (r->position >= r->limit) {
+        return LUDWIEG_OK;
+    }
+    LUDWIEG_TRY(take(r, 1, &p));
+    if ((p[0] & ~LUDWIEG_FLAG_EMPTY) != t) {
+        return LUDWIEG_ERR_UNEXPECTED_TYPE;
+    }
+    *present = (p[0] & LUDWIEG_FLAG_EMPTY) == 0;
+    return LUDWIEG_OK;
+}
+
+/* body initialises out to read the next value, advancing r past it */
+static ludwieg_error body(ludwieg_reader *r, ludwieg_reader *out)
+{
+    uint64_t size;
+    const uint8_t *p;
+    LUDWIEG_TRY(ludwieg_read_size(r, &size));
+    LUDWIEG_TRY(take(r, size, &p));
+    out->data = r->data;
+    out->position = (size_t)(p - r->data);
+    out->limit = r->position;
+    return LUDWIEG_OK;
+}
+
+static ludwieg_error read_fixed(ludwieg_reader *r, ludwieg_type t, bool *present, size_t n, uint64_t *out)
+{
+    const uint8_t *p;
+    LUDWIEG_TRY(begin(r, t, present));
+    if (!*present) {
+        return LUDWIEG_OK;
+    }
+    LUDWIEG_TRY(take(r, n, &p));
+    *out = little_endian(p, n);
+    return LUDWIEG_OK;
+}
+
+/* read_bytes reads strings and blobs into buffer, which must be able to hold
+ * an additional NUL terminator */
+static ludwieg_error read_bytes(ludwieg_reader *r, ludwieg_type t, bool *present, uint8_t *buffer, size_t capacity, size_t *length)
+{
+    uint64_t size;
+    const uint8_t *p;
+    *length = 0;
+    LUDWIEG_TRY(begin(r, t, present));
+    if (!*present) {
+        return LUDWIEG_OK;
+    }
+    LUDWIEG_TRY(ludwieg_read_size(r, &size));
+    if (size > capacity) {
+        return LUDWIEG_ERR_CAPACITY;
+    }
+    LUDWIEG_TRY(take(r, size, &p));
+    memcpy(buffer, p, (size_t)size);
+    buffer[size] = 0;
+    *length = (size_t)size;
+    return LUDWIEG_OK;
+}
+
+ludwieg_error ludwieg_read_uint8(ludwieg_reader *r, void *out)
+{
+    ludwieg_uint8 *v = out;
+    uint64_t value = 0;
+    LUDWIEG_TRY(read_fixed(r, LUDWIEG_TYPE_UINT8, &v->present, 1, &value));
+    v->value = (uint8_t)value;
+    return LUDWIEG_OK;
+}
+
+ludwieg_error ludwieg_read_uint32(ludwieg_reader *r, void *out)
+{
+    ludwieg_uint32 *v = out;
+    uint64_t value = 0;
+    LUDWIEG_TRY(read_fixed(r, LUDWIEG_TYPE_UINT32, &v->present, 4, &value));
+    v->value = (uint32_t)value;
+    return LUDWIEG_OK;
+}
+
+ludwieg_error ludwieg_read_uint64(ludwieg_reader *r, void *out)
+{
+    ludwieg_uint64 *v = out;
+    v->value = 0;
+    return read_fixed(r, LUDWIEG_TYPE_UINT64, &v->present, 8, &v->value);
+}
+
+ludwieg_error ludwieg_read_double(ludwieg_reader *r, void *out)
+{
+    ludwieg_double *v = out;
+    uint64_t bits = 0;
+    LUDWIEG_TRY(read_fixed(r, LUDWIEG_TYPE_DOUBLE, &v->present, 8, &bits));
+    memcpy(&v->value, &bits, sizeof(v->value));
+    return LUDWIEG_OK;
+}
+
+ludwieg_error ludwieg_read_string(ludwieg_reader *r, void *out)
+{
+    ludwieg_string *v = out;
+    return read_bytes(r, LUDWIEG_TYPE_STRING, &v->present, (uint8_t *)v->value, LUDWIEG_MAX_STRING_LENGTH, &v->length);
+}
+
+ludwieg_error ludwieg_read_blob(ludwieg_reader *r, void *out)
+{
+    ludwieg_blob *v = out;
+    uint8_t buffer[LUDWIEG_MAX_BLOB_LENGTH + 1];
+    LUDWIEG_TRY(read_bytes(r, LUDWIEG_TYPE_BLOB, &v->present, buffer, LUDWIEG_MAX_BLOB_LENGTH, &v->length));
+    memcpy(v->value, buffer, v->length);
+    return LUDWIEG_OK;
+}
+
+ludwieg_error ludwieg_read_bool(ludwieg_reader *r, void *out)
+{
+    ludwieg_bool *v = out;
+    uint64_t value = 0;
+    LUDWIEG_TRY(read_fixed(r, LUDWIEG_TYPE_BOOL, &v->present, 1, &value));
+    v->value = value != 0;
+    return LUDWIEG_OK;
+}
+
+ludwieg_error ludwieg_read_uuid(ludwieg_reader *r, void *out)
+{
+    ludwieg_uuid *v = out;
+    const uint8_t *p;
+    LUDWIEG_TRY(begin(r, LUDWIEG_TYPE_UUID, &v->present));
+    if (!v->present) {
+        return LUDWIEG_OK;
+    }
+    LUDWIEG_TRY(take(r, 16, &p));
+    memcpy(v->value, p, 16);
+    return LUDWIEG_OK;
+}
+
+ludwieg_error ludwieg_read_dynint(ludwieg_reader *r, void *out)
+{
+    ludwieg_dynint *v = out;
+    v->value = 0;
+    LUDWIEG_TRY(begin(r, LUDWIEG_TYPE_DYNINT, &v->present));
+    if (!v->present) {
+        return LUDWIEG_OK;
+    }
+    return ludwieg_read_size(r, &v->value);
+}
+
+/* read_any_array reads the body of an array held by an any field into v,
+ * validating its items */
+static ludwieg_error read_any_array(ludwieg_reader *r, ludwieg_any *v)
+{
+    ludwieg_reader b;
+    ludwieg_any item;
+    uint64_t n, i;
+    LUDWIEG_TRY(begin(r, LUDWIEG_TYPE_ARRAY, &v->present));
+    if (!v->present) {
+        return LUDWIEG_OK;
+    }
+    LUDWIEG_TRY(body(r, &b));
+    if (b.limit - b.position > LUDWIEG_MAX_ANY_LENGTH) {
+        return LUDWIEG_ERR_CAPACITY;
+    }
+    v->length = b.limit - b.position;
+    memcpy(v->data, b.data + b.position, v->length);
+
+    LUDWIEG_TRY(ludwieg_read_size(&b, &n));
+    for (i = 0; i < n; i++) {
+        if (b.position >= b.limit) {
+            return LUDWIEG_ERR_BOUNDARIES;
+        }
+        LUDWIEG_TRY(ludwieg_read_any(&b, &item));
+    }
+    return LUDWIEG_OK;
+}
+
+ludwieg_error ludwieg_read_any(ludwieg_reader *r, void *out)
+{
+    ludwieg_any *v = out;
+    ludwieg_type t;
+    uint64_t value = 0;
+    ludwieg_uuid uuid;
+    memset(v, 0, sizeof(*v));
+    LUDWIEG_TRY(begin(r, LUDWIEG_TYPE_ANY, &v->present));
+    if (!v->present) {
+        return LUDWIEG_OK;
+    }
+    if (r->position >= r->limit) {
+        return LUDWIEG_ERR_BOUNDARIES;
+    }
+    t = (ludwieg_type)(r->data[r->position] & ~LUDWIEG_FLAG_EMPTY);
+    v->type = t;
+    switch (t) {
+    case LUDWIEG_TYPE_UINT8:
+        LUDWIEG_TRY(read_fixed(r, t, &v->present, 1, &value));
+        v->value.u8 = (uint8_t)value;
+        break;
+    case LUDWIEG_TYPE_UINT32:
+        LUDWIEG_TRY(read_fixed(r, t, &v->present, 4, &value));
+        v->value.u32 = (uint32_t)value;
+        break;
+    case LUDWIEG_TYPE_UINT64:
+        LUDWIEG_TRY(read_fixed(r, t, &v->present, 8, &v->value.u64));
+        break;
+    case LUDWIEG_TYPE_DOUBLE:
+        LUDWIEG_TRY(read_fixed(r, t, &v->present, 8, &value));
+        memcpy(&v->value.f64, &value, sizeof(v->value.f64));
+        break;
+    case LUDWIEG_TYPE_STRING:
+    case LUDWIEG_TYPE_BLOB:
+        LUDWIEG_TRY(read_bytes(r, t, &v->present, v->data, LUDWIEG_MAX_ANY_LENGTH, &v->length));
+        break;
+    case LUDWIEG_TYPE_BOOL:
+        LUDWIEG_TRY(read_fixed(r, t, &v->present, 1, &value));
+        v->value.boolean = value != 0;
+        break;
+    case LUDWIEG_TYPE_UUID:
+        LUDWIEG_TRY(ludwieg_read_uuid(r, &uuid));
+        v->present = uuid.present;
+        memcpy(v->value.uuid, uuid.value, 16);
+        break;
+    case LUDWIEG_TYPE_DYNINT:
+        LUDWIEG_TRY(begin(r, t, &v->present));
+        if (v->present) {
+            LUDWIEG_TRY(ludwieg_read_size(r, &v->value.u64));
+        }
+        break;
+    case LUDWIEG_TYPE_ARRAY:
+        LUDWIEG_TRY(read_any_array(r, v));
+        break;
+    default:
+        return LUDWIEG_ERR_UNEXPECTED_TYPE;
+    }
+    return LUDWIEG_OK;
+}
+
+ludwieg_error ludwieg_read_array(ludwieg_reader *r, bool *present, size_t *count, void *items, size_t stride, size_t capacity, ludwieg_read_fn element)
+{
+    ludwieg_reader b;
+    uint64_t n, i;
+    *count = 0;
+    LUDWIEG_TRY(begin(r, LUDWIEG_TYPE_ARRAY, present));
+    if (!*present) {
+        return LUDWIEG_OK;
+    }
+    LUDWIEG_TRY(body(r, &b));
+    LUDWIEG_TRY(ludwieg_read_size(&b, &n));
+    if (n > capacity) {
+        return LUDWIEG_ERR_CAPACITY;
+    }
+    for (i = 0; i < n; i++) {
+        if (b.position >= b.limit) {
+            return LUDWIEG_ERR_BOUNDARIES;
+        }
+        LUDWIEG_TRY(element(&b, (uint8_t *)items + i * stride));
+    }
+    *count = (size_t)n;
+    return LUDWIEG_OK;
+}
+
+ludwieg_error ludwieg_read_struct(ludwieg_reader *r, bool *present, void *out, ludwieg_read_fn fields)
+{
+    ludwieg_reader b;
+    LUDWIEG_TRY(begin(r, LUDWIEG_TYPE_STRUCT, present));
+    if (!*present) {
+        return LUDWIEG_OK;
+    }
+    LUDWIEG_TRY(body(r, &b));
+    return fields(&b, out);
+}
+
+static ludwieg_error put(ludwieg_writer *w, const void *data, size_t n)
+{
+    if (w->data != NULL) {
+        if (n > w->capacity - w->length) {
+            return LUDWIEG_ERR_BUFFER_FULL;
+        }
+        memcpy(w->data + w->length, data, n);
+    }
+    w->length += n;
+    return LUDWIEG_OK;
+}
+
+static ludwieg_error put_byte(ludwieg_writer *w, uint8_t b)
+{
+    return put(w, &b, 1);
+}
+
+static ludwieg_error put_le(ludwieg_writer *w, uint64_t value, size_t n)
+{
+    uint8_t b[8];
+    size_t i;
+    for (i = 0; i < n; i++) {
+        b[i] = (uint8_t)(value >> (8 * i));
+    }
+    return put(w, b, n);
+}
+
+static ludwieg_error empty(ludwieg_writer *w, ludwieg_type t)
+{
+    return put_byte(w, (uint8_t)(t | LUDWIEG_FLAG_EMPTY));
+}
+
+ludwieg_error ludwieg_write_size(ludwieg_writer *w, uint64_t value)
+{
+    size_t width = 8;
+    if (value <= 0xFF) {
+        width = 1;
+    } else if (value <= 0xFFFF) {
+        width = 2;
+    } else if (value <= 0xFFFFFFFF) {
+        width = 4;
+    }
+    LUDWIEG_TRY(put_byte(w, (uint8_t)width));
+    return put_le(w, value, width);
+}
+
+static ludwieg_error write_fixed(ludwieg_writer *w, ludwieg_type t, bool present, uint64_t value, size_t n)
+{
+    if (!present) {
+        return empty(w, t);
+    }
+    LUDWIEG_TRY(put_byte(w, t));
+    return put_le(w, value, n);
+}
+
+static ludwieg_error write_bytes(ludwieg_writer *w, ludwieg_type t, bool present, const void *data, size_t length, size_t capacity)
+{
+    if (!present) {
+        return empty(w, t);
+    }
+    if (length > capacity) {
+        return LUDWIEG_ERR_CAPACITY;
+    }
+    LUDWIEG_TRY(put_byte(w, t));
+    LUDWIEG_TRY(ludwieg_write_size(w, length));
+    return put(w, data, length);
+}
+
+static uint64_t double_bits(double value)
+{
+    uint64_t bits;
+    memcpy(&bits, &value, sizeof(bits));
+    return bits;
+}
+
+ludwieg_error ludwieg_write_uint8(ludwieg_writer *w, const void *in)
+{
+    const ludwieg_uint8 *v = in;
+    return write_fixed(w, LUDWIEG_TYPE_UINT8, v->present, v->value, 1);
+}
+
+ludwieg_error ludwieg_write_uint32(ludwieg_writer *w, const void *in)
+{
+    const ludwieg_uint32 *v = in;
+    return write_fixed(w, LUDWIEG_TYPE_UINT32, v->present, v->value, 4);
+}
+
+ludwieg_error ludwieg_write_uint64(ludwieg_writer *w, const void *in)
+{
+    const ludwieg_uint64 *v = in;
+    return write_fixed(w, LUDWIEG_TYPE_UINT64, v->present, v->value, 8);
+}
+
+ludwieg_error ludwieg_write_double(ludwieg_writer *w, const void *in)
+{
+    const ludwieg_double *v = in;
+    return write_fixed(w, LUDWIEG_TYPE_DOUBLE, v->present, double_bits(v->value), 8);
+}
+
+ludwieg_error ludwieg_write_string(ludwieg_writer *w, const void *in)
+{
+    const ludwieg_string *v = in;
+    return write_bytes(w, LUDWIEG_TYPE_STRING, v->present, v->value, v->length, LUDWIEG_MAX_STRING_LENGTH);
+}
+
+ludwieg_error ludwieg_write_blob(ludwieg_writer *w, const void *in)
+{
+    const ludwieg_blob *v = in;
+    return write_bytes(w, LUDWIEG_TYPE_BLOB, v->present, v->value, v->length, LUDWIEG_MAX_BLOB_LENGTH);
+}
+
+ludwieg_error ludwieg_write_bool(ludwieg_writer *w, const void *in)
+{
+    const ludwieg_bool *v = in;
+    return write_fixed(w, LUDWIEG_TYPE_BOOL, v->present, v->value ? 1 : 0, 1);
+}
+
+ludwieg_error ludwieg_write_uuid(ludwieg_writer *w, const void *in)
+{
+    const ludwieg_uuid *v = in;
+    if (!v->present) {
+        return empty(w, LUDWIEG_TYPE_UUID);
+    }
+    LUDWIEG_TRY(put_byte(w, LUDWIEG_TYPE_UUID));
+    return put(w, v->value, 16);
+}
+
+ludwieg_error ludwieg_write_dynint(ludwieg_writer *w, const void *in)
+{
+    const ludwieg_dynint *v = in;
+    if (!v->present) {
+        return empty(w, LUDWIEG_TYPE_DYNINT);
+    }
+    LUDWIEG_TRY(put_byte(w, LUDWIEG_TYPE_DYNINT));
+    return ludwieg_write_size(w, v->value);
+}
+
+ludwieg_error ludwieg_write_any(ludwieg_writer *w, const void *in)
+{
+    const ludwieg_any *v = in;
+    ludwieg_uuid uuid;
+    ludwieg_dynint dynint;
+    if (!v->present) {
+        return empty(w, LUDWIEG_TYPE_ANY);
+    }
+    LUDWIEG_TRY(put_byte(w, LUDWIEG_TYPE_ANY));
+    switch (v->type) {
+    case LUDWIEG_TYPE_UINT8:
+        return write_fixed(w, v->type, true, v->value.u8, 1);
+    case LUDWIEG_TYPE_UINT32:
+        return write_fixed(w, v->type, true, v->value.u32, 4);
+    case LUDWIEG_TYPE_UINT64:
+        return write_fixed(w, v->type, true, v->value.u64, 8);
+    case LUDWIEG_TYPE_DOUBLE:
+        return write_fixed(w, v->type, true, double_bits(v->value.f64), 8);
+    case LUDWIEG_TYPE_STRING:
+    case LUDWIEG_TYPE_BLOB:
+    case LUDWIEG_TYPE_ARRAY:
+        return write_bytes(w, v->type, true, v->data, v->length, LUDWIEG_MAX_ANY_LENGTH);
+    case LUDWIEG_TYPE_BOOL:
+        return write_fixed(w, v->type, true, v->value.boolean ? 1 : 0, 1);
+    case LUDWIEG_TYPE_UUID:
+        uuid.present = true;
+        memcpy(uuid.value, v->value.uuid, 16);
+        return ludwieg_write_uuid(w, &uuid);
+    case LUDWIEG_TYPE_DYNINT:
+        dynint.present = true;
+        dynint.value = v->value.u64;
+        return ludwieg_write_dynint(w, &dynint);
+    default:
+        return LUDWIEG_ERR_UNEXPECTED_TYPE;
+    }
+}
+
+ludwieg_error ludwieg_write_array(ludwieg_writer *w, bool present, size_t count, const void *items, size_t stride, size_t capacity, ludwieg_write_fn element)
+{
+    ludwieg_writer counter;
+    size_t i;
+    if (!present) {
+        return empty(w, LUDWIEG_TYPE_ARRAY);
+    }
+    if (count > capacity) {
+        return LUDWIEG_ERR_CAPACITY;
+    }
+
+    /* Measure the body before writing it, as it is preceded by its size */
+    ludwieg_writer_init(&counter, NULL, 0);
+    LUDWIEG_TRY(ludwieg_write_size(&counter, count));
+    for (i = 0; i < count; i++) {
+        LUDWIEG_TRY(element(&counter, (const uint8_t *)items + i * stride));
+    }
+
+    LUDWIEG_TRY(put_byte(w, LUDWIEG_TYPE_ARRAY));
+    LUDWIEG_TRY(ludwieg_write_size(w, counter.length));
+    LUDWIEG_TRY(ludwieg_write_size(w, count));
+    for (i = 0; i < count; i++) {
+        LUDWIEG_TRY(element(w, (const uint8_t *)items + i * stride));
+    }
+    return LUDWIEG_OK;
+}
+
+ludwieg_error ludwieg_write_struct(ludwieg_writer *w, bool present, const void *in, ludwieg_write_fn fields)
+{
+    ludwieg_writer counter;
+    if (!present) {
+        return empty(w, LUDWIEG_TYPE_STRUCT);
+    }
+    ludwieg_writer_init(&counter, NULL, 0);
+    LUDWIEG_TRY(fields(&counter, in));
+
+    LUDWIEG_TRY(put_byte(w, LUDWIEG_TYPE_STRUCT));
+    LUDWIEG_TRY(ludwieg_write_size(w, counter.length));
+    return fields(w, in);
+}
+
+ludwieg_error ludwieg_read_header(const uint8_t *data, size_t length, uint8_t *message_id, uint8_t *package_id, ludwieg_reader *payload, size_t *used)
+{
+    const size_t header_size = LUDWIEG_MAGIC_LENGTH + 3;
+    size_t width, start;
+    uint64_t size;
+
+    if (length <= header_size) {
+        return LUDWIEG_ERR_SHORT_BUFFER;
+    }
+    if (memcmp(data, ludwieg_magic, LUDWIEG_MAGIC_LENGTH) != 0) {
+        return LUDWIEG_ERR_INVALID_MAGIC;
+    }
+    if (data[LUDWIEG_MAGIC_LENGTH] != LUDWIEG_PROTOCOL_VERSION) {
+        return LUDWIEG_ERR_UNSUPPORTED_VERSION;
+    }
+
+    width = data[header_size];
+    if (width != 1 && width != 2 && width != 4 && width != 8) {
+        return LUDWIEG_ERR_INVALID_SIZE;
+    }
+    start = header_size + 1 + width;
+    if (length < start) {
+        return LUDWIEG_ERR_SHORT_BUFFER;
+    }
+    size = little_endian(data + header_size + 1, width);
+    if (size > length - start) {
+        return LUDWIEG_ERR_SHORT_BUFFER;
+    }
+
+    *message_id = data[LUDWIEG_MAGIC_LENGTH + 1];
+    *package_id = data[LUDWIEG_MAGIC_LENGTH + 2];
+    payload->data = data;
+    payload->position = start;
+    payload->limit = start + (size_t)size;
+    *used = payload->limit;
+    return LUDWIEG_OK;
+}
+
+ludwieg_error ludwieg_write_header(ludwieg_writer *w, uint8_t message_id, uint8_t package_id, uint64_t payload_length)
+{
+    uint8_t ids[3];
+    ids[0] = LUDWIEG_PROTOCOL_VERSION;
+    ids[1] = message_id;
+    ids[2] = package_id;
+    LUDWIEG_TRY(put(w, ludwieg_magic, LUDWIEG_MAGIC_LENGTH));
+    LUDWIEG_TRY(put(w, ids, sizeof(ids)));
+    return ludwieg_write_size(w, payload_length);
+}
+`
+
+const cPackageHeader = `/* WARNING: Automatically generated by ludco. DO NOT EDIT. */
+
+#ifndef {{.guard}}
+#define {{.guard}}
+
+#include "ludwieg.h"
+
+#define {{.idMacro}} {{.id}}
+{{.types}}
+/* {{.name}}_decode decodes a payload into out. Absent fields are cleared. */
+ludwieg_error {{.name}}_decode(ludwieg_reader *r, {{.name}}_t *out);
+ludwieg_error {{.name}}_encode(ludwieg_writer *w, const {{.name}}_t *in);
+
+#endif
+`
+
+const cStructType = `
+typedef struct {
+{{.fields}}} {{.name}}_t;
+`
+
+const cArrayField = `struct {
+    bool present;
+    size_t count;
+    {{.type}} items[{{.size}}];
+} {{.name}};`
+
+const cPackageSource = `/* WARNING: Automatically generated by ludco. DO NOT EDIT. */
+
+#include <string.h>
+
+#include "{{.file}}.h"
+{{.prototypes}}{{.functions}}
+ludwieg_error {{.name}}_decode(ludwieg_reader *r, {{.name}}_t *out)
+{
+    memset(out, 0, sizeof(*out));
+    return {{.name}}_read_fields(r, out);
+}
+
+ludwieg_error {{.name}}_encode(ludwieg_writer *w, const {{.name}}_t *in)
+{
+    return {{.name}}_write_fields(w, in);
+}
+`
+
+const cFieldFunctions = `
+static ludwieg_error {{.name}}_read_fields(ludwieg_reader *r, void *out)
+{
+{{.read}}    return LUDWIEG_OK;
+}
+
+static ludwieg_error {{.name}}_write_fields(ludwieg_writer *w, const void *in)
+{
+{{.write}}    return LUDWIEG_OK;
+}
+`
+
+const cStructFunctions = `
+static ludwieg_error {{.name}}_read(ludwieg_reader *r, void *out)
+{
+    {{.name}}_t *v = out;
+    memset(v, 0, sizeof(*v));
+    return ludwieg_read_struct(r, &v->present, v, {{.name}}_read_fields);
+}
+
+static ludwieg_error {{.name}}_write(ludwieg_writer *w, const void *in)
+{
+    const {{.name}}_t *v = in;
+    return ludwieg_write_struct(w, v->present, v, {{.name}}_write_fields);
+}
+`
+
+const cMessagesHeader = `/* WARNING: Automatically generated by ludco. DO NOT EDIT. */
+
+#ifndef {{.guard}}
+#define {{.guard}}
+
+#include "ludwieg.h"
+{{.includes}}
+
+typedef struct {
+    uint8_t message_id;
+    uint8_t package_id;
+    union {
+{{.members}}    } package;
+} {{.prefix}}_message;
+
+/* {{.prefix}}_decode_message decodes the message at the beginning of data,
+ * storing the length of the whole message on used. LUDWIEG_ERR_SHORT_BUFFER
+ * is returned when data does not contain a complete message yet. */
+ludwieg_error {{.prefix}}_decode_message({{.prefix}}_message *out, const uint8_t *data, size_t length, size_t *used);
+
+/* {{.prefix}}_encode_message encodes the package identified by package_id */
+ludwieg_error {{.prefix}}_encode_message(ludwieg_writer *w, const {{.prefix}}_message *in);
+
+#endif
+`
+
+const cMessagesSource = `/* WARNING: Automatically generated by ludco. DO NOT EDIT. */
+
+#include "{{.file}}.h"
+
+static ludwieg_error encode_payload(ludwieg_writer *w, const {{.prefix}}_message *in)
+{
+    switch (in->package_id) {
+{{.encoders}}    default:
+        return LUDWIEG_ERR_UNKNOWN_PACKAGE;
+    }
+}
+
+ludwieg_error {{.prefix}}_decode_message({{.prefix}}_message *out, const uint8_t *data, size_t length, size_t *used)
+{
+    ludwieg_reader payload;
+    LUDWIEG_TRY(ludwieg_read_header(data, length, &out->message_id, &out->package_id, &payload, used));
+    switch (out->package_id) {
+{{.decoders}}    default:
+        return LUDWIEG_ERR_UNKNOWN_PACKAGE;
+    }
+}
+
+ludwieg_error {{.prefix}}_encode_message(ludwieg_writer *w, const {{.prefix}}_message *in)
+{
+    ludwieg_writer counter;
+    ludwieg_writer_init(&counter, NULL, 0);
+    LUDWIEG_TRY(encode_payload(&counter, in));
+    LUDWIEG_TRY(ludwieg_write_header(w, in->message_id, in->package_id, counter.length));
+    return encode_payload(w, in);
+}
+`
+
+const cIntegrationSteps = `You just generated C99 sources, which do not perform heap allocation. Compile
+every .c file of the output folder along with your firmware, and adjust
+capacities of dynamic values on {{.config}}, or through compiler flags:
+
+     {{.cFlags}}
+
+Messages are decoded and encoded through {{.messages}}:
+
+     {{.cUsage}}
+`
@@ -0,0 +1,101 @@
+package langs
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// cRoundTrip decodes every vector using generated sources, and checks that
+// encoding the decoded message again yields the very same bytes
+const cRoundTrip = `#include <stdio.h>
+#include <string.h>
+
+#include "ludwieg_messages.h"
+
+static const char *vectors[][2] = {
+%VECTORS%};
+
+int main(void)
+{
+    size_t i, j, length, used;
+    int failures = 0;
+    for (i = 0; i < sizeof(vectors) / sizeof(vectors[0]); i++) {
+        uint8_t data[4096], encoded[4096];
+        unsigned int b;
+        ludwieg_message message;
+        ludwieg_writer w;
+        ludwieg_error err;
+
+        length = strlen(vectors[i][1]) / 2;
+        for (j = 0; j < length; j++) {
+            sscanf(vectors[i][1] + j * 2, "%2x", &b);
+            data[j] = (uint8_t)b;
+        }
+        err = ludwieg_decode_message(&message, data, length, &used);
+        if (err == LUDWIEG_OK) {
+            ludwieg_writer_init(&w, encoded, sizeof(encoded));
+            err = ludwieg_encode_message(&w, &message);
+        }
+        if (err != LUDWIEG_OK) {
+            printf("%s: %s\n", vectors[i][0], ludwieg_error_string(err));
+            failures++;
+        } else if (used != length || w.length != length || memcmp(encoded, data, length) != 0) {
+            printf("%s: encoded message differs\n", vectors[i][0]);
+            failures++;
+        }
+    }
+    return failures > 0;
+}
+`
+
+func TestCRoundTrip(t *testing.T) {
+	cc := testTool(t, "cc")
+	packages := testPackages(t, vectorSource)
+	dir := testCompile(t, C{}, "", "", packages)
+	defer os.RemoveAll(dir)
+
+	sources, _ := filepath.Glob(filepath.Join(dir, "*.c"))
+	// Vectors exercise long strings and blobs, exceeding default capacities
+	build := []string{cc, "-std=c99", "-Wall", "-Werror", "-o", "check", "check.c",
+		"-DLUDWIEG_MAX_STRING_LENGTH=1024", "-DLUDWIEG_MAX_BLOB_LENGTH=1024", "-DLUDWIEG_MAX_ANY_LENGTH=1024"}
+	build = append(build, sources...)
+	testRoundTrip(t, dir, &(*packages)[0], "check.c", cRoundTrip, func(v testVector) string {
+		return fmt.Sprintf("    { %q, %q },\n", v.Name, v.Hex)
+	}, build, []string{filepath.Join(dir, "check")})
+}
+
+func TestCRejectsRecursiveStructs(t *testing.T) {
+	packages := testPackages(t, recursiveSource)
+	testExits(t, func() {
+		dir := testCompile(t, C{}, "", "", packages)
+		os.RemoveAll(dir)
+	})
+}
+
+func TestCRejectsReservedNames(t *testing.T) {
+	for _, tc := range []struct{ name, prefix string }{
+		{"ludwieg", ""},
+		{"ludwieg_config", ""},
+		{"ludwieg_messages", ""},
+		{"messages", "game"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			packages := testPackages(t, "package "+tc.name+" {\n    id 0x01\n    string text\n}\n")
+			testExits(t, func() {
+				dir := testCompile(t, C{}, "", tc.prefix, packages)
+				os.RemoveAll(dir)
+			})
+		})
+	}
+}
+
+func TestCPrefixedNames(t *testing.T) {
+	dir := testCompile(t, C{}, "", "game", testPackages(t, "package ludwieg {\n    id 0x01\n    string text\n}\n"))
+	defer os.RemoveAll(dir)
+
+	testContains(t, "game_ludwieg.h", testOutput(t, dir, "game_ludwieg.h"), "} game_ludwieg_t;")
+	testContains(t, "game_messages.h", testOutput(t, dir, "game_messages.h"), "game_ludwieg_t ludwieg;")
+	testContains(t, "ludwieg.h", testOutput(t, dir, "ludwieg.h"), "ludwieg_reader_init")
+}
@@ -0,0 +1,403 @@
+package langs
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/alecthomas/chroma/quick"
+	"github.com/logrusorgru/aurora"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/ludwieg/ludco/codec"
+	"github.com/ludwieg/ludco/models"
+)
+
+// cppKeywords lists reserved words that cannot be used as member names,
+// along with members declared by generated classes
+var cppKeywords = map[string]bool{
+	"alignas": true, "alignof": true, "and": true, "and_eq": true, "asm": true,
+	"auto": true, "bitand": true, "bitor": true, "bool": true, "break": true,
+	"case": true, "catch": true, "char": true, "char16_t": true, "char32_t": true,
+	"class": true, "compl": true, "const": true, "const_cast": true,
+	"constexpr": true, "continue": true, "decltype": true, "default": true,
+	"delete": true, "do": true, "double": true, "dynamic_cast": true,
+	"else": true, "enum": true, "explicit": true, "export": true, "extern": true,
+	"false": true, "float": true, "for": true, "friend": true, "goto": true,
+	"if": true, "inline": true, "int": true, "long": true, "mutable": true,
+	"namespace": true, "new": true, "noexcept": true, "not": true,
+	"not_eq": true, "nullptr": true, "operator": true, "or": true, "or_eq": true,
+	"private": true, "protected": true, "public": true, "register": true,
+	"reinterpret_cast": true, "return": true, "short": true, "signed": true,
+	"sizeof": true, "static": true, "static_assert": true, "static_cast": true,
+	"struct": true, "switch": true, "template": true, "this": true,
+	"thread_local": true, "throw": true, "true": true, "try": true,
+	"typedef": true, "typeid": true, "typename": true, "union": true,
+	"unsigned": true, "using": true, "virtual": true, "void": true,
+	"volatile": true, "wchar_t": true, "while": true, "xor": true,
+	"xor_eq": true,
+	"decode": true, "encode": true, "reader": true, "writer": true,
+}
+
+type Cpp struct {
+	namespace string
+	out       string
+
+	// classes maps structures to the name of their generated class
+	classes map[*models.Struct]string
+
+	// references maps structures to structures held by their non-array
+	// fields, which are used to order definitions and to detect recursive
+	// types
+	references map[*models.Struct][]*models.Struct
+}
+
+func (c Cpp) Compile(in, out, pkgName, prefix string, packages *models.PackageList) {
+	log.Infof("Initialising %s compiler", aurora.Blue("C++"))
+	if prefix != "" {
+		log.Warn("Ignoring unnecessary --prefix option.")
+	}
+	if pkgName == "" {
+		// Assume a namespace based on the output folder, like the Java
+		// compiler does for packages.
+		r := regexp.MustCompile("[^a-z0-9_]")
+		pkgName = string(r.ReplaceAll([]byte(strings.ToLower(filepath.Base(out))), []byte{}))
+		if pkgName == "" || (pkgName[0] >= '0' && pkgName[0] <= '9') {
+			pkgName = "ludwieg_" + pkgName
+		}
+		log.Warnf("No namespace was provided. Assumed %s based on output path.", aurora.Magenta(pkgName))
+		log.Warn("Please use the --package argument to define a custom namespace")
+	}
+	c.namespace = strings.Replace(pkgName, ".", "::", -1)
+	c.out = out
+	c.classes = map[*models.Struct]string{}
+	c.references = map[*models.Struct][]*models.Struct{}
+
+	var magic []string
+	for _, b := range codec.Magic {
+		magic = append(magic, fmt.Sprintf("0x%02X", b))
+	}
+	c.output("ludwieg.hpp", processTemplate("cppRuntimeHeader", cppRuntimeHeader, templateData{
+		"magic":       strings.Join(magic, ", "),
+		"magicLength": len(codec.Magic),
+		"version":     fmt.Sprintf("0x%02X", codec.ProtocolVersion),
+	}))
+	c.output("ludwieg.cpp", []byte(cppRuntimeSource))
+
+	for i := range *packages {
+		c.writePackage(&(*packages)[i])
+	}
+	c.writeRegistry(packages)
+
+	log.Info("Succeeded")
+	fmt.Println()
+	fmt.Println(c.integrationInstructions())
+}
+
+func (c Cpp) output(name string, contents []byte) {
+	log.Infof("Writing %s/%s", aurora.Magenta(filepath.Base(c.out)), aurora.Magenta(name))
+	err := ioutil.WriteFile(filepath.Join(c.out, name), contents, 0644)
+	if err != nil {
+		log.Errorf("Operation failed: %s", err)
+		os.Exit(1)
+	}
+}
+
+// cppFile returns the base name of the files of a package. Names taken by
+// the runtime and registry files are suffixed with an underscore.
+func cppFile(name string) string {
+	if name == "ludwieg" || name == "ludwieg_registry" {
+		return name + "_"
+	}
+	return name
+}
+
+func (c Cpp) writePackage(p *models.Package) {
+	name := convertToPascalCase(p.Name)
+	var all []*models.Struct
+	scopes := map[*models.Struct]*models.Scope{}
+	c.registerStructs(name, p.Scope(), p.Structs, &all, scopes)
+
+	// Classes are defined after the ones they hold, and declared beforehand
+	// so arrays and recursive fields may reference any of them
+	var ordered []*models.Struct
+	defined := map[*models.Struct]bool{}
+	for _, s := range all {
+		c.order(s, defined, &ordered)
+	}
+
+	var declarations, classes, methods []string
+	for _, s := range all {
+		declarations = append(declarations, "class "+c.classes[s]+";\n")
+	}
+	for _, s := range ordered {
+		class, method := c.generateClass(c.classes[s], "ludwieg::Codable", nil, s, scopes[s], s.Fields, s.Structs)
+		classes = append(classes, class)
+		methods = append(methods, method)
+	}
+	header := []string{fmt.Sprintf("static constexpr uint8_t LudwiegID = %s;", p.Identifier)}
+	class, method := c.generateClass(name, "ludwieg::Package", header, nil, p.Scope(), p.Fields, p.Structs)
+	classes = append(classes, class)
+	methods = append(methods, method)
+
+	file := cppFile(p.Name)
+	c.output(file+".hpp", processTemplate("cppPackageHeader", cppPackageHeader, templateData{
+		"namespace":    c.namespace,
+		"declarations": strings.Join(declarations, ""),
+		"classes":      strings.Join(classes, ""),
+	}))
+	c.output(file+".cpp", processTemplate("cppPackageSource", cppPackageSource, templateData{
+		"file":      file,
+		"namespace": c.namespace,
+		"methods":   strings.Join(methods, ""),
+	}))
+}
+
+// registerStructs assigns class names to structures, and collects their
+// references, scopes, and the structures themselves
+func (c Cpp) registerStructs(prefix string, scope *models.Scope, sArr []models.Struct, all *[]*models.Struct, scopes map[*models.Struct]*models.Scope) {
+	for i := range sArr {
+		s := &sArr[i]
+		name := prefix + convertToPascalCase(s.Name)
+		c.classes[s] = name
+		*all = append(*all, s)
+
+		inner := &models.Scope{Structs: s.Structs, Parent: scope}
+		scopes[s] = inner
+		for _, f := range s.Fields {
+			if f.Type.Source != models.SourceUser || f.IsArray() {
+				continue
+			}
+			if ref, _, ok := inner.Resolve(f.Type.CustomType); ok {
+				c.references[s] = append(c.references[s], ref)
+			}
+		}
+		c.registerStructs(name, inner, s.Structs, all, scopes)
+	}
+}
+
+// reaches determines whether a value of from may hold a value of to without
+// the indirection provided by arrays
+func (c Cpp) reaches(from, to *models.Struct, visited map[*models.Struct]bool) bool {
+	if from == to {
+		return true
+	}
+	if visited[from] {
+		return false
+	}
+	visited[from] = true
+	for _, ref := range c.references[from] {
+		if c.reaches(ref, to, visited) {
+			return true
+		}
+	}
+	return false
+}
+
+func (c Cpp) order(s *models.Struct, defined map[*models.Struct]bool, ordered *[]*models.Struct) {
+	if defined[s] {
+		return
+	}
+	defined[s] = true
+	for _, ref := range c.references[s] {
+		// Recursive references are held through pointers, and only
+		// require declarations
+		if !c.reaches(ref, s, map[*models.Struct]bool{}) {
+			c.order(ref, defined, ordered)
+		}
+	}
+	*ordered = append(*ordered, s)
+}
+
+// generateClass returns the definition of a class, along with the
+// implementation of its methods. Structures declared by it are exposed as
+// nested aliases.
+func (c Cpp) generateClass(name, base string, header []string, owner *models.Struct, scope *models.Scope, fArr []models.Field, sArr []models.Struct) (string, string) {
+	// Aliases cannot share names with the class itself or its members
+	taken := map[string]bool{name: true}
+	for _, f := range fArr {
+		taken[cppIdentifier(f.Name)] = true
+	}
+	body := header
+	for i := range sArr {
+		alias := convertToPascalCase(sArr[i].Name)
+		if !taken[alias] {
+			body = append(body, fmt.Sprintf("using %s = %s;", alias, c.classes[&sArr[i]]))
+		}
+	}
+	if len(body) > 0 {
+		body = append(body, "")
+	}
+
+	var decode, encode []string
+	for _, f := range fArr {
+		decl, dec, enc := c.generateField(owner, scope, &f)
+		body = append(body, decl)
+		decode = append(decode, "    "+dec+"\n")
+		encode = append(encode, "    "+enc+"\n")
+	}
+	if len(fArr) > 0 {
+		body = append(body, "")
+	}
+
+	if base == "ludwieg::Package" {
+		body = append(body, "uint8_t ludwiegID() const override { return LudwiegID; }")
+	}
+	body = append(body,
+		"void decode(ludwieg::Reader &reader) override;",
+		"void encode(ludwieg::Writer &writer) const override;")
+
+	// Unnamed parameters avoid warnings on empty classes
+	reader, writer := "reader", "writer"
+	if len(fArr) == 0 {
+		reader, writer = "", ""
+	}
+
+	class := string(processTemplate("cppClass", cppClass, templateData{
+		"name": name,
+		"base": base,
+		"body": indent(strings.Join(body, "\n")) + "\n",
+	}))
+	methods := string(processTemplate("cppMethods", cppMethods, templateData{
+		"name":   name,
+		"reader": reader,
+		"writer": writer,
+		"decode": strings.Join(decode, ""),
+		"encode": strings.Join(encode, ""),
+	}))
+	return class, methods
+}
+
+// cppType returns the C++ type holding values of a native type, along with
+// the suffix of the reader and writer methods handling it
+func cppType(t models.NativeType) (string, string) {
+	switch t {
+	case models.TypeUint8, models.TypeByte:
+		return "uint8_t", "Uint8"
+	case models.TypeUint32:
+		return "uint32_t", "Uint32"
+	case models.TypeUint64:
+		return "uint64_t", "Uint64"
+	case models.TypeDouble:
+		return "double", "Double"
+	case models.TypeString:
+		return "std::string", "String"
+	case models.TypeBlob:
+		return "ludwieg::Blob", "Blob"
+	case models.TypeBool:
+		return "bool", "Bool"
+	case models.TypeUUID:
+		return "ludwieg::UUID", "UUID"
+	case models.TypeAny:
+		return "ludwieg::Any", "Any"
+	case models.TypeDynInt:
+		return "uint64_t", "DynInt"
+	}
+	log.Fatalf("BUG: Cannot coerce unknown type %#v to native type.", t)
+	return "", ""
+}
+
+func cppIdentifier(name string) string {
+	if cppKeywords[name] {
+		return name + "_"
+	}
+	return name
+}
+
+// generateField returns the declaration of a field, along with statements
+// decoding and encoding it
+func (c Cpp) generateField(owner *models.Struct, scope *models.Scope, f *models.Field) (string, string, string) {
+	name := cppIdentifier(f.Name)
+
+	var t, read, write string
+	boxed := false
+	if f.Type.Source == models.SourceUser {
+		s, _, ok := scope.Resolve(f.Type.CustomType)
+		if !ok {
+			log.Errorf("BUG: Cannot resolve structure %s referenced by %s", f.Type.CustomType, f.Name)
+			os.Exit(1)
+		}
+		t = c.classes[s]
+		read = "readStruct<" + t + ">()"
+		write = "writeStruct"
+		boxed = !f.IsArray() && owner != nil && c.reaches(s, owner, map[*models.Struct]bool{})
+	} else {
+		var kind string
+		t, kind = cppType(f.Type.NativeType)
+		read = "read" + kind + "()"
+		write = "write" + kind
+	}
+
+	var decl, dec, enc string
+	switch {
+	case f.IsArray():
+		size := "std::nullopt"
+		if f.Size != "*" {
+			size = f.Size
+		}
+		decl = fmt.Sprintf("std::optional<std::vector<std::optional<%s>>> %s;", t, name)
+		dec = fmt.Sprintf("%s = reader.readArray([](ludwieg::Reader &e) { return e.%s; });", name, read)
+		enc = fmt.Sprintf("writer.writeArray(%s, %s, [](ludwieg::Writer &e, const auto &v) { e.%s(v); });", name, size, write)
+	case boxed:
+		// Recursive structures require indirection
+		decl = fmt.Sprintf("std::shared_ptr<%s> %s;", t, name)
+		dec = fmt.Sprintf("%s = reader.readSharedStruct<%s>();", name, t)
+		enc = fmt.Sprintf("writer.%s(%s);", write, name)
+	default:
+		decl = fmt.Sprintf("std::optional<%s> %s;", t, name)
+		dec = fmt.Sprintf("%s = reader.%s;", name, read)
+		enc = fmt.Sprintf("writer.%s(%s);", write, name)
+	}
+
+	if f.HasAttribute(models.AttributeDeprecated) {
+		decl = "[[deprecated]] " + decl
+	}
+	return decl, dec, enc
+}
+
+func (c Cpp) writeRegistry(pList *models.PackageList) {
+	var includes, packages []string
+	for _, p := range *pList {
+		includes = append(includes, fmt.Sprintf("#include \"%s.hpp\"", cppFile(p.Name)))
+		packages = append(packages, fmt.Sprintf("        r.add<%s>();\n", convertToPascalCase(p.Name)))
+	}
+	sort.Strings(includes)
+
+	c.output("ludwieg_registry.hpp", processTemplate("cppRegistryHeader", cppRegistryHeader, templateData{
+		"namespace": c.namespace,
+	}))
+	c.output("ludwieg_registry.cpp", processTemplate("cppRegistrySource", cppRegistrySource, templateData{
+		"namespace": c.namespace,
+		"includes":  strings.Join(includes, "\n"),
+		"packages":  strings.Join(packages, ""),
+	}))
+}
+
+func (c Cpp) formatCode(code string) string {
+	var buf bytes.Buffer
+	err := quick.Highlight(&buf, code, "cpp", "terminal", "pygments")
+	if err != nil {
+		log.Fatalf("BUG: Error processing C++ source: %s", err)
+	}
+
+	return string(buf.Bytes())
+}
+
+func (c Cpp) integrationInstructions() string {
+	usage := strings.Join([]string{
+		"#include \"ludwieg_registry.hpp\"",
+		"",
+		"     auto message = " + c.namespace + "::registry().decode(data.data(), data.size());",
+		"     auto encoded = " + c.namespace + "::registry().encode(message.messageID, *message.package);",
+	}, "\n")
+
+	return string(processTemplate("cppIntegration", cppIntegrationSteps, templateData{
+		"runtime":  aurora.Magenta("ludwieg.cpp"),
+		"cppUsage": c.formatCode(usage),
+	}))
+}
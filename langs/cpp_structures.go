@@ -0,0 +1,837 @@
+package langs
+
+const cppRuntimeHeader = `// WARNING: Automatically generated by ludco. DO NOT EDIT.
+//
+// Runtime used by generated packages to encode and decode values using the
+// Ludwieg wire format.
+
+#pragma once
+
+#include <array>
+#include <cstddef>
+#include <cstdint>
+#include <functional>
+#include <memory>
+#include <optional>
+#include <stdexcept>
+#include <string>
+#include <unordered_map>
+#include <utility>
+#include <variant>
+#include <vector>
+
+namespace ludwieg {
+
+constexpr std::array<uint8_t, {{.magicLength}}> Magic = { {{.magic}} };
+constexpr uint8_t ProtocolVersion = {{.version}};
+
+enum class Type : uint8_t {
+    Uint8 = 0x01,
+    Uint32 = 0x02,
+    Uint64 = 0x03,
+    Double = 0x04,
+    String = 0x05,
+    Blob = 0x06,
+    Bool = 0x07,
+    UUID = 0x08,
+    Any = 0x09,
+    Array = 0x0A,
+    Struct = 0x0B,
+    DynInt = 0x0C,
+};
+
+const char *typeName(Type t);
+
+using Blob = std::vector<uint8_t>;
+using UUID = std::array<uint8_t, 16>;
+
+// DynInt distinguishes dynamic integers from uint64 values held by Any
+struct DynInt {
+    uint64_t value;
+
+    bool operator==(const DynInt &other) const { return value == other.value; }
+    bool operator!=(const DynInt &other) const { return value != other.value; }
+};
+
+struct Any;
+using AnyArray = std::vector<std::optional<Any>>;
+
+// Any holds values of any fields. Its type is determined by the alternative
+// held by value.
+struct Any {
+    std::variant<uint8_t, uint32_t, uint64_t, double, std::string, Blob, bool, UUID, DynInt, AnyArray> value;
+
+    Type type() const;
+};
+
+bool operator==(const Any &a, const Any &b);
+bool operator!=(const Any &a, const Any &b);
+
+class DecodeError : public std::runtime_error {
+public:
+    DecodeError(size_t offset, const std::string &message);
+
+    // offset returns the position of data where decoding failed
+    size_t offset() const { return offset_; }
+
+private:
+    size_t offset_;
+};
+
+// ShortBufferError is thrown when data does not contain a complete message
+// yet
+class ShortBufferError : public DecodeError {
+public:
+    ShortBufferError();
+};
+
+class EncodeError : public std::runtime_error {
+public:
+    using std::runtime_error::runtime_error;
+};
+
+class Reader;
+class Writer;
+
+class Codable {
+public:
+    virtual ~Codable() = default;
+    virtual void decode(Reader &reader) = 0;
+    virtual void encode(Writer &writer) const = 0;
+};
+
+class Package : public Codable {
+public:
+    virtual uint8_t ludwiegID() const = 0;
+};
+
+class Writer {
+public:
+    const std::vector<uint8_t> &data() const { return data_; }
+
+    // writeSize writes a dynamic size, using the smallest width able to hold
+    // the value
+    void writeSize(uint64_t value);
+
+    void writeUint8(const std::optional<uint8_t> &value);
+    void writeUint32(const std::optional<uint32_t> &value);
+    void writeUint64(const std::optional<uint64_t> &value);
+    void writeDouble(const std::optional<double> &value);
+    void writeString(const std::optional<std::string> &value);
+    void writeBlob(const std::optional<Blob> &value);
+    void writeBool(const std::optional<bool> &value);
+    void writeUUID(const std::optional<UUID> &value);
+    void writeDynInt(const std::optional<uint64_t> &value);
+    void writeAny(const std::optional<Any> &value);
+
+    // writeArray writes items using element for each of them. When size is
+    // provided, arrays holding more items are rejected.
+    template <class T, class F>
+    void writeArray(const std::optional<std::vector<T>> &items, std::optional<size_t> size, F element)
+    {
+        if (!items) {
+            empty(Type::Array);
+            return;
+        }
+        if (size && items->size() > *size) {
+            throw EncodeError("array holds " + std::to_string(items->size()) + " items, exceeding its size of " + std::to_string(*size));
+        }
+        Writer body;
+        body.writeSize(items->size());
+        for (const auto &item : *items) {
+            element(body, item);
+        }
+        sized(Type::Array, body.data_);
+    }
+
+    template <class T>
+    void writeStruct(const std::optional<T> &value)
+    {
+        writeStruct(value ? &*value : nullptr);
+    }
+
+    template <class T>
+    void writeStruct(const std::shared_ptr<T> &value)
+    {
+        writeStruct(value.get());
+    }
+
+    void writeStruct(const Codable *value);
+
+    void writeRaw(const uint8_t *data, size_t length);
+
+private:
+    void empty(Type t);
+    void sized(Type t, const std::vector<uint8_t> &data);
+    void writeAnyValue(const Any &value);
+
+    std::vector<uint8_t> data_;
+};
+
+class Reader {
+public:
+    Reader(const uint8_t *data, size_t length) : Reader(data, 0, length) {}
+
+    size_t position() const { return position_; }
+
+    uint64_t readSize();
+
+    std::optional<uint8_t> readUint8();
+    std::optional<uint32_t> readUint32();
+    std::optional<uint64_t> readUint64();
+    std::optional<double> readDouble();
+    std::optional<std::string> readString();
+    std::optional<Blob> readBlob();
+    std::optional<bool> readBool();
+    std::optional<UUID> readUUID();
+    std::optional<uint64_t> readDynInt();
+    std::optional<Any> readAny();
+
+    // readArray reads an array, using element to read each of its items
+    template <class F>
+    auto readArray(F element) -> std::optional<std::vector<decltype(element(std::declval<Reader &>()))>>
+    {
+        if (!begin(Type::Array)) {
+            return std::nullopt;
+        }
+        Reader b = body();
+        uint64_t count = b.readSize();
+        std::vector<decltype(element(std::declval<Reader &>()))> items;
+        for (uint64_t i = 0; i < count; i++) {
+            if (b.position_ >= b.limit_) {
+                throw DecodeError(b.position_, "value exceeds message boundaries");
+            }
+            items.push_back(element(b));
+        }
+        return items;
+    }
+
+    template <class T>
+    std::optional<T> readStruct()
+    {
+        if (!begin(Type::Struct)) {
+            return std::nullopt;
+        }
+        Reader b = body();
+        T value;
+        value.decode(b);
+        return value;
+    }
+
+    template <class T>
+    std::shared_ptr<T> readSharedStruct()
+    {
+        auto value = readStruct<T>();
+        return value ? std::make_shared<T>(std::move(*value)) : nullptr;
+    }
+
+private:
+    friend class Registry;
+
+    Reader(const uint8_t *data, size_t position, size_t limit) : data_(data), position_(position), limit_(limit) {}
+
+    const uint8_t *take(uint64_t n);
+    uint64_t littleEndian(size_t n);
+    bool begin(Type t);
+    Reader body();
+
+    const uint8_t *data_;
+    size_t position_;
+    size_t limit_;
+};
+
+struct Message {
+    uint8_t messageID;
+    std::unique_ptr<Package> package;
+
+    // length holds the amount of bytes used by the message
+    size_t length;
+};
+
+// Registry dispatches messages to packages based on their LudwiegID
+class Registry {
+public:
+    template <class T>
+    void add()
+    {
+        factories_[T::LudwiegID] = [] { return std::unique_ptr<Package>(new T()); };
+    }
+
+    // decode decodes the message at the beginning of data. ShortBufferError
+    // is thrown when data does not contain a complete message yet.
+    Message decode(const uint8_t *data, size_t length) const;
+
+    std::vector<uint8_t> encode(uint8_t messageID, const Package &package) const;
+
+private:
+    std::unordered_map<uint8_t, std::function<std::unique_ptr<Package>()>> factories_;
+};
+
+} // namespace ludwieg
+`
+
+const cppRuntimeSource = `// WARNING: Automatically generated by ludco. DO NOT EDIT.
+
+#include "ludwieg.hpp"
+
+#include <cstring>
+
+namespace ludwieg {
+
+namespace {
+
+constexpr uint8_t FlagEmpty = 0x80;
+
+} // namespace
+
+const char *typeName(Type t)
+{
+    switch (t) {
+    case Type::Uint8: return "uint8";
+    case Type::Uint32: return "uint32";
+    case Type::Uint64: return "uint64";
+    case Type::Double: return "double";
+    case Type::String: return "string";
+    case Type::Blob: return "blob";
+    case Type::Bool: return "bool";
+    case Type::UUID: return "uuid";
+    case Type::Any: return "any";
+    case Type::Array: return "array";
+    case Type::Struct: return "struct";
+    case Type::DynInt: return "dynint";
+    }
+    return "unknown type";
+}
+
+Type Any::type() const
+{
+    static const Type types[] = {
+        Type::Uint8, Type::Uint32, Type::Uint64, Type::Double, Type::String,
+        Type::Blob, Type::Bool, Type::UUID, Type::DynInt, Type::Array,
+    };
+    return types[value.index()];
+}
+
+bool operator==(const Any &a, const Any &b)
+{
+    return a.value == b.value;
+}
+
+bool operator!=(const Any &a, const Any &b)
+{
+    return !(a == b);
+}
+
+DecodeError::DecodeError(size_t offset, const std::string &message)
+    : std::runtime_error("offset " + std::to_string(offset) + ": " + message), offset_(offset)
+{
+}
+
+ShortBufferError::ShortBufferError() : DecodeError(0, "buffer does not contain a complete message")
+{
+}
+
+void Writer::writeSize(uint64_t value)
+{
+    size_t width = 8;
+    if (value <= 0xFF) {
+        width = 1;
+    } else if (value <= 0xFFFF) {
+        width = 2;
+    } else if (value <= 0xFFFFFFFF) {
+        width = 4;
+    }
+    data_.push_back(static_cast<uint8_t>(width));
+    for (size_t i = 0; i < width; i++) {
+        data_.push_back(static_cast<uint8_t>(value >> (8 * i)));
+    }
+}
+
+void Writer::writeRaw(const uint8_t *data, size_t length)
+{
+    data_.insert(data_.end(), data, data + length);
+}
+
+void Writer::empty(Type t)
+{
+    data_.push_back(static_cast<uint8_t>(t) | FlagEmpty);
+}
+
+void Writer::sized(Type t, const std::vector<uint8_t> &data)
+{
+    data_.push_back(static_cast<uint8_t>(t));
+    writeSize(data.size());
+    writeRaw(data.data(), data.size());
+}
+
+namespace {
+
+void littleEndian(std::vector<uint8_t> &data, uint64_t value, size_t n)
+{
+    for (size_t i = 0; i < n; i++) {
+        data.push_back(static_cast<uint8_t>(value >> (8 * i)));
+    }
+}
+
+} // namespace
+
+void Writer::writeUint8(const std::optional<uint8_t> &value)
+{
+    if (!value) {
+        return empty(Type::Uint8);
+    }
+    data_.push_back(static_cast<uint8_t>(Type::Uint8));
+    data_.push_back(*value);
+}
+
+void Writer::writeUint32(const std::optional<uint32_t> &value)
+{
+    if (!value) {
+        return empty(Type::Uint32);
+    }
+    data_.push_back(static_cast<uint8_t>(Type::Uint32));
+    littleEndian(data_, *value, 4);
+}
+
+void Writer::writeUint64(const std::optional<uint64_t> &value)
+{
+    if (!value) {
+        return empty(Type::Uint64);
+    }
+    data_.push_back(static_cast<uint8_t>(Type::Uint64));
+    littleEndian(data_, *value, 8);
+}
+
+void Writer::writeDouble(const std::optional<double> &value)
+{
+    if (!value) {
+        return empty(Type::Double);
+    }
+    uint64_t bits;
+    std::memcpy(&bits, &*value, sizeof(bits));
+    data_.push_back(static_cast<uint8_t>(Type::Double));
+    littleEndian(data_, bits, 8);
+}
+
+void Writer::writeString(const std::optional<std::string> &value)
+{
+    if (!value) {
+        return empty(Type::String);
+    }
+    sized(Type::String, std::vector<uint8_t>(value->begin(), value->end()));
+}
+
+void Writer::writeBlob(const std::optional<Blob> &value)
+{
+    if (!value) {
+        return empty(Type::Blob);
+    }
+    sized(Type::Blob, *value);
+}
+
+void Writer::writeBool(const std::optional<bool> &value)
+{
+    if (!value) {
+        return empty(Type::Bool);
+    }
+    data_.push_back(static_cast<uint8_t>(Type::Bool));
+    data_.push_back(*value ? 1 : 0);
+}
+
+void Writer::writeUUID(const std::optional<UUID> &value)
+{
+    if (!value) {
+        return empty(Type::UUID);
+    }
+    data_.push_back(static_cast<uint8_t>(Type::UUID));
+    writeRaw(value->data(), value->size());
+}
+
+void Writer::writeDynInt(const std::optional<uint64_t> &value)
+{
+    if (!value) {
+        return empty(Type::DynInt);
+    }
+    data_.push_back(static_cast<uint8_t>(Type::DynInt));
+    writeSize(*value);
+}
+
+void Writer::writeAny(const std::optional<Any> &value)
+{
+    if (!value) {
+        return empty(Type::Any);
+    }
+    data_.push_back(static_cast<uint8_t>(Type::Any));
+    writeAnyValue(*value);
+}
+
+void Writer::writeAnyValue(const Any &value)
+{
+    std::visit([this](const auto &v) {
+        using T = std::decay_t<decltype(v)>;
+        if constexpr (std::is_same_v<T, uint8_t>) {
+            writeUint8(v);
+        } else if constexpr (std::is_same_v<T, uint32_t>) {
+            writeUint32(v);
+        } else if constexpr (std::is_same_v<T, uint64_t>) {
+            writeUint64(v);
+        } else if constexpr (std::is_same_v<T, double>) {
+            writeDouble(v);
+        } else if constexpr (std::is_same_v<T, std::string>) {
+            writeString(v);
+        } else if constexpr (std::is_same_v<T, Blob>) {
+            writeBlob(v);
+        } else if constexpr (std::is_same_v<T, bool>) {
+            writeBool(v);
+        } else if constexpr (std::is_same_v<T, UUID>) {
+            writeUUID(v);
+        } else if constexpr (std::is_same_v<T, DynInt>) {
+            writeDynInt(v.value);
+        } else {
+            writeArray(std::optional<AnyArray>(v), std::nullopt, [](Writer &e, const std::optional<Any> &item) { e.writeAny(item); });
+        }
+    }, value.value);
+}
+
+void Writer::writeStruct(const Codable *value)
+{
+    if (!value) {
+        return empty(Type::Struct);
+    }
+    Writer body;
+    value->encode(body);
+    sized(Type::Struct, body.data_);
+}
+
+const uint8_t *Reader::take(uint64_t n)
+{
+    if (n > limit_ - position_) {
+        throw DecodeError(position_, "value exceeds message boundaries");
+    }
+    const uint8_t *p = data_ + position_;
+    position_ += static_cast<size_t>(n);
+    return p;
+}
+
+uint64_t Reader::littleEndian(size_t n)
+{
+    const uint8_t *p = take(n);
+    uint64_t value = 0;
+    for (size_t i = n; i > 0; i--) {
+        value = (value << 8) | p[i - 1];
+    }
+    return value;
+}
+
+uint64_t Reader::readSize()
+{
+    size_t offset = position_;
+    uint8_t width = *take(1);
+    if (width != 1 && width != 2 && width != 4 && width != 8) {
+        throw DecodeError(offset, "invalid size width " + std::to_string(width));
+    }
+    return littleEndian(width);
+}
+
+// begin reads the type byte of a value, returning false when the value is
+// empty. Missing trailing values are also considered empty.
+bool Reader::begin(Type t)
+{
+    if (position_ >= limit_) {
+        return false;
+    }
+    size_t offset = position_;
+    uint8_t b = *take(1);
+    Type found = static_cast<Type>(b & ~FlagEmpty);
+    if (found != t) {
+        throw DecodeError(offset, std::string("expected ") + typeName(t) + ", found " + typeName(found));
+    }
+    return (b & FlagEmpty) == 0;
+}
+
+// body returns a reader limited to the next value, advancing past it
+Reader Reader::body()
+{
+    uint64_t size = readSize();
+    size_t start = position_;
+    take(size);
+    return Reader(data_, start, position_);
+}
+
+std::optional<uint8_t> Reader::readUint8()
+{
+    if (!begin(Type::Uint8)) {
+        return std::nullopt;
+    }
+    return *take(1);
+}
+
+std::optional<uint32_t> Reader::readUint32()
+{
+    if (!begin(Type::Uint32)) {
+        return std::nullopt;
+    }
+    return static_cast<uint32_t>(littleEndian(4));
+}
+
+std::optional<uint64_t> Reader::readUint64()
+{
+    if (!begin(Type::Uint64)) {
+        return std::nullopt;
+    }
+    return littleEndian(8);
+}
+
+std::optional<double> Reader::readDouble()
+{
+    if (!begin(Type::Double)) {
+        return std::nullopt;
+    }
+    uint64_t bits = littleEndian(8);
+    double value;
+    std::memcpy(&value, &bits, sizeof(value));
+    return value;
+}
+
+std::optional<std::string> Reader::readString()
+{
+    if (!begin(Type::String)) {
+        return std::nullopt;
+    }
+    uint64_t size = readSize();
+    const uint8_t *p = take(size);
+    return std::string(reinterpret_cast<const char *>(p), static_cast<size_t>(size));
+}
+
+std::optional<Blob> Reader::readBlob()
+{
+    if (!begin(Type::Blob)) {
+        return std::nullopt;
+    }
+    uint64_t size = readSize();
+    const uint8_t *p = take(size);
+    return Blob(p, p + size);
+}
+
+std::optional<bool> Reader::readBool()
+{
+    if (!begin(Type::Bool)) {
+        return std::nullopt;
+    }
+    return *take(1) != 0;
+}
+
+std::optional<UUID> Reader::readUUID()
+{
+    if (!begin(Type::UUID)) {
+        return std::nullopt;
+    }
+    UUID value;
+    std::memcpy(value.data(), take(16), 16);
+    return value;
+}
+
+std::optional<uint64_t> Reader::readDynInt()
+{
+    if (!begin(Type::DynInt)) {
+        return std::nullopt;
+    }
+    return readSize();
+}
+
+namespace {
+
+template <class T>
+std::optional<Any> wrap(const std::optional<T> &value)
+{
+    if (!value) {
+        return std::nullopt;
+    }
+    return Any{*value};
+}
+
+} // namespace
+
+std::optional<Any> Reader::readAny()
+{
+    if (!begin(Type::Any)) {
+        return std::nullopt;
+    }
+    if (position_ >= limit_) {
+        throw DecodeError(position_, "value exceeds message boundaries");
+    }
+    size_t offset = position_;
+    Type t = static_cast<Type>(data_[position_] & ~FlagEmpty);
+    switch (t) {
+    case Type::Uint8: return wrap(readUint8());
+    case Type::Uint32: return wrap(readUint32());
+    case Type::Uint64: return wrap(readUint64());
+    case Type::Double: return wrap(readDouble());
+    case Type::String: return wrap(readString());
+    case Type::Blob: return wrap(readBlob());
+    case Type::Bool: return wrap(readBool());
+    case Type::UUID: return wrap(readUUID());
+    case Type::DynInt: {
+        auto value = readDynInt();
+        return value ? std::optional<Any>(Any{DynInt{*value}}) : std::nullopt;
+    }
+    case Type::Array:
+        return wrap(readArray([](Reader &e) { return e.readAny(); }));
+    default:
+        throw DecodeError(offset, std::string(typeName(t)) + " values cannot be held by any");
+    }
+}
+
+Message Registry::decode(const uint8_t *data, size_t length) const
+{
+    const size_t headerSize = Magic.size() + 3;
+    if (length <= headerSize) {
+        throw ShortBufferError();
+    }
+    if (std::memcmp(data, Magic.data(), Magic.size()) != 0) {
+        throw DecodeError(0, "invalid magic");
+    }
+    if (data[Magic.size()] != ProtocolVersion) {
+        throw DecodeError(Magic.size(), "unsupported protocol version " + std::to_string(data[Magic.size()]));
+    }
+
+    uint8_t width = data[headerSize];
+    if (width != 1 && width != 2 && width != 4 && width != 8) {
+        throw DecodeError(headerSize, "invalid size width " + std::to_string(width));
+    }
+    size_t start = headerSize + 1 + width;
+    if (length < start) {
+        throw ShortBufferError();
+    }
+    uint64_t size = Reader(data, headerSize, start).readSize();
+    if (size > length - start) {
+        throw ShortBufferError();
+    }
+
+    uint8_t id = data[Magic.size() + 2];
+    auto factory = factories_.find(id);
+    if (factory == factories_.end()) {
+        throw DecodeError(Magic.size() + 2, "unknown package " + std::to_string(id));
+    }
+    Message message{data[Magic.size() + 1], factory->second(), start + static_cast<size_t>(size)};
+    Reader payload(data, start, message.length);
+    message.package->decode(payload);
+    return message;
+}
+
+std::vector<uint8_t> Registry::encode(uint8_t messageID, const Package &package) const
+{
+    Writer payload;
+    package.encode(payload);
+
+    Writer w;
+    const uint8_t header[] = {ProtocolVersion, messageID, package.ludwiegID()};
+    w.writeRaw(Magic.data(), Magic.size());
+    w.writeRaw(header, sizeof(header));
+    w.writeSize(payload.data().size());
+    w.writeRaw(payload.data().data(), payload.data().size());
+    return w.data();
+}
+
+} // namespace ludwieg
+`
+
+const cppPackageHeader = `// WARNING: Automatically generated by ludco. DO NOT EDIT.
+
+#pragma once
+
+#include "ludwieg.hpp"
+
+// Implicit members of classes holding deprecated fields must not warn
+#if defined(__GNUC__)
+#pragma GCC diagnostic push
+#pragma GCC diagnostic ignored "-Wdeprecated-declarations"
+#elif defined(_MSC_VER)
+#pragma warning(push)
+#pragma warning(disable : 4996)
+#endif
+
+namespace {{.namespace}} {
+
+{{.declarations}}{{.classes}}
+} // namespace {{.namespace}}
+
+#if defined(__GNUC__)
+#pragma GCC diagnostic pop
+#elif defined(_MSC_VER)
+#pragma warning(pop)
+#endif
+`
+
+const cppClass = `
+class {{.name}} : public {{.base}} {
+public:
+{{.body}}};
+`
+
+const cppPackageSource = `// WARNING: Automatically generated by ludco. DO NOT EDIT.
+
+#include "{{.file}}.hpp"
+
+// Deprecated fields are still encoded and decoded
+#if defined(__GNUC__)
+#pragma GCC diagnostic ignored "-Wdeprecated-declarations"
+#elif defined(_MSC_VER)
+#pragma warning(disable : 4996)
+#endif
+
+namespace {{.namespace}} {
+{{.methods}}
+} // namespace {{.namespace}}
+`
+
+const cppMethods = `
+void {{.name}}::decode(ludwieg::Reader &{{.reader}})
+{
+{{.decode}}}
+
+void {{.name}}::encode(ludwieg::Writer &{{.writer}}) const
+{
+{{.encode}}}
+`
+
+const cppRegistryHeader = `// WARNING: Automatically generated by ludco. DO NOT EDIT.
+
+#pragma once
+
+#include "ludwieg.hpp"
+
+namespace {{.namespace}} {
+
+// registry returns a registry holding every generated package
+const ludwieg::Registry &registry();
+
+} // namespace {{.namespace}}
+`
+
+const cppRegistrySource = `// WARNING: Automatically generated by ludco. DO NOT EDIT.
+
+#include "ludwieg_registry.hpp"
+
+{{.includes}}
+
+namespace {{.namespace}} {
+
+const ludwieg::Registry &registry()
+{
+    static const ludwieg::Registry instance = [] {
+        ludwieg::Registry r;
+{{.packages}}        return r;
+    }();
+    return instance;
+}
+
+} // namespace {{.namespace}}
+`
+
+const cppIntegrationSteps = `You just generated C++17 sources. No external library is required: {{.runtime}}
+implements the wire format. Compile every .cpp file of the output folder along
+with your project, and use the registry to decode and encode messages:
+
+     {{.cppUsage}}
+`
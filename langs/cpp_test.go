@@ -0,0 +1,92 @@
+package langs
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// cppReservedSource declares packages named after runtime and registry files
+const cppReservedSource = `package ludwieg {
+    id 0x01
+    string text
+}
+
+package ludwieg_registry {
+    id 0x02
+    string text
+}
+`
+
+// cppRoundTrip decodes every vector using generated sources, and checks that
+// encoding the decoded message again yields the very same bytes
+const cppRoundTrip = `#include <cstdio>
+#include <string>
+#include <vector>
+
+#include "ludwieg_registry.hpp"
+
+static const char *vectors[][2] = {
+%VECTORS%};
+
+int main()
+{
+    int failures = 0;
+    for (const auto &v : vectors) {
+        std::string hex = v[1];
+        std::vector<uint8_t> data;
+        for (size_t i = 0; i < hex.size(); i += 2) {
+            data.push_back(static_cast<uint8_t>(std::stoul(hex.substr(i, 2), nullptr, 16)));
+        }
+        try {
+            auto message = sample::registry().decode(data.data(), data.size());
+            auto encoded = sample::registry().encode(message.messageID, *message.package);
+            if (message.length != data.size() || encoded != data) {
+                std::printf("%s: encoded message differs\n", v[0]);
+                failures++;
+            }
+        } catch (const std::exception &e) {
+            std::printf("%s: %s\n", v[0], e.what());
+            failures++;
+        }
+    }
+    return failures > 0;
+}
+`
+
+func TestCppRoundTrip(t *testing.T) {
+	cxx := testTool(t, "g++")
+	packages := testPackages(t, vectorSource, recursiveSource, cppReservedSource)
+	dir := testCompile(t, Cpp{}, "sample", "", packages)
+	defer os.RemoveAll(dir)
+
+	sources, _ := filepath.Glob(filepath.Join(dir, "*.cpp"))
+	build := append([]string{cxx, "-std=c++17", "-Wall", "-Werror", "-o", "check", "check.cpp"}, sources...)
+	testRoundTrip(t, dir, &(*packages)[0], "check.cpp", cppRoundTrip, func(v testVector) string {
+		return fmt.Sprintf("    { %q, %q },\n", v.Name, v.Hex)
+	}, build, []string{filepath.Join(dir, "check")})
+}
+
+func TestCppRecursiveStructs(t *testing.T) {
+	dir := testCompile(t, Cpp{}, "sample", "", testPackages(t, recursiveSource))
+	defer os.RemoveAll(dir)
+
+	testContains(t, "tree.hpp", testOutput(t, dir, "tree.hpp"),
+		"std::optional<TreeNode> root;",
+		"std::shared_ptr<TreeNode> parent;",
+		"std::optional<std::vector<std::optional<TreeNode>>> children;",
+		"std::shared_ptr<TreeB> next;")
+}
+
+func TestCppReservedNames(t *testing.T) {
+	dir := testCompile(t, Cpp{}, "sample", "", testPackages(t, cppReservedSource))
+	defer os.RemoveAll(dir)
+
+	testContains(t, "ludwieg_.hpp", testOutput(t, dir, "ludwieg_.hpp"), "class Ludwieg : public ludwieg::Package")
+	testContains(t, "ludwieg_registry_.cpp", testOutput(t, dir, "ludwieg_registry_.cpp"), `#include "ludwieg_registry_.hpp"`)
+	testContains(t, "ludwieg_registry.cpp", testOutput(t, dir, "ludwieg_registry.cpp"),
+		`#include "ludwieg_.hpp"`,
+		"r.add<LudwiegRegistry>();")
+	testContains(t, "ludwieg.hpp", testOutput(t, dir, "ludwieg.hpp"), "class Registry {")
+}
@@ -0,0 +1,286 @@
+package langs
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/alecthomas/chroma/quick"
+	"github.com/logrusorgru/aurora"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/ludwieg/ludco/codec"
+	"github.com/ludwieg/ludco/models"
+)
+
+// csharpSerializerFile holds the name of the serializer written alongside
+// generated packages
+const csharpSerializerFile = "LudwiegSerializer.cs"
+
+// csharpReservedTypes lists runtime types, along with system types referred to
+// by generated sources, which generated classes must not shadow
+var csharpReservedTypes = map[string]bool{
+	"Action": true, "ArgumentException": true, "Array": true,
+	"BinaryPrimitives": true, "BitConverter": true, "Exception": true,
+	"Guid": true, "IList": true, "ILudwiegCodable": true, "ILudwiegPackage": true,
+	"List": true, "LudwiegAny": true, "LudwiegElementReader": true,
+	"LudwiegException": true, "LudwiegReader": true, "LudwiegSerializer": true,
+	"LudwiegShortBufferException": true, "LudwiegType": true,
+	"LudwiegWriter": true, "MemoryStream": true, "Obsolete": true,
+	"ReadOnlySpan": true, "Span": true, "System": true,
+}
+
+type CSharp struct {
+	namespace string
+	out       string
+
+	// classes maps structures to the qualified name of their generated class
+	classes map[*models.Struct]string
+}
+
+func (c CSharp) Compile(in, out, pkgName, prefix string, packages *models.PackageList) {
+	log.Infof("Initialising %s compiler", aurora.Magenta("C#"))
+	if prefix != "" {
+		log.Warn("Ignoring unnecessary --prefix option.")
+	}
+	if pkgName == "" {
+		// Assume a namespace based on the output folder, like the Java
+		// compiler does for packages.
+		r := regexp.MustCompile("[^A-Za-z0-9]")
+		pkgName = string(r.ReplaceAll([]byte(filepath.Base(out)), []byte{}))
+		if pkgName == "" || (pkgName[0] >= '0' && pkgName[0] <= '9') {
+			pkgName = "Ludwieg" + pkgName
+		}
+		pkgName = strings.ToUpper(pkgName[:1]) + pkgName[1:]
+		log.Warnf("No namespace was provided. Assumed %s based on output path.", aurora.Magenta(pkgName))
+		log.Warn("Please use the --package argument to define a custom namespace")
+	}
+	c.namespace = pkgName
+	c.out = out
+	c.classes = map[*models.Struct]string{}
+
+	var magic, cases []string
+	for _, b := range codec.Magic {
+		magic = append(magic, fmt.Sprintf("0x%02X", b))
+	}
+	for _, p := range *packages {
+		cases = append(cases, fmt.Sprintf("                case %s: return new %s();\n", p.Identifier, csharpPackageName(&p)))
+	}
+	c.output(csharpSerializerFile, processTemplate("csharpSerializer", csharpSerializer, templateData{
+		"namespace": c.namespace,
+		"magic":     strings.Join(magic, ", "),
+		"version":   fmt.Sprintf("0x%02X", codec.ProtocolVersion),
+		"cases":     strings.Join(cases, ""),
+	}))
+
+	for i := range *packages {
+		c.writePackage(&(*packages)[i])
+	}
+
+	log.Info("Succeeded")
+	fmt.Println()
+	fmt.Println(c.integrationInstructions())
+}
+
+func (c CSharp) output(name string, contents []byte) {
+	log.Infof("Writing %s/%s", aurora.Magenta(filepath.Base(c.out)), aurora.Magenta(name))
+	err := ioutil.WriteFile(filepath.Join(c.out, name), contents, 0644)
+	if err != nil {
+		log.Errorf("Operation failed: %s", err)
+		os.Exit(1)
+	}
+}
+
+// csharpPackageName returns the class name of a package, suffixing names of
+// runtime and system types
+func csharpPackageName(p *models.Package) string {
+	name := convertToPascalCase(p.Name)
+	if csharpReservedTypes[name] {
+		name += "Package"
+	}
+	return name
+}
+
+func (c CSharp) writePackage(p *models.Package) {
+	name := csharpPackageName(p)
+	properties := csharpPropertyNames(name, p.Fields)
+	c.registerStructs(name, name, properties, p.Structs)
+
+	header := []string{fmt.Sprintf("public const byte LudwiegId = %s;", p.Identifier), ""}
+	implementation := []string{"byte ILudwiegPackage.PackageId => LudwiegId;", ""}
+	class := c.generateClass(name, "ILudwiegPackage", header, implementation, p.Scope(), p.Fields, properties, p.Structs)
+
+	c.output(name+".cs", processTemplate("csharpPackage", csharpPackage, templateData{
+		"namespace": c.namespace,
+		"class":     indent(class),
+	}))
+}
+
+// csharpPropertyNames returns names of properties holding fields of a class.
+// C# forbids members named after their enclosing type, so those are
+// suffixed.
+func csharpPropertyNames(class string, fArr []models.Field) map[string]string {
+	names := map[string]string{}
+	for _, f := range fArr {
+		name := convertToPascalCase(f.Name)
+		if name == class {
+			name += "Value"
+		}
+		names[f.Name] = name
+	}
+	return names
+}
+
+// registerStructs assigns qualified class names to structures before classes
+// are generated, as fields may reference structures declared after them.
+// Nested classes sharing names with members of their enclosing class, or
+// with runtime and system types, are suffixed.
+func (c CSharp) registerStructs(qualified, enclosing string, properties map[string]string, sArr []models.Struct) {
+	taken := map[string]bool{enclosing: true}
+	for _, p := range properties {
+		taken[p] = true
+	}
+	for i := range sArr {
+		s := &sArr[i]
+		name := convertToPascalCase(s.Name)
+		if taken[name] || csharpReservedTypes[name] {
+			name += "Struct"
+		}
+		c.classes[s] = qualified + "." + name
+		c.registerStructs(qualified+"."+name, name, csharpPropertyNames(name, s.Fields), s.Structs)
+	}
+}
+
+// generateClass returns the class of a package or structure, along with
+// classes of structures declared by it, which are nested into it
+func (c CSharp) generateClass(name, iface string, header, implementation []string, scope *models.Scope, fArr []models.Field, properties map[string]string, sArr []models.Struct) string {
+	body := header
+	var decode, encode []string
+	for _, f := range fArr {
+		decl, dec, enc := c.generateField(scope, &f, properties[f.Name])
+		body = append(body, decl)
+		decode = append(decode, "    "+dec+"\n")
+		encode = append(encode, "    "+enc+"\n")
+	}
+	if len(fArr) > 0 {
+		body = append(body, "")
+	}
+	body = append(body, implementation...)
+	body = append(body, string(processTemplate("csharpMethods", csharpMethods, templateData{
+		"decode": strings.Join(decode, ""),
+		"encode": strings.Join(encode, ""),
+	})))
+
+	for i := range sArr {
+		s := &sArr[i]
+		qualified := c.classes[s]
+		inner := &models.Scope{Structs: s.Structs, Parent: scope}
+		simple := qualified[strings.LastIndex(qualified, ".")+1:]
+		body = append(body, c.generateClass(simple, "ILudwiegCodable", nil, nil, inner, s.Fields, csharpPropertyNames(simple, s.Fields), s.Structs))
+	}
+
+	return string(processTemplate("csharpClass", csharpClass, templateData{
+		"name":      name,
+		"interface": iface,
+		"body":      indent(strings.TrimSuffix(strings.Join(body, "\n"), "\n")) + "\n",
+	}))
+}
+
+// csharpType returns the C# type holding values of a native type, along with
+// the suffix of the reader and writer methods handling it
+func csharpType(t models.NativeType) (string, string) {
+	switch t {
+	case models.TypeUint8, models.TypeByte:
+		return "byte?", "UInt8"
+	case models.TypeUint32:
+		return "uint?", "UInt32"
+	case models.TypeUint64:
+		return "ulong?", "UInt64"
+	case models.TypeDouble:
+		return "double?", "Double"
+	case models.TypeString:
+		return "string", "String"
+	case models.TypeBlob:
+		return "byte[]", "Blob"
+	case models.TypeBool:
+		return "bool?", "Bool"
+	case models.TypeUUID:
+		return "Guid?", "UUID"
+	case models.TypeAny:
+		return "LudwiegAny", "Any"
+	case models.TypeDynInt:
+		return "ulong?", "DynInt"
+	}
+	log.Fatalf("BUG: Cannot coerce unknown type %#v to native type.", t)
+	return "", ""
+}
+
+// generateField returns the declaration of a field, along with statements
+// decoding and encoding it
+func (c CSharp) generateField(scope *models.Scope, f *models.Field, name string) (string, string, string) {
+	var t, read, write string
+	if f.Type.Source == models.SourceUser {
+		s, _, ok := scope.Resolve(f.Type.CustomType)
+		if !ok {
+			log.Errorf("BUG: Cannot resolve structure %s referenced by %s", f.Type.CustomType, f.Name)
+			os.Exit(1)
+		}
+		t = c.classes[s]
+		read = "ReadStruct<" + t + ">()"
+		write = "WriteStruct"
+	} else {
+		var kind string
+		t, kind = csharpType(f.Type.NativeType)
+		read = "Read" + kind + "()"
+		write = "Write" + kind
+	}
+
+	var dec, enc string
+	if f.IsArray() {
+		t = "List<" + t + ">"
+		size := "null"
+		if f.Size != "*" {
+			size = f.Size
+		}
+		dec = fmt.Sprintf("%s = r.ReadArray((ref LudwiegReader er) => er.%s);", name, read)
+		enc = fmt.Sprintf("w.WriteArray(%s, %s, (ew, v) => ew.%s(v));", name, size, write)
+	} else {
+		dec = fmt.Sprintf("%s = r.%s;", name, read)
+		enc = fmt.Sprintf("w.%s(%s);", write, name)
+	}
+
+	decl := fmt.Sprintf("public %s %s { get; set; }", t, name)
+	if f.HasAttribute(models.AttributeDeprecated) {
+		decl = "[Obsolete]\n" + decl
+	}
+	return decl, dec, enc
+}
+
+func (c CSharp) formatCode(code string) string {
+	var buf bytes.Buffer
+	err := quick.Highlight(&buf, code, "csharp", "terminal", "pygments")
+	if err != nil {
+		log.Fatalf("BUG: Error processing C# source: %s", err)
+	}
+
+	return string(buf.Bytes())
+}
+
+func (c CSharp) integrationInstructions() string {
+	usage := strings.Join([]string{
+		"var package = LudwiegSerializer.Decode(data, out var messageId, out var length);",
+		"     var encoded = LudwiegSerializer.Encode(messageId, package);",
+	}, "\n")
+
+	return string(processTemplate("csharpIntegration", csharpIntegrationSteps, templateData{
+		"file":       aurora.Magenta(csharpSerializerFile),
+		"serializer": aurora.Bold("LudwiegSerializer"),
+		"namespace":  aurora.Magenta(c.namespace),
+		"csUsing":    c.formatCode("using " + c.namespace + ";"),
+		"csUsage":    c.formatCode(usage),
+	}))
+}
@@ -0,0 +1,719 @@
+package langs
+
+const csharpPackage = `// WARNING: Automatically generated by ludco. DO NOT EDIT.
+
+#nullable disable
+#pragma warning disable 612, 618
+
+using System;
+using System.Collections.Generic;
+
+namespace {{.namespace}}
+{
+{{.class}}}
+`
+
+const csharpClass = `public class {{.name}} : {{.interface}}
+{
+{{.body}}}
+`
+
+const csharpMethods = `void ILudwiegCodable.Decode(ref LudwiegReader r)
+{
+{{.decode}}}
+
+void ILudwiegCodable.Encode(LudwiegWriter w)
+{
+{{.encode}}}
+`
+
+const csharpSerializer = `// WARNING: Automatically generated by ludco. DO NOT EDIT.
+//
+// Implements the Ludwieg wire format for packages generated alongside this
+// file.
+
+#nullable disable
+
+using System;
+using System.Buffers.Binary;
+using System.Collections.Generic;
+using System.IO;
+
+namespace {{.namespace}}
+{
+    public enum LudwiegType : byte
+    {
+        UInt8 = 0x01,
+        UInt32 = 0x02,
+        UInt64 = 0x03,
+        Double = 0x04,
+        String = 0x05,
+        Blob = 0x06,
+        Bool = 0x07,
+        UUID = 0x08,
+        Any = 0x09,
+        Array = 0x0A,
+        Struct = 0x0B,
+        DynInt = 0x0C,
+    }
+
+    /// <summary>
+    /// LudwiegAny holds values of any fields, along with their type. Values
+    /// are represented using the same types of regular fields, and arrays as
+    /// List&lt;LudwiegAny&gt;.
+    /// </summary>
+    public sealed class LudwiegAny
+    {
+        public LudwiegType Type { get; }
+        public object Value { get; }
+
+        public LudwiegAny(LudwiegType type, object value)
+        {
+            Type = type;
+            Value = value;
+        }
+
+        public override bool Equals(object obj)
+        {
+            var other = obj as LudwiegAny;
+            if (other == null || other.Type != Type)
+            {
+                return false;
+            }
+            if (Value is byte[] blob && other.Value is byte[] otherBlob)
+            {
+                return ((ReadOnlySpan<byte>)blob).SequenceEqual(otherBlob);
+            }
+            if (Value is List<LudwiegAny> items && other.Value is List<LudwiegAny> otherItems)
+            {
+                if (items.Count != otherItems.Count)
+                {
+                    return false;
+                }
+                for (var i = 0; i < items.Count; i++)
+                {
+                    if (!Equals(items[i], otherItems[i]))
+                    {
+                        return false;
+                    }
+                }
+                return true;
+            }
+            return Equals(Value, other.Value);
+        }
+
+        public override int GetHashCode() => Type.GetHashCode();
+
+        public override string ToString() => Type + "(" + Value + ")";
+    }
+
+    public class LudwiegException : Exception
+    {
+        /// <summary>Offset of the failure, or -1 when not applicable</summary>
+        public int Offset { get; }
+
+        public LudwiegException(int offset, string message) : base("offset " + offset + ": " + message)
+        {
+            Offset = offset;
+        }
+
+        public LudwiegException(string message) : base(message)
+        {
+            Offset = -1;
+        }
+    }
+
+    /// <summary>
+    /// LudwiegShortBufferException is thrown when data does not contain a
+    /// complete message yet
+    /// </summary>
+    public class LudwiegShortBufferException : LudwiegException
+    {
+        public LudwiegShortBufferException() : base("buffer does not contain a complete message")
+        {
+        }
+    }
+
+    public interface ILudwiegCodable
+    {
+        void Decode(ref LudwiegReader r);
+        void Encode(LudwiegWriter w);
+    }
+
+    public interface ILudwiegPackage : ILudwiegCodable
+    {
+        byte PackageId { get; }
+    }
+
+    public delegate T LudwiegElementReader<T>(ref LudwiegReader r);
+
+    public sealed class LudwiegWriter
+    {
+        private const byte FlagEmpty = 0x80;
+        private readonly MemoryStream buffer = new MemoryStream();
+
+        public byte[] ToArray() => buffer.ToArray();
+
+        private void Empty(LudwiegType t) => buffer.WriteByte((byte)((byte)t | FlagEmpty));
+
+        private void Type(LudwiegType t) => buffer.WriteByte((byte)t);
+
+        internal void WriteRaw(ReadOnlySpan<byte> data) => buffer.Write(data);
+
+        /// <summary>
+        /// WriteSize writes a dynamic size, using the smallest width able to
+        /// hold the value
+        /// </summary>
+        public void WriteSize(ulong value)
+        {
+            Span<byte> b = stackalloc byte[8];
+            if (value <= byte.MaxValue)
+            {
+                buffer.WriteByte(1);
+                buffer.WriteByte((byte)value);
+            }
+            else if (value <= ushort.MaxValue)
+            {
+                buffer.WriteByte(2);
+                BinaryPrimitives.WriteUInt16LittleEndian(b, (ushort)value);
+                buffer.Write(b.Slice(0, 2));
+            }
+            else if (value <= uint.MaxValue)
+            {
+                buffer.WriteByte(4);
+                BinaryPrimitives.WriteUInt32LittleEndian(b, (uint)value);
+                buffer.Write(b.Slice(0, 4));
+            }
+            else
+            {
+                buffer.WriteByte(8);
+                BinaryPrimitives.WriteUInt64LittleEndian(b, value);
+                buffer.Write(b);
+            }
+        }
+
+        private void Sized(LudwiegType t, ReadOnlySpan<byte> data)
+        {
+            Type(t);
+            WriteSize((ulong)data.Length);
+            buffer.Write(data);
+        }
+
+        public void WriteUInt8(byte? value)
+        {
+            if (value == null)
+            {
+                Empty(LudwiegType.UInt8);
+                return;
+            }
+            Type(LudwiegType.UInt8);
+            buffer.WriteByte(value.Value);
+        }
+
+        public void WriteUInt32(uint? value)
+        {
+            if (value == null)
+            {
+                Empty(LudwiegType.UInt32);
+                return;
+            }
+            Span<byte> b = stackalloc byte[4];
+            BinaryPrimitives.WriteUInt32LittleEndian(b, value.Value);
+            Type(LudwiegType.UInt32);
+            buffer.Write(b);
+        }
+
+        public void WriteUInt64(ulong? value)
+        {
+            if (value == null)
+            {
+                Empty(LudwiegType.UInt64);
+                return;
+            }
+            Span<byte> b = stackalloc byte[8];
+            BinaryPrimitives.WriteUInt64LittleEndian(b, value.Value);
+            Type(LudwiegType.UInt64);
+            buffer.Write(b);
+        }
+
+        public void WriteDouble(double? value)
+        {
+            if (value == null)
+            {
+                Empty(LudwiegType.Double);
+                return;
+            }
+            Span<byte> b = stackalloc byte[8];
+            BinaryPrimitives.WriteInt64LittleEndian(b, BitConverter.DoubleToInt64Bits(value.Value));
+            Type(LudwiegType.Double);
+            buffer.Write(b);
+        }
+
+        public void WriteString(string value)
+        {
+            if (value == null)
+            {
+                Empty(LudwiegType.String);
+                return;
+            }
+            Sized(LudwiegType.String, System.Text.Encoding.UTF8.GetBytes(value));
+        }
+
+        public void WriteBlob(byte[] value)
+        {
+            if (value == null)
+            {
+                Empty(LudwiegType.Blob);
+                return;
+            }
+            Sized(LudwiegType.Blob, value);
+        }
+
+        public void WriteBool(bool? value)
+        {
+            if (value == null)
+            {
+                Empty(LudwiegType.Bool);
+                return;
+            }
+            Type(LudwiegType.Bool);
+            buffer.WriteByte(value.Value ? (byte)1 : (byte)0);
+        }
+
+        public void WriteUUID(Guid? value)
+        {
+            if (value == null)
+            {
+                Empty(LudwiegType.UUID);
+                return;
+            }
+            Type(LudwiegType.UUID);
+            buffer.Write(LudwiegSerializer.GuidToBytes(value.Value));
+        }
+
+        public void WriteDynInt(ulong? value)
+        {
+            if (value == null)
+            {
+                Empty(LudwiegType.DynInt);
+                return;
+            }
+            Type(LudwiegType.DynInt);
+            WriteSize(value.Value);
+        }
+
+        public void WriteAny(LudwiegAny value)
+        {
+            if (value == null)
+            {
+                Empty(LudwiegType.Any);
+                return;
+            }
+            Type(LudwiegType.Any);
+            switch (value.Type)
+            {
+                case LudwiegType.UInt8: WriteUInt8((byte?)value.Value); break;
+                case LudwiegType.UInt32: WriteUInt32((uint?)value.Value); break;
+                case LudwiegType.UInt64: WriteUInt64((ulong?)value.Value); break;
+                case LudwiegType.Double: WriteDouble((double?)value.Value); break;
+                case LudwiegType.String: WriteString((string)value.Value); break;
+                case LudwiegType.Blob: WriteBlob((byte[])value.Value); break;
+                case LudwiegType.Bool: WriteBool((bool?)value.Value); break;
+                case LudwiegType.UUID: WriteUUID((Guid?)value.Value); break;
+                case LudwiegType.DynInt: WriteDynInt((ulong?)value.Value); break;
+                case LudwiegType.Array:
+                    WriteArray((List<LudwiegAny>)value.Value, null, (ew, v) => ew.WriteAny(v));
+                    break;
+                default:
+                    throw new ArgumentException(value.Type + " values cannot be held by any");
+            }
+        }
+
+        /// <summary>
+        /// WriteArray writes items using element for each of them. When size
+        /// is provided, arrays holding more items are rejected.
+        /// </summary>
+        public void WriteArray<T>(IList<T> items, int? size, Action<LudwiegWriter, T> element)
+        {
+            if (items == null)
+            {
+                Empty(LudwiegType.Array);
+                return;
+            }
+            if (size != null && items.Count > size)
+            {
+                throw new ArgumentException("array holds " + items.Count + " items, exceeding its size of " + size);
+            }
+            var body = new LudwiegWriter();
+            body.WriteSize((ulong)items.Count);
+            foreach (var item in items)
+            {
+                element(body, item);
+            }
+            Sized(LudwiegType.Array, body.buffer.ToArray());
+        }
+
+        public void WriteStruct(ILudwiegCodable value)
+        {
+            if (value == null)
+            {
+                Empty(LudwiegType.Struct);
+                return;
+            }
+            var body = new LudwiegWriter();
+            value.Encode(body);
+            Sized(LudwiegType.Struct, body.buffer.ToArray());
+        }
+    }
+
+    public ref struct LudwiegReader
+    {
+        private const byte FlagEmpty = 0x80;
+        private readonly ReadOnlySpan<byte> data;
+        private readonly int limit;
+        private int position;
+
+        public LudwiegReader(ReadOnlySpan<byte> data) : this(data, 0, data.Length)
+        {
+        }
+
+        internal LudwiegReader(ReadOnlySpan<byte> data, int position, int limit)
+        {
+            this.data = data;
+            this.position = position;
+            this.limit = limit;
+        }
+
+        public int Position => position;
+
+        private ReadOnlySpan<byte> Take(ulong n)
+        {
+            if (n > (ulong)(limit - position))
+            {
+                throw new LudwiegException(position, "value exceeds message boundaries");
+            }
+            var result = data.Slice(position, (int)n);
+            position += (int)n;
+            return result;
+        }
+
+        public ulong ReadSize()
+        {
+            var offset = position;
+            var width = Take(1)[0];
+            switch (width)
+            {
+                case 1: return Take(1)[0];
+                case 2: return BinaryPrimitives.ReadUInt16LittleEndian(Take(2));
+                case 4: return BinaryPrimitives.ReadUInt32LittleEndian(Take(4));
+                case 8: return BinaryPrimitives.ReadUInt64LittleEndian(Take(8));
+            }
+            throw new LudwiegException(offset, "invalid size width " + width);
+        }
+
+        /// <summary>
+        /// Begin reads the type byte of a value, returning false when the
+        /// value is empty. Missing trailing values are also considered empty.
+        /// </summary>
+        private bool Begin(LudwiegType t)
+        {
+            if (position >= limit)
+            {
+                return false;
+            }
+            var offset = position;
+            var b = Take(1)[0];
+            if ((b & ~FlagEmpty) != (byte)t)
+            {
+                throw new LudwiegException(offset, "expected " + t + ", found " + (LudwiegType)(b & ~FlagEmpty));
+            }
+            return (b & FlagEmpty) == 0;
+        }
+
+        /// <summary>
+        /// Body returns a reader limited to the next value, advancing past it
+        /// </summary>
+        private LudwiegReader Body()
+        {
+            var size = ReadSize();
+            var start = position;
+            Take(size);
+            return new LudwiegReader(data, start, position);
+        }
+
+        public byte? ReadUInt8()
+        {
+            if (!Begin(LudwiegType.UInt8))
+            {
+                return null;
+            }
+            return Take(1)[0];
+        }
+
+        public uint? ReadUInt32()
+        {
+            if (!Begin(LudwiegType.UInt32))
+            {
+                return null;
+            }
+            return BinaryPrimitives.ReadUInt32LittleEndian(Take(4));
+        }
+
+        public ulong? ReadUInt64()
+        {
+            if (!Begin(LudwiegType.UInt64))
+            {
+                return null;
+            }
+            return BinaryPrimitives.ReadUInt64LittleEndian(Take(8));
+        }
+
+        public double? ReadDouble()
+        {
+            if (!Begin(LudwiegType.Double))
+            {
+                return null;
+            }
+            return BitConverter.Int64BitsToDouble(BinaryPrimitives.ReadInt64LittleEndian(Take(8)));
+        }
+
+        public string ReadString()
+        {
+            if (!Begin(LudwiegType.String))
+            {
+                return null;
+            }
+            var size = ReadSize();
+            var offset = position;
+            try
+            {
+                return new System.Text.UTF8Encoding(false, true).GetString(Take(size));
+            }
+            catch (ArgumentException)
+            {
+                throw new LudwiegException(offset, "invalid UTF-8 string");
+            }
+        }
+
+        public byte[] ReadBlob()
+        {
+            if (!Begin(LudwiegType.Blob))
+            {
+                return null;
+            }
+            return Take(ReadSize()).ToArray();
+        }
+
+        public bool? ReadBool()
+        {
+            if (!Begin(LudwiegType.Bool))
+            {
+                return null;
+            }
+            return Take(1)[0] != 0;
+        }
+
+        public Guid? ReadUUID()
+        {
+            if (!Begin(LudwiegType.UUID))
+            {
+                return null;
+            }
+            return LudwiegSerializer.GuidFromBytes(Take(16));
+        }
+
+        public ulong? ReadDynInt()
+        {
+            if (!Begin(LudwiegType.DynInt))
+            {
+                return null;
+            }
+            return ReadSize();
+        }
+
+        public LudwiegAny ReadAny()
+        {
+            if (!Begin(LudwiegType.Any))
+            {
+                return null;
+            }
+            if (position >= limit)
+            {
+                throw new LudwiegException(position, "value exceeds message boundaries");
+            }
+            var offset = position;
+            var t = (LudwiegType)(data[position] & ~FlagEmpty);
+            object value;
+            switch (t)
+            {
+                case LudwiegType.UInt8: value = ReadUInt8(); break;
+                case LudwiegType.UInt32: value = ReadUInt32(); break;
+                case LudwiegType.UInt64: value = ReadUInt64(); break;
+                case LudwiegType.Double: value = ReadDouble(); break;
+                case LudwiegType.String: value = ReadString(); break;
+                case LudwiegType.Blob: value = ReadBlob(); break;
+                case LudwiegType.Bool: value = ReadBool(); break;
+                case LudwiegType.UUID: value = ReadUUID(); break;
+                case LudwiegType.DynInt: value = ReadDynInt(); break;
+                case LudwiegType.Array: value = ReadArray((ref LudwiegReader er) => er.ReadAny()); break;
+                default: throw new LudwiegException(offset, t + " values cannot be held by any");
+            }
+            return value == null ? null : new LudwiegAny(t, value);
+        }
+
+        /// <summary>
+        /// ReadArray reads an array, using element to read each of its items
+        /// </summary>
+        public List<T> ReadArray<T>(LudwiegElementReader<T> element)
+        {
+            if (!Begin(LudwiegType.Array))
+            {
+                return null;
+            }
+            var body = Body();
+            var count = body.ReadSize();
+            var items = new List<T>();
+            for (ulong i = 0; i < count; i++)
+            {
+                if (body.position >= body.limit)
+                {
+                    throw new LudwiegException(body.position, "value exceeds message boundaries");
+                }
+                items.Add(element(ref body));
+            }
+            return items;
+        }
+
+        public T ReadStruct<T>() where T : class, ILudwiegCodable, new()
+        {
+            if (!Begin(LudwiegType.Struct))
+            {
+                return null;
+            }
+            var body = Body();
+            var value = new T();
+            value.Decode(ref body);
+            return value;
+        }
+    }
+
+    public static class LudwiegSerializer
+    {
+        public static readonly byte[] Magic = { {{.magic}} };
+        public const byte ProtocolVersion = {{.version}};
+
+        /// <summary>
+        /// CreatePackage returns a new instance of the package identified by
+        /// id, or null when the identifier is unknown
+        /// </summary>
+        public static ILudwiegPackage CreatePackage(byte id)
+        {
+            switch (id)
+            {
+{{.cases}}                default: return null;
+            }
+        }
+
+        /// <summary>
+        /// Decode decodes the message at the beginning of data, returning its
+        /// package along with its message identifier and the amount of bytes
+        /// it used. LudwiegShortBufferException is thrown when data does not
+        /// contain a complete message yet.
+        /// </summary>
+        public static ILudwiegPackage Decode(ReadOnlySpan<byte> data, out byte messageId, out int length)
+        {
+            var headerSize = Magic.Length + 3;
+            if (data.Length <= headerSize)
+            {
+                throw new LudwiegShortBufferException();
+            }
+            if (!data.Slice(0, Magic.Length).SequenceEqual(Magic))
+            {
+                throw new LudwiegException(0, "invalid magic");
+            }
+            if (data[Magic.Length] != ProtocolVersion)
+            {
+                throw new LudwiegException(Magic.Length, "unsupported protocol version 0x" + data[Magic.Length].ToString("X2"));
+            }
+
+            var width = data[headerSize];
+            if (width != 1 && width != 2 && width != 4 && width != 8)
+            {
+                throw new LudwiegException(headerSize, "invalid size width " + width);
+            }
+            var start = headerSize + 1 + width;
+            if (data.Length < start)
+            {
+                throw new LudwiegShortBufferException();
+            }
+            var size = new LudwiegReader(data, headerSize, start).ReadSize();
+            if (size > (ulong)(data.Length - start))
+            {
+                throw new LudwiegShortBufferException();
+            }
+
+            var id = data[Magic.Length + 2];
+            var package = CreatePackage(id);
+            if (package == null)
+            {
+                throw new LudwiegException(Magic.Length + 2, "unknown package 0x" + id.ToString("X2"));
+            }
+            messageId = data[Magic.Length + 1];
+            length = start + (int)size;
+            var reader = new LudwiegReader(data, start, length);
+            package.Decode(ref reader);
+            return package;
+        }
+
+        /// <summary>
+        /// Encode serializes a package into a message with the given
+        /// identifier
+        /// </summary>
+        public static byte[] Encode(byte messageId, ILudwiegPackage package)
+        {
+            var payload = new LudwiegWriter();
+            package.Encode(payload);
+            var body = payload.ToArray();
+
+            var w = new LudwiegWriter();
+            w.WriteRaw(Magic);
+            w.WriteRaw(new[] { ProtocolVersion, messageId, package.PackageId });
+            w.WriteSize((ulong)body.Length);
+            w.WriteRaw(body);
+            return w.ToArray();
+        }
+
+        // Guid stores its first three groups as little-endian, while UUIDs
+        // are transmitted in network order.
+        internal static Guid GuidFromBytes(ReadOnlySpan<byte> data)
+        {
+            var b = data.ToArray();
+            Array.Reverse(b, 0, 4);
+            Array.Reverse(b, 4, 2);
+            Array.Reverse(b, 6, 2);
+            return new Guid(b);
+        }
+
+        internal static byte[] GuidToBytes(Guid value)
+        {
+            var b = value.ToByteArray();
+            Array.Reverse(b, 0, 4);
+            Array.Reverse(b, 4, 2);
+            Array.Reverse(b, 6, 2);
+            return b;
+        }
+    }
+}
+`
+
+const csharpIntegrationSteps = `You just generated C# sources. No external library is required: {{.file}}
+implements the wire format using Span<byte>, and requires C# 8 (Unity 2021 or
+later, or .NET Core 2.1 or later). Add the output folder to your project, and
+use the {{.namespace}} namespace:
+
+     {{.csUsing}}
+
+Messages are decoded and encoded through {{.serializer}}:
+
+     {{.csUsage}}
+`
@@ -0,0 +1,112 @@
+package langs
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// csharpReservedSource declares packages and structures named after runtime
+// and system types
+const csharpReservedSource = `package ludwieg_serializer {
+    id 0x01
+    string text
+}
+
+package system {
+    id 0x02
+    @guid value
+    @list[*] items
+    uuid guid
+    struct guid {
+        uuid value
+    }
+    struct list {
+        string[*] list
+    }
+}
+`
+
+// csharpProject builds the round-trip check along with generated sources
+const csharpProject = `<Project Sdk="Microsoft.NET.Sdk">
+  <PropertyGroup>
+    <OutputType>Exe</OutputType>
+    <TargetFramework>net8.0</TargetFramework>
+    <TreatWarningsAsErrors>true</TreatWarningsAsErrors>
+  </PropertyGroup>
+</Project>
+`
+
+// csharpRoundTrip decodes every vector using generated sources, and checks
+// that encoding the decoded package again yields the very same bytes
+const csharpRoundTrip = `using System;
+using System.Linq;
+using Sample.Protocol;
+
+var vectors = new (string Name, string Hex)[]
+{
+%VECTORS%};
+
+var failures = 0;
+foreach (var (name, hex) in vectors)
+{
+    try
+    {
+        var data = Convert.FromHexString(hex);
+        var package = LudwiegSerializer.Decode(data, out var messageId, out var length);
+        var encoded = LudwiegSerializer.Encode(messageId, package);
+        if (length != data.Length || !encoded.SequenceEqual(data))
+        {
+            Console.WriteLine($"{name}: encoded message differs");
+            failures++;
+        }
+    }
+    catch (Exception e)
+    {
+        Console.WriteLine($"{name}: {e.Message}");
+        failures++;
+    }
+}
+return failures > 0 ? 1 : 0;
+`
+
+func TestCSharpRoundTrip(t *testing.T) {
+	dotnet := testTool(t, "dotnet")
+	packages := testPackages(t, vectorSource, recursiveSource, csharpReservedSource)
+	dir := testCompile(t, CSharp{}, "Sample.Protocol", "", packages)
+	defer os.RemoveAll(dir)
+
+	if err := ioutil.WriteFile(filepath.Join(dir, "check.csproj"), []byte(csharpProject), 0644); err != nil {
+		t.Fatal(err)
+	}
+	testRoundTrip(t, dir, &(*packages)[0], "Check.cs", csharpRoundTrip, func(v testVector) string {
+		return fmt.Sprintf("    (%q, %q),\n", v.Name, v.Hex)
+	}, []string{dotnet, "run"})
+}
+
+func TestCSharpRecursiveStructs(t *testing.T) {
+	dir := testCompile(t, CSharp{}, "Sample.Protocol", "", testPackages(t, recursiveSource))
+	defer os.RemoveAll(dir)
+
+	testContains(t, "Tree.cs", testOutput(t, dir, "Tree.cs"),
+		"public Tree.Node Parent { get; set; }",
+		"public List<Tree.Node> Children { get; set; }",
+		"public Tree.A Back { get; set; }")
+}
+
+func TestCSharpReservedNames(t *testing.T) {
+	dir := testCompile(t, CSharp{}, "Sample.Protocol", "", testPackages(t, csharpReservedSource))
+	defer os.RemoveAll(dir)
+
+	testContains(t, "LudwiegSerializer.cs", testOutput(t, dir, "LudwiegSerializer.cs"),
+		"public static class LudwiegSerializer",
+		"case 0x01: return new LudwiegSerializerPackage();")
+	testContains(t, "LudwiegSerializerPackage.cs", testOutput(t, dir, "LudwiegSerializerPackage.cs"),
+		"public class LudwiegSerializerPackage : ILudwiegPackage")
+	testContains(t, "SystemPackage.cs", testOutput(t, dir, "SystemPackage.cs"),
+		"public SystemPackage.GuidStruct Value { get; set; }",
+		"public Guid? Guid { get; set; }",
+		"public class ListStruct : ILudwiegCodable")
+}
@@ -0,0 +1,311 @@
+package langs
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/alecthomas/chroma/quick"
+	"github.com/logrusorgru/aurora"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/ludwieg/ludco/codec"
+	"github.com/ludwieg/ludco/models"
+)
+
+// dartReserved lists reserved words and types that cannot be used as field
+// names, along with members declared by generated classes
+var dartReserved = map[string]bool{
+	"assert": true, "break": true, "case": true, "catch": true, "class": true,
+	"const": true, "continue": true, "default": true, "do": true, "else": true,
+	"enum": true, "extends": true, "false": true, "final": true,
+	"finally": true, "for": true, "if": true, "in": true, "is": true,
+	"new": true, "null": true, "rethrow": true, "return": true, "super": true,
+	"switch": true, "this": true, "throw": true, "true": true, "try": true,
+	"var": true, "void": true, "while": true, "with": true,
+	"bool": true, "double": true, "dynamic": true, "int": true,
+	"decode": true, "encode": true, "ludwieg": true, "ludwiegId": true,
+	"ludwiegType": true, "packageId": true, "hashCode": true,
+	"runtimeType": true, "toString": true, "noSuchMethod": true,
+}
+
+// dartReservedTypes lists core types referred to by generated sources, which
+// generated classes must not shadow
+var dartReservedTypes = map[string]bool{
+	"BigInt": true, "Deprecated": true, "List": true, "String": true,
+	"Uint8List": true,
+}
+
+type Dart struct {
+	out string
+
+	// classes maps structures to the name of their generated class
+	classes map[*models.Struct]string
+}
+
+func (c Dart) Compile(in, out, pkgName, prefix string, packages *models.PackageList) {
+	log.Infof("Initialising %s compiler", aurora.Blue("Dart"))
+	if pkgName != "" {
+		log.Warn("Ignoring unnecessary --package option")
+	}
+	if prefix != "" {
+		log.Warn("Ignoring unnecessary --prefix option.")
+	}
+	c.out = out
+	c.classes = map[*models.Struct]string{}
+
+	var magic []string
+	for _, b := range codec.Magic {
+		magic = append(magic, fmt.Sprintf("0x%02x", b))
+	}
+	c.output("ludwieg", processTemplate("dartRuntime", dartRuntime, templateData{
+		"magic":   strings.Join(magic, ", "),
+		"version": fmt.Sprintf("0x%02x", codec.ProtocolVersion),
+	}))
+
+	for i := range *packages {
+		c.writePackage(&(*packages)[i])
+	}
+	c.writeRegistry(packages)
+
+	log.Info("Succeeded")
+	fmt.Println()
+	fmt.Println(c.integrationInstructions())
+}
+
+func (c Dart) output(name string, contents []byte) {
+	log.Infof("Writing %s/%s", aurora.Magenta(filepath.Base(c.out)), aurora.Magenta(name+".dart"))
+	err := ioutil.WriteFile(filepath.Join(c.out, name+".dart"), contents, 0644)
+	if err != nil {
+		log.Errorf("Operation failed: %s", err)
+		os.Exit(1)
+	}
+}
+
+// dartFile returns the name of the library of a package, suffixing names
+// taken by the runtime and registry libraries with an underscore
+func dartFile(name string) string {
+	if name == "ludwieg" || name == "registry" {
+		return name + "_"
+	}
+	return name
+}
+
+// dartClassName suffixes names of core types with an underscore
+func dartClassName(name string) string {
+	if dartReservedTypes[name] {
+		return name + "_"
+	}
+	return name
+}
+
+func (c Dart) writePackage(p *models.Package) {
+	name := dartClassName(convertToPascalCase(p.Name))
+	c.registerStructs(convertToPascalCase(p.Name), p.Structs)
+
+	header := strings.Join([]string{
+		fmt.Sprintf("  static const int ludwiegId = %s;", p.Identifier),
+		fmt.Sprintf("  static const ludwieg.PackageType ludwiegType = ludwieg.PackageType(ludwiegId, %s.decode);", name),
+		"",
+		"  @override",
+		"  int get packageId => ludwiegId;",
+		"",
+		"",
+	}, "\n")
+	imports := map[string]bool{}
+	classes := []string{c.generateClass(name, "ludwieg.Package", header, p.Scope(), p.Fields, imports)}
+	c.generateStructs(p.Scope(), p.Structs, &classes, imports)
+
+	var importList []string
+	for i := range imports {
+		importList = append(importList, fmt.Sprintf("import '%s';\n", i))
+	}
+	sort.Strings(importList)
+	if len(importList) > 0 {
+		importList = append(importList, "\n")
+	}
+
+	c.output(dartFile(p.Name), processTemplate("dartPackage", dartPackage, templateData{
+		"imports": strings.Join(importList, ""),
+		"classes": strings.Join(classes, ""),
+	}))
+}
+
+// registerStructs assigns class names to structures before classes are
+// generated, as fields may reference structures declared after them
+func (c Dart) registerStructs(prefix string, sArr []models.Struct) {
+	for i := range sArr {
+		s := &sArr[i]
+		name := prefix + convertToPascalCase(s.Name)
+		c.classes[s] = dartClassName(name)
+		c.registerStructs(name, s.Structs)
+	}
+}
+
+func (c Dart) generateStructs(scope *models.Scope, sArr []models.Struct, classes *[]string, imports map[string]bool) {
+	for i := range sArr {
+		s := &sArr[i]
+		inner := &models.Scope{Structs: s.Structs, Parent: scope}
+		*classes = append(*classes, c.generateClass(c.classes[s], "ludwieg.Codable", "", inner, s.Fields, imports))
+		c.generateStructs(inner, s.Structs, classes, imports)
+	}
+}
+
+func (c Dart) generateClass(name, base, header string, scope *models.Scope, fArr []models.Field, imports map[string]bool) string {
+	var fields, params, decode, encode []string
+	for _, f := range fArr {
+		field, param, dec, enc := c.generateField(scope, &f, imports)
+		fields = append(fields, field)
+		params = append(params, param)
+		decode = append(decode, "    "+dec+"\n")
+		encode = append(encode, "    "+enc+"\n")
+	}
+	if len(fields) > 0 {
+		fields = append(fields, "")
+	}
+
+	paramList := ""
+	if len(params) > 0 {
+		paramList = "{" + strings.Join(params, ", ") + "}"
+	}
+
+	return string(processTemplate("dartClass", dartClass, templateData{
+		"name":   name,
+		"base":   base,
+		"header": header,
+		"fields": strings.Join(fields, "\n"),
+		"params": paramList,
+		"decode": strings.Join(decode, ""),
+		"encode": strings.Join(encode, ""),
+	}))
+}
+
+// dartType returns the Dart type holding values of a native type, along with
+// the suffix of the reader and writer methods handling it
+func dartType(t models.NativeType) (string, string) {
+	switch t {
+	case models.TypeUint8, models.TypeByte:
+		return "int", "Uint8"
+	case models.TypeUint32:
+		return "int", "Uint32"
+	case models.TypeUint64:
+		return "BigInt", "Uint64"
+	case models.TypeDouble:
+		return "double", "Double"
+	case models.TypeString:
+		return "String", "String"
+	case models.TypeBlob:
+		return "Uint8List", "Blob"
+	case models.TypeBool:
+		return "bool", "Bool"
+	case models.TypeUUID:
+		return "String", "UUID"
+	case models.TypeAny:
+		return "ludwieg.AnyValue", "Any"
+	case models.TypeDynInt:
+		return "BigInt", "DynInt"
+	}
+	log.Fatalf("BUG: Cannot coerce unknown type %#v to native type.", t)
+	return "", ""
+}
+
+// generateField returns the declaration of a field, its constructor
+// parameter, and statements decoding and encoding it
+func (c Dart) generateField(scope *models.Scope, f *models.Field, imports map[string]bool) (string, string, string, string) {
+	name := convertToCamelCase(f.Name)
+	if dartReserved[name] {
+		name += "Value"
+	}
+
+	var t, read, write string
+	if f.Type.Source == models.SourceUser {
+		s, _, ok := scope.Resolve(f.Type.CustomType)
+		if !ok {
+			log.Errorf("BUG: Cannot resolve structure %s referenced by %s", f.Type.CustomType, f.Name)
+			os.Exit(1)
+		}
+		t = c.classes[s]
+		read = "r.readStruct(" + t + ".decode)"
+		write = "w.writeStruct(%s)"
+	} else {
+		var kind string
+		t, kind = dartType(f.Type.NativeType)
+		read = "r.read" + kind + "()"
+		write = "w.write" + kind + "(%s)"
+		if f.Type.NativeType == models.TypeBlob {
+			imports["dart:typed_data"] = true
+		}
+	}
+
+	var dec, enc string
+	if f.IsArray() {
+		size := "null"
+		if f.Size != "*" {
+			size = f.Size
+		}
+		dec = fmt.Sprintf("v.%s = r.readArray((r) => %s);", name, read)
+		enc = fmt.Sprintf("w.writeArray<%s>(this.%s, %s, (w, v) => %s);", t, name, size, fmt.Sprintf(write, "v"))
+		t = "List<" + t + "?>"
+	} else {
+		dec = fmt.Sprintf("v.%s = %s;", name, read)
+		enc = fmt.Sprintf(write, "this."+name) + ";"
+	}
+
+	decl := fmt.Sprintf("  %s? %s;", t, name)
+	if f.HasAttribute(models.AttributeDeprecated) {
+		decl = "  @Deprecated('Deprecated by its Ludwieg definition')\n" + decl
+	}
+	return decl, "this." + name, dec, enc
+}
+
+func (c Dart) writeRegistry(pList *models.PackageList) {
+	var imports, exports, packages []string
+	for _, p := range *pList {
+		imports = append(imports, fmt.Sprintf("import '%s.dart';", dartFile(p.Name)))
+		exports = append(exports, fmt.Sprintf("export '%s.dart';", dartFile(p.Name)))
+		packages = append(packages, fmt.Sprintf("  %s.ludwiegType,\n", dartClassName(convertToPascalCase(p.Name))))
+	}
+
+	c.output("registry", processTemplate("dartRegistry", dartRegistry, templateData{
+		"imports":  strings.Join(imports, "\n"),
+		"exports":  strings.Join(exports, "\n"),
+		"packages": strings.Join(packages, ""),
+	}))
+}
+
+func (c Dart) formatCode(code string) string {
+	var buf bytes.Buffer
+	err := quick.Highlight(&buf, code, "dart", "terminal", "pygments")
+	if err != nil {
+		log.Fatalf("BUG: Error processing Dart source: %s", err)
+	}
+
+	return string(buf.Bytes())
+}
+
+func (c Dart) integrationInstructions() string {
+	usage := strings.Join([]string{
+		"final message = registry.decode(data);",
+		"    final encoded = registry.encode(message.messageId, message.package);",
+	}, "\n")
+
+	data := templateData{
+		"dependencies": aurora.Bold("Dependencies"),
+		"integration":  aurora.Bold("Integration"),
+		"usage":        aurora.Bold("Usage"),
+
+		"sdk":      aurora.Magenta("2.17"),
+		"runtime":  aurora.Magenta("ludwieg.dart"),
+		"registry": aurora.Magenta("registry.dart"),
+		"lib":      aurora.Magenta("lib/" + filepath.Base(c.out)),
+
+		"dartImport": c.formatCode("import '" + filepath.Base(c.out) + "/registry.dart';"),
+		"dartUsage":  c.formatCode(usage),
+	}
+
+	return string(processTemplate("dartIntegration", dartIntegrationSteps, data))
+}
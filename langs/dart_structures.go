@@ -0,0 +1,594 @@
+package langs
+
+const dartPackage = `// WARNING: Automatically generated by ludco. DO NOT EDIT.
+// ignore_for_file: deprecated_member_use_from_same_package, unnecessary_this
+
+{{.imports}}import 'ludwieg.dart' as ludwieg;
+{{.classes}}`
+
+const dartClass = `
+class {{.name}} implements {{.base}} {
+{{.header}}{{.fields}}
+  {{.name}}({{.params}});
+
+  static {{.name}} decode(ludwieg.Reader r) {
+    final v = {{.name}}();
+{{.decode}}    return v;
+  }
+
+  @override
+  void encode(ludwieg.Writer w) {
+{{.encode}}  }
+}
+`
+
+const dartRegistry = `// WARNING: Automatically generated by ludco. DO NOT EDIT.
+
+import 'ludwieg.dart' as ludwieg;
+{{.imports}}
+
+{{.exports}}
+
+/// registry holds every generated package
+final ludwieg.Registry registry = ludwieg.Registry([
+{{.packages}}]);
+`
+
+const dartRuntime = `// WARNING: Automatically generated by ludco. DO NOT EDIT.
+//
+// Runtime used by generated packages to encode and decode values using the
+// Ludwieg wire format. It has no dependencies besides the Dart SDK, and
+// avoids 64-bit integer operations so it also runs on the web.
+
+import 'dart:convert';
+import 'dart:typed_data';
+
+const List<int> magic = [{{.magic}}];
+const int protocolVersion = {{.version}};
+const int _flagEmpty = 0x80;
+
+enum ProtocolType {
+  uint8(0x01),
+  uint32(0x02),
+  uint64(0x03),
+  double(0x04),
+  string(0x05),
+  blob(0x06),
+  bool(0x07),
+  uuid(0x08),
+  any(0x09),
+  array(0x0a),
+  struct(0x0b),
+  dynInt(0x0c);
+
+  const ProtocolType(this.code);
+
+  final int code;
+
+  static ProtocolType? fromCode(int code) {
+    for (final t in values) {
+      if (t.code == code) {
+        return t;
+      }
+    }
+    return null;
+  }
+}
+
+/// AnyValue holds values of any fields, along with their type
+class AnyValue {
+  final ProtocolType type;
+  final Object value;
+
+  /// AnyValue creates a value of a given type. Values must use the Dart type
+  /// generated for fields of that type, and lists of AnyValue for arrays.
+  const AnyValue(this.type, this.value);
+}
+
+abstract class Codable {
+  void encode(Writer w);
+}
+
+abstract class Package implements Codable {
+  int get packageId;
+}
+
+/// PackageType describes a generated package to a Registry
+class PackageType {
+  final int id;
+  final Package Function(Reader r) decode;
+
+  const PackageType(this.id, this.decode);
+}
+
+class Message {
+  final int messageId;
+  final Package package;
+
+  /// length holds the amount of bytes used by the message
+  final int length;
+
+  const Message(this.messageId, this.package, this.length);
+}
+
+/// DecodeException indicates that data does not represent a valid message
+class DecodeException implements Exception {
+  final int offset;
+  final String reason;
+
+  DecodeException(this.offset, this.reason);
+
+  @override
+  String toString() => 'DecodeException: offset $offset: $reason';
+}
+
+/// ShortBufferException indicates that data does not contain a complete
+/// message yet
+class ShortBufferException implements Exception {
+  @override
+  String toString() => 'ShortBufferException: buffer does not contain a complete message';
+}
+
+final BigInt _maxUint64 = (BigInt.one << 64) - BigInt.one;
+
+Uint8List _parseUUID(String value) {
+  final hex = value.replaceAll('-', '');
+  if (!RegExp(r'^[0-9a-fA-F]{32}$').hasMatch(hex)) {
+    throw FormatException('invalid UUID', value);
+  }
+  final bytes = Uint8List(16);
+  for (var i = 0; i < 16; i++) {
+    bytes[i] = int.parse(hex.substring(i * 2, i * 2 + 2), radix: 16);
+  }
+  return bytes;
+}
+
+String _formatUUID(Uint8List bytes) {
+  final hex = bytes.map((b) => b.toRadixString(16).padLeft(2, '0')).join();
+  return '${hex.substring(0, 8)}-${hex.substring(8, 12)}-${hex.substring(12, 16)}-'
+      '${hex.substring(16, 20)}-${hex.substring(20)}';
+}
+
+class Writer {
+  final BytesBuilder _buf = BytesBuilder(copy: false);
+
+  /// bytes returns a copy of the data written so far
+  Uint8List bytes() => _buf.toBytes();
+
+  void writeByte(int b) => _buf.addByte(b);
+
+  void writeBytes(List<int> data) => _buf.add(data);
+
+  void _writeEmpty(ProtocolType t) => writeByte(t.code | _flagEmpty);
+
+  void _writeLittleEndian(BigInt value, int width) {
+    for (var i = 0; i < width; i++) {
+      writeByte(((value >> (i * 8)) & BigInt.from(0xff)).toInt());
+    }
+  }
+
+  /// writeBigSize writes a dynamic size, using the smallest width able to
+  /// hold it
+  void writeBigSize(BigInt value) {
+    if (value.isNegative || value > _maxUint64) {
+      throw ArgumentError.value(value, 'value', 'exceeds uint64 range');
+    }
+    var width = 8;
+    if (value.bitLength <= 8) {
+      width = 1;
+    } else if (value.bitLength <= 16) {
+      width = 2;
+    } else if (value.bitLength <= 32) {
+      width = 4;
+    }
+    writeByte(width);
+    _writeLittleEndian(value, width);
+  }
+
+  void writeSize(int value) => writeBigSize(BigInt.from(value));
+
+  void writeUint8(int? value) {
+    if (value == null) {
+      return _writeEmpty(ProtocolType.uint8);
+    }
+    writeByte(ProtocolType.uint8.code);
+    writeByte(value);
+  }
+
+  void writeUint32(int? value) {
+    if (value == null) {
+      return _writeEmpty(ProtocolType.uint32);
+    }
+    writeByte(ProtocolType.uint32.code);
+    writeBytes((ByteData(4)..setUint32(0, value, Endian.little)).buffer.asUint8List());
+  }
+
+  void writeUint64(BigInt? value) {
+    if (value == null) {
+      return _writeEmpty(ProtocolType.uint64);
+    }
+    if (value.isNegative || value > _maxUint64) {
+      throw ArgumentError.value(value, 'value', 'exceeds uint64 range');
+    }
+    writeByte(ProtocolType.uint64.code);
+    _writeLittleEndian(value, 8);
+  }
+
+  void writeDouble(double? value) {
+    if (value == null) {
+      return _writeEmpty(ProtocolType.double);
+    }
+    writeByte(ProtocolType.double.code);
+    writeBytes((ByteData(8)..setFloat64(0, value, Endian.little)).buffer.asUint8List());
+  }
+
+  void writeString(String? value) {
+    if (value == null) {
+      return _writeEmpty(ProtocolType.string);
+    }
+    final data = utf8.encode(value);
+    writeByte(ProtocolType.string.code);
+    writeSize(data.length);
+    writeBytes(data);
+  }
+
+  void writeBlob(Uint8List? value) {
+    if (value == null) {
+      return _writeEmpty(ProtocolType.blob);
+    }
+    writeByte(ProtocolType.blob.code);
+    writeSize(value.length);
+    writeBytes(value);
+  }
+
+  void writeBool(bool? value) {
+    if (value == null) {
+      return _writeEmpty(ProtocolType.bool);
+    }
+    writeByte(ProtocolType.bool.code);
+    writeByte(value ? 1 : 0);
+  }
+
+  void writeUUID(String? value) {
+    if (value == null) {
+      return _writeEmpty(ProtocolType.uuid);
+    }
+    writeByte(ProtocolType.uuid.code);
+    writeBytes(_parseUUID(value));
+  }
+
+  void writeDynInt(BigInt? value) {
+    if (value == null) {
+      return _writeEmpty(ProtocolType.dynInt);
+    }
+    writeByte(ProtocolType.dynInt.code);
+    writeBigSize(value);
+  }
+
+  void writeAny(AnyValue? value) {
+    if (value == null) {
+      return _writeEmpty(ProtocolType.any);
+    }
+    writeByte(ProtocolType.any.code);
+    final v = value.value;
+    switch (value.type) {
+      case ProtocolType.uint8:
+        return writeUint8(v as int);
+      case ProtocolType.uint32:
+        return writeUint32(v as int);
+      case ProtocolType.uint64:
+        return writeUint64(v as BigInt);
+      case ProtocolType.double:
+        return writeDouble(v as double);
+      case ProtocolType.string:
+        return writeString(v as String);
+      case ProtocolType.blob:
+        return writeBlob(v as Uint8List);
+      case ProtocolType.bool:
+        return writeBool(v as bool);
+      case ProtocolType.uuid:
+        return writeUUID(v as String);
+      case ProtocolType.dynInt:
+        return writeDynInt(v as BigInt);
+      case ProtocolType.array:
+        return writeArray<AnyValue>(v as List<AnyValue?>, null, (w, v) => w.writeAny(v));
+      default:
+        throw ArgumentError('${value.type.name} values cannot be held by any');
+    }
+  }
+
+  /// writeArray writes items using element for each of them. When size is
+  /// provided, arrays holding more items are rejected.
+  void writeArray<T>(List<T?>? items, int? size, void Function(Writer w, T? v) element) {
+    if (items == null) {
+      return _writeEmpty(ProtocolType.array);
+    }
+    if (size != null && items.length > size) {
+      throw ArgumentError('array holds ${items.length} items, exceeding its size of $size');
+    }
+    final body = Writer();
+    body.writeSize(items.length);
+    for (final item in items) {
+      element(body, item);
+    }
+    final data = body.bytes();
+    writeByte(ProtocolType.array.code);
+    writeSize(data.length);
+    writeBytes(data);
+  }
+
+  void writeStruct(Codable? value) {
+    if (value == null) {
+      return _writeEmpty(ProtocolType.struct);
+    }
+    final body = Writer();
+    value.encode(body);
+    final data = body.bytes();
+    writeByte(ProtocolType.struct.code);
+    writeSize(data.length);
+    writeBytes(data);
+  }
+}
+
+class Reader {
+  final Uint8List _data;
+  final ByteData _view;
+  int position;
+  final int _limit;
+
+  Reader(this._data, [this.position = 0, int? limit])
+      : _view = ByteData.sublistView(_data),
+        _limit = limit ?? _data.length;
+
+  int _take(int n) {
+    if (n > _limit - position) {
+      throw DecodeException(position, 'value exceeds message boundaries');
+    }
+    final offset = position;
+    position += n;
+    return offset;
+  }
+
+  int _byte() => _data[_take(1)];
+
+  BigInt _readLittleEndian(int width) {
+    final offset = _take(width);
+    var value = BigInt.zero;
+    for (var i = width - 1; i >= 0; i--) {
+      value = (value << 8) | BigInt.from(_data[offset + i]);
+    }
+    return value;
+  }
+
+  BigInt readBigSize() {
+    final offset = position;
+    final width = _byte();
+    if (width != 1 && width != 2 && width != 4 && width != 8) {
+      throw DecodeException(offset, 'invalid size width $width');
+    }
+    return _readLittleEndian(width);
+  }
+
+  int readSize() {
+    final offset = position;
+    final size = readBigSize();
+    if (size > BigInt.from(_limit - position)) {
+      throw DecodeException(offset, 'value exceeds message boundaries');
+    }
+    return size.toInt();
+  }
+
+  /// _begin reads the type byte of a value, returning false when the value
+  /// is empty. Missing trailing values are also considered empty.
+  bool _begin(ProtocolType t) {
+    if (position >= _limit) {
+      return false;
+    }
+    final offset = position;
+    final b = _byte();
+    if (b & ~_flagEmpty != t.code) {
+      final found = ProtocolType.fromCode(b & ~_flagEmpty)?.name ?? 'unknown type';
+      throw DecodeException(offset, 'expected ${t.name}, found $found');
+    }
+    return b & _flagEmpty == 0;
+  }
+
+  /// _body returns a reader limited to the next value, advancing past it
+  Reader _body() {
+    final size = readSize();
+    final start = _take(size);
+    return Reader(_data, start, start + size);
+  }
+
+  int? readUint8() => _begin(ProtocolType.uint8) ? _byte() : null;
+
+  int? readUint32() => _begin(ProtocolType.uint32) ? _view.getUint32(_take(4), Endian.little) : null;
+
+  BigInt? readUint64() => _begin(ProtocolType.uint64) ? _readLittleEndian(8) : null;
+
+  double? readDouble() => _begin(ProtocolType.double) ? _view.getFloat64(_take(8), Endian.little) : null;
+
+  String? readString() {
+    if (!_begin(ProtocolType.string)) {
+      return null;
+    }
+    final size = readSize();
+    final offset = _take(size);
+    try {
+      return utf8.decode(Uint8List.sublistView(_data, offset, offset + size));
+    } on FormatException {
+      throw DecodeException(offset, 'invalid UTF-8 string');
+    }
+  }
+
+  Uint8List? readBlob() {
+    if (!_begin(ProtocolType.blob)) {
+      return null;
+    }
+    final size = readSize();
+    final offset = _take(size);
+    return Uint8List.fromList(Uint8List.sublistView(_data, offset, offset + size));
+  }
+
+  bool? readBool() => _begin(ProtocolType.bool) ? _byte() != 0 : null;
+
+  String? readUUID() {
+    if (!_begin(ProtocolType.uuid)) {
+      return null;
+    }
+    final offset = _take(16);
+    return _formatUUID(Uint8List.sublistView(_data, offset, offset + 16));
+  }
+
+  BigInt? readDynInt() => _begin(ProtocolType.dynInt) ? readBigSize() : null;
+
+  AnyValue? readAny() {
+    if (!_begin(ProtocolType.any)) {
+      return null;
+    }
+    if (position >= _limit) {
+      throw DecodeException(position, 'value exceeds message boundaries');
+    }
+    final offset = position;
+    final t = ProtocolType.fromCode(_data[position] & ~_flagEmpty);
+    AnyValue? wrap(Object? value) => value == null ? null : AnyValue(t!, value);
+    switch (t) {
+      case ProtocolType.uint8:
+        return wrap(readUint8());
+      case ProtocolType.uint32:
+        return wrap(readUint32());
+      case ProtocolType.uint64:
+        return wrap(readUint64());
+      case ProtocolType.double:
+        return wrap(readDouble());
+      case ProtocolType.string:
+        return wrap(readString());
+      case ProtocolType.blob:
+        return wrap(readBlob());
+      case ProtocolType.bool:
+        return wrap(readBool());
+      case ProtocolType.uuid:
+        return wrap(readUUID());
+      case ProtocolType.dynInt:
+        return wrap(readDynInt());
+      case ProtocolType.array:
+        return wrap(readArray((r) => r.readAny()));
+      default:
+        throw DecodeException(offset, '${t?.name ?? 'unknown type'} values cannot be held by any');
+    }
+  }
+
+  /// readArray reads an array, using element to read each of its items
+  List<T?>? readArray<T>(T? Function(Reader r) element) {
+    if (!_begin(ProtocolType.array)) {
+      return null;
+    }
+    final body = _body();
+    final count = body.readBigSize();
+    final items = <T?>[];
+    for (var i = BigInt.zero; i < count; i += BigInt.one) {
+      if (body.position >= body._limit) {
+        throw DecodeException(body.position, 'value exceeds message boundaries');
+      }
+      items.add(element(body));
+    }
+    return items;
+  }
+
+  T? readStruct<T>(T Function(Reader r) decode) {
+    if (!_begin(ProtocolType.struct)) {
+      return null;
+    }
+    return decode(_body());
+  }
+}
+
+/// Registry decodes messages into the packages registered on it
+class Registry {
+  final Map<int, PackageType> _packages = {};
+
+  Registry([List<PackageType> types = const []]) {
+    types.forEach(register);
+  }
+
+  void register(PackageType type) {
+    _packages[type.id] = type;
+  }
+
+  /// decode decodes the message at the beginning of data. ShortBufferException
+  /// is thrown when data does not contain a complete message yet.
+  Message decode(Uint8List data) {
+    final headerSize = magic.length + 3;
+    if (data.length <= headerSize) {
+      throw ShortBufferException();
+    }
+    for (var i = 0; i < magic.length; i++) {
+      if (data[i] != magic[i]) {
+        throw DecodeException(0, 'invalid magic');
+      }
+    }
+    final version = data[magic.length];
+    if (version != protocolVersion) {
+      throw DecodeException(magic.length, 'unsupported protocol version $version');
+    }
+    final messageId = data[magic.length + 1];
+    final packageId = data[magic.length + 2];
+
+    final width = data[headerSize];
+    if (width != 1 && width != 2 && width != 4 && width != 8) {
+      throw DecodeException(headerSize, 'invalid size width $width');
+    }
+    final header = Reader(data, headerSize);
+    final int size;
+    try {
+      size = header.readSize();
+    } on DecodeException {
+      throw ShortBufferException();
+    }
+    final start = header.position;
+
+    final type = _packages[packageId];
+    if (type == null) {
+      throw DecodeException(magic.length + 2, 'unknown package $packageId');
+    }
+    final package = type.decode(Reader(data, start, start + size));
+    return Message(messageId, package, start + size);
+  }
+
+  /// encode serializes a package into a message
+  Uint8List encode(int messageId, Package package) {
+    final body = Writer();
+    package.encode(body);
+    final payload = body.bytes();
+    final w = Writer();
+    w.writeBytes(magic);
+    w.writeByte(protocolVersion);
+    w.writeByte(messageId);
+    w.writeByte(package.packageId);
+    w.writeSize(payload.length);
+    w.writeBytes(payload);
+    return w.bytes();
+  }
+}
+`
+
+const dartIntegrationSteps = `You just generated Dart sources. In order to use them you need to perform a few
+tasks.
+
+  1. {{.dependencies}}
+  Generated sources are null-safe and depend only on the Dart SDK, version
+  {{.sdk}} or later. {{.runtime}} holds the encoder and decoder used by them.
+
+  2. {{.integration}}
+  Copy output files to your project, for instance into {{.lib}}
+
+  3. {{.usage}}
+  {{.registry}} exports every package, and holds a registry already
+  aware of them:
+
+    {{.dartImport}}
+
+    ...
+
+    {{.dartUsage}}
+`
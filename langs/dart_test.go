@@ -0,0 +1,54 @@
+package langs
+
+import (
+	"os"
+	"testing"
+)
+
+func TestDartRecursiveStructs(t *testing.T) {
+	dir := testCompile(t, Dart{}, "", "", testPackages(t, recursiveSource))
+	defer os.RemoveAll(dir)
+
+	testContains(t, "tree.dart", testOutput(t, dir, "tree.dart"),
+		"class TreeNode implements ludwieg.Codable {",
+		"  TreeNode? parent;",
+		"  List<TreeNode?>? children;",
+		"  TreeA? back;")
+}
+
+func TestDartReservedNames(t *testing.T) {
+	dir := testCompile(t, Dart{}, "", "", testPackages(t, `
+package registry {
+    id 0x01
+    string text
+}
+
+package ludwieg {
+    id 0x02
+    string text
+}
+
+package string {
+    id 0x03
+    @list items
+    struct list {
+        string text
+    }
+}
+`))
+	defer os.RemoveAll(dir)
+
+	testContains(t, "registry.dart", testOutput(t, dir, "registry.dart"),
+		"import 'registry_.dart';",
+		"export 'ludwieg_.dart';",
+		"  Registry.ludwiegType,",
+		"  String_.ludwiegType,",
+		"final ludwieg.Registry registry")
+	testContains(t, "registry_.dart", testOutput(t, dir, "registry_.dart"), "class Registry implements ludwieg.Package {")
+	testContains(t, "ludwieg_.dart", testOutput(t, dir, "ludwieg_.dart"), "class Ludwieg implements ludwieg.Package {")
+	testContains(t, "ludwieg.dart", testOutput(t, dir, "ludwieg.dart"), "class Registry {")
+	testContains(t, "string.dart", testOutput(t, dir, "string.dart"),
+		"class String_ implements ludwieg.Package {",
+		"  StringList? items;",
+		"  String? text;")
+}
@@ -0,0 +1,353 @@
+package langs
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/alecthomas/chroma/quick"
+	"github.com/logrusorgru/aurora"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/ludwieg/ludco/codec"
+	"github.com/ludwieg/ludco/models"
+)
+
+// elixirReserved lists reserved words that cannot be used as variable names
+var elixirReserved = map[string]bool{
+	"after": true, "and": true, "catch": true, "do": true, "else": true,
+	"end": true, "false": true, "fn": true, "in": true, "nil": true,
+	"not": true, "or": true, "rescue": true, "true": true, "when": true,
+}
+
+// elixirSegment matches segments of namespaces provided through --package
+var elixirSegment = regexp.MustCompile("^[A-Za-z][A-Za-z0-9_]*$")
+
+// elixirNamespace converts each dot-separated segment of name to an alias,
+// such as my_app.proto to MyApp.Proto. It returns false when a segment cannot
+// be converted.
+func elixirNamespace(name string) (string, bool) {
+	var segments []string
+	for _, s := range strings.Split(name, ".") {
+		if !elixirSegment.MatchString(s) {
+			return "", false
+		}
+		var alias string
+		for _, w := range strings.Split(s, "_") {
+			if w != "" {
+				alias += strings.ToUpper(w[:1]) + w[1:]
+			}
+		}
+		segments = append(segments, alias)
+	}
+	return strings.Join(segments, "."), true
+}
+
+type Elixir struct {
+	namespace string
+	out       string
+
+	// modules maps structures to the name of their generated module
+	modules map[*models.Struct]string
+}
+
+func (c Elixir) Compile(in, out, pkgName, prefix string, packages *models.PackageList) {
+	log.Infof("Initialising %s compiler", aurora.Magenta("Elixir"))
+	if prefix != "" {
+		log.Warn("Ignoring unnecessary --prefix option.")
+	}
+	if pkgName == "" {
+		// Assume a namespace based on the output folder, like the Java
+		// compiler does for packages.
+		r := regexp.MustCompile("[^a-z0-9]+")
+		pkgName = strings.Trim(string(r.ReplaceAll([]byte(strings.ToLower(filepath.Base(out))), []byte("_"))), "_")
+		if pkgName == "" || (pkgName[0] >= '0' && pkgName[0] <= '9') {
+			pkgName = "ludwieg_" + pkgName
+		}
+		pkgName = convertToPascalCase(strings.Trim(pkgName, "_"))
+		log.Warnf("No namespace was provided. Assumed %s based on output path.", aurora.Magenta(pkgName))
+		log.Warn("Please use the --package argument to define a custom namespace")
+	} else {
+		namespace, ok := elixirNamespace(pkgName)
+		if !ok {
+			log.Errorf("Invalid namespace %s: each of its segments must start with a letter, followed by letters, digits or underscores", aurora.Magenta(pkgName))
+			os.Exit(1)
+		}
+		if namespace != pkgName {
+			log.Warnf("Using namespace %s in place of %s", aurora.Magenta(namespace), aurora.Magenta(pkgName))
+		}
+		pkgName = namespace
+	}
+	c.namespace = pkgName
+	c.out = out
+	c.modules = map[*models.Struct]string{}
+
+	var magic []string
+	for _, b := range codec.Magic {
+		magic = append(magic, fmt.Sprintf("0x%02X", b))
+	}
+	c.output("ludwieg", processTemplate("elixirRuntime", elixirRuntime, templateData{
+		"name": c.runtime(),
+	}))
+
+	for i := range *packages {
+		c.writePackage(&(*packages)[i])
+	}
+
+	var entries []string
+	for _, p := range *packages {
+		entries = append(entries, fmt.Sprintf("    %s => %s,\n", p.Identifier, c.namespace+"."+elixirPackageModule(p.Name)))
+	}
+	c.output("registry", processTemplate("elixirRegistry", elixirRegistry, templateData{
+		"name":     c.registry(),
+		"runtime":  c.runtime(),
+		"magic":    strings.Join(magic, ", "),
+		"version":  fmt.Sprintf("0x%02X", codec.ProtocolVersion),
+		"packages": strings.TrimSuffix(strings.Join(entries, ""), ",\n") + "\n",
+	}))
+
+	log.Info("Succeeded")
+	fmt.Println()
+	fmt.Println(c.integrationInstructions())
+}
+
+func (c Elixir) runtime() string {
+	return c.namespace + ".Ludwieg"
+}
+
+func (c Elixir) registry() string {
+	return c.namespace + ".Registry"
+}
+
+func (c Elixir) output(name string, contents []byte) {
+	log.Infof("Writing %s/%s", aurora.Magenta(filepath.Base(c.out)), aurora.Magenta(name+".ex"))
+	err := ioutil.WriteFile(filepath.Join(c.out, name+".ex"), contents, 0644)
+	if err != nil {
+		log.Errorf("Operation failed: %s", err)
+		os.Exit(1)
+	}
+}
+
+// elixirFile returns the name of the file of a package, suffixing names taken
+// by the runtime and registry with an underscore
+func elixirFile(name string) string {
+	if name == "ludwieg" || name == "registry" {
+		return name + "_"
+	}
+	return name
+}
+
+// elixirPackageModule returns the alias of the module of a package, which is
+// suffixed along with its file
+func elixirPackageModule(name string) string {
+	if name == "ludwieg" || name == "registry" {
+		return convertToPascalCase(name) + "_"
+	}
+	return convertToPascalCase(name)
+}
+
+func (c Elixir) writePackage(p *models.Package) {
+	name := c.namespace + "." + elixirPackageModule(p.Name)
+	c.registerStructs(name, p.Structs)
+
+	header := string(processTemplate("elixirPackageFunctions", elixirPackageFunctions, templateData{
+		"id": p.Identifier,
+	}))
+	doc := fmt.Sprintf("Package `%s`, identified by `%s`.", p.Name, p.Identifier)
+	modules := []string{c.generateModule(name, doc, header, p.Scope(), p.Fields)}
+	c.generateStructs(p.Name, p.Scope(), p.Structs, &modules)
+
+	c.output(elixirFile(p.Name), processTemplate("elixirPackage", elixirPackage, templateData{
+		"modules": strings.Join(modules, ""),
+	}))
+}
+
+// registerStructs assigns module names to structures before modules are
+// generated, as fields may reference structures declared after them
+func (c Elixir) registerStructs(prefix string, sArr []models.Struct) {
+	for i := range sArr {
+		s := &sArr[i]
+		name := prefix + "." + convertToPascalCase(s.Name)
+		c.modules[s] = name
+		c.registerStructs(name, s.Structs)
+	}
+}
+
+func (c Elixir) generateStructs(pkg string, scope *models.Scope, sArr []models.Struct, modules *[]string) {
+	for i := range sArr {
+		s := &sArr[i]
+		inner := &models.Scope{Structs: s.Structs, Parent: scope}
+		doc := fmt.Sprintf("Structure `%s` of package `%s`.", s.Name, pkg)
+		*modules = append(*modules, c.generateModule(c.modules[s], doc, "", inner, s.Fields))
+		c.generateStructs(pkg, inner, s.Structs, modules)
+	}
+}
+
+func (c Elixir) generateModule(name, doc, header string, scope *models.Scope, fArr []models.Field) string {
+	var fields, types, encode, decode, assigns []string
+	for i, f := range fArr {
+		variable := f.Name
+		if elixirReserved[variable] || strings.HasPrefix(variable, "_") || variable == "data" {
+			variable = "field_" + strings.TrimLeft(variable, "_")
+		}
+		t, enc, dec := c.generateField(scope, &f)
+
+		field := ":" + f.Name
+		if f.HasAttribute(models.AttributeDeprecated) {
+			field = "# Deprecated\n" + field
+		}
+		fields = append(fields, field)
+		types = append(types, fmt.Sprintf("%s: %s | nil", f.Name, t))
+
+		access := "v." + f.Name
+		if elixirReserved[f.Name] {
+			access = fmt.Sprintf("Map.fetch!(v, :%s)", f.Name)
+		}
+		encode = append(encode, fmt.Sprintf(enc, access))
+
+		// The remaining data is discarded after the last field, as
+		// values unknown to this version are skipped
+		rest := "data"
+		if i == len(fArr)-1 {
+			rest = "_data"
+		}
+		decode = append(decode, fmt.Sprintf("    {%s, %s} = %s\n", variable, rest, fmt.Sprintf(dec, "data")))
+		assigns = append(assigns, fmt.Sprintf("%s: %s", f.Name, variable))
+	}
+
+	assignList := strings.Join(assigns, ", ")
+	if len(assignList) > 80 {
+		assignList = elixirList(assigns, "      ", "\n    ")
+	}
+
+	value, data := "v", "data"
+	if len(fArr) == 0 {
+		value, data = "_v", "_data"
+	}
+
+	return string(processTemplate("elixirModule", elixirModule, templateData{
+		"name":    name,
+		"doc":     doc,
+		"runtime": c.runtime(),
+		"header":  header,
+		"fields":  elixirList(fields, "    ", "\n  "),
+		"types":   elixirList(types, "          ", "\n        "),
+		"value":   value,
+		"data":    data,
+		"encode":  elixirList(encode, "      ", "\n    "),
+		"decode":  strings.Join(decode, ""),
+		"assigns": assignList,
+	}))
+}
+
+// elixirList formats items of a multi-line list, literal or map, following
+// mix format's layout
+func elixirList(items []string, indentation, closing string) string {
+	if len(items) == 0 {
+		return ""
+	}
+	for i := range items {
+		items[i] = indentation + strings.Replace(items[i], "\n", "\n"+indentation, -1)
+	}
+	return "\n" + strings.Join(items, ",\n") + closing
+}
+
+// elixirType returns the typespec of values of a native type, along with the
+// suffix of the runtime functions handling it
+func elixirType(t models.NativeType) (string, string) {
+	switch t {
+	case models.TypeUint8, models.TypeByte:
+		return "0..255", "uint8"
+	case models.TypeUint32:
+		return "non_neg_integer()", "uint32"
+	case models.TypeUint64:
+		return "non_neg_integer()", "uint64"
+	case models.TypeDouble:
+		return "float()", "double"
+	case models.TypeString:
+		return "String.t()", "string"
+	case models.TypeBlob:
+		return "binary()", "blob"
+	case models.TypeBool:
+		return "boolean()", "bool"
+	case models.TypeUUID:
+		return "<<_::128>>", "uuid"
+	case models.TypeAny:
+		return "Ludwieg.any_value()", "any"
+	case models.TypeDynInt:
+		return "non_neg_integer()", "dynint"
+	}
+	log.Fatalf("BUG: Cannot coerce unknown type %#v to native type.", t)
+	return "", ""
+}
+
+// generateField returns the typespec of a field, along with format strings of
+// expressions encoding and decoding it
+func (c Elixir) generateField(scope *models.Scope, f *models.Field) (string, string, string) {
+	var t, read, write, readFn, writeFn string
+	if f.Type.Source == models.SourceUser {
+		s, _, ok := scope.Resolve(f.Type.CustomType)
+		if !ok {
+			log.Errorf("BUG: Cannot resolve structure %s referenced by %s", f.Type.CustomType, f.Name)
+			os.Exit(1)
+		}
+		t = c.modules[s] + ".t()"
+		read = "Ludwieg.decode_struct(%s, " + c.modules[s] + ")"
+		write = "Ludwieg.encode_struct(%s)"
+		readFn = "&Ludwieg.decode_struct(&1, " + c.modules[s] + ")"
+		writeFn = "&Ludwieg.encode_struct/1"
+	} else {
+		var kind string
+		t, kind = elixirType(f.Type.NativeType)
+		read = "Ludwieg.decode_" + kind + "(%s)"
+		write = "Ludwieg.encode_" + kind + "(%s)"
+		readFn = "&Ludwieg.decode_" + kind + "/1"
+		writeFn = "&Ludwieg.encode_" + kind + "/1"
+	}
+
+	if f.IsArray() {
+		size := "nil"
+		if f.Size != "*" {
+			size = f.Size
+		}
+		return "[" + t + " | nil]",
+			"Ludwieg.encode_array(%s, " + size + ", " + writeFn + ")",
+			"Ludwieg.decode_array(%s, " + readFn + ")"
+	}
+	return t, write, read
+}
+
+func (c Elixir) formatCode(code string) string {
+	var buf bytes.Buffer
+	err := quick.Highlight(&buf, code, "elixir", "terminal", "pygments")
+	if err != nil {
+		log.Fatalf("BUG: Error processing Elixir source: %s", err)
+	}
+
+	return string(buf.Bytes())
+}
+
+func (c Elixir) integrationInstructions() string {
+	usage := strings.Join([]string{
+		"{:ok, message_id, package, rest} = " + c.registry() + ".decode(data)",
+		"    encoded = " + c.registry() + ".encode(message_id, package)",
+	}, "\n")
+
+	data := templateData{
+		"dependencies": aurora.Bold("Dependencies"),
+		"integration":  aurora.Bold("Integration"),
+		"usage":        aurora.Bold("Usage"),
+
+		"runtime":  aurora.Magenta(c.runtime()),
+		"registry": aurora.Magenta(c.registry()),
+		"lib":      aurora.Magenta("lib/" + filepath.Base(c.out)),
+
+		"exUsage": c.formatCode(usage),
+	}
+
+	return string(processTemplate("elixirIntegration", elixirIntegrationSteps, data))
+}
@@ -0,0 +1,417 @@
+package langs
+
+const elixirPackage = `# WARNING: Automatically generated by ludco. DO NOT EDIT.
+{{.modules}}`
+
+const elixirModule = `
+defmodule {{.name}} do
+  @moduledoc """
+  {{.doc}}
+  """
+
+  alias {{.runtime}}
+
+  defstruct [{{.fields}}]
+
+  @type t :: %__MODULE__{{"{"}}{{.types}}}
+{{.header}}
+  @doc false
+  def encode_fields(%__MODULE__{} = {{.value}}) do
+    [{{.encode}}]
+  end
+
+  @doc false
+  def decode_fields({{.data}}) do
+{{.decode}}    %__MODULE__{{"{"}}{{.assigns}}}
+  end
+end
+`
+
+const elixirPackageFunctions = `
+  @doc "Returns the identifier of the package."
+  @spec ludwieg_id() :: 0..255
+  def ludwieg_id, do: {{.id}}
+
+  @doc "Encodes the package into a payload."
+  @spec encode(t()) :: binary()
+  def encode(%__MODULE__{} = value), do: IO.iodata_to_binary(encode_fields(value))
+
+  @doc "Decodes the package from a payload."
+  @spec decode(binary()) :: {:ok, t()} | {:error, term()}
+  def decode(data) when is_binary(data) do
+    {:ok, decode_fields(data)}
+  catch
+    {:ludwieg_error, reason} -> {:error, reason}
+  end
+`
+
+const elixirRegistry = `# WARNING: Automatically generated by ludco. DO NOT EDIT.
+
+defmodule {{.name}} do
+  @moduledoc """
+  Dispatches messages to generated packages based on their identifiers.
+  """
+
+  alias {{.runtime}}
+
+  @magic <<{{.magic}}>>
+  @version {{.version}}
+
+  @packages %{
+{{.packages}}  }
+
+  @doc "Returns the module of the package identified by id."
+  @spec module_for(0..255) :: {:ok, module()} | :error
+  def module_for(id), do: Map.fetch(@packages, id)
+
+  @doc """
+  Decodes the message at the beginning of data, returning its identifier and
+  package along with the remaining bytes. ` + "`{:error, :incomplete}`" + ` is returned
+  when data does not contain a complete message yet.
+  """
+  @spec decode(binary()) :: {:ok, 0..255, struct(), binary()} | {:error, term()}
+  def decode(<<{{.magic}}, version, message_id, package_id, data::binary>>) do
+    with :ok <- check_version(version),
+         {:ok, payload, rest} <- Ludwieg.split_payload(data),
+         {:ok, module} <- fetch_package(package_id),
+         {:ok, package} <- module.decode(payload) do
+      {:ok, message_id, package, rest}
+    end
+  end
+
+  def decode(data) when is_binary(data) do
+    size = min(byte_size(data), byte_size(@magic))
+
+    if binary_part(data, 0, size) == binary_part(@magic, 0, size) do
+      {:error, :incomplete}
+    else
+      {:error, :invalid_magic}
+    end
+  end
+
+  @doc "Encodes a package into a message."
+  @spec encode(0..255, struct()) :: binary()
+  def encode(message_id, %module{} = package) do
+    payload = module.encode(package)
+
+    IO.iodata_to_binary([
+      @magic,
+      @version,
+      message_id,
+      module.ludwieg_id(),
+      Ludwieg.encode_size(byte_size(payload)),
+      payload
+    ])
+  end
+
+  defp check_version(@version), do: :ok
+  defp check_version(version), do: {:error, {:unsupported_version, version}}
+
+  defp fetch_package(id) do
+    case module_for(id) do
+      {:ok, module} -> {:ok, module}
+      :error -> {:error, {:unknown_package, id}}
+    end
+  end
+end
+`
+
+const elixirRuntime = `# WARNING: Automatically generated by ludco. DO NOT EDIT.
+
+defmodule {{.name}} do
+  @moduledoc """
+  Encodes and decodes values using the Ludwieg wire format. Used by generated
+  packages.
+
+  Decoding functions take the remaining data and return the decoded value
+  along with the bytes following it. Failures are thrown as
+  ` + "`{:ludwieg_error, reason}`" + `, which generated packages convert into
+  ` + "`{:error, reason}`" + `. Encoding functions return iodata, and raise
+  ` + "`ArgumentError`" + ` on values not fitting their types.
+  """
+
+  import Bitwise
+
+  @empty 0x80
+
+  @uint8 0x01
+  @uint32 0x02
+  @uint64 0x03
+  @double 0x04
+  @string 0x05
+  @blob 0x06
+  @bool 0x07
+  @uuid 0x08
+  @any 0x09
+  @array 0x0A
+  @struct 0x0B
+  @dynint 0x0C
+
+  @max_uint32 0xFFFFFFFF
+  @max_uint64 0xFFFFFFFFFFFFFFFF
+
+  @typedoc "Value held by any fields, tagged by its type"
+  @type any_value ::
+          {:uint8, 0..255}
+          | {:uint32, non_neg_integer()}
+          | {:uint64, non_neg_integer()}
+          | {:double, float()}
+          | {:string, String.t()}
+          | {:blob, binary()}
+          | {:bool, boolean()}
+          | {:uuid, <<_::128>>}
+          | {:dynint, non_neg_integer()}
+          | {:array, [any_value() | nil]}
+
+  # Sizes
+
+  @doc "Encodes a dynamic size, using the smallest width able to hold it."
+  def encode_size(n) when is_integer(n) and n >= 0 and n <= 0xFF, do: <<1, n>>
+  def encode_size(n) when is_integer(n) and n >= 0 and n <= 0xFFFF, do: <<2, n::little-16>>
+  def encode_size(n) when is_integer(n) and n >= 0 and n <= @max_uint32, do: <<4, n::little-32>>
+  def encode_size(n) when is_integer(n) and n >= 0 and n <= @max_uint64, do: <<8, n::little-64>>
+  def encode_size(n), do: invalid!(:size, n)
+
+  @doc false
+  def decode_size(<<1, n, rest::binary>>), do: {n, rest}
+  def decode_size(<<2, n::little-16, rest::binary>>), do: {n, rest}
+  def decode_size(<<4, n::little-32, rest::binary>>), do: {n, rest}
+  def decode_size(<<8, n::little-64, rest::binary>>), do: {n, rest}
+  def decode_size(<<w, _::binary>>) when w not in [1, 2, 4, 8], do: fail({:invalid_size_width, w})
+  def decode_size(_), do: fail(:out_of_bounds)
+
+  @doc false
+  def split_payload(data) do
+    {size, data} = decode_size(data)
+
+    case data do
+      <<payload::binary-size(size), rest::binary>> -> {:ok, payload, rest}
+      _ -> {:error, :incomplete}
+    end
+  catch
+    {:ludwieg_error, :out_of_bounds} -> {:error, :incomplete}
+    {:ludwieg_error, reason} -> {:error, reason}
+  end
+
+  # Encoding
+
+  @doc false
+  def encode_uint8(nil), do: <<@uint8 + @empty>>
+  def encode_uint8(v) when is_integer(v) and v >= 0 and v <= 0xFF, do: <<@uint8, v>>
+  def encode_uint8(v), do: invalid!(:uint8, v)
+
+  @doc false
+  def encode_uint32(nil), do: <<@uint32 + @empty>>
+  def encode_uint32(v) when is_integer(v) and v >= 0 and v <= @max_uint32, do: <<@uint32, v::little-32>>
+  def encode_uint32(v), do: invalid!(:uint32, v)
+
+  @doc false
+  def encode_uint64(nil), do: <<@uint64 + @empty>>
+  def encode_uint64(v) when is_integer(v) and v >= 0 and v <= @max_uint64, do: <<@uint64, v::little-64>>
+  def encode_uint64(v), do: invalid!(:uint64, v)
+
+  @doc false
+  def encode_double(nil), do: <<@double + @empty>>
+  def encode_double(v) when is_number(v), do: <<@double, v::little-float-64>>
+  def encode_double(v), do: invalid!(:double, v)
+
+  @doc false
+  def encode_string(nil), do: <<@string + @empty>>
+  def encode_string(v) when is_binary(v), do: [@string, encode_size(byte_size(v)), v]
+  def encode_string(v), do: invalid!(:string, v)
+
+  @doc false
+  def encode_blob(nil), do: <<@blob + @empty>>
+  def encode_blob(v) when is_binary(v), do: [@blob, encode_size(byte_size(v)), v]
+  def encode_blob(v), do: invalid!(:blob, v)
+
+  @doc false
+  def encode_bool(nil), do: <<@bool + @empty>>
+  def encode_bool(true), do: <<@bool, 1>>
+  def encode_bool(false), do: <<@bool, 0>>
+  def encode_bool(v), do: invalid!(:bool, v)
+
+  @doc false
+  def encode_uuid(nil), do: <<@uuid + @empty>>
+  def encode_uuid(<<_::binary-16>> = v), do: [@uuid, v]
+  def encode_uuid(v), do: invalid!(:uuid, v)
+
+  @doc false
+  def encode_dynint(nil), do: <<@dynint + @empty>>
+  def encode_dynint(v), do: [@dynint, encode_size(v)]
+
+  @doc false
+  def encode_any(nil), do: <<@any + @empty>>
+  def encode_any({:uint8, v}), do: [@any, encode_uint8(v)]
+  def encode_any({:uint32, v}), do: [@any, encode_uint32(v)]
+  def encode_any({:uint64, v}), do: [@any, encode_uint64(v)]
+  def encode_any({:double, v}), do: [@any, encode_double(v)]
+  def encode_any({:string, v}), do: [@any, encode_string(v)]
+  def encode_any({:blob, v}), do: [@any, encode_blob(v)]
+  def encode_any({:bool, v}), do: [@any, encode_bool(v)]
+  def encode_any({:uuid, v}), do: [@any, encode_uuid(v)]
+  def encode_any({:dynint, v}), do: [@any, encode_dynint(v)]
+  def encode_any({:array, v}), do: [@any, encode_array(v, nil, &encode_any/1)]
+  def encode_any(v), do: invalid!(:any, v)
+
+  @doc false
+  def encode_array(nil, _size, _fun), do: <<@array + @empty>>
+
+  def encode_array(items, size, fun) when is_list(items) do
+    count = length(items)
+
+    if size != nil and count > size do
+      raise ArgumentError, "array holds #{count} items, exceeding its size of #{size}"
+    end
+
+    wrap(@array, [encode_size(count) | Enum.map(items, fun)])
+  end
+
+  def encode_array(items, _size, _fun), do: invalid!(:array, items)
+
+  @doc false
+  def encode_struct(nil), do: <<@struct + @empty>>
+  def encode_struct(%module{} = v), do: wrap(@struct, module.encode_fields(v))
+  def encode_struct(v), do: invalid!(:struct, v)
+
+  defp wrap(type, body), do: [type, encode_size(IO.iodata_length(body)), body]
+
+  defp invalid!(type, v) do
+    raise ArgumentError, "cannot encode #{inspect(v)} as #{type}"
+  end
+
+  # Decoding
+
+  @doc false
+  def decode_uint8(<<@uint8, v, rest::binary>>), do: {v, rest}
+  def decode_uint8(data), do: empty(data, @uint8)
+
+  @doc false
+  def decode_uint32(<<@uint32, v::little-32, rest::binary>>), do: {v, rest}
+  def decode_uint32(data), do: empty(data, @uint32)
+
+  @doc false
+  def decode_uint64(<<@uint64, v::little-64, rest::binary>>), do: {v, rest}
+  def decode_uint64(data), do: empty(data, @uint64)
+
+  @doc false
+  def decode_double(<<@double, v::little-float-64, rest::binary>>), do: {v, rest}
+  def decode_double(data), do: empty(data, @double)
+
+  @doc false
+  def decode_string(<<@string, data::binary>>) do
+    {v, rest} = take(data)
+    if String.valid?(v), do: {v, rest}, else: fail(:invalid_string)
+  end
+
+  def decode_string(data), do: empty(data, @string)
+
+  @doc false
+  def decode_blob(<<@blob, data::binary>>), do: take(data)
+  def decode_blob(data), do: empty(data, @blob)
+
+  @doc false
+  def decode_bool(<<@bool, v, rest::binary>>), do: {v != 0, rest}
+  def decode_bool(data), do: empty(data, @bool)
+
+  @doc false
+  def decode_uuid(<<@uuid, v::binary-16, rest::binary>>), do: {v, rest}
+  def decode_uuid(data), do: empty(data, @uuid)
+
+  @doc false
+  def decode_dynint(<<@dynint, data::binary>>), do: decode_size(data)
+  def decode_dynint(data), do: empty(data, @dynint)
+
+  @doc false
+  def decode_any(<<@any, data::binary>>) do
+    case data do
+      <<t, _::binary>> -> decode_any_value(t &&& 0x7F, data)
+      <<>> -> fail(:out_of_bounds)
+    end
+  end
+
+  def decode_any(data), do: empty(data, @any)
+
+  defp decode_any_value(@uint8, data), do: tag(:uint8, decode_uint8(data))
+  defp decode_any_value(@uint32, data), do: tag(:uint32, decode_uint32(data))
+  defp decode_any_value(@uint64, data), do: tag(:uint64, decode_uint64(data))
+  defp decode_any_value(@double, data), do: tag(:double, decode_double(data))
+  defp decode_any_value(@string, data), do: tag(:string, decode_string(data))
+  defp decode_any_value(@blob, data), do: tag(:blob, decode_blob(data))
+  defp decode_any_value(@bool, data), do: tag(:bool, decode_bool(data))
+  defp decode_any_value(@uuid, data), do: tag(:uuid, decode_uuid(data))
+  defp decode_any_value(@dynint, data), do: tag(:dynint, decode_dynint(data))
+  defp decode_any_value(@array, data), do: tag(:array, decode_array(data, &decode_any/1))
+  defp decode_any_value(t, _data), do: fail({:unsupported_any, t})
+
+  defp tag(_type, {nil, rest}), do: {nil, rest}
+
+  defp tag(type, {v, rest}) do
+    value = {type, v}
+    {value, rest}
+  end
+
+  @doc false
+  def decode_array(<<@array, data::binary>>, fun) do
+    {body, rest} = take(data)
+    {count, body} = decode_size(body)
+    {decode_items(body, count, fun, []), rest}
+  end
+
+  def decode_array(data, _fun), do: empty(data, @array)
+
+  defp decode_items(_body, 0, _fun, acc), do: Enum.reverse(acc)
+  defp decode_items(<<>>, _count, _fun, _acc), do: fail(:out_of_bounds)
+
+  defp decode_items(body, count, fun, acc) do
+    {v, body} = fun.(body)
+    decode_items(body, count - 1, fun, [v | acc])
+  end
+
+  @doc false
+  def decode_struct(<<@struct, data::binary>>, module) do
+    {body, rest} = take(data)
+    {module.decode_fields(body), rest}
+  end
+
+  def decode_struct(data, _module), do: empty(data, @struct)
+
+  # take returns the sized value at the beginning of data, along with the
+  # bytes following it
+  defp take(data) do
+    {size, data} = decode_size(data)
+
+    case data do
+      <<v::binary-size(size), rest::binary>> -> {v, rest}
+      _ -> fail(:out_of_bounds)
+    end
+  end
+
+  # empty handles values not matched by decoders, which are either empty,
+  # truncated, or of another type. Missing trailing values are considered
+  # empty.
+  defp empty(<<>>, _type), do: {nil, <<>>}
+  defp empty(<<b, rest::binary>>, type) when b == type + @empty, do: {nil, rest}
+  defp empty(<<b, _::binary>>, type) when b == type, do: fail(:out_of_bounds)
+  defp empty(<<b, _::binary>>, type), do: fail({:unexpected_type, type, b &&& 0x7F})
+
+  defp fail(reason), do: throw({:ludwieg_error, reason})
+end
+`
+
+const elixirIntegrationSteps = `You just generated Elixir sources. In order to use them you need to perform a few
+tasks.
+
+  1. {{.dependencies}}
+  Generated modules depend only on the Elixir standard library. {{.runtime}}
+  holds the encoder and decoder used by them.
+
+  2. {{.integration}}
+  Copy output files to your Mix project, for instance into {{.lib}}
+
+  3. {{.usage}}
+  {{.registry}} maps package identifiers to their modules, and handles
+  messages:
+
+    {{.exUsage}}
+`
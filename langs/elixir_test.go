@@ -0,0 +1,63 @@
+package langs
+
+import (
+	"os"
+	"testing"
+)
+
+func TestElixirNamespace(t *testing.T) {
+	tests := []struct {
+		name     string
+		expected string
+		ok       bool
+	}{
+		{"MyApp.Proto", "MyApp.Proto", true},
+		{"my_app.proto", "MyApp.Proto", true},
+		{"myapp", "Myapp", true},
+		{"my__app.v2", "MyApp.V2", true},
+		{"my-app", "", false},
+		{"MyApp..Proto", "", false},
+		{"MyApp.", "", false},
+		{"2fa.Proto", "", false},
+		{"", "", false},
+	}
+	for _, tt := range tests {
+		namespace, ok := elixirNamespace(tt.name)
+		if namespace != tt.expected || ok != tt.ok {
+			t.Errorf("elixirNamespace(%q) = %q, %v, expected %q, %v", tt.name, namespace, ok, tt.expected, tt.ok)
+		}
+	}
+}
+
+func TestElixirRecursiveStructs(t *testing.T) {
+	dir := testCompile(t, Elixir{}, "MyApp.Proto", "", testPackages(t, recursiveSource))
+	defer os.RemoveAll(dir)
+
+	testContains(t, "tree.ex", testOutput(t, dir, "tree.ex"),
+		"defmodule MyApp.Proto.Tree.Node do",
+		"parent: MyApp.Proto.Tree.Node.t() | nil",
+		"back: MyApp.Proto.Tree.A.t() | nil")
+}
+
+func TestElixirReservedNames(t *testing.T) {
+	dir := testCompile(t, Elixir{}, "MyApp.Proto", "", testPackages(t, `
+package registry {
+    id 0x01
+    string text
+}
+
+package ludwieg {
+    id 0x02
+    string text
+}
+`))
+	defer os.RemoveAll(dir)
+
+	testContains(t, "registry.ex", testOutput(t, dir, "registry.ex"),
+		"defmodule MyApp.Proto.Registry do",
+		"0x01 => MyApp.Proto.Registry_,",
+		"0x02 => MyApp.Proto.Ludwieg_")
+	testContains(t, "registry_.ex", testOutput(t, dir, "registry_.ex"), "defmodule MyApp.Proto.Registry_ do")
+	testContains(t, "ludwieg_.ex", testOutput(t, dir, "ludwieg_.ex"), "defmodule MyApp.Proto.Ludwieg_ do")
+	testContains(t, "ludwieg.ex", testOutput(t, dir, "ludwieg.ex"), "defmodule MyApp.Proto.Ludwieg do")
+}
@@ -94,6 +94,7 @@ func (c Go) writeEmptyPackage(p *models.Package) {
 		"pkg":  c.pkgName,
 		"id":   p.Identifier,
 		"name": convertToPascalCase(p.Name),
+		"doc":  lineComment(p.Doc, ""),
 	}))
 }
 
@@ -104,6 +105,7 @@ func (c Go) writeNormalPackage(p *models.Package) {
 		"pkg":         c.pkgName,
 		"id":          p.Identifier,
 		"name":        pkgName,
+		"doc":         lineComment(p.Doc, ""),
 		"fields":      c.generateFields(p.Fields, pkgName),
 		"annotations": c.generateAnnotations(p.Fields, pkgName),
 		"structures":  c.generateStructs(p.Structs, pkgName),
@@ -143,6 +145,7 @@ func (c Go) writeStruct(s *models.Struct, prefix string) []byte {
 	pkgName := prefix + convertToPascalCase(s.Name)
 	return processTemplate("struct", goStruct, templateData{
 		"name":        pkgName,
+		"doc":         lineComment(s.Doc, ""),
 		"fields":      c.generateFields(s.Fields, pkgName),
 		"annotations": c.generateAnnotations(s.Fields, pkgName),
 		"structures":  c.generateStructs(s.Structs, pkgName),
@@ -209,6 +212,7 @@ func (c Go) writeField(f *models.Field, prefix string) []byte {
 	return processTemplate("field", goField, templateData{
 		"name": n,
 		"type": t,
+		"doc":  lineComment(f.Doc, "\t"),
 	})
 }
 
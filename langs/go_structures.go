@@ -4,18 +4,18 @@ const goEmptyPackage = `// WARNING: Automatically generated by ludco. DO NOT EDI
 
 package {{.pkg}}
 
-type {{.name}} struct{}
+{{.doc}}type {{.name}} struct{}
 func (t {{.name}}) LudwiegID() byte { return {{.id}} }
 func (t {{.name}}) LudwiegMeta() []LudwiegTypeAnnotation { return []LudwiegTypeAnnotation{} }
 `
 
-const goField = "	{{.name}} {{.type}}\n"
+const goField = "{{.doc}}	{{.name}} {{.type}}\n"
 
 const goPackage = `// WARNING: Automatically generated by ludco. DO NOT EDIT.
 
 package {{.pkg}}
 
-type {{.name}} struct {
+{{.doc}}type {{.name}} struct {
 {{.fields}}
 }
 
@@ -26,7 +26,7 @@ func (t {{.name}}) LudwiegMeta() []LudwiegTypeAnnotation { return []LudwiegTypeA
 `
 
 const goStruct = `
-type {{.name}} struct {
+{{.doc}}type {{.name}} struct {
 {{.fields}}
 }
 
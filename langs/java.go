@@ -70,6 +70,7 @@ func (c Java) writeEmptyPackage(p *models.Package) {
 	c.output(name, processTemplate("emptyPackage", javaEmptyPackage, templateData{
 		"pkg":        c.pkgName,
 		"name":       name,
+		"doc":        blockComment(p.Doc, "", "/**"),
 		"annotation": c.getClassAnnotationFor(p),
 	}))
 }
@@ -79,6 +80,7 @@ func (c Java) writeNormalPackage(p *models.Package) {
 
 	c.output(pkgName, processTemplate("package", javaPackage, templateData{
 		"pkg":        c.pkgName,
+		"doc":        blockComment(p.Doc, "", "/**"),
 		"annotation": c.getClassAnnotationFor(p),
 		"name":       pkgName,
 		"fields":     c.generateFields(p.Fields, pkgName),
@@ -94,6 +96,7 @@ func (c Java) generateStructs(sArr []models.Struct, pkgName string) {
 		name := pkgName + convertToPascalCase(s.Name)
 		c.output(name, processTemplate("package", javaPackage, templateData{
 			"pkg":        c.pkgName,
+			"doc":        blockComment(s.Doc, "", "/**"),
 			"annotation": c.getClassAnnotationFor(&s),
 			"name":       pkgName,
 			"fields":     c.generateFields(s.Fields, pkgName),
@@ -222,7 +225,7 @@ func (c Java) generateGetters(fArr []models.Field, pkgName string) string {
 				template = javaGetterCustom
 			}
 		}
-		items = append(items, strings.Repeat(" ", 4)+string(processTemplate("javaGetter", template, data)))
+		items = append(items, blockComment(f.Doc, strings.Repeat(" ", 4), "/**")+strings.Repeat(" ", 4)+string(processTemplate("javaGetter", template, data)))
 	}
 	return strings.Join(items, "\n")
 }
@@ -258,7 +261,7 @@ func (c Java) generateSetters(fArr []models.Field, pkgName string) string {
 				template = javaSetterCustom
 			}
 		}
-		items = append(items, strings.Repeat(" ", 4)+string(processTemplate("javaGetter", template, data)))
+		items = append(items, blockComment(f.Doc, strings.Repeat(" ", 4), "/**")+strings.Repeat(" ", 4)+string(processTemplate("javaGetter", template, data)))
 	}
 	return strings.Join(items, "\n")
 }
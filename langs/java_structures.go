@@ -90,7 +90,7 @@ package {{.pkg}};
 
 import io.vito.ludwieg.LudwiegPackage;
 
-{{.annotation}}
+{{.doc}}{{.annotation}}
 public final class {{.name}} { }
 `
 
@@ -102,7 +102,7 @@ import java.util.*;
 import io.vito.ludwieg.*;
 import io.vito.ludwieg.types.*;
 
-{{.annotation}}
+{{.doc}}{{.annotation}}
 public final class {{.name}} {
     public {{.name}}() { }
 
@@ -0,0 +1,272 @@
+package langs
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/alecthomas/chroma/quick"
+	"github.com/logrusorgru/aurora"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/ludwieg/ludco/models"
+)
+
+// kotlinKeywords lists hard keywords that must be escaped when used as
+// property names
+var kotlinKeywords = map[string]bool{
+	"as": true, "break": true, "class": true, "continue": true, "do": true,
+	"else": true, "false": true, "for": true, "fun": true, "if": true, "in": true,
+	"interface": true, "is": true, "null": true, "object": true, "package": true,
+	"return": true, "super": true, "this": true, "throw": true, "true": true,
+	"try": true, "typealias": true, "typeof": true, "val": true, "var": true,
+	"when": true, "while": true,
+}
+
+// kotlinReservedTypes lists runtime types referred to by generated sources,
+// which packages and structures must not shadow
+var kotlinReservedTypes = map[string]bool{
+	"DynInt": true, "LudwiegField": true, "LudwiegPackage": true,
+	"ProtocolType": true, "Registry": true, "Serializable": true,
+}
+
+type Kotlin struct {
+	pkgName string
+	out     string
+}
+
+func (c Kotlin) Compile(in, out, pkgName, prefix string, packages *models.PackageList) {
+	log.Infof("Initialising %s compiler", aurora.Magenta("Kotlin"))
+	if prefix != "" {
+		log.Warn("Ignoring unnecessary --prefix option.")
+		prefix = ""
+	}
+	if pkgName == "" {
+		// This means that no package name was provided. So we can assume
+		// it using `out` basename.
+		r := regexp.MustCompile("[^a-z]")
+		pkgName = string(r.ReplaceAll([]byte(strings.ToLower(filepath.Base(out))), []byte{}))
+		log.Warnf("No package name was provided. Assumed %s based on output path.", aurora.Magenta(pkgName))
+		log.Warn("Please use the --package argument to define a custom package name")
+	}
+	c.pkgName = pkgName
+	c.out = out
+
+	for _, p := range *packages {
+		c.writePackage(&p)
+	}
+
+	log.Info("Succeeded")
+	fmt.Println()
+	fmt.Println(c.integrationInstructions(packages))
+}
+
+func (c Kotlin) output(name string, contents []byte) {
+	log.Infof("Writing %s/%s", aurora.Magenta(filepath.Base(c.out)), aurora.Magenta(name+".kt"))
+	err := ioutil.WriteFile(filepath.Join(c.out, name+".kt"), contents, 0644)
+	if err != nil {
+		log.Errorf("Operation failed: %s", err)
+		os.Exit(1)
+	}
+}
+
+func (c Kotlin) writePackage(p *models.Package) {
+	name := kotlinClassName(p.Name)
+	annotation := string(processTemplate("classAnnotation", kotlinAnnotationPackage, templateData{"id": p.Identifier}))
+	c.output(name, processTemplate("kotlinFile", kotlinFile, templateData{
+		"pkg":   c.pkgName,
+		"class": c.generateClass(name, annotation, p.Fields, p.Structs),
+	}))
+}
+
+// generateClass returns the class of a package or structure. Structures are
+// nested into the class declaring them.
+func (c Kotlin) generateClass(name, annotation string, fArr []models.Field, sArr []models.Struct) string {
+	var structs []string
+	for _, s := range sArr {
+		structs = append(structs, indent(c.generateClass(kotlinClassName(s.Name), kotlinAnnotationStruct, s.Fields, s.Structs)))
+	}
+	nested := ""
+	if len(structs) > 0 {
+		nested = " {\n" + strings.Join(structs, "\n") + "}"
+	}
+
+	data := templateData{
+		"annotation": annotation,
+		"name":       name,
+		"structures": nested,
+	}
+	if len(fArr) == 0 {
+		// Data classes require at least one property
+		return string(processTemplate("kotlinEmptyClass", kotlinEmptyClass, data))
+	}
+	data["fields"] = c.generateFields(fArr)
+	return string(processTemplate("kotlinDataClass", kotlinDataClass, data))
+}
+
+func (c Kotlin) generateFields(fArr []models.Field) string {
+	var result []string
+	for i, f := range fArr {
+		lines := []string{}
+		if f.HasAttribute(models.AttributeDeprecated) {
+			lines = append(lines, string(processTemplate("kotlinDeprecated", kotlinDeprecated, templateData{
+				"name": convertToCamelCase(f.Name),
+			})))
+		}
+		lines = append(lines, c.generateFieldAnnotation(i, &f))
+		lines = append(lines, string(processTemplate("kotlinField", kotlinField, templateData{
+			"name": kotlinIdentifier(f.Name),
+			"type": c.nativeTypeForField(&f),
+		})))
+		result = append(result, "    "+strings.Join(lines, "\n    "))
+	}
+	return strings.Join(result, ",\n")
+}
+
+func kotlinIdentifier(name string) string {
+	name = convertToCamelCase(name)
+	if kotlinKeywords[name] {
+		return "`" + name + "`"
+	}
+	return name
+}
+
+// kotlinClassName returns the class name of a package or structure, suffixing
+// names of runtime types with an underscore
+func kotlinClassName(name string) string {
+	name = convertToPascalCase(name)
+	if kotlinReservedTypes[name] {
+		return name + "_"
+	}
+	return name
+}
+
+func (c Kotlin) generateFieldAnnotation(index int, f *models.Field) string {
+	data := templateData{
+		"index": index,
+	}
+	template := ""
+
+	if f.Type.Source == models.SourceNative {
+		data["type"] = strings.ToUpper(string(f.Type.NativeType))
+		if f.IsArray() {
+			template = kotlinFieldAnnotationNativeArray
+		} else {
+			template = kotlinFieldAnnotationNative
+		}
+	} else {
+		data["type"] = kotlinClassName(f.Type.CustomType)
+		if f.IsArray() {
+			template = kotlinFieldAnnotationCustomArray
+		} else {
+			template = kotlinFieldAnnotationCustom
+		}
+	}
+
+	return string(processTemplate("fieldAnnotation", template, data))
+}
+
+func (c Kotlin) nativeTypeForField(f *models.Field) string {
+	var kind string
+	if f.Type.Source == models.SourceNative {
+		kind = c.nativeTypeForProtocolType(f.Type.NativeType)
+	} else {
+		kind = kotlinClassName(f.Type.CustomType)
+	}
+	if f.IsArray() {
+		kind = "kotlin.collections.List<" + kind + "?>"
+	}
+	return kind + "?"
+}
+
+// nativeTypeForProtocolType returns the fully qualified Kotlin type holding
+// values of a native type, preventing nested classes such as `String` or `List`
+// from shadowing them
+func (c Kotlin) nativeTypeForProtocolType(t models.NativeType) string {
+	kind := ""
+	switch t {
+	case models.TypeUint8, models.TypeUint32, models.TypeByte:
+		kind = "kotlin.Int"
+	case models.TypeUint64:
+		kind = "kotlin.Long"
+	case models.TypeDouble:
+		kind = "kotlin.Double"
+	case models.TypeString:
+		kind = "kotlin.String"
+	case models.TypeBlob:
+		kind = "kotlin.ByteArray"
+	case models.TypeBool:
+		kind = "kotlin.Boolean"
+	case models.TypeUUID:
+		kind = "java.util.UUID"
+	case models.TypeAny:
+		kind = "kotlin.Any"
+	case models.TypeDynInt:
+		kind = "DynInt"
+	}
+
+	if kind == "" {
+		log.Fatalf("BUG: Cannot coerce unknown type %#v to native type.", t)
+	}
+
+	return kind
+}
+
+func (c Kotlin) integrationInstructions(pList *models.PackageList) string {
+	list := []string{}
+	for _, p := range *pList {
+		list = append(list, kotlinClassName(p.Name)+"::class.java")
+	}
+	sort.Strings(list)
+
+	separator := ", "
+	if len(list) > 2 {
+		separator = ",\n" + strings.Repeat(" ", 30)
+	}
+
+	jitPackRepository := `      allprojects {
+          repositories {
+              ...
+              maven { url 'https://jitpack.io' }
+          }
+      }`
+
+	jitPackDependency := `      dependencies {
+          implementation 'com.github.ludwieg:kotlin:v0.1.6'
+      }`
+
+	data := templateData{
+		"dependencies":   aurora.Bold("Dependencies"),
+		"integration":    aurora.Bold("Integration"),
+		"initialization": aurora.Bold("Initialization"),
+		"jitPack":        aurora.Bold("JitPack"),
+
+		"gradle":  aurora.Magenta("Gradle"),
+		"maven":   aurora.Magenta("Maven"),
+		"libPath": aurora.Magenta("ludwieg/kotlin"),
+
+		"jitPackRepository": c.formatCode(jitPackRepository, "groovy"),
+		"jitPackDependency": c.formatCode(jitPackDependency, "groovy"),
+
+		"kotlinImportLudwieg": c.formatCode("import io.vito.ludwieg.Registry", "kotlin"),
+		"kotlinImportPkg":     c.formatCode("import "+c.pkgName+".*", "kotlin"),
+		"kotlinRegister":      c.formatCode("Registry.instance.register("+strings.Join(list, separator)+")", "kotlin"),
+	}
+
+	return string(processTemplate("kotlinIntegration", kotlinIntegrationSteps, data))
+}
+
+func (c Kotlin) formatCode(code, lang string) string {
+	var buf bytes.Buffer
+	err := quick.Highlight(&buf, code, lang, "terminal", "pygments")
+	if err != nil {
+		log.Fatalf("BUG: Error processing kotlin source: %s", err)
+	}
+
+	return string(buf.Bytes())
+}
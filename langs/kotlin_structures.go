@@ -0,0 +1,65 @@
+package langs
+
+const kotlinAnnotationPackage = "@LudwiegPackage(id = {{.id}})"
+const kotlinAnnotationStruct = "@Serializable"
+
+const kotlinFieldAnnotationNative = "@field:LudwiegField(index = {{.index}}, protocolType = ProtocolType.{{.type}})"
+const kotlinFieldAnnotationNativeArray = "@field:LudwiegField(index = {{.index}}, protocolType = ProtocolType.ARRAY, arrayType = ProtocolType.{{.type}})"
+const kotlinFieldAnnotationCustom = "@field:LudwiegField(index = {{.index}}, protocolType = ProtocolType.STRUCT, structType = {{.type}}::class)"
+const kotlinFieldAnnotationCustomArray = "@field:LudwiegField(index = {{.index}}, protocolType = ProtocolType.ARRAY, arrayType = ProtocolType.STRUCT, structType = {{.type}}::class)"
+
+const kotlinDeprecated = `@kotlin.Deprecated("{{.name}} is deprecated")`
+
+const kotlinField = "var {{.name}}: {{.type}} = null"
+
+const kotlinFile = `// WARNING: Automatically generated by ludco. DO NOT EDIT.
+
+package {{.pkg}}
+
+import io.vito.ludwieg.*
+import io.vito.ludwieg.types.*
+
+{{.class}}`
+
+const kotlinEmptyClass = `{{.annotation}}
+class {{.name}}{{.structures}}
+`
+
+const kotlinDataClass = `{{.annotation}}
+data class {{.name}}(
+{{.fields}}
+){{.structures}}
+`
+
+const kotlinIntegrationSteps = `You just generated Kotlin sources. In order to use them you need to perform a few
+tasks.
+
+  1. {{.dependencies}}
+  *Notice*: This step assumes you use {{.gradle}} to handle your build automation. In
+  case you use another tool, such as {{.maven}}, please refer to the documentation in
+  the framework repository https://github.com/ludwieg/kotlin#installing and skip
+  to step 2.
+
+    1.1. Add {{.jitPack}} to your {{.gradle}} repository list:
+
+{{.jitPackRepository}}
+
+    1.2. Add {{.libPath}} as a dependency of your project:
+
+{{.jitPackDependency}}
+
+  2. {{.integration}}
+  Copy output files to your project
+
+  3. {{.initialization}}
+  Before attempting deserializing data, you must register output packages.
+  This operation must be performed only once, usually in your application's
+  boot:
+
+    {{.kotlinImportLudwieg}}
+    {{.kotlinImportPkg}}
+
+    ...
+
+    {{.kotlinRegister}}
+`
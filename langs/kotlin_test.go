@@ -0,0 +1,38 @@
+package langs
+
+import (
+	"os"
+	"testing"
+)
+
+func TestKotlinRecursiveStructs(t *testing.T) {
+	dir := testCompile(t, Kotlin{}, "example", "", testPackages(t, recursiveSource))
+	defer os.RemoveAll(dir)
+
+	testContains(t, "Tree.kt", testOutput(t, dir, "Tree.kt"),
+		"data class Node(",
+		"structType = Node::class)\n        var parent: Node? = null",
+		"var children: kotlin.collections.List<Node?>? = null",
+		"var back: A? = null")
+}
+
+func TestKotlinReservedNames(t *testing.T) {
+	dir := testCompile(t, Kotlin{}, "example", "", testPackages(t, `
+package registry {
+    id 0x01
+    @protocol_type kind
+    struct protocol_type {
+        dynint value
+    }
+}
+`))
+	defer os.RemoveAll(dir)
+
+	testContains(t, "Registry_.kt", testOutput(t, dir, "Registry_.kt"),
+		"data class Registry_(",
+		"structType = ProtocolType_::class)",
+		"var kind: ProtocolType_? = null",
+		"data class ProtocolType_(",
+		"protocolType = ProtocolType.DYNINT)",
+		"var value: DynInt? = null")
+}
@@ -46,6 +46,33 @@ func convertToPascalCase(val string) string {
 	return strings.Join(arr, "")
 }
 
+// lineComment formats documentation as a line comment, such as GoDoc
+func lineComment(doc, indent string) string {
+	if doc == "" {
+		return ""
+	}
+	var result []string
+	for _, l := range strings.Split(doc, "\n") {
+		result = append(result, strings.TrimRight(indent+"// "+l, " "))
+	}
+	return strings.Join(result, "\n") + "\n"
+}
+
+// blockComment formats documentation as a block comment started by open, such
+// as "/**" for Javadoc or "/*!" for HeaderDoc
+func blockComment(doc, indent, open string) string {
+	if doc == "" {
+		return ""
+	}
+	result := []string{indent + open}
+	for _, l := range strings.Split(doc, "\n") {
+		l = strings.Replace(l, "*/", "* /", -1)
+		result = append(result, strings.TrimRight(indent+" * "+l, " "))
+	}
+	result = append(result, indent+" */")
+	return strings.Join(result, "\n") + "\n"
+}
+
 func convertToCamelCase(val string) string {
 	var arr []string
 	for i, s := range strings.Split(val, "_") {
@@ -0,0 +1,172 @@
+package langs
+
+import (
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ludwieg/ludco/codec"
+	"github.com/ludwieg/ludco/instances"
+	"github.com/ludwieg/ludco/models"
+	"github.com/ludwieg/ludco/parser"
+)
+
+// vectorSource declares a package using every type, which round-trip tests
+// encode and decode through generated sources
+const vectorSource = `package sample {
+    id 0x05
+
+    uint8       small
+    uint32      medium
+    uint64      large
+    double      real
+    string      text
+    blob        raw
+    bool        flag
+    uuid        ident
+    dynint      counter
+    any         value
+    any[*]      values
+    string[*]   names
+    uint32[3]   numbers
+    @item[2]    items
+    @item       head
+
+    struct item {
+        string      label
+        blob[*]     chunks
+    }
+}
+`
+
+// recursiveSource declares structures holding themselves, directly and
+// through each other
+const recursiveSource = `package tree {
+    id 0x06
+
+    @node       root
+
+    struct node {
+        string      label
+        @node       parent
+        @node[*]    children
+        @a          first
+    }
+
+    struct a {
+        @b          next
+    }
+
+    struct b {
+        @a          back
+    }
+}
+`
+
+// testPackages parses definition files, returning the packages they declare
+func testPackages(t *testing.T, sources ...string) *models.PackageList {
+	list := models.PackageList{}
+	for i, src := range sources {
+		out, err := parser.Parse(fmt.Sprintf("test%d.lud", i), []byte(src))
+		if err != nil {
+			t.Fatalf("parsing test package: %s", err)
+		}
+		for _, p := range out.([]interface{}) {
+			list = append(list, *models.ConvertASTPackage(p.(parser.Package)))
+		}
+	}
+	return &list
+}
+
+// testCompile runs a compiler, returning the folder holding its output. The
+// folder must be removed by the caller.
+func testCompile(t *testing.T, c Compiler, pkgName, prefix string, packages *models.PackageList) string {
+	dir, err := ioutil.TempDir("", "ludco-langs")
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.Compile("", dir, pkgName, prefix, packages)
+	return dir
+}
+
+// testOutput returns the contents of a generated file
+func testOutput(t *testing.T, dir, name string) string {
+	data, err := ioutil.ReadFile(filepath.Join(dir, name))
+	if err != nil {
+		t.Fatalf("reading generated file: %s", err)
+	}
+	return string(data)
+}
+
+// testVector holds the name of a vector along with its encoded message
+type testVector struct {
+	Name string
+	Hex  string
+}
+
+// testRoundTrip checks that sources generated into dir decode every vector of
+// p, encoding them back into the very same bytes. program is written to file
+// with %VECTORS% replaced by vectors formatted by format, and commands are
+// then run on dir, failing the test when any of them fails.
+func testRoundTrip(t *testing.T, dir string, p *models.Package, file, program string, format func(v testVector) string, commands ...[]string) {
+	var vectors []string
+	for i, v := range instances.Vectors(p) {
+		data, err := codec.Encode(p, byte(i+1), v.Value)
+		if err != nil {
+			t.Fatalf("encoding %s: %s", v.Name, err)
+		}
+		vectors = append(vectors, format(testVector{v.Name, hex.EncodeToString(data)}))
+	}
+	program = strings.Replace(program, "%VECTORS%", strings.Join(vectors, ""), 1)
+	if err := ioutil.WriteFile(filepath.Join(dir, file), []byte(program), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, args := range commands {
+		cmd := exec.Command(args[0], args[1:]...)
+		cmd.Dir = dir
+		if output, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("running %s: %s\n%s", strings.Join(args, " "), err, output)
+		}
+	}
+}
+
+// testTool returns the path of an executable, skipping the test when it is
+// not available
+func testTool(t *testing.T, name string) string {
+	path, err := exec.LookPath(name)
+	if err != nil {
+		t.Skipf("%s is not available", name)
+	}
+	return path
+}
+
+// testContains fails the test when code lacks any of the snippets
+func testContains(t *testing.T, name, code string, snippets ...string) {
+	for _, s := range snippets {
+		if !strings.Contains(code, s) {
+			t.Errorf("%s does not contain %q", name, s)
+		}
+	}
+}
+
+// testExits checks that compile terminates the process with a failure, as
+// compilers do when rejecting packages. compile is run by a copy of the test
+// binary, which only executes the calling test.
+func testExits(t *testing.T, compile func()) {
+	if os.Getenv("LUDCO_TEST_EXIT") == t.Name() {
+		compile()
+		return
+	}
+	cmd := exec.Command(os.Args[0], "-test.run=^"+t.Name()+"$")
+	cmd.Env = append(os.Environ(), "LUDCO_TEST_EXIT="+t.Name())
+	output, err := cmd.CombinedOutput()
+	if e, ok := err.(*exec.ExitError); !ok || e.Success() {
+		t.Fatalf("compiler did not fail: %v\n%s", err, output)
+	}
+}
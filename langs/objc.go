@@ -68,6 +68,7 @@ func (c ObjC) writeEmptyPackage(p *models.Package) {
 	c.output(p.Name+".h", processTemplate("emptyPackageHeader", objcEmptyPackageHeader, templateData{
 		"prefix": c.prefix,
 		"name":   convertToPascalCase(p.Name),
+		"doc":    blockComment(p.Doc, "", "/*!"),
 	}))
 
 	c.output(p.Name+".m", processTemplate("emptyPackageImplementation", objcEmptyPackageImplementation, templateData{
@@ -83,6 +84,7 @@ func (c ObjC) writeNormalPackage(p *models.Package) {
 	c.output(p.Name+".h", processTemplate("objcPackageHeader", objcPackageHeader, templateData{
 		"prefix":     c.prefix,
 		"name":       pkgName,
+		"doc":        blockComment(p.Doc, "", "/*!"),
 		"fields":     c.generateFields(p.Fields, pkgName),
 		"structures": c.generateStructsHeaders(p.Structs, pkgName),
 	}))
@@ -146,6 +148,7 @@ func (c ObjC) writeStructHeaders(s *models.Struct, prefix string) []byte {
 
 	return processTemplate("objcStruct", objcStructHeader, templateData{
 		"name":       pkgName,
+		"doc":        blockComment(s.Doc, "", "/*!"),
 		"fields":     c.generateFields(s.Fields, pkgName),
 		"structures": c.generateStructsHeaders(s.Structs, pkgName),
 	})
@@ -191,7 +194,7 @@ func (c ObjC) writeField(f *models.Field, prefix string) []byte {
 		t = "NSArray<" + t + "> *"
 	}
 
-	t = "@property (nullable, nonatomic, retain) " + t + convertToCamelCase(f.Name) + ";\n"
+	t = blockComment(f.Doc, "", "/*!") + "@property (nullable, nonatomic, retain) " + t + convertToCamelCase(f.Name) + ";\n"
 	return []byte(t)
 }
 
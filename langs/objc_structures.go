@@ -4,7 +4,7 @@ const objcEmptyPackageHeader = `// WARNING: Automatically generated by ludco. DO
 #import <Foundation/Foundation.h>
 #import <Ludwieg/Ludwieg.h>
 
-@interface {{.prefix}}{{.name}} : NSObject <LUDSerializablePackage>
+{{.doc}}@interface {{.prefix}}{{.name}} : NSObject <LUDSerializablePackage>
 @end
 `
 
@@ -24,7 +24,7 @@ const objcPackageHeader = `// WARNING: Automatically generated by ludco. DO NOT
 #import <Ludwieg/Ludwieg.h>
 {{.structures}}
 
-@interface {{.prefix}}{{.name}} : NSObject <LUDSerializablePackage>
+{{.doc}}@interface {{.prefix}}{{.name}} : NSObject <LUDSerializablePackage>
 
 {{.fields}}
 @end
@@ -48,7 +48,7 @@ const objcPackageImplementation = `// WARNING: Automatically generated by ludco.
 `
 
 const objcStructHeader = `{{.structures}}
-@interface {{.name}} : NSObject <LUDSerializable>
+{{.doc}}@interface {{.name}} : NSObject <LUDSerializable>
 
 {{.fields}}
 @end`
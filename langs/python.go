@@ -0,0 +1,330 @@
+package langs
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/alecthomas/chroma/quick"
+	"github.com/logrusorgru/aurora"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/ludwieg/ludco/codec"
+	"github.com/ludwieg/ludco/models"
+)
+
+// pythonKeywords lists reserved words that cannot be used as attribute names
+var pythonKeywords = map[string]bool{
+	"False": true, "None": true, "True": true, "and": true, "as": true,
+	"assert": true, "async": true, "await": true, "break": true, "class": true,
+	"continue": true, "def": true, "del": true, "elif": true, "else": true,
+	"except": true, "finally": true, "for": true, "from": true, "global": true,
+	"if": true, "import": true, "in": true, "is": true, "lambda": true,
+	"nonlocal": true, "not": true, "or": true, "pass": true, "raise": true,
+	"return": true, "try": true, "while": true, "with": true, "yield": true,
+}
+
+// pythonReservedNames lists names imported by generated modules, or exported by
+// the runtime, which package classes must not shadow
+var pythonReservedNames = map[string]bool{
+	"AnyValue": true, "ClassVar": true, "DecodeError": true, "List": true,
+	"Message": true, "Optional": true, "ProtocolType": true, "Reader": true,
+	"Registry": true, "ShortBufferError": true, "T": true, "UUID": true,
+	"Writer": true,
+}
+
+type Python struct {
+	out string
+
+	// classes maps structures to the qualified name of their generated class
+	classes map[*models.Struct]string
+}
+
+// pythonImports collects names imported by a generated module
+type pythonImports map[string]bool
+
+func (c Python) Compile(in, out, pkgName, prefix string, packages *models.PackageList) {
+	log.Infof("Initialising %s compiler", aurora.Brown("Python"))
+	if pkgName != "" {
+		log.Warn("Ignoring unnecessary --package option")
+	}
+	if prefix != "" {
+		log.Warn("Ignoring unnecessary --prefix option.")
+	}
+	c.out = out
+	c.classes = map[*models.Struct]string{}
+
+	var magic []string
+	for _, b := range codec.Magic {
+		magic = append(magic, fmt.Sprintf("0x%02X", b))
+	}
+	c.output("ludwieg", processTemplate("pythonRuntime", pythonRuntime, templateData{
+		"magic":   strings.Join(magic, ", "),
+		"version": fmt.Sprintf("0x%02X", codec.ProtocolVersion),
+	}))
+
+	for i := range *packages {
+		c.writePackage(&(*packages)[i])
+	}
+	c.writeInit(packages)
+
+	log.Info("Succeeded")
+	fmt.Println()
+	fmt.Println(c.integrationInstructions(packages))
+}
+
+func (c Python) output(name string, contents []byte) {
+	log.Infof("Writing %s/%s", aurora.Magenta(filepath.Base(c.out)), aurora.Magenta(name+".py"))
+	err := ioutil.WriteFile(filepath.Join(c.out, name+".py"), contents, 0644)
+	if err != nil {
+		log.Errorf("Operation failed: %s", err)
+		os.Exit(1)
+	}
+}
+
+func (c Python) writePackage(p *models.Package) {
+	name := pythonClassName(p.Name)
+	c.registerStructs(name, p.Structs)
+
+	imports := pythonImports{"Reader": true, "Writer": true}
+	class := c.generateClass(name, name, fmt.Sprintf("LUDWIEG_ID: ClassVar[int] = %s", p.Identifier), p.Scope(), p.Fields, p.Structs, imports)
+	imports["ClassVar"] = true
+
+	c.output(pythonModule(p.Name), processTemplate("pythonPackage", pythonPackage, templateData{
+		"imports": imports.String(),
+		"class":   class,
+	}))
+}
+
+// String returns import statements for the collected names
+func (i pythonImports) String() string {
+	// dataclasses is imported as a module, as its members could be shadowed
+	// by fields of generated classes
+	lines := []string{"import dataclasses"}
+	modules := []struct {
+		module string
+		names  []string
+	}{
+		{"typing", []string{"ClassVar", "List", "Optional"}},
+		{"uuid", []string{"UUID"}},
+		{"", nil},
+		{".ludwieg", []string{"AnyValue", "Reader", "Writer"}},
+	}
+	for _, m := range modules {
+		if m.module == "" {
+			lines = append(lines, "")
+			continue
+		}
+		var names []string
+		for _, n := range m.names {
+			if i[n] {
+				names = append(names, n)
+			}
+		}
+		if len(names) > 0 {
+			lines = append(lines, fmt.Sprintf("from %s import %s", m.module, strings.Join(names, ", ")))
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// registerStructs assigns qualified class names to structures before classes
+// are generated, as fields may reference structures declared after them
+func (c Python) registerStructs(prefix string, sArr []models.Struct) {
+	for i := range sArr {
+		s := &sArr[i]
+		name := prefix + "." + convertToPascalCase(s.Name)
+		c.classes[s] = name
+		c.registerStructs(name, s.Structs)
+	}
+}
+
+// generateClass returns the dataclass of a package or structure, along with
+// classes of structures declared by it, which are nested into it
+func (c Python) generateClass(name, qualified, header string, scope *models.Scope, fArr []models.Field, sArr []models.Struct, imports pythonImports) string {
+	var body []string
+	if header != "" {
+		body = append(body, header, "")
+	}
+
+	var decode, encode []string
+	for _, f := range fArr {
+		decl, dec, enc := c.generateField(scope, &f, imports)
+		body = append(body, decl)
+		decode = append(decode, "    "+dec+"\n")
+		encode = append(encode, "    "+enc+"\n")
+	}
+	if len(fArr) > 0 {
+		body = append(body, "")
+	}
+	if len(encode) == 0 {
+		encode = append(encode, "    pass\n")
+	}
+
+	for i := range sArr {
+		s := &sArr[i]
+		inner := &models.Scope{Structs: s.Structs, Parent: scope}
+		body = append(body, c.generateClass(convertToPascalCase(s.Name), c.classes[s], "", inner, s.Fields, s.Structs, imports))
+	}
+
+	body = append(body, string(processTemplate("pythonMethods", pythonMethods, templateData{
+		"qualified": qualified,
+		"decode":    strings.Join(decode, ""),
+		"encode":    strings.Join(encode, ""),
+	})))
+
+	return string(processTemplate("pythonClass", pythonClass, templateData{
+		"name": name,
+		"body": indent(strings.Join(body, "\n")),
+	}))
+}
+
+// pythonType returns the type hint of a native type, along with the suffix
+// of the reader and writer methods handling it
+func pythonType(t models.NativeType, imports pythonImports) (string, string) {
+	switch t {
+	case models.TypeUint8, models.TypeByte:
+		return "int", "uint8"
+	case models.TypeUint32:
+		return "int", "uint32"
+	case models.TypeUint64:
+		return "int", "uint64"
+	case models.TypeDouble:
+		return "float", "double"
+	case models.TypeString:
+		return "str", "string"
+	case models.TypeBlob:
+		return "bytes", "blob"
+	case models.TypeBool:
+		return "bool", "bool"
+	case models.TypeUUID:
+		imports["UUID"] = true
+		return "UUID", "uuid"
+	case models.TypeAny:
+		imports["AnyValue"] = true
+		return "AnyValue", "any"
+	case models.TypeDynInt:
+		return "int", "dynint"
+	}
+	log.Fatalf("BUG: Cannot coerce unknown type %#v to native type.", t)
+	return "", ""
+}
+
+func pythonIdentifier(name string) string {
+	if pythonKeywords[name] {
+		return name + "_"
+	}
+	return name
+}
+
+// pythonModule returns the name of the module holding a package. Names
+// clashing with keywords or with the runtime module are escaped, as they could
+// not be imported otherwise, as is registry, which importing the module would
+// rebind on the parent package.
+func pythonModule(name string) string {
+	if pythonKeywords[name] || name == "ludwieg" || name == "registry" {
+		return name + "_"
+	}
+	return name
+}
+
+// pythonClassName returns the class name of a package, suffixing names of
+// runtime and imported types with an underscore
+func pythonClassName(name string) string {
+	name = convertToPascalCase(name)
+	if pythonReservedNames[name] {
+		return name + "_"
+	}
+	return name
+}
+
+// generateField returns the declaration of a field, along with statements
+// decoding and encoding it
+func (c Python) generateField(scope *models.Scope, f *models.Field, imports pythonImports) (string, string, string) {
+	name := pythonIdentifier(f.Name)
+	imports["Optional"] = true
+
+	var t, read, readItem, writeItem string
+	if f.Type.Source == models.SourceUser {
+		s, _, ok := scope.Resolve(f.Type.CustomType)
+		if !ok {
+			log.Errorf("BUG: Cannot resolve structure %s referenced by %s", f.Type.CustomType, f.Name)
+			os.Exit(1)
+		}
+		t = `"` + c.classes[s] + `"`
+		read = "r.read_struct(" + c.classes[s] + ")"
+		readItem = "lambda r: " + read
+		writeItem = "Writer.write_struct"
+	} else {
+		var kind string
+		t, kind = pythonType(f.Type.NativeType, imports)
+		read = "r.read_" + kind + "()"
+		readItem = "Reader.read_" + kind
+		writeItem = "Writer.write_" + kind
+	}
+
+	var dec, enc string
+	if f.IsArray() {
+		imports["List"] = true
+		t = "List[Optional[" + t + "]]"
+		size := "None"
+		if f.Size != "*" {
+			size = f.Size
+		}
+		dec = fmt.Sprintf("v.%s = r.read_array(%s)", name, readItem)
+		enc = fmt.Sprintf("w.write_array(self.%s, %s, %s)", name, size, writeItem)
+	} else {
+		dec = fmt.Sprintf("v.%s = %s", name, read)
+		enc = fmt.Sprintf("w.%s(self.%s)", strings.TrimPrefix(writeItem, "Writer."), name)
+	}
+
+	decl := fmt.Sprintf("%s: Optional[%s] = None", name, t)
+	if f.HasAttribute(models.AttributeDeprecated) {
+		decl = fmt.Sprintf(`%s: Optional[%s] = dataclasses.field(default=None, metadata={"deprecated": True})`, name, t)
+	}
+	return decl, dec, enc
+}
+
+func (c Python) writeInit(pList *models.PackageList) {
+	var imports, names []string
+	for _, p := range *pList {
+		name := pythonClassName(p.Name)
+		imports = append(imports, fmt.Sprintf("from .%s import %s", pythonModule(p.Name), name))
+		names = append(names, name)
+	}
+	sort.Strings(imports)
+
+	c.output("__init__", processTemplate("pythonInit", pythonInit, templateData{
+		"imports":  strings.Join(imports, "\n"),
+		"packages": strings.Join(names, ", "),
+	}))
+}
+
+func (c Python) formatCode(code string) string {
+	var buf bytes.Buffer
+	err := quick.Highlight(&buf, code, "python", "terminal", "pygments")
+	if err != nil {
+		log.Fatalf("BUG: Error processing Python source: %s", err)
+	}
+
+	return string(buf.Bytes())
+}
+
+func (c Python) integrationInstructions(pList *models.PackageList) string {
+	module := filepath.Base(c.out)
+	usage := strings.Join([]string{
+		"from " + module + " import registry",
+		"",
+		"     message, length = registry.decode(data)",
+		"     encoded = registry.encode(message.message_id, message.package)",
+	}, "\n")
+
+	return string(processTemplate("pythonIntegration", pythonIntegrationSteps, templateData{
+		"runtime":     aurora.Magenta("ludwieg.py"),
+		"pythonUsage": c.formatCode(usage),
+	}))
+}
@@ -0,0 +1,426 @@
+package langs
+
+const pythonPackage = `# WARNING: Automatically generated by ludco. DO NOT EDIT.
+
+{{.imports}}
+
+
+{{.class}}`
+
+const pythonClass = `@dataclasses.dataclass
+class {{.name}}:
+{{.body}}`
+
+const pythonMethods = `@classmethod
+def ludwieg_decode(cls, r: Reader) -> "{{.qualified}}":
+    v = cls()
+{{.decode}}    return v
+
+def ludwieg_encode(self, w: Writer) -> None:
+{{.encode}}`
+
+const pythonInit = `# WARNING: Automatically generated by ludco. DO NOT EDIT.
+
+from .ludwieg import *  # noqa: F401,F403
+from .ludwieg import registry
+{{.imports}}
+
+registry.register({{.packages}})
+`
+
+const pythonRuntime = `# WARNING: Automatically generated by ludco. DO NOT EDIT.
+"""
+Runtime used by generated packages to encode and decode values using the
+Ludwieg wire format. It only depends on the Python standard library, and
+requires Python 3.7 or later.
+"""
+
+import enum
+import struct
+import uuid
+from dataclasses import dataclass
+from typing import Any, Callable, Dict, List, Optional, Tuple, Type, TypeVar
+
+__all__ = [
+    "MAGIC", "PROTOCOL_VERSION", "ProtocolType", "AnyValue", "DecodeError",
+    "ShortBufferError", "Writer", "Reader", "Message", "Registry", "registry",
+]
+
+MAGIC = bytes([{{.magic}}])
+PROTOCOL_VERSION = {{.version}}
+FLAG_EMPTY = 0x80
+
+T = TypeVar("T")
+
+
+class ProtocolType(enum.IntEnum):
+    UINT8 = 0x01
+    UINT32 = 0x02
+    UINT64 = 0x03
+    DOUBLE = 0x04
+    STRING = 0x05
+    BLOB = 0x06
+    BOOL = 0x07
+    UUID = 0x08
+    ANY = 0x09
+    ARRAY = 0x0A
+    STRUCT = 0x0B
+    DYNINT = 0x0C
+
+
+@dataclass(frozen=True)
+class AnyValue:
+    """AnyValue holds values of any fields, along with their type"""
+    type: ProtocolType
+    value: Any
+
+
+class DecodeError(Exception):
+    """DecodeError indicates that data does not represent a valid message"""
+
+    def __init__(self, offset: int, reason: str):
+        super().__init__("offset %d: %s" % (offset, reason))
+        self.offset = offset
+        self.reason = reason
+
+
+class ShortBufferError(Exception):
+    """ShortBufferError indicates that data does not contain a complete
+    message yet"""
+
+    def __init__(self):
+        super().__init__("buffer does not contain a complete message")
+
+
+def _type_name(t: int) -> str:
+    try:
+        return ProtocolType(t).name.lower()
+    except ValueError:
+        return "unknown(0x%02x)" % t
+
+
+class Writer:
+    def __init__(self):
+        self._buf = bytearray()
+
+    def getvalue(self) -> bytes:
+        return bytes(self._buf)
+
+    def _empty(self, t: ProtocolType) -> None:
+        self._buf.append(t | FLAG_EMPTY)
+
+    def write_size(self, value: int) -> None:
+        """write_size writes a dynamic size, using the smallest width able to
+        hold the value"""
+        if value < 0 or value > 0xFFFFFFFFFFFFFFFF:
+            raise ValueError("size %d out of range" % value)
+        for width, fmt in ((1, "<B"), (2, "<H"), (4, "<I"), (8, "<Q")):
+            if value < 1 << (8 * width):
+                self._buf.append(width)
+                self._buf += struct.pack(fmt, value)
+                return
+
+    def _fixed(self, t: ProtocolType, fmt: str, value: Any) -> None:
+        if value is None:
+            return self._empty(t)
+        self._buf.append(t)
+        self._buf += struct.pack(fmt, value)
+
+    def write_uint8(self, value: Optional[int]) -> None:
+        self._fixed(ProtocolType.UINT8, "<B", value)
+
+    def write_uint32(self, value: Optional[int]) -> None:
+        self._fixed(ProtocolType.UINT32, "<I", value)
+
+    def write_uint64(self, value: Optional[int]) -> None:
+        self._fixed(ProtocolType.UINT64, "<Q", value)
+
+    def write_double(self, value: Optional[float]) -> None:
+        self._fixed(ProtocolType.DOUBLE, "<d", value)
+
+    def write_bool(self, value: Optional[bool]) -> None:
+        self._fixed(ProtocolType.BOOL, "<B", None if value is None else int(bool(value)))
+
+    def _sized(self, t: ProtocolType, data: Optional[bytes]) -> None:
+        if data is None:
+            return self._empty(t)
+        self._buf.append(t)
+        self.write_size(len(data))
+        self._buf += data
+
+    def write_string(self, value: Optional[str]) -> None:
+        self._sized(ProtocolType.STRING, None if value is None else value.encode("utf-8"))
+
+    def write_blob(self, value: Optional[bytes]) -> None:
+        self._sized(ProtocolType.BLOB, None if value is None else bytes(value))
+
+    def write_uuid(self, value: Optional[uuid.UUID]) -> None:
+        if value is None:
+            return self._empty(ProtocolType.UUID)
+        self._buf.append(ProtocolType.UUID)
+        self._buf += value.bytes
+
+    def write_dynint(self, value: Optional[int]) -> None:
+        if value is None:
+            return self._empty(ProtocolType.DYNINT)
+        self._buf.append(ProtocolType.DYNINT)
+        self.write_size(value)
+
+    def write_any(self, value: Optional[AnyValue]) -> None:
+        if value is None:
+            return self._empty(ProtocolType.ANY)
+        writer = _ANY_WRITERS.get(value.type)
+        if writer is None:
+            raise ValueError("%s values cannot be held by any" % _type_name(value.type))
+        self._buf.append(ProtocolType.ANY)
+        writer(self, value.value)
+
+    def write_array(self, items: Optional[List[Optional[T]]], size: Optional[int],
+                    element: Callable[["Writer", Optional[T]], None]) -> None:
+        """write_array writes items using element for each of them. When size
+        is provided, arrays holding more items are rejected."""
+        if items is None:
+            return self._empty(ProtocolType.ARRAY)
+        if size is not None and len(items) > size:
+            raise ValueError("array holds %d items, exceeding its size of %d" % (len(items), size))
+        body = Writer()
+        body.write_size(len(items))
+        for item in items:
+            element(body, item)
+        self._sized(ProtocolType.ARRAY, body.getvalue())
+
+    def write_struct(self, value: Any) -> None:
+        if value is None:
+            return self._empty(ProtocolType.STRUCT)
+        body = Writer()
+        value.ludwieg_encode(body)
+        self._sized(ProtocolType.STRUCT, body.getvalue())
+
+
+_ANY_WRITERS = {
+    ProtocolType.UINT8: Writer.write_uint8,
+    ProtocolType.UINT32: Writer.write_uint32,
+    ProtocolType.UINT64: Writer.write_uint64,
+    ProtocolType.DOUBLE: Writer.write_double,
+    ProtocolType.STRING: Writer.write_string,
+    ProtocolType.BLOB: Writer.write_blob,
+    ProtocolType.BOOL: Writer.write_bool,
+    ProtocolType.UUID: Writer.write_uuid,
+    ProtocolType.DYNINT: Writer.write_dynint,
+    ProtocolType.ARRAY: lambda w, v: w.write_array(v, None, Writer.write_any),
+}
+
+
+class Reader:
+    def __init__(self, data: bytes, position: int = 0, limit: Optional[int] = None):
+        self._data = data
+        self.position = position
+        self.limit = len(data) if limit is None else limit
+
+    def _take(self, n: int) -> bytes:
+        if n > self.limit - self.position:
+            raise DecodeError(self.position, "value exceeds message boundaries")
+        start = self.position
+        self.position += n
+        return self._data[start:self.position]
+
+    def _unpack(self, fmt: str) -> Any:
+        return struct.unpack(fmt, self._take(struct.calcsize(fmt)))[0]
+
+    def read_size(self) -> int:
+        offset = self.position
+        width = self._unpack("<B")
+        fmt = {1: "<B", 2: "<H", 4: "<I", 8: "<Q"}.get(width)
+        if fmt is None:
+            raise DecodeError(offset, "invalid size width %d" % width)
+        return self._unpack(fmt)
+
+    def _begin(self, t: ProtocolType) -> bool:
+        """_begin reads the type byte of a value, returning False when the
+        value is empty. Missing trailing values are also considered empty."""
+        if self.position >= self.limit:
+            return False
+        offset = self.position
+        b = self._unpack("<B")
+        if b & ~FLAG_EMPTY != t:
+            raise DecodeError(offset, "expected %s, found %s" % (_type_name(t), _type_name(b & ~FLAG_EMPTY)))
+        return b & FLAG_EMPTY == 0
+
+    def _body(self) -> "Reader":
+        """_body returns a reader limited to the next value, advancing past
+        it"""
+        size = self.read_size()
+        start = self.position
+        self._take(size)
+        return Reader(self._data, start, self.position)
+
+    def _fixed(self, t: ProtocolType, fmt: str) -> Any:
+        return self._unpack(fmt) if self._begin(t) else None
+
+    def read_uint8(self) -> Optional[int]:
+        return self._fixed(ProtocolType.UINT8, "<B")
+
+    def read_uint32(self) -> Optional[int]:
+        return self._fixed(ProtocolType.UINT32, "<I")
+
+    def read_uint64(self) -> Optional[int]:
+        return self._fixed(ProtocolType.UINT64, "<Q")
+
+    def read_double(self) -> Optional[float]:
+        return self._fixed(ProtocolType.DOUBLE, "<d")
+
+    def read_bool(self) -> Optional[bool]:
+        v = self._fixed(ProtocolType.BOOL, "<B")
+        return None if v is None else v != 0
+
+    def read_string(self) -> Optional[str]:
+        if not self._begin(ProtocolType.STRING):
+            return None
+        size = self.read_size()
+        offset = self.position
+        try:
+            return self._take(size).decode("utf-8")
+        except UnicodeDecodeError:
+            raise DecodeError(offset, "invalid UTF-8 string")
+
+    def read_blob(self) -> Optional[bytes]:
+        if not self._begin(ProtocolType.BLOB):
+            return None
+        return self._take(self.read_size())
+
+    def read_uuid(self) -> Optional[uuid.UUID]:
+        if not self._begin(ProtocolType.UUID):
+            return None
+        return uuid.UUID(bytes=self._take(16))
+
+    def read_dynint(self) -> Optional[int]:
+        return self.read_size() if self._begin(ProtocolType.DYNINT) else None
+
+    def read_any(self) -> Optional[AnyValue]:
+        if not self._begin(ProtocolType.ANY):
+            return None
+        if self.position >= self.limit:
+            raise DecodeError(self.position, "value exceeds message boundaries")
+        offset = self.position
+        t = self._data[self.position] & ~FLAG_EMPTY
+        reader = _ANY_READERS.get(t)
+        if reader is None:
+            raise DecodeError(offset, "%s values cannot be held by any" % _type_name(t))
+        value = reader(self)
+        return None if value is None else AnyValue(ProtocolType(t), value)
+
+    def read_array(self, element: Callable[["Reader"], Optional[T]]) -> Optional[List[Optional[T]]]:
+        """read_array reads an array, using element to read each of its
+        items"""
+        if not self._begin(ProtocolType.ARRAY):
+            return None
+        body = self._body()
+        count = body.read_size()
+        items = []
+        for _ in range(count):
+            if body.position >= body.limit:
+                raise DecodeError(body.position, "value exceeds message boundaries")
+            items.append(element(body))
+        return items
+
+    def read_struct(self, cls: Type[T]) -> Optional[T]:
+        if not self._begin(ProtocolType.STRUCT):
+            return None
+        return cls.ludwieg_decode(self._body())
+
+
+_ANY_READERS = {
+    ProtocolType.UINT8: Reader.read_uint8,
+    ProtocolType.UINT32: Reader.read_uint32,
+    ProtocolType.UINT64: Reader.read_uint64,
+    ProtocolType.DOUBLE: Reader.read_double,
+    ProtocolType.STRING: Reader.read_string,
+    ProtocolType.BLOB: Reader.read_blob,
+    ProtocolType.BOOL: Reader.read_bool,
+    ProtocolType.UUID: Reader.read_uuid,
+    ProtocolType.DYNINT: Reader.read_dynint,
+    ProtocolType.ARRAY: lambda r: r.read_array(Reader.read_any),
+}
+
+
+@dataclass
+class Message:
+    message_id: int
+    package_id: int
+    package: Any
+
+
+class Registry:
+    """Registry decodes messages into the packages registered on it"""
+
+    def __init__(self):
+        self._packages = {}  # type: Dict[int, type]
+
+    def register(self, *packages: type) -> None:
+        for p in packages:
+            self._packages[p.LUDWIEG_ID] = p
+
+    def decode(self, data: bytes) -> Tuple[Message, int]:
+        """decode decodes the message at the beginning of data, returning it
+        along with the amount of bytes it used. ShortBufferError is raised
+        when data does not contain a complete message yet."""
+        data = bytes(data)
+        header_size = len(MAGIC) + 3
+        if len(data) <= header_size:
+            raise ShortBufferError()
+        if data[:len(MAGIC)] != MAGIC:
+            raise DecodeError(0, "invalid magic")
+        version, message_id, package_id = data[len(MAGIC):header_size]
+        if version != PROTOCOL_VERSION:
+            raise DecodeError(len(MAGIC), "unsupported protocol version 0x%02x" % version)
+        if data[header_size] not in (1, 2, 4, 8):
+            raise DecodeError(header_size, "invalid size width %d" % data[header_size])
+
+        header = Reader(data, header_size)
+        try:
+            size = header.read_size()
+        except DecodeError:
+            raise ShortBufferError()
+        start = header.position
+        if size > len(data) - start:
+            raise ShortBufferError()
+
+        cls = self._packages.get(package_id)
+        if cls is None:
+            raise DecodeError(len(MAGIC) + 2, "unknown package 0x%02x" % package_id)
+        package = cls.ludwieg_decode(Reader(data, start, start + size))
+        return Message(message_id, package_id, package), start + size
+
+    def decode_all(self, data: bytes) -> List[Message]:
+        """decode_all decodes a sequence of messages"""
+        messages = []
+        while data:
+            message, n = self.decode(data)
+            messages.append(message)
+            data = data[n:]
+        return messages
+
+    def encode(self, message_id: int, package: Any) -> bytes:
+        """encode serializes a package into a message"""
+        body = Writer()
+        package.ludwieg_encode(body)
+        payload = body.getvalue()
+        w = Writer()
+        w._buf += MAGIC
+        w._buf += bytes([PROTOCOL_VERSION, message_id, type(package).LUDWIEG_ID])
+        w.write_size(len(payload))
+        w._buf += payload
+        return w.getvalue()
+
+
+registry = Registry()
+`
+
+const pythonIntegrationSteps = `You just generated Python sources. The output folder is a Python package, which
+can be imported once its parent folder is on your module search path. It
+contains {{.runtime}}, which holds the encoder and decoder used by generated
+packages, and requires no third-party dependencies. Packages are registered
+when the folder is imported:
+
+     {{.pythonUsage}}
+`
@@ -0,0 +1,80 @@
+package langs
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// pyReservedSource declares packages named after runtime modules and types
+const pyReservedSource = `package registry {
+    id 0x01
+    string text
+}
+
+package writer {
+    id 0x02
+    @reader value
+    struct reader {
+        string text
+    }
+}
+`
+
+// pyRoundTrip decodes every vector using generated sources, and checks that
+// encoding the decoded package again yields the very same bytes
+const pyRoundTrip = `from . import registry
+
+vectors = [
+%VECTORS%]
+
+failures = []
+for name, expected in vectors:
+    try:
+        message, length = registry.decode(bytes.fromhex(expected))
+        encoded = registry.encode(message.message_id, message.package).hex()
+        if length * 2 != len(expected) or encoded != expected:
+            failures.append("%s: encoded %s, expected %s" % (name, encoded, expected))
+    except Exception as e:
+        failures.append("%s: %r" % (name, e))
+if failures:
+    raise SystemExit("\n".join(failures))
+`
+
+func TestPythonRoundTrip(t *testing.T) {
+	python := testTool(t, "python3")
+	packages := testPackages(t, vectorSource, recursiveSource, pyReservedSource)
+	dir := testCompile(t, Python{}, "", "", packages)
+	defer os.RemoveAll(dir)
+
+	// The output folder is a package, so the check is run as one of its
+	// modules
+	run := fmt.Sprintf("import importlib, sys; sys.path.insert(0, '..'); importlib.import_module('%s.check')", filepath.Base(dir))
+	testRoundTrip(t, dir, &(*packages)[0], "check.py", pyRoundTrip, func(v testVector) string {
+		return fmt.Sprintf("    (%q, %q),\n", v.Name, v.Hex)
+	}, []string{python, "-c", run})
+}
+
+func TestPythonRecursiveStructs(t *testing.T) {
+	dir := testCompile(t, Python{}, "", "", testPackages(t, recursiveSource))
+	defer os.RemoveAll(dir)
+
+	testContains(t, "tree.py", testOutput(t, dir, "tree.py"),
+		`root: Optional["Tree.Node"] = None`,
+		`parent: Optional["Tree.Node"] = None`,
+		`back: Optional["Tree.A"] = None`)
+}
+
+func TestPythonReservedNames(t *testing.T) {
+	dir := testCompile(t, Python{}, "", "", testPackages(t, pyReservedSource))
+	defer os.RemoveAll(dir)
+
+	testContains(t, "__init__.py", testOutput(t, dir, "__init__.py"),
+		"from .registry_ import Registry_",
+		"from .writer import Writer_",
+		"registry.register(Registry_, Writer_)")
+	testContains(t, "writer.py", testOutput(t, dir, "writer.py"),
+		"class Writer_:",
+		"def ludwieg_encode(self, w: Writer) -> None:")
+}
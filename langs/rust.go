@@ -0,0 +1,383 @@
+package langs
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/alecthomas/chroma/quick"
+	"github.com/logrusorgru/aurora"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/ludwieg/ludco/codec"
+	"github.com/ludwieg/ludco/models"
+)
+
+// rustKeywords lists reserved words that must be written as raw identifiers
+// when used as field names
+var rustKeywords = map[string]bool{
+	"abstract": true, "as": true, "async": true, "await": true, "become": true,
+	"box": true, "break": true, "const": true, "continue": true, "do": true,
+	"dyn": true, "else": true, "enum": true, "extern": true, "false": true,
+	"final": true, "fn": true, "for": true, "if": true, "impl": true, "in": true,
+	"let": true, "loop": true, "macro": true, "match": true, "mod": true,
+	"move": true, "mut": true, "override": true, "priv": true, "pub": true,
+	"ref": true, "return": true, "static": true, "struct": true, "trait": true,
+	"true": true, "try": true, "type": true, "typeof": true, "unsafe": true,
+	"unsized": true, "use": true, "virtual": true, "where": true, "while": true,
+	"yield": true,
+}
+
+// rustReservedTypes lists runtime and prelude types referred to by generated
+// sources, which generated structures must not shadow
+var rustReservedTypes = map[string]bool{
+	"Any": true, "Box": true, "Codable": true, "Default": true, "Error": true,
+	"LudwiegPackage": true, "Option": true, "Package": true, "Reader": true,
+	"Result": true, "Self": true, "String": true, "Vec": true, "Writer": true,
+}
+
+type Rust struct {
+	out string
+
+	// structs maps structures to the name of their generated type
+	structs map[*models.Struct]string
+
+	// references maps structures to structures held by their non-array
+	// fields, which are used to detect recursive types
+	references map[*models.Struct][]*models.Struct
+}
+
+func (c Rust) Compile(in, out, pkgName, prefix string, packages *models.PackageList) {
+	log.Infof("Initialising %s compiler", aurora.Brown("Rust"))
+	if pkgName != "" {
+		log.Warn("Ignoring unnecessary --package option")
+	}
+	if prefix != "" {
+		log.Warn("Ignoring unnecessary --prefix option.")
+	}
+	c.out = out
+	c.structs = map[*models.Struct]string{}
+	c.references = map[*models.Struct][]*models.Struct{}
+
+	var magic []string
+	for _, b := range codec.Magic {
+		magic = append(magic, fmt.Sprintf("0x%02x", b))
+	}
+	c.output("runtime", processTemplate("rustRuntime", rustRuntime, templateData{
+		"magic":       strings.Join(magic, ", "),
+		"magicLength": len(codec.Magic),
+		"version":     fmt.Sprintf("0x%02x", codec.ProtocolVersion),
+	}))
+
+	for i := range *packages {
+		c.writePackage(&(*packages)[i])
+	}
+	c.writeMod(packages)
+
+	log.Info("Succeeded")
+	fmt.Println()
+	fmt.Println(c.integrationInstructions(packages))
+}
+
+func (c Rust) output(name string, contents []byte) {
+	log.Infof("Writing %s/%s", aurora.Magenta(filepath.Base(c.out)), aurora.Magenta(name+".rs"))
+	err := ioutil.WriteFile(filepath.Join(c.out, name+".rs"), contents, 0644)
+	if err != nil {
+		log.Errorf("Operation failed: %s", err)
+		os.Exit(1)
+	}
+}
+
+// rustModule returns the name of the module of a package. Keywords, along
+// with the names of the runtime and parent modules, are suffixed with an
+// underscore.
+func rustModule(name string) string {
+	if rustKeywords[name] || name == "self" || name == "super" || name == "crate" || name == "runtime" {
+		return name + "_"
+	}
+	return name
+}
+
+// rustTypeName suffixes names of runtime and prelude types with an
+// underscore
+func rustTypeName(name string) string {
+	if rustReservedTypes[name] {
+		return name + "_"
+	}
+	return name
+}
+
+func (c Rust) writePackage(p *models.Package) {
+	name := rustTypeName(convertToPascalCase(p.Name))
+	c.registerStructs(convertToPascalCase(p.Name), p.Scope(), p.Structs)
+
+	imports := map[string]bool{"Codable": true, "Error": true, "LudwiegPackage": true, "Reader": true, "Writer": true}
+	id := string(processTemplate("rustPackageID", rustPackageID, templateData{
+		"name": name,
+		"id":   p.Identifier,
+	}))
+	structs := []string{c.generateStruct(name, id, nil, p.Scope(), p.Fields, imports)}
+	c.generateStructs(p.Scope(), p.Structs, &structs, imports)
+
+	var importList []string
+	for i := range imports {
+		importList = append(importList, i)
+	}
+	sort.Strings(importList)
+
+	c.output(rustModule(p.Name), processTemplate("rustPackage", rustPackage, templateData{
+		"imports": "{" + strings.Join(importList, ", ") + "}",
+		"structs": strings.Join(structs, ""),
+	}))
+}
+
+// registerStructs assigns type names to structures before they are
+// generated, as fields may reference structures declared after them
+func (c Rust) registerStructs(prefix string, scope *models.Scope, sArr []models.Struct) {
+	for i := range sArr {
+		s := &sArr[i]
+		name := prefix + convertToPascalCase(s.Name)
+		c.structs[s] = rustTypeName(name)
+
+		inner := &models.Scope{Structs: s.Structs, Parent: scope}
+		for _, f := range s.Fields {
+			if f.Type.Source != models.SourceUser || f.IsArray() {
+				continue
+			}
+			if ref, _, ok := inner.Resolve(f.Type.CustomType); ok {
+				c.references[s] = append(c.references[s], ref)
+			}
+		}
+		c.registerStructs(name, inner, s.Structs)
+	}
+}
+
+// reaches determines whether a value of from may hold a value of to without
+// the indirection provided by arrays
+func (c Rust) reaches(from, to *models.Struct, visited map[*models.Struct]bool) bool {
+	if from == to {
+		return true
+	}
+	if visited[from] {
+		return false
+	}
+	visited[from] = true
+	for _, ref := range c.references[from] {
+		if c.reaches(ref, to, visited) {
+			return true
+		}
+	}
+	return false
+}
+
+func (c Rust) generateStructs(scope *models.Scope, sArr []models.Struct, structs *[]string, imports map[string]bool) {
+	for i := range sArr {
+		s := &sArr[i]
+		inner := &models.Scope{Structs: s.Structs, Parent: scope}
+		*structs = append(*structs, c.generateStruct(c.structs[s], "", s, inner, s.Fields, imports))
+		c.generateStructs(inner, s.Structs, structs, imports)
+	}
+}
+
+func (c Rust) generateStruct(name, id string, owner *models.Struct, scope *models.Scope, fArr []models.Field, imports map[string]bool) string {
+	var fields, decode, encode []string
+	for _, f := range fArr {
+		field, dec, enc := c.generateField(owner, scope, &f, imports)
+		fields = append(fields, field)
+		decode = append(decode, "        "+dec+"\n")
+		encode = append(encode, "        "+enc+"\n")
+	}
+
+	// Unused parameters and bindings of empty structures would otherwise
+	// cause warnings
+	reader, writer, binding := "r", "w", "let mut v"
+	if len(fArr) == 0 {
+		reader, writer, binding = "_r", "_w", "let v"
+	}
+	decode = append([]string{"        " + binding + " = Self::default();\n"}, decode...)
+
+	return string(processTemplate("rustStruct", rustStruct, templateData{
+		"name":   name,
+		"id":     id,
+		"fields": strings.Join(fields, ""),
+		"reader": reader,
+		"writer": writer,
+		"decode": strings.Join(decode, ""),
+		"encode": strings.Join(encode, ""),
+	}))
+}
+
+// rustType returns the Rust type holding values of a native type, along with
+// the suffix of the reader and writer methods handling it
+func rustType(t models.NativeType) (string, string) {
+	switch t {
+	case models.TypeUint8, models.TypeByte:
+		return "u8", "uint8"
+	case models.TypeUint32:
+		return "u32", "uint32"
+	case models.TypeUint64:
+		return "u64", "uint64"
+	case models.TypeDouble:
+		return "f64", "double"
+	case models.TypeString:
+		return "String", "string"
+	case models.TypeBlob:
+		return "Vec<u8>", "blob"
+	case models.TypeBool:
+		return "bool", "bool"
+	case models.TypeUUID:
+		return "[u8; 16]", "uuid"
+	case models.TypeAny:
+		return "Any", "any"
+	case models.TypeDynInt:
+		return "u64", "dynint"
+	}
+	log.Fatalf("BUG: Cannot coerce unknown type %#v to native type.", t)
+	return "", ""
+}
+
+// rustBorrow returns the method converting a reference to an optional value
+// into the argument expected by writers, or an empty string for values
+// passed by copy
+func rustBorrow(t models.NativeType) string {
+	switch t {
+	case models.TypeString, models.TypeBlob:
+		return "as_deref"
+	case models.TypeAny:
+		return "as_ref"
+	}
+	return ""
+}
+
+func rustIdentifier(name string) string {
+	switch {
+	case name == "self" || name == "super" || name == "crate":
+		// These cannot be used as raw identifiers
+		return name + "_"
+	case rustKeywords[name]:
+		return "r#" + name
+	}
+	return name
+}
+
+// generateField returns the declaration of a field, along with statements
+// decoding and encoding it
+func (c Rust) generateField(owner *models.Struct, scope *models.Scope, f *models.Field, imports map[string]bool) (string, string, string) {
+	name := rustIdentifier(f.Name)
+
+	var t, read, write, borrow string
+	boxed := false
+	if f.Type.Source == models.SourceUser {
+		s, _, ok := scope.Resolve(f.Type.CustomType)
+		if !ok {
+			log.Errorf("BUG: Cannot resolve structure %s referenced by %s", f.Type.CustomType, f.Name)
+			os.Exit(1)
+		}
+		t = c.structs[s]
+		read = "r.read_struct::<" + t + ">()"
+		write = "write_struct"
+		borrow = "as_ref"
+		boxed = !f.IsArray() && owner != nil && c.reaches(s, owner, map[*models.Struct]bool{})
+	} else {
+		var kind string
+		t, kind = rustType(f.Type.NativeType)
+		read = "r.read_" + kind + "()"
+		write = "write_" + kind
+		borrow = rustBorrow(f.Type.NativeType)
+		if f.Type.NativeType == models.TypeAny {
+			imports["Any"] = true
+		}
+	}
+
+	var dec, enc string
+	if f.IsArray() {
+		t = "Vec<Option<" + t + ">>"
+		size := "None"
+		if f.Size != "*" {
+			size = "Some(" + f.Size + ")"
+		}
+		item := "*v"
+		if borrow != "" {
+			item = "v." + borrow + "()"
+		}
+		dec = fmt.Sprintf("v.%s = r.read_array(|r| %s)?;", name, read)
+		enc = fmt.Sprintf("w.write_array(self.%s.as_deref(), %s, |w, v| w.%s(%s))?;", name, size, write, item)
+	} else if boxed {
+		// Recursive structures require indirection
+		t = "Box<" + t + ">"
+		dec = fmt.Sprintf("v.%s = %s?.map(Box::new);", name, read)
+		enc = fmt.Sprintf("w.%s(self.%s.as_deref())?;", write, name)
+	} else {
+		value := "self." + name
+		if borrow != "" {
+			value += "." + borrow + "()"
+		}
+		dec = fmt.Sprintf("v.%s = %s?;", name, read)
+		enc = fmt.Sprintf("w.%s(%s)?;", write, value)
+	}
+
+	decl := fmt.Sprintf("    pub %s: Option<%s>,\n", name, t)
+	if f.HasAttribute(models.AttributeDeprecated) {
+		decl = "    #[deprecated]\n" + decl
+	}
+	return decl, dec, enc
+}
+
+func (c Rust) writeMod(pList *models.PackageList) {
+	var modules, exports, variants, ids, decoders, encoders, conversions []string
+	for _, p := range *pList {
+		name := rustTypeName(convertToPascalCase(p.Name))
+		module := rustModule(p.Name)
+		qualified := module + "::" + name
+		modules = append(modules, fmt.Sprintf("pub mod %s;", module))
+		exports = append(exports, fmt.Sprintf("pub use self::%s::*;", module))
+		variants = append(variants, fmt.Sprintf("    %s(%s),\n", name, qualified))
+		ids = append(ids, fmt.Sprintf("            Package::%s(_) => %s::ID,\n", name, qualified))
+		decoders = append(decoders, fmt.Sprintf("            %s => Package::%s(%s::decode(&mut r)?),\n", p.Identifier, name, qualified))
+		encoders = append(encoders, fmt.Sprintf("            Package::%s(p) => p.encode(&mut w)?,\n", name))
+		conversions = append(conversions, string(processTemplate("rustConversion", rustConversion, templateData{
+			"type":    qualified,
+			"variant": name,
+		})))
+	}
+	sort.Strings(modules)
+	sort.Strings(exports)
+
+	c.output("mod", processTemplate("rustMod", rustMod, templateData{
+		"modules":     strings.Join(modules, "\n"),
+		"exports":     strings.Join(exports, "\n"),
+		"variants":    strings.Join(variants, ""),
+		"ids":         strings.Join(ids, ""),
+		"decoders":    strings.Join(decoders, ""),
+		"encoders":    strings.Join(encoders, ""),
+		"conversions": strings.Join(conversions, ""),
+	}))
+}
+
+func (c Rust) formatCode(code string) string {
+	var buf bytes.Buffer
+	err := quick.Highlight(&buf, code, "rust", "terminal", "pygments")
+	if err != nil {
+		log.Fatalf("BUG: Error processing Rust source: %s", err)
+	}
+
+	return string(buf.Bytes())
+}
+
+func (c Rust) integrationInstructions(pList *models.PackageList) string {
+	module := filepath.Base(c.out)
+	usage := strings.Join([]string{
+		"let (message_id, package, length) = " + module + "::Package::decode(&data)?;",
+		"     let encoded = package.encode(message_id)?;",
+	}, "\n")
+
+	return string(processTemplate("rustIntegration", rustIntegrationSteps, templateData{
+		"rustMod":   c.formatCode("mod " + module + ";"),
+		"package":   aurora.Bold("Package"),
+		"rustUsage": c.formatCode(usage),
+	}))
+}
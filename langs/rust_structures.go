@@ -0,0 +1,615 @@
+package langs
+
+const rustPackage = `// WARNING: Automatically generated by ludco. DO NOT EDIT.
+
+#![allow(deprecated)]
+
+use super::runtime::{{.imports}};
+{{.structs}}`
+
+const rustStruct = `
+#[derive(Debug, Clone, Default, PartialEq)]
+pub struct {{.name}} {
+{{.fields}}}
+{{.id}}
+impl Codable for {{.name}} {
+    fn decode({{.reader}}: &mut Reader) -> Result<Self, Error> {
+{{.decode}}        Ok(v)
+    }
+
+    fn encode(&self, {{.writer}}: &mut Writer) -> Result<(), Error> {
+{{.encode}}        Ok(())
+    }
+}
+`
+
+const rustPackageID = `
+impl LudwiegPackage for {{.name}} {
+    const ID: u8 = {{.id}};
+}
+`
+
+const rustMod = `// WARNING: Automatically generated by ludco. DO NOT EDIT.
+
+#![allow(unused_imports)]
+
+pub mod runtime;
+{{.modules}}
+
+pub use self::runtime::{Any, Codable, Error, LudwiegPackage, Reader, Writer};
+{{.exports}}
+
+/// Package holds any package of the protocol, and dispatches messages to
+/// their types based on package identifiers
+#[derive(Debug, Clone, PartialEq)]
+pub enum Package {
+{{.variants}}}
+
+impl Package {
+    /// id returns the identifier of the package
+    pub fn id(&self) -> u8 {
+        match self {
+{{.ids}}        }
+    }
+
+    /// decode decodes the message at the beginning of data, returning its
+    /// message identifier, package, and the amount of bytes it used.
+    /// Error::ShortBuffer is returned when data does not contain a complete
+    /// message yet.
+    pub fn decode(data: &[u8]) -> Result<(u8, Package, usize), Error> {
+        let (message_id, package_id, mut r, length) = runtime::decode_header(data)?;
+        let package = match package_id {
+{{.decoders}}            id => return Err(Error::UnknownPackage(id)),
+        };
+        Ok((message_id, package, length))
+    }
+
+    /// encode serializes the package into a message with the given
+    /// identifier
+    pub fn encode(&self, message_id: u8) -> Result<Vec<u8>, Error> {
+        let mut w = Writer::new();
+        match self {
+{{.encoders}}        }
+        Ok(runtime::encode_message(message_id, self.id(), &w.into_bytes()))
+    }
+}
+{{.conversions}}`
+
+const rustConversion = `
+impl From<{{.type}}> for Package {
+    fn from(p: {{.type}}) -> Self {
+        Package::{{.variant}}(p)
+    }
+}
+`
+
+const rustRuntime = `// WARNING: Automatically generated by ludco. DO NOT EDIT.
+//
+// Runtime used by generated packages to encode and decode values using the
+// Ludwieg wire format.
+
+#![allow(dead_code)]
+
+use std::fmt;
+
+pub const MAGIC: [u8; {{.magicLength}}] = [{{.magic}}];
+pub const PROTOCOL_VERSION: u8 = {{.version}};
+const FLAG_EMPTY: u8 = 0x80;
+
+pub const TYPE_UINT8: u8 = 0x01;
+pub const TYPE_UINT32: u8 = 0x02;
+pub const TYPE_UINT64: u8 = 0x03;
+pub const TYPE_DOUBLE: u8 = 0x04;
+pub const TYPE_STRING: u8 = 0x05;
+pub const TYPE_BLOB: u8 = 0x06;
+pub const TYPE_BOOL: u8 = 0x07;
+pub const TYPE_UUID: u8 = 0x08;
+pub const TYPE_ANY: u8 = 0x09;
+pub const TYPE_ARRAY: u8 = 0x0A;
+pub const TYPE_STRUCT: u8 = 0x0B;
+pub const TYPE_DYNINT: u8 = 0x0C;
+
+#[derive(Debug, Clone, PartialEq, Eq)]
+pub enum Error {
+    /// Data does not contain a complete message yet
+    ShortBuffer,
+    InvalidMagic,
+    UnsupportedVersion(u8),
+    UnknownPackage(u8),
+    InvalidSizeWidth { width: u8, offset: usize },
+    UnexpectedType { expected: u8, found: u8, offset: usize },
+    ExceedsBoundaries { offset: usize },
+    InvalidString { offset: usize },
+    InvalidAny { offset: usize },
+    ArrayTooLarge { count: usize, size: usize },
+}
+
+impl fmt::Display for Error {
+    fn fmt(&self, f: &mut fmt::Formatter) -> fmt::Result {
+        match self {
+            Error::ShortBuffer => write!(f, "buffer does not contain a complete message"),
+            Error::InvalidMagic => write!(f, "invalid magic"),
+            Error::UnsupportedVersion(v) => write!(f, "unsupported protocol version 0x{:02x}", v),
+            Error::UnknownPackage(id) => write!(f, "unknown package 0x{:02x}", id),
+            Error::InvalidSizeWidth { width, offset } => write!(f, "offset {}: invalid size width {}", offset, width),
+            Error::UnexpectedType { expected, found, offset } => {
+                write!(f, "offset {}: expected {}, found {}", offset, type_name(*expected), type_name(*found))
+            }
+            Error::ExceedsBoundaries { offset } => write!(f, "offset {}: value exceeds message boundaries", offset),
+            Error::InvalidString { offset } => write!(f, "offset {}: invalid UTF-8 string", offset),
+            Error::InvalidAny { offset } => write!(f, "offset {}: value cannot be held by any", offset),
+            Error::ArrayTooLarge { count, size } => {
+                write!(f, "array holds {} items, exceeding its size of {}", count, size)
+            }
+        }
+    }
+}
+
+impl std::error::Error for Error {}
+
+fn type_name(t: u8) -> &'static str {
+    match t {
+        TYPE_UINT8 => "uint8",
+        TYPE_UINT32 => "uint32",
+        TYPE_UINT64 => "uint64",
+        TYPE_DOUBLE => "double",
+        TYPE_STRING => "string",
+        TYPE_BLOB => "blob",
+        TYPE_BOOL => "bool",
+        TYPE_UUID => "uuid",
+        TYPE_ANY => "any",
+        TYPE_ARRAY => "array",
+        TYPE_STRUCT => "struct",
+        TYPE_DYNINT => "dynint",
+        _ => "unknown type",
+    }
+}
+
+/// Any holds values of any fields, along with their type
+#[derive(Debug, Clone, PartialEq)]
+pub enum Any {
+    Uint8(u8),
+    Uint32(u32),
+    Uint64(u64),
+    Double(f64),
+    String(String),
+    Blob(Vec<u8>),
+    Bool(bool),
+    Uuid([u8; 16]),
+    DynInt(u64),
+    Array(Vec<Option<Any>>),
+}
+
+pub trait Codable: Sized {
+    fn decode(r: &mut Reader) -> Result<Self, Error>;
+    fn encode(&self, w: &mut Writer) -> Result<(), Error>;
+}
+
+pub trait LudwiegPackage: Codable {
+    const ID: u8;
+}
+
+#[derive(Debug, Default)]
+pub struct Writer {
+    buf: Vec<u8>,
+}
+
+impl Writer {
+    pub fn new() -> Self {
+        Self::default()
+    }
+
+    pub fn into_bytes(self) -> Vec<u8> {
+        self.buf
+    }
+
+    fn empty(&mut self, t: u8) -> Result<(), Error> {
+        self.buf.push(t | FLAG_EMPTY);
+        Ok(())
+    }
+
+    /// write_size writes a dynamic size, using the smallest width able to
+    /// hold the value
+    pub fn write_size(&mut self, value: u64) {
+        if value <= u64::from(u8::max_value()) {
+            self.buf.push(1);
+            self.buf.push(value as u8);
+        } else if value <= u64::from(u16::max_value()) {
+            self.buf.push(2);
+            self.buf.extend_from_slice(&(value as u16).to_le_bytes());
+        } else if value <= u64::from(u32::max_value()) {
+            self.buf.push(4);
+            self.buf.extend_from_slice(&(value as u32).to_le_bytes());
+        } else {
+            self.buf.push(8);
+            self.buf.extend_from_slice(&value.to_le_bytes());
+        }
+    }
+
+    fn sized(&mut self, t: u8, data: &[u8]) -> Result<(), Error> {
+        self.buf.push(t);
+        self.write_size(data.len() as u64);
+        self.buf.extend_from_slice(data);
+        Ok(())
+    }
+
+    pub fn write_uint8(&mut self, value: Option<u8>) -> Result<(), Error> {
+        match value {
+            None => self.empty(TYPE_UINT8),
+            Some(v) => {
+                self.buf.extend_from_slice(&[TYPE_UINT8, v]);
+                Ok(())
+            }
+        }
+    }
+
+    pub fn write_uint32(&mut self, value: Option<u32>) -> Result<(), Error> {
+        match value {
+            None => self.empty(TYPE_UINT32),
+            Some(v) => {
+                self.buf.push(TYPE_UINT32);
+                self.buf.extend_from_slice(&v.to_le_bytes());
+                Ok(())
+            }
+        }
+    }
+
+    pub fn write_uint64(&mut self, value: Option<u64>) -> Result<(), Error> {
+        match value {
+            None => self.empty(TYPE_UINT64),
+            Some(v) => {
+                self.buf.push(TYPE_UINT64);
+                self.buf.extend_from_slice(&v.to_le_bytes());
+                Ok(())
+            }
+        }
+    }
+
+    pub fn write_double(&mut self, value: Option<f64>) -> Result<(), Error> {
+        match value {
+            None => self.empty(TYPE_DOUBLE),
+            Some(v) => {
+                self.buf.push(TYPE_DOUBLE);
+                self.buf.extend_from_slice(&v.to_bits().to_le_bytes());
+                Ok(())
+            }
+        }
+    }
+
+    pub fn write_string(&mut self, value: Option<&str>) -> Result<(), Error> {
+        match value {
+            None => self.empty(TYPE_STRING),
+            Some(v) => self.sized(TYPE_STRING, v.as_bytes()),
+        }
+    }
+
+    pub fn write_blob(&mut self, value: Option<&[u8]>) -> Result<(), Error> {
+        match value {
+            None => self.empty(TYPE_BLOB),
+            Some(v) => self.sized(TYPE_BLOB, v),
+        }
+    }
+
+    pub fn write_bool(&mut self, value: Option<bool>) -> Result<(), Error> {
+        match value {
+            None => self.empty(TYPE_BOOL),
+            Some(v) => {
+                self.buf.extend_from_slice(&[TYPE_BOOL, v as u8]);
+                Ok(())
+            }
+        }
+    }
+
+    pub fn write_uuid(&mut self, value: Option<[u8; 16]>) -> Result<(), Error> {
+        match value {
+            None => self.empty(TYPE_UUID),
+            Some(v) => {
+                self.buf.push(TYPE_UUID);
+                self.buf.extend_from_slice(&v);
+                Ok(())
+            }
+        }
+    }
+
+    pub fn write_dynint(&mut self, value: Option<u64>) -> Result<(), Error> {
+        match value {
+            None => self.empty(TYPE_DYNINT),
+            Some(v) => {
+                self.buf.push(TYPE_DYNINT);
+                self.write_size(v);
+                Ok(())
+            }
+        }
+    }
+
+    pub fn write_any(&mut self, value: Option<&Any>) -> Result<(), Error> {
+        let value = match value {
+            None => return self.empty(TYPE_ANY),
+            Some(v) => v,
+        };
+        self.buf.push(TYPE_ANY);
+        match value {
+            Any::Uint8(v) => self.write_uint8(Some(*v)),
+            Any::Uint32(v) => self.write_uint32(Some(*v)),
+            Any::Uint64(v) => self.write_uint64(Some(*v)),
+            Any::Double(v) => self.write_double(Some(*v)),
+            Any::String(v) => self.write_string(Some(v)),
+            Any::Blob(v) => self.write_blob(Some(v)),
+            Any::Bool(v) => self.write_bool(Some(*v)),
+            Any::Uuid(v) => self.write_uuid(Some(*v)),
+            Any::DynInt(v) => self.write_dynint(Some(*v)),
+            Any::Array(v) => self.write_array(Some(v), None, |w, item| w.write_any(item.as_ref())),
+        }
+    }
+
+    /// write_array writes items using element for each of them. When size
+    /// is provided, arrays holding more items are rejected.
+    pub fn write_array<T, F>(&mut self, items: Option<&[Option<T>]>, size: Option<usize>, mut element: F) -> Result<(), Error>
+    where
+        F: FnMut(&mut Writer, &Option<T>) -> Result<(), Error>,
+    {
+        let items = match items {
+            None => return self.empty(TYPE_ARRAY),
+            Some(v) => v,
+        };
+        if let Some(size) = size {
+            if items.len() > size {
+                return Err(Error::ArrayTooLarge { count: items.len(), size });
+            }
+        }
+        let mut body = Writer::new();
+        body.write_size(items.len() as u64);
+        for item in items {
+            element(&mut body, item)?;
+        }
+        self.sized(TYPE_ARRAY, &body.buf)
+    }
+
+    pub fn write_struct<T: Codable>(&mut self, value: Option<&T>) -> Result<(), Error> {
+        let value = match value {
+            None => return self.empty(TYPE_STRUCT),
+            Some(v) => v,
+        };
+        let mut body = Writer::new();
+        value.encode(&mut body)?;
+        self.sized(TYPE_STRUCT, &body.buf)
+    }
+}
+
+pub struct Reader<'a> {
+    data: &'a [u8],
+    pos: usize,
+    limit: usize,
+}
+
+impl<'a> Reader<'a> {
+    pub fn new(data: &'a [u8]) -> Self {
+        Reader { data, pos: 0, limit: data.len() }
+    }
+
+    fn take(&mut self, n: u64) -> Result<&'a [u8], Error> {
+        if n > (self.limit - self.pos) as u64 {
+            return Err(Error::ExceedsBoundaries { offset: self.pos });
+        }
+        let start = self.pos;
+        self.pos += n as usize;
+        Ok(&self.data[start..self.pos])
+    }
+
+    fn byte(&mut self) -> Result<u8, Error> {
+        Ok(self.take(1)?[0])
+    }
+
+    fn fixed<const N: usize>(&mut self) -> Result<[u8; N], Error> {
+        let mut v = [0u8; N];
+        v.copy_from_slice(self.take(N as u64)?);
+        Ok(v)
+    }
+
+    pub fn read_size(&mut self) -> Result<u64, Error> {
+        let offset = self.pos;
+        match self.byte()? {
+            1 => Ok(u64::from(self.byte()?)),
+            2 => Ok(u64::from(u16::from_le_bytes(self.fixed()?))),
+            4 => Ok(u64::from(u32::from_le_bytes(self.fixed()?))),
+            8 => Ok(u64::from_le_bytes(self.fixed()?)),
+            width => Err(Error::InvalidSizeWidth { width, offset }),
+        }
+    }
+
+    /// begin reads the type byte of a value, returning false when the value
+    /// is empty. Missing trailing values are also considered empty.
+    fn begin(&mut self, t: u8) -> Result<bool, Error> {
+        if self.pos >= self.limit {
+            return Ok(false);
+        }
+        let offset = self.pos;
+        let b = self.byte()?;
+        if b & !FLAG_EMPTY != t {
+            return Err(Error::UnexpectedType { expected: t, found: b & !FLAG_EMPTY, offset });
+        }
+        Ok(b & FLAG_EMPTY == 0)
+    }
+
+    /// body returns a reader limited to the next value, advancing past it
+    fn body(&mut self) -> Result<Reader<'a>, Error> {
+        let size = self.read_size()?;
+        let start = self.pos;
+        self.take(size)?;
+        Ok(Reader { data: self.data, pos: start, limit: self.pos })
+    }
+
+    pub fn read_uint8(&mut self) -> Result<Option<u8>, Error> {
+        if !self.begin(TYPE_UINT8)? {
+            return Ok(None);
+        }
+        Ok(Some(self.byte()?))
+    }
+
+    pub fn read_uint32(&mut self) -> Result<Option<u32>, Error> {
+        if !self.begin(TYPE_UINT32)? {
+            return Ok(None);
+        }
+        Ok(Some(u32::from_le_bytes(self.fixed()?)))
+    }
+
+    pub fn read_uint64(&mut self) -> Result<Option<u64>, Error> {
+        if !self.begin(TYPE_UINT64)? {
+            return Ok(None);
+        }
+        Ok(Some(u64::from_le_bytes(self.fixed()?)))
+    }
+
+    pub fn read_double(&mut self) -> Result<Option<f64>, Error> {
+        if !self.begin(TYPE_DOUBLE)? {
+            return Ok(None);
+        }
+        Ok(Some(f64::from_bits(u64::from_le_bytes(self.fixed()?))))
+    }
+
+    pub fn read_string(&mut self) -> Result<Option<String>, Error> {
+        if !self.begin(TYPE_STRING)? {
+            return Ok(None);
+        }
+        let size = self.read_size()?;
+        let offset = self.pos;
+        let data = self.take(size)?;
+        match std::str::from_utf8(data) {
+            Ok(s) => Ok(Some(s.to_owned())),
+            Err(_) => Err(Error::InvalidString { offset }),
+        }
+    }
+
+    pub fn read_blob(&mut self) -> Result<Option<Vec<u8>>, Error> {
+        if !self.begin(TYPE_BLOB)? {
+            return Ok(None);
+        }
+        let size = self.read_size()?;
+        Ok(Some(self.take(size)?.to_vec()))
+    }
+
+    pub fn read_bool(&mut self) -> Result<Option<bool>, Error> {
+        if !self.begin(TYPE_BOOL)? {
+            return Ok(None);
+        }
+        Ok(Some(self.byte()? != 0))
+    }
+
+    pub fn read_uuid(&mut self) -> Result<Option<[u8; 16]>, Error> {
+        if !self.begin(TYPE_UUID)? {
+            return Ok(None);
+        }
+        Ok(Some(self.fixed()?))
+    }
+
+    pub fn read_dynint(&mut self) -> Result<Option<u64>, Error> {
+        if !self.begin(TYPE_DYNINT)? {
+            return Ok(None);
+        }
+        Ok(Some(self.read_size()?))
+    }
+
+    pub fn read_any(&mut self) -> Result<Option<Any>, Error> {
+        if !self.begin(TYPE_ANY)? {
+            return Ok(None);
+        }
+        if self.pos >= self.limit {
+            return Err(Error::ExceedsBoundaries { offset: self.pos });
+        }
+        let offset = self.pos;
+        Ok(match self.data[self.pos] & !FLAG_EMPTY {
+            TYPE_UINT8 => self.read_uint8()?.map(Any::Uint8),
+            TYPE_UINT32 => self.read_uint32()?.map(Any::Uint32),
+            TYPE_UINT64 => self.read_uint64()?.map(Any::Uint64),
+            TYPE_DOUBLE => self.read_double()?.map(Any::Double),
+            TYPE_STRING => self.read_string()?.map(Any::String),
+            TYPE_BLOB => self.read_blob()?.map(Any::Blob),
+            TYPE_BOOL => self.read_bool()?.map(Any::Bool),
+            TYPE_UUID => self.read_uuid()?.map(Any::Uuid),
+            TYPE_DYNINT => self.read_dynint()?.map(Any::DynInt),
+            TYPE_ARRAY => self.read_array(|r| r.read_any())?.map(Any::Array),
+            _ => return Err(Error::InvalidAny { offset }),
+        })
+    }
+
+    /// read_array reads an array, using element to read each of its items
+    pub fn read_array<T, F>(&mut self, mut element: F) -> Result<Option<Vec<Option<T>>>, Error>
+    where
+        F: FnMut(&mut Reader<'a>) -> Result<Option<T>, Error>,
+    {
+        if !self.begin(TYPE_ARRAY)? {
+            return Ok(None);
+        }
+        let mut body = self.body()?;
+        let count = body.read_size()?;
+        let mut items = Vec::new();
+        for _ in 0..count {
+            if body.pos >= body.limit {
+                return Err(Error::ExceedsBoundaries { offset: body.pos });
+            }
+            items.push(element(&mut body)?);
+        }
+        Ok(Some(items))
+    }
+
+    pub fn read_struct<T: Codable>(&mut self) -> Result<Option<T>, Error> {
+        if !self.begin(TYPE_STRUCT)? {
+            return Ok(None);
+        }
+        let mut body = self.body()?;
+        Ok(Some(T::decode(&mut body)?))
+    }
+}
+
+/// decode_header validates the header of the message at the beginning of
+/// data, returning its message and package identifiers, a reader for its
+/// payload, and the length of the message
+pub fn decode_header(data: &[u8]) -> Result<(u8, u8, Reader<'_>, usize), Error> {
+    let header_size = MAGIC.len() + 3;
+    if data.len() <= header_size {
+        return Err(Error::ShortBuffer);
+    }
+    if data[..MAGIC.len()] != MAGIC {
+        return Err(Error::InvalidMagic);
+    }
+    let version = data[MAGIC.len()];
+    if version != PROTOCOL_VERSION {
+        return Err(Error::UnsupportedVersion(version));
+    }
+    let mut header = Reader { data, pos: header_size, limit: data.len() };
+    let size = match header.read_size() {
+        Ok(size) => size,
+        Err(Error::ExceedsBoundaries { .. }) => return Err(Error::ShortBuffer),
+        Err(e) => return Err(e),
+    };
+    let start = header.pos;
+    if size > (data.len() - start) as u64 {
+        return Err(Error::ShortBuffer);
+    }
+    let end = start + size as usize;
+    let payload = Reader { data, pos: start, limit: end };
+    Ok((data[MAGIC.len() + 1], data[MAGIC.len() + 2], payload, end))
+}
+
+/// encode_message prepends a message header to an encoded payload
+pub fn encode_message(message_id: u8, package_id: u8, payload: &[u8]) -> Vec<u8> {
+    let mut w = Writer::new();
+    w.buf.extend_from_slice(&MAGIC);
+    w.buf.extend_from_slice(&[PROTOCOL_VERSION, message_id, package_id]);
+    w.write_size(payload.len() as u64);
+    w.buf.extend_from_slice(payload);
+    w.buf
+}
+`
+
+const rustIntegrationSteps = `You just generated Rust sources. The output folder is a Rust module, which does
+not require any external crate. Declare it in your crate, using the name of the
+output folder:
+
+     {{.rustMod}}
+
+Messages are decoded and encoded through the {{.package}} enum, which holds a
+variant for each package:
+
+     {{.rustUsage}}
+`
@@ -0,0 +1,104 @@
+package langs
+
+import (
+	"fmt"
+	"os"
+	"testing"
+)
+
+// rustReservedSource declares packages named after keywords, modules and
+// types used by generated sources
+const rustReservedSource = `package runtime {
+    id 0x01
+    string text
+}
+
+package type {
+    id 0x02
+    string text
+}
+
+package option {
+    id 0x03
+    @vec items
+    struct vec {
+        string text
+    }
+}
+`
+
+// rustRoundTrip decodes every vector using generated sources, and checks that
+// encoding the decoded package again yields the very same bytes
+const rustRoundTrip = `#[path = "mod.rs"]
+mod ludwieg;
+
+const VECTORS: &[(&str, &str)] = &[
+%VECTORS%];
+
+fn from_hex(value: &str) -> Vec<u8> {
+    (0..value.len()).step_by(2).map(|i| u8::from_str_radix(&value[i..i + 2], 16).unwrap()).collect()
+}
+
+fn main() {
+    let mut failures = 0;
+    for (name, expected) in VECTORS {
+        let data = from_hex(expected);
+        match ludwieg::Package::decode(&data) {
+            Ok((message_id, package, length)) => match package.encode(message_id) {
+                Ok(encoded) if length == data.len() && encoded == data => {}
+                Ok(_) => {
+                    println!("{}: encoded message differs", name);
+                    failures += 1;
+                }
+                Err(e) => {
+                    println!("{}: {:?}", name, e);
+                    failures += 1;
+                }
+            },
+            Err(e) => {
+                println!("{}: {:?}", name, e);
+                failures += 1;
+            }
+        }
+    }
+    std::process::exit(if failures > 0 { 1 } else { 0 });
+}
+`
+
+func TestRustRoundTrip(t *testing.T) {
+	rustc := testTool(t, "rustc")
+	packages := testPackages(t, vectorSource, recursiveSource, rustReservedSource)
+	dir := testCompile(t, Rust{}, "", "", packages)
+	defer os.RemoveAll(dir)
+
+	testRoundTrip(t, dir, &(*packages)[0], "main.rs", rustRoundTrip, func(v testVector) string {
+		return fmt.Sprintf("    (%q, %q),\n", v.Name, v.Hex)
+	}, []string{rustc, "--edition", "2018", "-o", "check", "main.rs"}, []string{"./check"})
+}
+
+func TestRustRecursiveStructs(t *testing.T) {
+	dir := testCompile(t, Rust{}, "", "", testPackages(t, recursiveSource))
+	defer os.RemoveAll(dir)
+
+	testContains(t, "tree.rs", testOutput(t, dir, "tree.rs"),
+		"pub root: Option<TreeNode>,",
+		"pub parent: Option<Box<TreeNode>>,",
+		"pub children: Option<Vec<Option<TreeNode>>>,",
+		"pub next: Option<Box<TreeB>>,",
+		"pub back: Option<Box<TreeA>>,")
+}
+
+func TestRustReservedNames(t *testing.T) {
+	dir := testCompile(t, Rust{}, "", "", testPackages(t, rustReservedSource))
+	defer os.RemoveAll(dir)
+
+	testContains(t, "mod.rs", testOutput(t, dir, "mod.rs"),
+		"pub mod runtime;",
+		"pub mod runtime_;",
+		"pub mod type_;",
+		"Option_(option::Option_),")
+	testContains(t, "option.rs", testOutput(t, dir, "option.rs"),
+		"pub struct Option_ {",
+		"pub items: Option<OptionVec>,")
+	testContains(t, "runtime.rs", testOutput(t, dir, "runtime.rs"), "pub struct Writer {")
+}
@@ -0,0 +1,341 @@
+package langs
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/alecthomas/chroma/quick"
+	"github.com/logrusorgru/aurora"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/ludwieg/ludco/codec"
+	"github.com/ludwieg/ludco/models"
+)
+
+// swiftRuntimeFile holds the name of the runtime written alongside generated
+// packages
+const swiftRuntimeFile = "Ludwieg.swift"
+
+// swiftKeywords lists reserved words that must be escaped when used as
+// property names
+var swiftKeywords = map[string]bool{
+	"as": true, "associatedtype": true, "break": true, "case": true, "catch": true,
+	"class": true, "continue": true, "default": true, "defer": true, "deinit": true,
+	"do": true, "else": true, "enum": true, "extension": true, "fallthrough": true,
+	"false": true, "fileprivate": true, "for": true, "func": true, "guard": true,
+	"if": true, "import": true, "in": true, "init": true, "inout": true,
+	"internal": true, "is": true, "let": true, "nil": true, "open": true,
+	"operator": true, "private": true, "protocol": true, "public": true,
+	"repeat": true, "rethrows": true, "return": true, "self": true, "static": true,
+	"struct": true, "subscript": true, "super": true, "switch": true, "throw": true,
+	"throws": true, "true": true, "try": true, "typealias": true, "var": true,
+	"where": true, "while": true,
+}
+
+// swiftReservedTypes lists type names used by generated code. Nested types
+// named after one of them are prefixed with the name of their enclosing type,
+// preventing them from shadowing standard or runtime types.
+var swiftReservedTypes = map[string]bool{
+	"Any": true, "Array": true, "Bool": true, "Data": true, "Double": true,
+	"Error": true, "Int": true, "Optional": true, "Protocol": true, "Self": true,
+	"String": true, "Type": true, "UInt8": true, "UInt32": true, "UInt64": true,
+	"UUID": true, "Ludwieg": true, "LudwiegAny": true, "LudwiegCodable": true,
+	"LudwiegError": true, "LudwiegMessage": true, "LudwiegPackage": true,
+	"LudwiegProtocolType": true, "LudwiegReader": true, "LudwiegRegistry": true,
+	"LudwiegWriter": true,
+}
+
+type Swift struct {
+	prefix string
+	out    string
+	names  map[*models.Struct]string
+
+	// references maps structures to structures held by their non-array
+	// fields, which are used to detect recursive types
+	references map[*models.Struct][]*models.Struct
+}
+
+func (c Swift) Compile(in, out, pkgName, prefix string, packages *models.PackageList) {
+	log.Infof("Initialising %s compiler", aurora.Brown("Swift"))
+	if pkgName != "" {
+		log.Warn("Ignoring unnecessary --package option")
+	}
+	c.prefix = strings.ToUpper(prefix)
+	c.out = out
+	c.names = map[*models.Struct]string{}
+	c.references = map[*models.Struct][]*models.Struct{}
+
+	var magic []string
+	for _, b := range codec.Magic {
+		magic = append(magic, fmt.Sprintf("0x%02X", b))
+	}
+	c.output(swiftRuntimeFile, processTemplate("swiftRuntime", swiftRuntime, templateData{
+		"magic":   strings.Join(magic, ", "),
+		"version": fmt.Sprintf("0x%02X", codec.ProtocolVersion),
+	}))
+
+	for _, p := range *packages {
+		c.writePackage(&p)
+	}
+	log.Info("Succeeded")
+	fmt.Println()
+	fmt.Println(c.integrationInstructions(packages))
+}
+
+func (c Swift) output(name string, contents []byte) {
+	log.Infof("Writing %s/%s", aurora.Magenta(filepath.Base(c.out)), aurora.Magenta(name))
+	err := ioutil.WriteFile(filepath.Join(c.out, name), contents, 0644)
+	if err != nil {
+		log.Errorf("Operation failed: %s", err)
+		os.Exit(1)
+	}
+}
+
+// packageName returns the name of the type and file of a package. Names
+// shadowing standard or runtime types, including the runtime file, are
+// suffixed with an underscore.
+func (c Swift) packageName(p *models.Package) string {
+	name := c.prefix + convertToPascalCase(p.Name)
+	for swiftReservedTypes[name] {
+		name += "_"
+	}
+	return name
+}
+
+func (c Swift) writePackage(p *models.Package) {
+	name := c.packageName(p)
+	c.nameStructs(name, p.Scope(), p.Structs)
+	c.output(name+".swift", processTemplate("swiftPackage", swiftPackage, templateData{
+		"name": name,
+		"id":   p.Identifier,
+		"body": c.generateBody(p.Scope(), p.Fields, p.Structs),
+	}))
+}
+
+// nameStructs assigns a Swift type name to every structure nested into the
+// given owner type, and collects their references
+func (c Swift) nameStructs(owner string, scope *models.Scope, sArr []models.Struct) {
+	for i := range sArr {
+		s := &sArr[i]
+		name := convertToPascalCase(s.Name)
+		if swiftReservedTypes[name] {
+			name = owner + name
+		}
+		c.names[s] = name
+
+		inner := &models.Scope{Structs: s.Structs, Parent: scope}
+		for _, f := range s.Fields {
+			if f.Type.Source != models.SourceUser || f.IsArray() {
+				continue
+			}
+			if ref, _, ok := inner.Resolve(f.Type.CustomType); ok {
+				c.references[s] = append(c.references[s], ref)
+			}
+		}
+		c.nameStructs(name, inner, s.Structs)
+	}
+}
+
+// reaches determines whether a value of from may hold a value of to without
+// the indirection provided by arrays
+func (c Swift) reaches(from, to *models.Struct, visited map[*models.Struct]bool) bool {
+	if visited[from] {
+		return false
+	}
+	visited[from] = true
+	for _, ref := range c.references[from] {
+		if ref == to || c.reaches(ref, to, visited) {
+			return true
+		}
+	}
+	return false
+}
+
+// generateBody returns properties, initialisers and nested types of a
+// package or structure
+func (c Swift) generateBody(scope *models.Scope, fArr []models.Field, sArr []models.Struct) string {
+	var fields, decode, encode []string
+	for _, f := range fArr {
+		field, dec, enc := c.generateField(scope, &f)
+		fields = append(fields, field)
+		decode = append(decode, "        "+dec+"\n")
+		encode = append(encode, "        "+enc+"\n")
+	}
+
+	var structs []string
+	for i := range sArr {
+		s := &sArr[i]
+		inner := &models.Scope{Structs: s.Structs, Parent: scope}
+		// Value types cannot hold themselves, so recursive structures are
+		// written as classes
+		kind := "struct"
+		if c.reaches(s, s, map[*models.Struct]bool{}) {
+			kind = "final class"
+		}
+		structs = append(structs, indent(string(processTemplate("swiftStruct", swiftStruct, templateData{
+			"kind": kind,
+			"name": c.names[s],
+			"body": c.generateBody(inner, s.Fields, s.Structs),
+		}))))
+	}
+
+	return string(processTemplate("swiftBody", swiftBody, templateData{
+		"fields":     strings.Join(fields, ""),
+		"decode":     strings.Join(decode, ""),
+		"encode":     strings.Join(encode, ""),
+		"structures": strings.Join(structs, ""),
+	}))
+}
+
+// indent shifts every non-empty line of code by one level, nesting it into
+// the enclosing type
+func indent(code string) string {
+	lines := strings.Split(code, "\n")
+	for i, l := range lines {
+		if l != "" {
+			lines[i] = "    " + l
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// swiftKind returns the suffix of the reader and writer methods handling a
+// native type
+func swiftKind(t models.NativeType) string {
+	switch t {
+	case models.TypeUint8, models.TypeByte:
+		return "UInt8"
+	case models.TypeUint32:
+		return "UInt32"
+	case models.TypeUint64:
+		return "UInt64"
+	case models.TypeUUID:
+		return "UUID"
+	}
+	return convertToPascalCase(string(t))
+}
+
+// swiftType returns the Swift type holding values of a native type
+func swiftType(t models.NativeType) string {
+	switch t {
+	case models.TypeDouble, models.TypeString, models.TypeBool:
+		return convertToPascalCase(string(t))
+	case models.TypeBlob:
+		return "Data"
+	case models.TypeDynInt:
+		return "UInt64"
+	case models.TypeAny:
+		return "LudwiegAny"
+	}
+	return swiftKind(t)
+}
+
+func swiftIdentifier(name string) string {
+	name = convertToCamelCase(name)
+	if swiftKeywords[name] {
+		return "`" + name + "`"
+	}
+	return name
+}
+
+// generateField returns the declaration of a field, along with statements
+// decoding and encoding it
+func (c Swift) generateField(scope *models.Scope, f *models.Field) (string, string, string) {
+	name := swiftIdentifier(f.Name)
+	storage := name
+	if f.HasAttribute(models.AttributeDeprecated) {
+		// Deprecated properties are backed by a private one, preventing
+		// generated code from triggering deprecation warnings
+		storage = "_" + convertToCamelCase(f.Name)
+	}
+
+	var t, read, write string
+	throws := false
+	if f.Type.Source == models.SourceUser {
+		t = convertToPascalCase(f.Type.CustomType)
+		if s, _, ok := scope.Resolve(f.Type.CustomType); ok {
+			t = c.names[s]
+		}
+		read = "try $0.readStruct(" + t + ".self)"
+		write = "try $0.writeStruct($1)"
+		throws = true
+	} else {
+		t = swiftType(f.Type.NativeType)
+		kind := swiftKind(f.Type.NativeType)
+		read = "try $0.read" + kind + "()"
+		write = "$0.write" + kind + "($1)"
+		if f.Type.NativeType == models.TypeAny {
+			write = "try " + write
+			throws = true
+		}
+	}
+
+	var dec, enc string
+	if f.IsArray() {
+		t = "[" + t + "?]"
+		size := ""
+		if f.Size != "*" {
+			size = ", size: " + f.Size
+		}
+		dec = fmt.Sprintf("self.%s = try reader.readArray { %s }", storage, read)
+		enc = fmt.Sprintf("try writer.writeArray(self.%s%s) { %s }", storage, size, write)
+	} else {
+		dec = fmt.Sprintf("self.%s = %s", storage, strings.Replace(read, "$0", "reader", 1))
+		enc = strings.Replace(strings.Replace(write, "$0", "writer", 1), "$1", "self."+storage, 1)
+		if !throws {
+			enc = strings.TrimPrefix(enc, "try ")
+		}
+	}
+	t += "?"
+
+	var decl string
+	if storage != name {
+		decl = string(processTemplate("swiftDeprecatedField", swiftDeprecatedField, templateData{
+			"name":    name,
+			"storage": storage,
+			"type":    t,
+		}))
+	} else {
+		decl = "    public var " + name + ": " + t + "\n"
+	}
+	return decl, dec, enc
+}
+
+func (c Swift) formatCode(code string) string {
+	var buf bytes.Buffer
+	err := quick.Highlight(&buf, code, "swift", "terminal", "pygments")
+	if err != nil {
+		log.Fatalf("BUG: Error processing Swift source: %s", err)
+	}
+
+	return string(buf.Bytes())
+}
+
+func (c Swift) integrationInstructions(pList *models.PackageList) string {
+	var list []string
+	for _, p := range *pList {
+		list = append(list, c.packageName(&p)+".self")
+	}
+	sort.Strings(list)
+
+	separator := ", "
+	if len(list) > 2 {
+		separator = ",\n" + strings.Repeat(" ", 37)
+	}
+
+	usage := strings.Join([]string{
+		"let (message, length) = try LudwiegRegistry.shared.decode(data)",
+		"     let encoded = try Ludwieg.encode(message.package, messageID: message.messageID)",
+	}, "\n")
+
+	return string(processTemplate("swiftIntegration", swiftIntegrationSteps, templateData{
+		"runtime":       aurora.Magenta(swiftRuntimeFile),
+		"swiftRegister": c.formatCode(fmt.Sprintf("LudwiegRegistry.shared.register(%s)", strings.Join(list, separator))),
+		"swiftUsage":    c.formatCode(usage),
+	}))
+}
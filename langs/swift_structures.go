@@ -0,0 +1,484 @@
+package langs
+
+const swiftPackage = `// WARNING: Automatically generated by ludco. DO NOT EDIT.
+
+import Foundation
+
+public struct {{.name}}: LudwiegPackage {
+    public static let ludwiegID: UInt8 = {{.id}}
+
+{{.body}}}
+`
+
+const swiftStruct = `
+public {{.kind}} {{.name}}: LudwiegCodable {
+{{.body}}}
+`
+
+const swiftBody = `{{.fields}}{{if .fields}}
+{{end}}    public init() {}
+
+    public init(from reader: inout LudwiegReader) throws {
+{{.decode}}    }
+
+    public func encode(to writer: inout LudwiegWriter) throws {
+{{.encode}}    }
+{{.structures}}`
+
+const swiftDeprecatedField = `    @available(*, deprecated)
+    public var {{.name}}: {{.type}} {
+        get { return {{.storage}} }
+        set { {{.storage}} = newValue }
+    }
+    private var {{.storage}}: {{.type}}
+`
+
+const swiftRuntime = `// WARNING: Automatically generated by ludco. DO NOT EDIT.
+//
+// Runtime used by generated packages to encode and decode values using the
+// Ludwieg wire format.
+
+import Foundation
+
+public let ludwiegMagic: [UInt8] = [{{.magic}}]
+public let ludwiegProtocolVersion: UInt8 = {{.version}}
+let ludwiegFlagEmpty: UInt8 = 0x80
+
+public enum LudwiegProtocolType: UInt8 {
+    case uint8 = 0x01
+    case uint32 = 0x02
+    case uint64 = 0x03
+    case double = 0x04
+    case string = 0x05
+    case blob = 0x06
+    case bool = 0x07
+    case uuid = 0x08
+    case any = 0x09
+    case array = 0x0A
+    case structure = 0x0B
+    case dynInt = 0x0C
+}
+
+public enum LudwiegError: Error, Equatable {
+    /// Data does not contain a complete message yet
+    case shortBuffer
+    case invalidMagic
+    case unsupportedVersion(UInt8)
+    case unknownPackage(UInt8)
+    case invalidSizeWidth(UInt8, offset: Int)
+    case unexpectedType(expected: LudwiegProtocolType, found: UInt8, offset: Int)
+    case exceedsBoundaries(offset: Int)
+    case invalidString(offset: Int)
+    case invalidAny(offset: Int)
+    case arrayTooLarge(count: Int, size: Int)
+}
+
+/// LudwiegAny holds values of any fields
+public enum LudwiegAny: Equatable {
+    case uint8(UInt8)
+    case uint32(UInt32)
+    case uint64(UInt64)
+    case double(Double)
+    case string(String)
+    case blob(Data)
+    case bool(Bool)
+    case uuid(UUID)
+    case dynInt(UInt64)
+    case array([LudwiegAny?])
+}
+
+public protocol LudwiegCodable {
+    init(from reader: inout LudwiegReader) throws
+    func encode(to writer: inout LudwiegWriter) throws
+}
+
+public protocol LudwiegPackage: LudwiegCodable {
+    static var ludwiegID: UInt8 { get }
+}
+
+public struct LudwiegMessage {
+    public let messageID: UInt8
+    public let package: LudwiegPackage
+}
+
+public struct LudwiegWriter {
+    public private(set) var data = Data()
+
+    public init() {}
+
+    mutating func append(_ byte: UInt8) {
+        data.append(byte)
+    }
+
+    mutating func append(contentsOf other: Data) {
+        data.append(other)
+    }
+
+    mutating func appendLittleEndian<T: FixedWidthInteger>(_ value: T) {
+        var le = value.littleEndian
+        withUnsafeBytes(of: &le) { data.append(contentsOf: $0) }
+    }
+
+    mutating func writeEmpty(_ type: LudwiegProtocolType) {
+        append(type.rawValue | ludwiegFlagEmpty)
+    }
+
+    /// writeSize writes a dynamic size, using the smallest width able to
+    /// hold the value
+    public mutating func writeSize(_ value: UInt64) {
+        if value <= UInt64(UInt8.max) {
+            append(1)
+            append(UInt8(value))
+        } else if value <= UInt64(UInt16.max) {
+            append(2)
+            appendLittleEndian(UInt16(value))
+        } else if value <= UInt64(UInt32.max) {
+            append(4)
+            appendLittleEndian(UInt32(value))
+        } else {
+            append(8)
+            appendLittleEndian(value)
+        }
+    }
+
+    public mutating func writeUInt8(_ value: UInt8?) {
+        guard let value = value else { return writeEmpty(.uint8) }
+        append(LudwiegProtocolType.uint8.rawValue)
+        append(value)
+    }
+
+    public mutating func writeUInt32(_ value: UInt32?) {
+        guard let value = value else { return writeEmpty(.uint32) }
+        append(LudwiegProtocolType.uint32.rawValue)
+        appendLittleEndian(value)
+    }
+
+    public mutating func writeUInt64(_ value: UInt64?) {
+        guard let value = value else { return writeEmpty(.uint64) }
+        append(LudwiegProtocolType.uint64.rawValue)
+        appendLittleEndian(value)
+    }
+
+    public mutating func writeDouble(_ value: Double?) {
+        guard let value = value else { return writeEmpty(.double) }
+        append(LudwiegProtocolType.double.rawValue)
+        appendLittleEndian(value.bitPattern)
+    }
+
+    public mutating func writeString(_ value: String?) {
+        guard let value = value else { return writeEmpty(.string) }
+        let bytes = Data(value.utf8)
+        append(LudwiegProtocolType.string.rawValue)
+        writeSize(UInt64(bytes.count))
+        data.append(bytes)
+    }
+
+    public mutating func writeBlob(_ value: Data?) {
+        guard let value = value else { return writeEmpty(.blob) }
+        append(LudwiegProtocolType.blob.rawValue)
+        writeSize(UInt64(value.count))
+        data.append(value)
+    }
+
+    public mutating func writeBool(_ value: Bool?) {
+        guard let value = value else { return writeEmpty(.bool) }
+        append(LudwiegProtocolType.bool.rawValue)
+        append(value ? 1 : 0)
+    }
+
+    public mutating func writeUUID(_ value: UUID?) {
+        guard let value = value else { return writeEmpty(.uuid) }
+        append(LudwiegProtocolType.uuid.rawValue)
+        var uuid = value.uuid
+        withUnsafeBytes(of: &uuid) { data.append(contentsOf: $0) }
+    }
+
+    public mutating func writeDynInt(_ value: UInt64?) {
+        guard let value = value else { return writeEmpty(.dynInt) }
+        append(LudwiegProtocolType.dynInt.rawValue)
+        writeSize(value)
+    }
+
+    public mutating func writeAny(_ value: LudwiegAny?) throws {
+        guard let value = value else { return writeEmpty(.any) }
+        append(LudwiegProtocolType.any.rawValue)
+        switch value {
+        case .uint8(let v): writeUInt8(v)
+        case .uint32(let v): writeUInt32(v)
+        case .uint64(let v): writeUInt64(v)
+        case .double(let v): writeDouble(v)
+        case .string(let v): writeString(v)
+        case .blob(let v): writeBlob(v)
+        case .bool(let v): writeBool(v)
+        case .uuid(let v): writeUUID(v)
+        case .dynInt(let v): writeDynInt(v)
+        case .array(let v): try writeArray(v) { try $0.writeAny($1) }
+        }
+    }
+
+    /// writeArray writes items using element for each of them. When size is
+    /// provided, arrays holding more items are rejected.
+    public mutating func writeArray<T>(_ items: [T?]?, size: Int? = nil, _ element: (inout LudwiegWriter, T?) throws -> Void) throws {
+        guard let items = items else { return writeEmpty(.array) }
+        if let size = size, items.count > size {
+            throw LudwiegError.arrayTooLarge(count: items.count, size: size)
+        }
+        var body = LudwiegWriter()
+        body.writeSize(UInt64(items.count))
+        for item in items {
+            try element(&body, item)
+        }
+        append(LudwiegProtocolType.array.rawValue)
+        writeSize(UInt64(body.data.count))
+        data.append(body.data)
+    }
+
+    public mutating func writeStruct<T: LudwiegCodable>(_ value: T?) throws {
+        guard let value = value else { return writeEmpty(.structure) }
+        var body = LudwiegWriter()
+        try value.encode(to: &body)
+        append(LudwiegProtocolType.structure.rawValue)
+        writeSize(UInt64(body.data.count))
+        data.append(body.data)
+    }
+}
+
+public struct LudwiegReader {
+    let bytes: [UInt8]
+    public private(set) var position: Int
+    let limit: Int
+
+    init(bytes: [UInt8], position: Int, limit: Int) {
+        self.bytes = bytes
+        self.position = position
+        self.limit = limit
+    }
+
+    mutating func byte() throws -> UInt8 {
+        guard position < limit else { throw LudwiegError.exceedsBoundaries(offset: position) }
+        defer { position += 1 }
+        return bytes[position]
+    }
+
+    mutating func take(_ count: UInt64) throws -> ArraySlice<UInt8> {
+        guard count <= UInt64(limit - position) else { throw LudwiegError.exceedsBoundaries(offset: position) }
+        let n = Int(count)
+        defer { position += n }
+        return bytes[position..<position + n]
+    }
+
+    mutating func littleEndian<T: FixedWidthInteger>(_ type: T.Type) throws -> T {
+        var value: T = 0
+        for (i, b) in try take(UInt64(MemoryLayout<T>.size)).enumerated() {
+            value |= T(b) << (8 * i)
+        }
+        return value
+    }
+
+    public mutating func readSize() throws -> UInt64 {
+        let offset = position
+        let width = try byte()
+        switch width {
+        case 1: return UInt64(try byte())
+        case 2: return UInt64(try littleEndian(UInt16.self))
+        case 4: return UInt64(try littleEndian(UInt32.self))
+        case 8: return try littleEndian(UInt64.self)
+        default: throw LudwiegError.invalidSizeWidth(width, offset: offset)
+        }
+    }
+
+    /// begin reads the type byte of a value, returning false when the value
+    /// is empty. Missing trailing values are also considered empty.
+    mutating func begin(_ type: LudwiegProtocolType) throws -> Bool {
+        guard position < limit else { return false }
+        let offset = position
+        let t = try byte()
+        guard t & ~ludwiegFlagEmpty == type.rawValue else {
+            throw LudwiegError.unexpectedType(expected: type, found: t & ~ludwiegFlagEmpty, offset: offset)
+        }
+        return t & ludwiegFlagEmpty == 0
+    }
+
+    /// body returns a reader limited to the next value of the given size,
+    /// advancing past it
+    mutating func body(_ size: UInt64) throws -> LudwiegReader {
+        let start = position
+        _ = try take(size)
+        return LudwiegReader(bytes: bytes, position: start, limit: position)
+    }
+
+    public mutating func readUInt8() throws -> UInt8? {
+        guard try begin(.uint8) else { return nil }
+        return try byte()
+    }
+
+    public mutating func readUInt32() throws -> UInt32? {
+        guard try begin(.uint32) else { return nil }
+        return try littleEndian(UInt32.self)
+    }
+
+    public mutating func readUInt64() throws -> UInt64? {
+        guard try begin(.uint64) else { return nil }
+        return try littleEndian(UInt64.self)
+    }
+
+    public mutating func readDouble() throws -> Double? {
+        guard try begin(.double) else { return nil }
+        return Double(bitPattern: try littleEndian(UInt64.self))
+    }
+
+    public mutating func readString() throws -> String? {
+        guard try begin(.string) else { return nil }
+        let size = try readSize()
+        let offset = position
+        guard let value = String(bytes: try take(size), encoding: .utf8) else {
+            throw LudwiegError.invalidString(offset: offset)
+        }
+        return value
+    }
+
+    public mutating func readBlob() throws -> Data? {
+        guard try begin(.blob) else { return nil }
+        return Data(try take(try readSize()))
+    }
+
+    public mutating func readBool() throws -> Bool? {
+        guard try begin(.bool) else { return nil }
+        return try byte() != 0
+    }
+
+    public mutating func readUUID() throws -> UUID? {
+        guard try begin(.uuid) else { return nil }
+        let b = Array(try take(16))
+        return UUID(uuid: (b[0], b[1], b[2], b[3], b[4], b[5], b[6], b[7],
+                           b[8], b[9], b[10], b[11], b[12], b[13], b[14], b[15]))
+    }
+
+    public mutating func readDynInt() throws -> UInt64? {
+        guard try begin(.dynInt) else { return nil }
+        return try readSize()
+    }
+
+    public mutating func readAny() throws -> LudwiegAny? {
+        guard try begin(.any) else { return nil }
+        guard position < limit else { throw LudwiegError.exceedsBoundaries(offset: position) }
+        let offset = position
+        switch LudwiegProtocolType(rawValue: bytes[position] & ~ludwiegFlagEmpty) {
+        case .uint8?: return try readUInt8().map(LudwiegAny.uint8)
+        case .uint32?: return try readUInt32().map(LudwiegAny.uint32)
+        case .uint64?: return try readUInt64().map(LudwiegAny.uint64)
+        case .double?: return try readDouble().map(LudwiegAny.double)
+        case .string?: return try readString().map(LudwiegAny.string)
+        case .blob?: return try readBlob().map(LudwiegAny.blob)
+        case .bool?: return try readBool().map(LudwiegAny.bool)
+        case .uuid?: return try readUUID().map(LudwiegAny.uuid)
+        case .dynInt?: return try readDynInt().map(LudwiegAny.dynInt)
+        case .array?: return try readArray { try $0.readAny() }.map(LudwiegAny.array)
+        default: throw LudwiegError.invalidAny(offset: offset)
+        }
+    }
+
+    /// readArray reads an array, using element to read each of its items
+    public mutating func readArray<T>(_ element: (inout LudwiegReader) throws -> T?) throws -> [T?]? {
+        guard try begin(.array) else { return nil }
+        var body = try self.body(try readSize())
+        let count = try body.readSize()
+        var items: [T?] = []
+        for _ in 0..<count {
+            guard body.position < body.limit else { throw LudwiegError.exceedsBoundaries(offset: body.position) }
+            items.append(try element(&body))
+        }
+        return items
+    }
+
+    public mutating func readStruct<T: LudwiegCodable>(_ type: T.Type) throws -> T? {
+        guard try begin(.structure) else { return nil }
+        var body = try self.body(try readSize())
+        return try T(from: &body)
+    }
+}
+
+/// LudwiegRegistry decodes messages into the packages registered on it
+public final class LudwiegRegistry {
+    public static let shared = LudwiegRegistry()
+
+    private var packages: [UInt8: LudwiegPackage.Type] = [:]
+    private let lock = NSLock()
+
+    public init() {}
+
+    /// register makes packages available for decoding. This is usually
+    /// performed once, during the application's boot.
+    public func register(_ types: LudwiegPackage.Type...) {
+        lock.lock()
+        defer { lock.unlock() }
+        for type in types {
+            packages[type.ludwiegID] = type
+        }
+    }
+
+    /// decode decodes the message at the beginning of data, returning it
+    /// along with the amount of bytes it used. LudwiegError.shortBuffer is
+    /// thrown when data does not contain a complete message yet.
+    public func decode(_ data: Data) throws -> (message: LudwiegMessage, length: Int) {
+        let bytes = [UInt8](data)
+        let headerSize = ludwiegMagic.count + 3
+        guard bytes.count > headerSize else { throw LudwiegError.shortBuffer }
+        guard Array(bytes[0..<ludwiegMagic.count]) == ludwiegMagic else { throw LudwiegError.invalidMagic }
+        let version = bytes[ludwiegMagic.count]
+        guard version == ludwiegProtocolVersion else { throw LudwiegError.unsupportedVersion(version) }
+        let messageID = bytes[ludwiegMagic.count + 1]
+        let packageID = bytes[ludwiegMagic.count + 2]
+
+        var header = LudwiegReader(bytes: bytes, position: headerSize, limit: bytes.count)
+        let payload: LudwiegReader
+        do {
+            payload = try header.body(try header.readSize())
+        } catch LudwiegError.exceedsBoundaries(_) {
+            throw LudwiegError.shortBuffer
+        }
+
+        lock.lock()
+        let type = packages[packageID]
+        lock.unlock()
+        guard let packageType = type else { throw LudwiegError.unknownPackage(packageID) }
+
+        var reader = payload
+        let package = try packageType.init(from: &reader)
+        return (LudwiegMessage(messageID: messageID, package: package), payload.limit)
+    }
+}
+
+public enum Ludwieg {
+    /// encode serializes a package into a message with the given identifier
+    public static func encode(_ package: LudwiegPackage, messageID: UInt8) throws -> Data {
+        var payload = LudwiegWriter()
+        try package.encode(to: &payload)
+        var writer = LudwiegWriter()
+        writer.append(contentsOf: Data(ludwiegMagic))
+        writer.append(ludwiegProtocolVersion)
+        writer.append(messageID)
+        writer.append(type(of: package).ludwiegID)
+        writer.writeSize(UInt64(payload.data.count))
+        writer.append(contentsOf: payload.data)
+        return writer.data
+    }
+}
+`
+
+const swiftIntegrationSteps = `You just generated Swift sources. In order to use them you need to perform
+a few tasks:
+
+  1. Copy output files to your project, including {{.runtime}}, which holds
+     the encoder and decoder used by generated packages. No other dependency
+     is required.
+  2. Before attempting deserializing data, you must register output packages.
+     This operation must be performed only once, usually in your application's
+     boot:
+
+     {{.swiftRegister}}
+
+  3. Messages can then be decoded and encoded:
+
+     {{.swiftUsage}}
+`
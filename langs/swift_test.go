@@ -0,0 +1,43 @@
+package langs
+
+import (
+	"os"
+	"testing"
+)
+
+func TestSwiftRecursiveStructs(t *testing.T) {
+	dir := testCompile(t, Swift{}, "", "", testPackages(t, recursiveSource))
+	defer os.RemoveAll(dir)
+
+	testContains(t, "Tree.swift", testOutput(t, dir, "Tree.swift"),
+		"public final class Node: LudwiegCodable {",
+		"public final class A: LudwiegCodable {",
+		"public final class B: LudwiegCodable {",
+		"public var parent: Node?")
+}
+
+func TestSwiftReservedPackageNames(t *testing.T) {
+	dir := testCompile(t, Swift{}, "", "", testPackages(t, `
+package data {
+    id 0x01
+    @data value
+    struct data {
+        string text
+    }
+}
+
+package ludwieg {
+    id 0x02
+    string text
+}
+`))
+	defer os.RemoveAll(dir)
+
+	testContains(t, "Data_.swift", testOutput(t, dir, "Data_.swift"),
+		"public struct Data_: LudwiegPackage {",
+		"public struct Data_Data: LudwiegCodable {")
+	testContains(t, "Ludwieg_.swift", testOutput(t, dir, "Ludwieg_.swift"),
+		"public struct Ludwieg_: LudwiegPackage {")
+	testContains(t, "Ludwieg.swift", testOutput(t, dir, "Ludwieg.swift"),
+		"public struct LudwiegWriter")
+}
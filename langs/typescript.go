@@ -0,0 +1,268 @@
+package langs
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/alecthomas/chroma/quick"
+	"github.com/logrusorgru/aurora"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/ludwieg/ludco/codec"
+	"github.com/ludwieg/ludco/models"
+)
+
+// tsReservedNames lists names exported by the runtime, along with globals
+// referenced by generated classes, which classes must not shadow
+var tsReservedNames = map[string]bool{
+	"AnyValue": true, "Codable": true, "CodableType": true, "DecodeError": true,
+	"MAGIC": true, "Message": true, "Object": true, "PROTOCOL_VERSION": true,
+	"PackageType": true, "Partial": true, "ProtocolType": true, "Reader": true,
+	"Registry": true, "ShortBufferError": true, "Uint8Array": true, "Writer": true,
+	"registry": true,
+}
+
+type TypeScript struct {
+	out string
+
+	// classes maps structures to the name of their generated class
+	classes map[*models.Struct]string
+}
+
+func (c TypeScript) Compile(in, out, pkgName, prefix string, packages *models.PackageList) {
+	log.Infof("Initialising %s compiler", aurora.Blue("TypeScript"))
+	if pkgName != "" {
+		log.Warn("Ignoring unnecessary --package option")
+	}
+	if prefix != "" {
+		log.Warn("Ignoring unnecessary --prefix option.")
+	}
+	c.out = out
+	c.classes = map[*models.Struct]string{}
+
+	var magic []string
+	for _, b := range codec.Magic {
+		magic = append(magic, fmt.Sprintf("0x%02x", b))
+	}
+	c.output("ludwieg", processTemplate("tsRuntime", tsRuntime, templateData{
+		"magic":   strings.Join(magic, ", "),
+		"version": fmt.Sprintf("0x%02x", codec.ProtocolVersion),
+	}))
+
+	for i := range *packages {
+		c.writePackage(&(*packages)[i])
+	}
+	c.writeIndex(packages)
+
+	log.Info("Succeeded")
+	fmt.Println()
+	fmt.Println(c.integrationInstructions(packages))
+}
+
+func (c TypeScript) output(name string, contents []byte) {
+	log.Infof("Writing %s/%s", aurora.Magenta(filepath.Base(c.out)), aurora.Magenta(name+".ts"))
+	err := ioutil.WriteFile(filepath.Join(c.out, name+".ts"), contents, 0644)
+	if err != nil {
+		log.Errorf("Operation failed: %s", err)
+		os.Exit(1)
+	}
+}
+
+// tsModule returns the name of the module of a package, suffixing names
+// taken by the runtime and index modules with an underscore
+func tsModule(name string) string {
+	if name == "index" || name == "ludwieg" {
+		return name + "_"
+	}
+	return name
+}
+
+// tsClassName suffixes names of runtime exports with an underscore
+func tsClassName(name string) string {
+	if tsReservedNames[name] {
+		return name + "_"
+	}
+	return name
+}
+
+func (c TypeScript) writePackage(p *models.Package) {
+	name := tsClassName(convertToPascalCase(p.Name))
+	c.registerStructs(convertToPascalCase(p.Name), p.Structs)
+
+	imports := map[string]bool{"Codable": true, "Reader": true, "Writer": true}
+	classes := []string{c.generateClass(name, fmt.Sprintf("    static readonly id = %s;\n\n", p.Identifier), p.Scope(), p.Fields, imports)}
+	c.generateStructs(p.Scope(), p.Structs, &classes, imports)
+
+	var importList []string
+	for i := range imports {
+		importList = append(importList, i)
+	}
+	sort.Strings(importList)
+
+	c.output(tsModule(p.Name), processTemplate("tsPackage", tsPackage, templateData{
+		"imports": strings.Join(importList, ", "),
+		"classes": strings.Join(classes, ""),
+	}))
+}
+
+// registerStructs assigns class names to structures before classes are
+// generated, as fields may reference structures declared after them
+func (c TypeScript) registerStructs(prefix string, sArr []models.Struct) {
+	for i := range sArr {
+		s := &sArr[i]
+		name := prefix + convertToPascalCase(s.Name)
+		c.classes[s] = tsClassName(name)
+		c.registerStructs(name, s.Structs)
+	}
+}
+
+func (c TypeScript) generateStructs(scope *models.Scope, sArr []models.Struct, classes *[]string, imports map[string]bool) {
+	for i := range sArr {
+		s := &sArr[i]
+		inner := &models.Scope{Structs: s.Structs, Parent: scope}
+		*classes = append(*classes, c.generateClass(c.classes[s], "", inner, s.Fields, imports))
+		c.generateStructs(inner, s.Structs, classes, imports)
+	}
+}
+
+func (c TypeScript) generateClass(name, id string, scope *models.Scope, fArr []models.Field, imports map[string]bool) string {
+	var fields, decode, encode []string
+	for _, f := range fArr {
+		field, dec, enc := c.generateField(scope, &f, imports)
+		fields = append(fields, field)
+		decode = append(decode, "        "+dec+"\n")
+		encode = append(encode, "        "+enc+"\n")
+	}
+	if len(fields) > 0 {
+		fields = append(fields, "")
+	}
+
+	return string(processTemplate("tsClass", tsClass, templateData{
+		"name":   name,
+		"id":     id,
+		"fields": strings.Join(fields, "\n"),
+		"decode": strings.Join(decode, ""),
+		"encode": strings.Join(encode, ""),
+	}))
+}
+
+// tsType returns the TypeScript type holding values of a native type, along
+// with the suffix of the reader and writer methods handling it
+func tsType(t models.NativeType) (string, string) {
+	switch t {
+	case models.TypeUint8, models.TypeByte:
+		return "number", "Uint8"
+	case models.TypeUint32:
+		return "number", "Uint32"
+	case models.TypeUint64:
+		return "bigint", "Uint64"
+	case models.TypeDouble:
+		return "number", "Double"
+	case models.TypeString:
+		return "string", "String"
+	case models.TypeBlob:
+		return "Uint8Array", "Blob"
+	case models.TypeBool:
+		return "boolean", "Bool"
+	case models.TypeUUID:
+		return "string", "UUID"
+	case models.TypeAny:
+		return "AnyValue", "Any"
+	case models.TypeDynInt:
+		return "bigint", "DynInt"
+	}
+	log.Fatalf("BUG: Cannot coerce unknown type %#v to native type.", t)
+	return "", ""
+}
+
+// generateField returns the declaration of a field, along with statements
+// decoding and encoding it
+func (c TypeScript) generateField(scope *models.Scope, f *models.Field, imports map[string]bool) (string, string, string) {
+	name := convertToCamelCase(f.Name)
+
+	var t, read, write string
+	if f.Type.Source == models.SourceUser {
+		s, _, ok := scope.Resolve(f.Type.CustomType)
+		if !ok {
+			log.Errorf("BUG: Cannot resolve structure %s referenced by %s", f.Type.CustomType, f.Name)
+			os.Exit(1)
+		}
+		t = c.classes[s]
+		read = "r.readStruct(" + t + ")"
+		write = "w.writeStruct(%s)"
+	} else {
+		var kind string
+		t, kind = tsType(f.Type.NativeType)
+		read = "r.read" + kind + "()"
+		write = "w.write" + kind + "(%s)"
+		if f.Type.NativeType == models.TypeAny {
+			imports["AnyValue"] = true
+		}
+	}
+
+	var dec, enc string
+	if f.IsArray() {
+		t = "(" + t + " | null)[]"
+		size := "null"
+		if f.Size != "*" {
+			size = f.Size
+		}
+		dec = fmt.Sprintf("v.%s = r.readArray((r) => %s);", name, read)
+		enc = fmt.Sprintf("w.writeArray(this.%s, %s, (w, v) => %s);", name, size, fmt.Sprintf(write, "v"))
+	} else {
+		dec = fmt.Sprintf("v.%s = %s;", name, read)
+		enc = fmt.Sprintf(write, "this."+name) + ";"
+	}
+
+	decl := fmt.Sprintf("    %s: %s | null = null;", name, t)
+	if f.HasAttribute(models.AttributeDeprecated) {
+		decl = "    /** @deprecated */\n" + decl
+	}
+	return decl, dec, enc
+}
+
+func (c TypeScript) writeIndex(pList *models.PackageList) {
+	var imports, exports, names []string
+	for _, p := range *pList {
+		name := tsClassName(convertToPascalCase(p.Name))
+		imports = append(imports, fmt.Sprintf("import { %s } from \"./%s\";", name, tsModule(p.Name)))
+		exports = append(exports, fmt.Sprintf("export * from \"./%s\";", tsModule(p.Name)))
+		names = append(names, name)
+	}
+
+	c.output("index", processTemplate("tsIndex", tsIndex, templateData{
+		"imports":  strings.Join(imports, "\n"),
+		"exports":  strings.Join(exports, "\n"),
+		"packages": strings.Join(names, ", "),
+	}))
+}
+
+func (c TypeScript) formatCode(code string) string {
+	var buf bytes.Buffer
+	err := quick.Highlight(&buf, code, "typescript", "terminal", "pygments")
+	if err != nil {
+		log.Fatalf("BUG: Error processing TypeScript source: %s", err)
+	}
+
+	return string(buf.Bytes())
+}
+
+func (c TypeScript) integrationInstructions(pList *models.PackageList) string {
+	usage := strings.Join([]string{
+		"const { message, length } = registry.decode(data);",
+		"     const encoded = registry.encode(message.messageId, message.package);",
+	}, "\n")
+
+	return string(processTemplate("tsIntegration", tsIntegrationSteps, templateData{
+		"runtime":  aurora.Magenta("ludwieg.ts"),
+		"index":    aurora.Magenta("index.ts"),
+		"bigint":   aurora.Bold("bigint"),
+		"tsImport": c.formatCode(`import { registry } from "./` + filepath.Base(c.out) + `";`),
+		"tsUsage":  c.formatCode(usage),
+	}))
+}
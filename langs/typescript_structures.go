@@ -0,0 +1,585 @@
+package langs
+
+const tsPackage = `// WARNING: Automatically generated by ludco. DO NOT EDIT.
+
+import { {{.imports}} } from "./ludwieg";
+{{.classes}}`
+
+const tsClass = `
+export class {{.name}} implements Codable {
+{{.id}}{{.fields}}
+    constructor(init?: Partial<{{.name}}>) {
+        if (init) {
+            Object.assign(this, init);
+        }
+    }
+
+    static decode(r: Reader): {{.name}} {
+        const v = new {{.name}}();
+{{.decode}}        return v;
+    }
+
+    encode(w: Writer): void {
+{{.encode}}    }
+}
+`
+
+const tsIndex = `// WARNING: Automatically generated by ludco. DO NOT EDIT.
+
+import { registry } from "./ludwieg";
+{{.imports}}
+
+export * from "./ludwieg";
+{{.exports}}
+
+registry.register({{.packages}});
+`
+
+const tsRuntime = `// WARNING: Automatically generated by ludco. DO NOT EDIT.
+//
+// Runtime used by generated packages to encode and decode values using the
+// Ludwieg wire format. It has no dependencies, and requires an ES2020
+// environment providing BigInt, TextEncoder and TextDecoder.
+
+export const MAGIC = new Uint8Array([{{.magic}}]);
+export const PROTOCOL_VERSION = {{.version}};
+const FLAG_EMPTY = 0x80;
+
+export enum ProtocolType {
+    Uint8 = 0x01,
+    Uint32 = 0x02,
+    Uint64 = 0x03,
+    Double = 0x04,
+    String = 0x05,
+    Blob = 0x06,
+    Bool = 0x07,
+    UUID = 0x08,
+    Any = 0x09,
+    Array = 0x0a,
+    Struct = 0x0b,
+    DynInt = 0x0c,
+}
+
+/** AnyValue holds values of any fields, along with their type */
+export type AnyValue =
+    | { type: ProtocolType.Uint8; value: number }
+    | { type: ProtocolType.Uint32; value: number }
+    | { type: ProtocolType.Uint64; value: bigint }
+    | { type: ProtocolType.Double; value: number }
+    | { type: ProtocolType.String; value: string }
+    | { type: ProtocolType.Blob; value: Uint8Array }
+    | { type: ProtocolType.Bool; value: boolean }
+    | { type: ProtocolType.UUID; value: string }
+    | { type: ProtocolType.DynInt; value: bigint }
+    | { type: ProtocolType.Array; value: (AnyValue | null)[] };
+
+export interface Codable {
+    encode(w: Writer): void;
+}
+
+export interface CodableType<T extends Codable = Codable> {
+    decode(r: Reader): T;
+}
+
+export interface PackageType<T extends Codable = Codable> extends CodableType<T> {
+    readonly id: number;
+}
+
+export interface Message {
+    messageId: number;
+    packageId: number;
+    package: Codable;
+}
+
+/** DecodeError indicates that data does not represent a valid message */
+export class DecodeError extends Error {
+    constructor(readonly offset: number, readonly reason: string) {
+        super("offset " + offset + ": " + reason);
+        Object.setPrototypeOf(this, new.target.prototype);
+        this.name = "DecodeError";
+    }
+}
+
+/** ShortBufferError indicates that data does not contain a complete message yet */
+export class ShortBufferError extends Error {
+    constructor() {
+        super("buffer does not contain a complete message");
+        Object.setPrototypeOf(this, new.target.prototype);
+        this.name = "ShortBufferError";
+    }
+}
+
+const textEncoder = new TextEncoder();
+const textDecoder = new TextDecoder("utf-8", { fatal: true });
+
+function parseUUID(value: string): Uint8Array {
+    const hex = value.replace(/-/g, "");
+    if (!/^[0-9a-fA-F]{32}$/.test(hex)) {
+        throw new Error("invalid UUID " + JSON.stringify(value));
+    }
+    const bytes = new Uint8Array(16);
+    for (let i = 0; i < 16; i++) {
+        bytes[i] = parseInt(hex.substr(i * 2, 2), 16);
+    }
+    return bytes;
+}
+
+function formatUUID(bytes: Uint8Array): string {
+    let hex = "";
+    bytes.forEach((b) => {
+        hex += (b < 0x10 ? "0" : "") + b.toString(16);
+    });
+    return hex.substr(0, 8) + "-" + hex.substr(8, 4) + "-" + hex.substr(12, 4) + "-" +
+        hex.substr(16, 4) + "-" + hex.substr(20);
+}
+
+export class Writer {
+    private buf = new Uint8Array(64);
+    private view = new DataView(this.buf.buffer);
+    private length = 0;
+
+    /** bytes returns a copy of the data written so far */
+    bytes(): Uint8Array {
+        return this.buf.slice(0, this.length);
+    }
+
+    /**
+     * reserve grows the buffer to fit n more bytes, returning the offset they
+     * must be written at. Callers must not read buf or view before calling it,
+     * as both may be replaced.
+     */
+    private reserve(n: number): number {
+        if (this.length + n > this.buf.length) {
+            let size = this.buf.length * 2;
+            while (size < this.length + n) {
+                size *= 2;
+            }
+            const buf = new Uint8Array(size);
+            buf.set(this.buf.subarray(0, this.length));
+            this.buf = buf;
+            this.view = new DataView(buf.buffer);
+        }
+        const offset = this.length;
+        this.length += n;
+        return offset;
+    }
+
+    writeByte(b: number): void {
+        const o = this.reserve(1);
+        this.buf[o] = b;
+    }
+
+    writeBytes(data: Uint8Array): void {
+        const o = this.reserve(data.length);
+        this.buf.set(data, o);
+    }
+
+    private writeEmpty(t: ProtocolType): void {
+        this.writeByte(t | FLAG_EMPTY);
+    }
+
+    /** writeSize writes a dynamic size, using the smallest width able to hold it */
+    writeSize(value: number | bigint): void {
+        const v = BigInt(value);
+        if (v <= BigInt(0xff)) {
+            this.writeByte(1);
+            this.writeByte(Number(v));
+        } else if (v <= BigInt(0xffff)) {
+            this.writeByte(2);
+            const o = this.reserve(2);
+            this.view.setUint16(o, Number(v), true);
+        } else if (v <= BigInt(0xffffffff)) {
+            this.writeByte(4);
+            const o = this.reserve(4);
+            this.view.setUint32(o, Number(v), true);
+        } else {
+            this.writeByte(8);
+            const o = this.reserve(8);
+            this.view.setBigUint64(o, v, true);
+        }
+    }
+
+    writeUint8(value: number | null | undefined): void {
+        if (value == null) {
+            return this.writeEmpty(ProtocolType.Uint8);
+        }
+        this.writeByte(ProtocolType.Uint8);
+        this.writeByte(value);
+    }
+
+    writeUint32(value: number | null | undefined): void {
+        if (value == null) {
+            return this.writeEmpty(ProtocolType.Uint32);
+        }
+        this.writeByte(ProtocolType.Uint32);
+        const o = this.reserve(4);
+        this.view.setUint32(o, value, true);
+    }
+
+    writeUint64(value: bigint | null | undefined): void {
+        if (value == null) {
+            return this.writeEmpty(ProtocolType.Uint64);
+        }
+        this.writeByte(ProtocolType.Uint64);
+        const o = this.reserve(8);
+        this.view.setBigUint64(o, value, true);
+    }
+
+    writeDouble(value: number | null | undefined): void {
+        if (value == null) {
+            return this.writeEmpty(ProtocolType.Double);
+        }
+        this.writeByte(ProtocolType.Double);
+        const o = this.reserve(8);
+        this.view.setFloat64(o, value, true);
+    }
+
+    writeString(value: string | null | undefined): void {
+        if (value == null) {
+            return this.writeEmpty(ProtocolType.String);
+        }
+        const data = textEncoder.encode(value);
+        this.writeByte(ProtocolType.String);
+        this.writeSize(data.length);
+        this.writeBytes(data);
+    }
+
+    writeBlob(value: Uint8Array | null | undefined): void {
+        if (value == null) {
+            return this.writeEmpty(ProtocolType.Blob);
+        }
+        this.writeByte(ProtocolType.Blob);
+        this.writeSize(value.length);
+        this.writeBytes(value);
+    }
+
+    writeBool(value: boolean | null | undefined): void {
+        if (value == null) {
+            return this.writeEmpty(ProtocolType.Bool);
+        }
+        this.writeByte(ProtocolType.Bool);
+        this.writeByte(value ? 1 : 0);
+    }
+
+    writeUUID(value: string | null | undefined): void {
+        if (value == null) {
+            return this.writeEmpty(ProtocolType.UUID);
+        }
+        this.writeByte(ProtocolType.UUID);
+        this.writeBytes(parseUUID(value));
+    }
+
+    writeDynInt(value: bigint | null | undefined): void {
+        if (value == null) {
+            return this.writeEmpty(ProtocolType.DynInt);
+        }
+        this.writeByte(ProtocolType.DynInt);
+        this.writeSize(value);
+    }
+
+    writeAny(value: AnyValue | null | undefined): void {
+        if (value == null) {
+            return this.writeEmpty(ProtocolType.Any);
+        }
+        this.writeByte(ProtocolType.Any);
+        switch (value.type) {
+            case ProtocolType.Uint8: return this.writeUint8(value.value);
+            case ProtocolType.Uint32: return this.writeUint32(value.value);
+            case ProtocolType.Uint64: return this.writeUint64(value.value);
+            case ProtocolType.Double: return this.writeDouble(value.value);
+            case ProtocolType.String: return this.writeString(value.value);
+            case ProtocolType.Blob: return this.writeBlob(value.value);
+            case ProtocolType.Bool: return this.writeBool(value.value);
+            case ProtocolType.UUID: return this.writeUUID(value.value);
+            case ProtocolType.DynInt: return this.writeDynInt(value.value);
+            case ProtocolType.Array: return this.writeArray(value.value, null, (w, v) => w.writeAny(v));
+        }
+    }
+
+    /**
+     * writeArray writes items using element for each of them. When size is
+     * provided, arrays holding more items are rejected.
+     */
+    writeArray<T>(items: (T | null)[] | null | undefined, size: number | null, element: (w: Writer, v: T | null) => void): void {
+        if (items == null) {
+            return this.writeEmpty(ProtocolType.Array);
+        }
+        if (size !== null && items.length > size) {
+            throw new Error("array holds " + items.length + " items, exceeding its size of " + size);
+        }
+        const body = new Writer();
+        body.writeSize(items.length);
+        items.forEach((item) => element(body, item));
+        const data = body.bytes();
+        this.writeByte(ProtocolType.Array);
+        this.writeSize(data.length);
+        this.writeBytes(data);
+    }
+
+    writeStruct(value: Codable | null | undefined): void {
+        if (value == null) {
+            return this.writeEmpty(ProtocolType.Struct);
+        }
+        const body = new Writer();
+        value.encode(body);
+        const data = body.bytes();
+        this.writeByte(ProtocolType.Struct);
+        this.writeSize(data.length);
+        this.writeBytes(data);
+    }
+}
+
+export class Reader {
+    private readonly view: DataView;
+
+    constructor(private readonly data: Uint8Array, public position = 0, private readonly limit = data.length) {
+        this.view = new DataView(data.buffer, data.byteOffset, data.byteLength);
+    }
+
+    private take(n: number): number {
+        if (n > this.limit - this.position) {
+            throw new DecodeError(this.position, "value exceeds message boundaries");
+        }
+        const offset = this.position;
+        this.position += n;
+        return offset;
+    }
+
+    private byte(): number {
+        return this.data[this.take(1)];
+    }
+
+    readBigSize(): bigint {
+        const offset = this.position;
+        const width = this.byte();
+        switch (width) {
+            case 1: return BigInt(this.byte());
+            case 2: return BigInt(this.view.getUint16(this.take(2), true));
+            case 4: return BigInt(this.view.getUint32(this.take(4), true));
+            case 8: return this.view.getBigUint64(this.take(8), true);
+        }
+        throw new DecodeError(offset, "invalid size width " + width);
+    }
+
+    readSize(): number {
+        const offset = this.position;
+        const size = this.readBigSize();
+        if (size > BigInt(this.limit - this.position)) {
+            throw new DecodeError(offset, "value exceeds message boundaries");
+        }
+        return Number(size);
+    }
+
+    /**
+     * begin reads the type byte of a value, returning false when the value
+     * is empty. Missing trailing values are also considered empty.
+     */
+    private begin(t: ProtocolType): boolean {
+        if (this.position >= this.limit) {
+            return false;
+        }
+        const offset = this.position;
+        const b = this.byte();
+        if ((b & ~FLAG_EMPTY) !== t) {
+            throw new DecodeError(offset, "expected " + ProtocolType[t] + ", found " + (ProtocolType[b & ~FLAG_EMPTY] || "unknown type"));
+        }
+        return (b & FLAG_EMPTY) === 0;
+    }
+
+    /** body returns a reader limited to the next value, advancing past it */
+    private body(): Reader {
+        const size = this.readSize();
+        const start = this.take(size);
+        return new Reader(this.data, start, start + size);
+    }
+
+    readUint8(): number | null {
+        return this.begin(ProtocolType.Uint8) ? this.byte() : null;
+    }
+
+    readUint32(): number | null {
+        return this.begin(ProtocolType.Uint32) ? this.view.getUint32(this.take(4), true) : null;
+    }
+
+    readUint64(): bigint | null {
+        return this.begin(ProtocolType.Uint64) ? this.view.getBigUint64(this.take(8), true) : null;
+    }
+
+    readDouble(): number | null {
+        return this.begin(ProtocolType.Double) ? this.view.getFloat64(this.take(8), true) : null;
+    }
+
+    readString(): string | null {
+        if (!this.begin(ProtocolType.String)) {
+            return null;
+        }
+        const size = this.readSize();
+        const offset = this.take(size);
+        try {
+            return textDecoder.decode(this.data.subarray(offset, offset + size));
+        } catch (e) {
+            throw new DecodeError(offset, "invalid UTF-8 string");
+        }
+    }
+
+    readBlob(): Uint8Array | null {
+        if (!this.begin(ProtocolType.Blob)) {
+            return null;
+        }
+        const size = this.readSize();
+        const offset = this.take(size);
+        return this.data.slice(offset, offset + size);
+    }
+
+    readBool(): boolean | null {
+        return this.begin(ProtocolType.Bool) ? this.byte() !== 0 : null;
+    }
+
+    readUUID(): string | null {
+        if (!this.begin(ProtocolType.UUID)) {
+            return null;
+        }
+        const offset = this.take(16);
+        return formatUUID(this.data.subarray(offset, offset + 16));
+    }
+
+    readDynInt(): bigint | null {
+        return this.begin(ProtocolType.DynInt) ? this.readBigSize() : null;
+    }
+
+    readAny(): AnyValue | null {
+        if (!this.begin(ProtocolType.Any)) {
+            return null;
+        }
+        if (this.position >= this.limit) {
+            throw new DecodeError(this.position, "value exceeds message boundaries");
+        }
+        const offset = this.position;
+        const t = this.data[this.position] & ~FLAG_EMPTY;
+        const wrap = (type: ProtocolType, value: unknown): AnyValue | null => (value === null ? null : { type, value } as AnyValue);
+        switch (t) {
+            case ProtocolType.Uint8: return wrap(t, this.readUint8());
+            case ProtocolType.Uint32: return wrap(t, this.readUint32());
+            case ProtocolType.Uint64: return wrap(t, this.readUint64());
+            case ProtocolType.Double: return wrap(t, this.readDouble());
+            case ProtocolType.String: return wrap(t, this.readString());
+            case ProtocolType.Blob: return wrap(t, this.readBlob());
+            case ProtocolType.Bool: return wrap(t, this.readBool());
+            case ProtocolType.UUID: return wrap(t, this.readUUID());
+            case ProtocolType.DynInt: return wrap(t, this.readDynInt());
+            case ProtocolType.Array: return wrap(t, this.readArray((r) => r.readAny()));
+        }
+        throw new DecodeError(offset, (ProtocolType[t] || "unknown type") + " values cannot be held by any");
+    }
+
+    /** readArray reads an array, using element to read each of its items */
+    readArray<T>(element: (r: Reader) => T | null): (T | null)[] | null {
+        if (!this.begin(ProtocolType.Array)) {
+            return null;
+        }
+        const body = this.body();
+        const count = body.readBigSize();
+        const items: (T | null)[] = [];
+        for (let i = BigInt(0); i < count; i++) {
+            if (body.position >= body.limit) {
+                throw new DecodeError(body.position, "value exceeds message boundaries");
+            }
+            items.push(element(body));
+        }
+        return items;
+    }
+
+    readStruct<T extends Codable>(type: CodableType<T>): T | null {
+        if (!this.begin(ProtocolType.Struct)) {
+            return null;
+        }
+        return type.decode(this.body());
+    }
+}
+
+/** Registry decodes messages into the packages registered on it */
+export class Registry {
+    private readonly packages = new Map<number, PackageType>();
+
+    register(...types: PackageType[]): void {
+        types.forEach((t) => this.packages.set(t.id, t));
+    }
+
+    /**
+     * decode decodes the message at the beginning of data, returning it along
+     * with the amount of bytes it used. ShortBufferError is thrown when data
+     * does not contain a complete message yet.
+     */
+    decode(data: Uint8Array): { message: Message; length: number } {
+        const headerSize = MAGIC.length + 3;
+        if (data.length <= headerSize) {
+            throw new ShortBufferError();
+        }
+        for (let i = 0; i < MAGIC.length; i++) {
+            if (data[i] !== MAGIC[i]) {
+                throw new DecodeError(0, "invalid magic");
+            }
+        }
+        const version = data[MAGIC.length];
+        if (version !== PROTOCOL_VERSION) {
+            throw new DecodeError(MAGIC.length, "unsupported protocol version " + version);
+        }
+        const messageId = data[MAGIC.length + 1];
+        const packageId = data[MAGIC.length + 2];
+
+        const width = data[headerSize];
+        if (width !== 1 && width !== 2 && width !== 4 && width !== 8) {
+            throw new DecodeError(headerSize, "invalid size width " + width);
+        }
+        const header = new Reader(data, headerSize);
+        let size: number;
+        try {
+            size = header.readSize();
+        } catch (e) {
+            throw new ShortBufferError();
+        }
+        const start = header.position;
+
+        const type = this.packages.get(packageId);
+        if (type === undefined) {
+            throw new DecodeError(MAGIC.length + 2, "unknown package " + packageId);
+        }
+        const pkg = type.decode(new Reader(data, start, start + size));
+        return { message: { messageId, packageId, package: pkg }, length: start + size };
+    }
+
+    /** encode serializes a registered package into a message */
+    encode(messageId: number, pkg: Codable): Uint8Array {
+        const type = pkg.constructor as unknown as PackageType;
+        if (typeof type.id !== "number") {
+            throw new Error("object is not a package");
+        }
+        const body = new Writer();
+        pkg.encode(body);
+        const payload = body.bytes();
+        const w = new Writer();
+        w.writeBytes(MAGIC);
+        w.writeByte(PROTOCOL_VERSION);
+        w.writeByte(messageId);
+        w.writeByte(type.id);
+        w.writeSize(payload.length);
+        w.writeBytes(payload);
+        return w.bytes();
+    }
+}
+
+/** registry holds packages registered by generated sources */
+export const registry = new Registry();
+`
+
+const tsIntegrationSteps = `You just generated TypeScript sources. In order to use them, copy output files
+to your project. {{.runtime}} holds the encoder and decoder used by generated
+packages, and requires no other dependencies, while {{.index}} registers all
+packages and re-exports them:
+
+     {{.tsImport}}
+
+     {{.tsUsage}}
+
+Sources target ES2020 or later, as {{.bigint}} is used for uint64 and dynint values.
+`
@@ -0,0 +1,95 @@
+package langs
+
+import (
+	"fmt"
+	"os"
+	"testing"
+)
+
+// tsReservedSource declares packages named after runtime exports and modules
+const tsReservedSource = `package writer {
+    id 0x01
+    @reader value
+    struct reader {
+        string text
+    }
+}
+
+package index {
+    id 0x02
+    string text
+}
+
+package ludwieg {
+    id 0x03
+    string text
+}
+`
+
+// tsRoundTrip decodes every vector using generated sources, and checks that
+// encoding the decoded package again yields the very same bytes
+const tsRoundTrip = `import { registry } from "./index";
+
+const vectors: [string, string][] = [
+%VECTORS%];
+
+function toHex(data: Uint8Array): string {
+    return Array.from(data, (b) => (b < 0x10 ? "0" : "") + b.toString(16)).join("");
+}
+
+function fromHex(value: string): Uint8Array {
+    const data = new Uint8Array(value.length / 2);
+    for (let i = 0; i < data.length; i++) {
+        data[i] = parseInt(value.substr(i * 2, 2), 16);
+    }
+    return data;
+}
+
+const failures: string[] = [];
+for (const [name, expected] of vectors) {
+    try {
+        const { message, length } = registry.decode(fromHex(expected));
+        const encoded = toHex(registry.encode(message.messageId, message.package));
+        if (length * 2 !== expected.length || encoded !== expected) {
+            failures.push(name + ": encoded " + encoded + ", expected " + expected);
+        }
+    } catch (e) {
+        failures.push(name + ": " + e);
+    }
+}
+if (failures.length > 0) {
+    throw new Error(failures.join("\n"));
+}
+console.log(vectors.length + " vectors");
+`
+
+func TestTypeScriptRoundTrip(t *testing.T) {
+	tsc := testTool(t, "tsc")
+	node := testTool(t, "node")
+	packages := testPackages(t, vectorSource, tsReservedSource)
+	dir := testCompile(t, TypeScript{}, "", "", packages)
+	defer os.RemoveAll(dir)
+
+	testRoundTrip(t, dir, &(*packages)[0], "check.ts", tsRoundTrip, func(v testVector) string {
+		return fmt.Sprintf("    [%q, %q],\n", v.Name, v.Hex)
+	}, []string{tsc, "--strict", "--target", "es2020", "--module", "commonjs", "--lib", "es2020,dom", "check.ts"},
+		[]string{node, "check.js"})
+}
+
+func TestTypeScriptReservedNames(t *testing.T) {
+	dir := testCompile(t, TypeScript{}, "", "", testPackages(t, tsReservedSource))
+	defer os.RemoveAll(dir)
+
+	testContains(t, "writer.ts", testOutput(t, dir, "writer.ts"),
+		"export class Writer_ implements Codable {",
+		"export class WriterReader implements Codable {",
+		"encode(w: Writer): void {")
+	testContains(t, "index_.ts", testOutput(t, dir, "index_.ts"), "export class Index implements Codable {")
+	testContains(t, "ludwieg_.ts", testOutput(t, dir, "ludwieg_.ts"), "export class Ludwieg implements Codable {")
+	testContains(t, "ludwieg.ts", testOutput(t, dir, "ludwieg.ts"), "export class Registry {")
+	testContains(t, "index.ts", testOutput(t, dir, "index.ts"),
+		`import { Writer_ } from "./writer";`,
+		`import { Index } from "./index_";`,
+		`export * from "./ludwieg_";`,
+		"registry.register(Writer_, Index, Ludwieg);")
+}
@@ -0,0 +1,243 @@
+package langs
+
+import (
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/logrusorgru/aurora"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/ludwieg/ludco/codec"
+	"github.com/ludwieg/ludco/models"
+)
+
+// wiresharkPort holds the TCP port registered by generated dissectors
+const wiresharkPort = 9000
+
+// wiresharkItemSuffix is appended to keys of arrays to obtain keys of their
+// items. Identifiers cannot hold dashes, so it cannot clash with fields of
+// structures.
+const wiresharkItemSuffix = "-item"
+
+// wiresharkAnyTypes lists types that can be held by any fields
+var wiresharkAnyTypes = []models.NativeType{
+	models.TypeUint8,
+	models.TypeUint32,
+	models.TypeUint64,
+	models.TypeDouble,
+	models.TypeString,
+	models.TypeBlob,
+	models.TypeBool,
+	models.TypeUUID,
+	models.TypeDynInt,
+}
+
+type Wireshark struct {
+	proto string
+	out   string
+
+	// structs maps structures to the key of their schema on the generated
+	// dissector
+	structs map[*models.Struct]string
+}
+
+func (c Wireshark) Compile(in, out, pkgName, prefix string, packages *models.PackageList) {
+	log.Infof("Initialising %s compiler", aurora.Cyan("Wireshark"))
+	if prefix != "" {
+		log.Warn("Ignoring unnecessary --prefix option.")
+		prefix = ""
+	}
+	if pkgName == "" {
+		pkgName = "ludwieg"
+	}
+	// Protocol names are used on display filters, and must be lowercase
+	r := regexp.MustCompile("[^a-z0-9_]")
+	c.proto = string(r.ReplaceAll([]byte(strings.ToLower(pkgName)), []byte{}))
+	c.out = out
+	c.structs = map[*models.Struct]string{}
+
+	var fields, schemas, definitions, pkgs []string
+	for _, t := range wiresharkAnyTypes {
+		name := codec.ProtocolTypeFor(t).String()
+		fields = append(fields, c.protoField("any_"+name, name, name))
+	}
+	for i := range *packages {
+		p := &(*packages)[i]
+		c.registerStructs(p.Name, p.Structs)
+	}
+	for i := range *packages {
+		p := &(*packages)[i]
+		fields = append(fields, c.generateFields(p.Name, p.Fields)...)
+		schemas = append(schemas, fmt.Sprintf("schemas[%q] = { name = %q }", p.Name, p.Name))
+		definitions = append(definitions, fmt.Sprintf("schemas[%q].fields = %s", p.Name, c.generateDescriptors(p.Name, p.Scope(), p.Fields)))
+		c.generateStructs(p.Scope(), p.Structs, &fields, &schemas, &definitions)
+		pkgs = append(pkgs, fmt.Sprintf("packages[%s] = schemas[%q]", p.Identifier, p.Name))
+	}
+	// Schemas are declared before being defined, as descriptors may
+	// reference any of them
+	schemas = append(schemas, definitions...)
+
+	var types []string
+	for _, t := range []codec.ProtocolType{
+		codec.TypeUint8, codec.TypeUint32, codec.TypeUint64, codec.TypeDouble,
+		codec.TypeString, codec.TypeBlob, codec.TypeBool, codec.TypeUUID,
+		codec.TypeAny, codec.TypeArray, codec.TypeStruct, codec.TypeDynInt,
+	} {
+		types = append(types, fmt.Sprintf("    %s = 0x%02x,", t, byte(t)))
+	}
+
+	c.output(processTemplate("wiresharkDissector", wiresharkDissector, templateData{
+		"proto":       c.proto,
+		"display":     convertToPascalCase(c.proto),
+		"port":        wiresharkPort,
+		"magic":       strings.ToUpper(hex.EncodeToString(codec.Magic)),
+		"magicLength": len(codec.Magic),
+		"version":     fmt.Sprintf("0x%02x", codec.ProtocolVersion),
+		"headerSize":  len(codec.Magic) + 4,
+		"types":       strings.Join(types, "\n"),
+		"fields":      strings.Join(fields, "\n"),
+		"schemas":     strings.Join(schemas, "\n"),
+		"packages":    strings.Join(pkgs, "\n"),
+	}))
+
+	log.Info("Succeeded")
+	fmt.Println()
+	fmt.Println(c.integrationInstructions(packages))
+}
+
+func (c Wireshark) output(contents []byte) {
+	name := c.proto + ".lua"
+	log.Infof("Writing %s/%s", aurora.Magenta(filepath.Base(c.out)), aurora.Magenta(name))
+	err := ioutil.WriteFile(filepath.Join(c.out, name), contents, 0644)
+	if err != nil {
+		log.Errorf("Operation failed: %s", err)
+		os.Exit(1)
+	}
+}
+
+// registerStructs assigns schema keys to structures before fields are
+// generated, as fields may reference structures declared after them
+func (c Wireshark) registerStructs(prefix string, sArr []models.Struct) {
+	for i := range sArr {
+		s := &sArr[i]
+		key := prefix + "." + s.Name
+		c.structs[s] = key
+		c.registerStructs(key, s.Structs)
+	}
+}
+
+func (c Wireshark) generateStructs(scope *models.Scope, sArr []models.Struct, fields, schemas, definitions *[]string) {
+	for i := range sArr {
+		s := &sArr[i]
+		key := c.structs[s]
+		inner := &models.Scope{Structs: s.Structs, Parent: scope}
+		*fields = append(*fields, c.generateFields(key, s.Fields)...)
+		*schemas = append(*schemas, fmt.Sprintf("schemas[%q] = { name = %q }", key, s.Name))
+		*definitions = append(*definitions, fmt.Sprintf("schemas[%q].fields = %s", key, c.generateDescriptors(key, inner, s.Fields)))
+		c.generateStructs(inner, s.Structs, fields, schemas, definitions)
+	}
+}
+
+// fieldKind returns the type name used by descriptors of a field
+func (c Wireshark) fieldKind(f *models.Field) string {
+	if f.Type.Source == models.SourceUser {
+		return codec.TypeStruct.String()
+	}
+	return codec.ProtocolTypeFor(f.Type.NativeType).String()
+}
+
+func (c Wireshark) protoField(key, name, kind string) string {
+	data := templateData{
+		"key":   strconv.Quote(key),
+		"abbr":  strconv.Quote(c.proto + "." + key),
+		"name":  strconv.Quote(name),
+		"extra": "",
+	}
+	switch kind {
+	case "uint8", "uint32", "uint64":
+		data["kind"], data["extra"] = kind, ", base.DEC"
+	case "dynint":
+		data["kind"], data["extra"] = "uint64", ", base.DEC"
+	case "double", "string", "bool":
+		data["kind"] = kind
+	case "blob":
+		data["kind"] = "bytes"
+	case "uuid":
+		data["kind"] = "guid"
+	default:
+		data["kind"] = "none"
+	}
+	return string(processTemplate("wiresharkField", wiresharkField, data))
+}
+
+func (c Wireshark) generateFields(prefix string, fArr []models.Field) []string {
+	var result []string
+	for _, f := range fArr {
+		key := prefix + "." + f.Name
+		if f.IsArray() {
+			result = append(result, c.protoField(key, f.Name, "array"))
+			key += wiresharkItemSuffix
+		}
+		result = append(result, c.protoField(key, f.Name, c.fieldKind(&f)))
+	}
+	return result
+}
+
+func (c Wireshark) generateDescriptors(prefix string, scope *models.Scope, fArr []models.Field) string {
+	if len(fArr) == 0 {
+		return "{}"
+	}
+	var result []string
+	for _, f := range fArr {
+		key := prefix + "." + f.Name
+		extra := fmt.Sprintf(", pf = fields[%q]", key)
+		if f.IsArray() {
+			extra += fmt.Sprintf(", array = true, item = fields[%q]", key+wiresharkItemSuffix)
+		}
+		if f.Type.Source == models.SourceUser {
+			s, _, ok := scope.Resolve(f.Type.CustomType)
+			if !ok {
+				log.Errorf("BUG: Cannot resolve structure %s referenced by %s", f.Type.CustomType, key)
+				os.Exit(1)
+			}
+			extra += fmt.Sprintf(", struct = schemas[%q]", c.structs[s])
+		}
+		if f.HasAttribute(models.AttributeDeprecated) {
+			extra += ", deprecated = true"
+		}
+		result = append(result, "    "+string(processTemplate("wiresharkDescriptor", wiresharkDescriptor, templateData{
+			"name":  strconv.Quote(f.Name),
+			"type":  strconv.Quote(c.fieldKind(&f)),
+			"extra": extra,
+		})))
+	}
+	return "{\n" + strings.Join(result, ",\n") + ",\n}"
+}
+
+func (c Wireshark) integrationInstructions(pList *models.PackageList) string {
+	filter := c.proto + ".package"
+	if len(*pList) > 0 {
+		names := []string{}
+		for _, p := range *pList {
+			names = append(names, p.Name)
+		}
+		sort.Strings(names)
+		filter = c.proto + ".package == \"" + names[0] + "\""
+	}
+
+	return string(processTemplate("wiresharkIntegration", wiresharkIntegrationSteps, templateData{
+		"file":        aurora.Magenta(c.proto + ".lua"),
+		"about":       aurora.Bold("Help › About Wireshark › Folders"),
+		"port":        wiresharkPort,
+		"preferences": aurora.Bold("Preferences › Protocols › " + convertToPascalCase(c.proto)),
+		"decodeAs":    aurora.Bold("Decode As..."),
+		"filter":      aurora.Magenta(filter),
+	}))
+}
@@ -0,0 +1,299 @@
+package langs
+
+const wiresharkField = `fields[{{.key}}] = ProtoField.{{.kind}}({{.abbr}}, {{.name}}{{.extra}})`
+
+const wiresharkDescriptor = `{ name = {{.name}}, type = {{.type}}{{.extra}} }`
+
+const wiresharkDissector = `-- WARNING: Automatically generated by ludco. DO NOT EDIT.
+--
+-- Wireshark dissector for the {{.display}} protocol. Packages are decoded on
+-- TCP port {{.port}} by default, which can be changed on the protocol
+-- preferences, or through "Decode As...".
+
+local proto = Proto("{{.proto}}", "{{.display}} Protocol")
+
+local MAGIC = "{{.magic}}"
+local MAGIC_LENGTH = {{.magicLength}}
+local PROTOCOL_VERSION = {{.version}}
+local FLAG_EMPTY = 0x80
+local HEADER_SIZE = {{.headerSize}}
+
+local types = {
+{{.types}}
+}
+local type_names = {}
+for name, code in pairs(types) do
+    type_names[code] = name
+end
+
+-- Keys of header and any fields hold no dots, while keys of package fields
+-- hold at least one, so they never clash
+local fields = {}
+fields["magic"] = ProtoField.bytes("{{.proto}}.magic", "Magic")
+fields["version"] = ProtoField.uint8("{{.proto}}.version", "Version", base.HEX)
+fields["message_id"] = ProtoField.uint8("{{.proto}}.message_id", "Message ID", base.DEC)
+fields["package_id"] = ProtoField.uint8("{{.proto}}.package_id", "Package ID", base.HEX)
+fields["package"] = ProtoField.string("{{.proto}}.package", "Package")
+fields["size"] = ProtoField.uint64("{{.proto}}.size", "Payload size", base.DEC)
+fields["any_array"] = ProtoField.none("{{.proto}}.any_array", "array")
+fields["any_value"] = ProtoField.none("{{.proto}}.any_value", "any")
+{{.fields}}
+
+local field_list = {}
+for _, f in pairs(fields) do
+    table.insert(field_list, f)
+end
+proto.fields = field_list
+
+local expert_malformed = ProtoExpert.new("{{.proto}}.malformed", "Malformed package", expert.group.MALFORMED, expert.severity.ERROR)
+local expert_unknown_package = ProtoExpert.new("{{.proto}}.unknown_package", "Unknown package", expert.group.UNDECODED, expert.severity.WARN)
+local expert_unknown_values = ProtoExpert.new("{{.proto}}.unknown_values", "Unknown trailing values", expert.group.UNDECODED, expert.severity.NOTE)
+proto.experts = { expert_malformed, expert_unknown_package, expert_unknown_values }
+
+local schemas = {}
+{{.schemas}}
+
+local packages = {}
+{{.packages}}
+
+-- Values carried by any fields are described by their own type byte
+local any_descs = {}
+for name, _ in pairs(types) do
+    any_descs[name] = { name = name, type = name, pf = fields["any_" .. name] }
+end
+any_descs["array"] = { name = "array", type = "any", array = true, pf = fields["any_array"], item = fields["any_value"] }
+
+-- Failures are raised as tables, and reported through expert info by
+-- dissect_message
+local function fail(tree, offset, message)
+    error({ tree = tree, offset = offset, message = message }, 0)
+end
+
+local function need(tree, offset, n, limit)
+    if offset + n > limit then
+        fail(tree, offset, "value exceeds message boundaries")
+    end
+end
+
+local function read_size(tvb, tree, offset, limit)
+    need(tree, offset, 1, limit)
+    local width = tvb(offset, 1):uint()
+    if width ~= 1 and width ~= 2 and width ~= 4 and width ~= 8 then
+        fail(tree, offset, string.format("invalid size width %d", width))
+    end
+    need(tree, offset + 1, width, limit)
+    return tvb(offset + 1, width):le_uint64():tonumber(), 1 + width
+end
+
+local function type_name(code)
+    return type_names[code] or string.format("unknown(0x%02x)", code)
+end
+
+local function add_native(tvb, tree, offset, limit, pf, name)
+    if name == "uint8" or name == "bool" then
+        need(tree, offset, 1, limit)
+        return offset + 1, tree:add(pf, tvb(offset, 1))
+    elseif name == "uint32" then
+        need(tree, offset, 4, limit)
+        return offset + 4, tree:add_le(pf, tvb(offset, 4))
+    elseif name == "uint64" or name == "double" then
+        need(tree, offset, 8, limit)
+        return offset + 8, tree:add_le(pf, tvb(offset, 8))
+    elseif name == "uuid" then
+        need(tree, offset, 16, limit)
+        return offset + 16, tree:add(pf, tvb(offset, 16))
+    elseif name == "dynint" then
+        local _, n = read_size(tvb, tree, offset, limit)
+        return offset + n, tree:add_le(pf, tvb(offset + 1, n - 1))
+    end
+
+    local size, n = read_size(tvb, tree, offset, limit)
+    need(tree, offset + n, size, limit)
+    local range = tvb(offset + n, size)
+    if name == "string" then
+        return offset + n + size, tree:add_packet_field(pf, range, ENC_UTF_8)
+    end
+    return offset + n + size, tree:add(pf, range)
+end
+
+local dissect_fields
+
+-- dissect_value adds a complete value, starting on its type byte, to the
+-- tree, returning the offset following it
+local function dissect_value(tvb, tree, offset, limit, desc)
+    need(tree, offset, 1, limit)
+    local t = tvb(offset, 1):uint()
+    local code = t % FLAG_EMPTY
+    local expected = desc.array and "array" or desc.type
+    if code ~= types[expected] then
+        fail(tree, offset, string.format("%s: expected %s, found %s", desc.name, expected, type_name(code)))
+    end
+    if t >= FLAG_EMPTY then
+        local item = tree:add(tvb(offset, 1), desc.name .. ": (empty)")
+        if desc.deprecated then
+            item:append_text(" [Deprecated]")
+        end
+        return offset + 1
+    end
+
+    local item, pos
+    if desc.array then
+        local size, n = read_size(tvb, tree, offset + 1, limit)
+        local body = offset + 1 + n
+        need(tree, body, size, limit)
+        item = tree:add(desc.pf, tvb(offset, 1 + n + size))
+        local count, m = read_size(tvb, item, body, body + size)
+        item:append_text(string.format(" (%d items)", count))
+        local item_desc = { name = desc.name, type = desc.type, pf = desc.item, struct = desc.struct }
+        pos = body + m
+        for _ = 1, count do
+            pos = dissect_value(tvb, item, pos, body + size, item_desc)
+        end
+        pos = body + size
+    elseif desc.type == "struct" then
+        local size, n = read_size(tvb, tree, offset + 1, limit)
+        need(tree, offset + 1 + n, size, limit)
+        item = tree:add(desc.pf, tvb(offset, 1 + n + size))
+        item:append_text(" (" .. desc.struct.name .. ")")
+        pos = offset + 1 + n + size
+        dissect_fields(tvb, item, offset + 1 + n, pos, desc.struct.fields)
+    elseif desc.type == "any" then
+        need(tree, offset + 1, 1, limit)
+        local inner = type_name(tvb(offset + 1, 1):uint() % FLAG_EMPTY)
+        local inner_desc = any_descs[inner]
+        if inner_desc == nil or inner == "any" or inner == "struct" then
+            fail(tree, offset + 1, string.format("%s: %s values cannot be held by any", desc.name, inner))
+        end
+        item = tree:add(desc.pf, tvb(offset, 2))
+        pos = dissect_value(tvb, item, offset + 1, limit, inner_desc)
+        item:set_len(pos - offset)
+    else
+        pos, item = add_native(tvb, tree, offset + 1, limit, desc.pf, desc.type)
+    end
+
+    if desc.deprecated then
+        item:append_text(" [Deprecated]")
+    end
+    return pos
+end
+
+dissect_fields = function(tvb, tree, offset, limit, fields)
+    for _, desc in ipairs(fields) do
+        -- Missing trailing fields are considered empty
+        if offset >= limit then
+            break
+        end
+        offset = dissect_value(tvb, tree, offset, limit, desc)
+    end
+    if offset < limit then
+        local item = tree:add(tvb(offset, limit - offset), "Unknown trailing values")
+        item:add_proto_expert_info(expert_unknown_values)
+    end
+end
+
+-- dissect_message returns the length of the message starting at offset, a
+-- negative value indicating how many bytes are missing to complete it, or 0
+-- when data does not belong to a message
+local function dissect_message(tvb, pinfo, tree, offset)
+    local remaining = tvb:len() - offset
+    if remaining < HEADER_SIZE then
+        return -DESEGMENT_ONE_MORE_SEGMENT
+    end
+    if tvb(offset, MAGIC_LENGTH):bytes():tohex() ~= MAGIC then
+        return 0
+    end
+    local width = tvb(offset + HEADER_SIZE - 1, 1):uint()
+    if width ~= 1 and width ~= 2 and width ~= 4 and width ~= 8 then
+        return 0
+    end
+    if remaining < HEADER_SIZE + width then
+        return -DESEGMENT_ONE_MORE_SEGMENT
+    end
+    local size = tvb(offset + HEADER_SIZE, width):le_uint64():tonumber()
+    local total = HEADER_SIZE + width + size
+    if remaining < total then
+        return -(total - remaining)
+    end
+
+    local magic_len = MAGIC_LENGTH
+    local version = tvb(offset + magic_len, 1):uint()
+    local message_id = tvb(offset + magic_len + 1, 1):uint()
+    local package_id = tvb(offset + magic_len + 2, 1):uint()
+
+    local subtree = tree:add(proto, tvb(offset, total))
+    local header = subtree:add(tvb(offset, HEADER_SIZE + width), "Header")
+    header:add(fields["magic"], tvb(offset, magic_len))
+    header:add(fields["version"], tvb(offset + magic_len, 1))
+    header:add(fields["message_id"], tvb(offset + magic_len + 1, 1))
+    local package_item = header:add(fields["package_id"], tvb(offset + magic_len + 2, 1))
+    header:add_le(fields["size"], tvb(offset + HEADER_SIZE, width))
+
+    if version ~= PROTOCOL_VERSION then
+        subtree:add_proto_expert_info(expert_malformed, string.format("unsupported protocol version 0x%02x", version))
+        return total, "malformed"
+    end
+    local pkg = packages[package_id]
+    if pkg == nil then
+        package_item:add_proto_expert_info(expert_unknown_package, string.format("unknown package identifier 0x%02x", package_id))
+        return total, string.format("unknown(0x%02x)", package_id)
+    end
+    subtree:append_text(string.format(", %s, Message ID: %d", pkg.name, message_id))
+    header:add(fields["package"], tvb(offset + magic_len + 2, 1), pkg.name)
+
+    local ok, err = pcall(dissect_fields, tvb, subtree, offset + HEADER_SIZE + width, offset + total, pkg.fields)
+    if not ok then
+        if type(err) ~= "table" then
+            error(err, 0)
+        end
+        err.tree:add_proto_expert_info(expert_malformed, string.format("offset %d: %s", err.offset - offset, err.message))
+    end
+    return total, pkg.name
+end
+
+function proto.dissector(tvb, pinfo, tree)
+    local offset = 0
+    local names = {}
+    while offset < tvb:len() do
+        local n, name = dissect_message(tvb, pinfo, tree, offset)
+        if n < 0 then
+            pinfo.desegment_offset = offset
+            pinfo.desegment_len = -n
+            break
+        elseif n == 0 then
+            if offset == 0 then
+                return 0
+            end
+            break
+        end
+        table.insert(names, name)
+        offset = offset + n
+    end
+
+    if #names > 0 then
+        pinfo.cols.protocol = "{{.display}}"
+        pinfo.cols.info = table.concat(names, ", ")
+    end
+    return tvb:len()
+end
+
+proto.prefs.port = Pref.uint("TCP port", {{.port}}, "TCP port used by {{.display}} servers")
+
+local registered_port = proto.prefs.port
+DissectorTable.get("tcp.port"):add(registered_port, proto)
+
+function proto.prefs_changed()
+    local tcp = DissectorTable.get("tcp.port")
+    tcp:remove(registered_port, proto)
+    registered_port = proto.prefs.port
+    tcp:add(registered_port, proto)
+end
+`
+
+const wiresharkIntegrationSteps = `You just generated a Wireshark dissector. In order to use it, copy
+{{.file}} to your Wireshark personal plugins folder, listed under
+{{.about}}, and restart Wireshark.
+
+Packages sent to or from TCP port {{.port}} are decoded automatically. Other ports
+can be configured on {{.preferences}}, or by using {{.decodeAs}}.
+Display filters are available for every field, such as {{.filter}}.
+`
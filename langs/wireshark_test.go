@@ -0,0 +1,66 @@
+package langs
+
+import (
+	"os"
+	"regexp"
+	"testing"
+)
+
+func TestWiresharkUniqueFields(t *testing.T) {
+	dir := testCompile(t, Wireshark{}, "", "", testPackages(t, vectorSource, recursiveSource, `
+package header {
+    id 0x01
+    uint8 magic
+    string package
+}
+
+package any {
+    id 0x02
+    uint8 value
+    @array[*] array
+    struct array {
+        string value
+    }
+}
+
+package list {
+    id 0x03
+    @x[*] x
+    struct x {
+        string item
+        @y[*] y
+        struct y {
+            string item
+        }
+    }
+}
+`))
+	defer os.RemoveAll(dir)
+
+	code := testOutput(t, dir, "ludwieg.lua")
+	keys := map[string]bool{}
+	for _, m := range regexp.MustCompile(`(?m)^fields\["([^"]+)"\] = ProtoField\.`).FindAllStringSubmatch(code, -1) {
+		if keys[m[1]] {
+			t.Errorf("field key %s is declared more than once", m[1])
+		}
+		keys[m[1]] = true
+	}
+	abbrs := map[string]bool{}
+	for _, m := range regexp.MustCompile(`ProtoField\.\w+\("([^"]+)"`).FindAllStringSubmatch(code, -1) {
+		if abbrs[m[1]] {
+			t.Errorf("field abbreviation %s is declared more than once", m[1])
+		}
+		abbrs[m[1]] = true
+	}
+	if len(keys) != len(abbrs) {
+		t.Errorf("%d field keys are declared for %d abbreviations", len(keys), len(abbrs))
+	}
+
+	testContains(t, "ludwieg.lua", code,
+		`fields["header.magic"] = ProtoField.uint8("ludwieg.header.magic"`,
+		`fields["any.value"] = ProtoField.uint8("ludwieg.any.value"`,
+		`fields["any_value"] = ProtoField.none("ludwieg.any_value"`,
+		`fields["list.x-item"] = ProtoField.none("ludwieg.list.x-item"`,
+		`fields["list.x.item"] = ProtoField.string("ludwieg.list.x.item"`,
+		`item = fields["list.x-item"], struct = schemas["list.x"]`)
+}
@@ -19,6 +19,19 @@ func main() {
 	app.Commands = []cli.Command{
 		cmd.Compile,
 		cmd.Show,
+		cmd.TestVectors,
+		cmd.Test,
+		cmd.Fake,
+		cmd.Mock,
+		cmd.Proxy,
+		cmd.Record,
+		cmd.Replay,
+		cmd.Decode,
+		cmd.Pcap,
+		cmd.Export,
+		cmd.Import,
+		cmd.Doc,
+		cmd.Graph,
 	}
 
 	app.Action = func(c *cli.Context) error {
@@ -0,0 +1,39 @@
+package models
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"fmt"
+	"sort"
+)
+
+// Fingerprint returns a SHA-256 hash identifying the wire layout of all
+// packages in the list. Packages are hashed in identifier order, and only
+// names, identifiers, types, and array sizes are considered, so that
+// attributes and examples do not change the result.
+func (s PackageList) Fingerprint() [sha256.Size]byte {
+	sorted := append(PackageList{}, s...)
+	sort.Sort(sorted)
+
+	var buf bytes.Buffer
+	for _, p := range sorted {
+		fmt.Fprintf(&buf, "package %s %s\n", p.Identifier, p.Name)
+		fingerprintBody(&buf, p.Structs, p.Fields)
+	}
+	return sha256.Sum256(buf.Bytes())
+}
+
+func fingerprintBody(buf *bytes.Buffer, structs []Struct, fields []Field) {
+	for _, f := range fields {
+		t := string(f.Type.NativeType)
+		if f.Type.Source == SourceUser {
+			t = "@" + f.Type.CustomType
+		}
+		fmt.Fprintf(buf, "field %s %s [%s]\n", f.Name, t, f.Size)
+	}
+	for _, str := range structs {
+		fmt.Fprintf(buf, "struct %s {\n", str.Name)
+		fingerprintBody(buf, str.Structs, str.Fields)
+		buf.WriteString("}\n")
+	}
+}
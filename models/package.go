@@ -123,11 +123,17 @@ type Package struct {
 	// protocol
 	Identifier string
 
+	// Doc holds the text of the doc comment preceding the package, if any
+	Doc string
+
 	// Structs defines custom user types used in the current package
 	Structs []Struct
 
 	// Fields defines all fields that the package carries
 	Fields []Field
+
+	// Examples holds instances of the package declared alongside it
+	Examples []Example
 }
 
 // RawIdentifier returns the raw identifier of a package as a byte
@@ -161,6 +167,9 @@ type Struct struct {
 	// language then generating sources
 	Name string
 
+	// Doc holds the text of the doc comment preceding the structure, if any
+	Doc string
+
 	// Structs defines custom user types used in the current structure
 	Structs []Struct
 
@@ -168,6 +177,35 @@ type Struct struct {
 	Fields []Field
 }
 
+// Scope lists the structures visible from a package or struct body. User
+// types are looked up on the innermost scope first, and then on its parents.
+type Scope struct {
+	// Structs holds structures declared on the body
+	Structs []Struct
+
+	// Parent holds the scope enclosing the body, if any
+	Parent *Scope
+}
+
+// Scope returns the scope of the package body
+func (p *Package) Scope() *Scope {
+	return &Scope{Structs: p.Structs}
+}
+
+// Resolve looks up a structure by name, returning it along with the scope of
+// its own body
+func (s *Scope) Resolve(name string) (*Struct, *Scope, bool) {
+	for cur := s; cur != nil; cur = cur.Parent {
+		for i := range cur.Structs {
+			if cur.Structs[i].Name == name {
+				str := &cur.Structs[i]
+				return str, &Scope{Structs: str.Structs, Parent: cur}, true
+			}
+		}
+	}
+	return nil, nil, false
+}
+
 // Field contains metadata about a field defined in a package.
 type Field struct {
 
@@ -188,6 +226,9 @@ type Field struct {
 
 	// Attributes holds any attribute added to the field
 	Attributes []Attribute
+
+	// Doc holds the text of the doc comment attached to the field, if any
+	Doc string
 }
 
 // HasAttribute determines whether a field contains a given attribute
@@ -205,6 +246,70 @@ func (f Field) IsArray() bool {
 	return f.Size != ""
 }
 
+// LiteralKind identifies the notation used to write a literal value
+type LiteralKind string
+
+const (
+	// LiteralNumber represents a decimal number, such as 27 or 30.2
+	LiteralNumber LiteralKind = "number"
+
+	// LiteralHex represents an hexadecimal value, such as 0x272450. It may be
+	// used by integer and blob fields.
+	LiteralHex LiteralKind = "hex"
+
+	// LiteralString represents a double-quoted string
+	LiteralString LiteralKind = "string"
+
+	// LiteralBool represents either true or false
+	LiteralBool LiteralKind = "bool"
+
+	// LiteralNull represents an empty value
+	LiteralNull LiteralKind = "null"
+
+	// LiteralUUID represents an UUID in its canonical form
+	LiteralUUID LiteralKind = "uuid"
+
+	// LiteralList represents a list of values, used by arrays
+	LiteralList LiteralKind = "list"
+
+	// LiteralStruct represents a set of assignments, used by user types
+	LiteralStruct LiteralKind = "struct"
+)
+
+// Literal represents a value written on an example
+type Literal struct {
+	// Kind indicates the notation used by the literal
+	Kind LiteralKind
+
+	// Value holds the literal text of scalar values. Strings are unquoted.
+	Value string
+
+	// Items holds values of a LiteralList
+	Items []Literal
+
+	// Fields holds assignments of a LiteralStruct
+	Fields []Assignment
+}
+
+// Assignment associates a value to a field on an example
+type Assignment struct {
+	// Name indicates the field being assigned
+	Name string
+
+	// Value holds the value assigned to the field
+	Value Literal
+}
+
+// Example represents a named instance of a package, declared along with it
+type Example struct {
+	// Name identifies the example among other examples on the package
+	Name string
+
+	// Fields holds values assigned to the package fields. Fields not
+	// assigned are considered empty.
+	Fields []Assignment
+}
+
 func sourceFromParser(source string) Source {
 	if source == parser.SourceNative {
 		return SourceNative
@@ -274,6 +379,7 @@ func nativeTypeFromParser(t string) NativeType {
 func structFromParser(obj parser.Object) Struct {
 	str := Struct{
 		Name:    obj.Name,
+		Doc:     obj.Doc,
 		Fields:  []Field{},
 		Structs: []Struct{},
 	}
@@ -296,6 +402,7 @@ func fieldFromParser(obj parser.Object) Field {
 		ObjectType: ObjectTypeField,
 		Name:       obj.Name,
 		Attributes: attributesFromParser(obj.Attributes),
+		Doc:        obj.Doc,
 	}
 
 	if obj.ObjectType == parser.ObjArray {
@@ -305,6 +412,39 @@ func fieldFromParser(obj parser.Object) Field {
 	return result
 }
 
+func literalFromParser(lit parser.Literal) Literal {
+	result := Literal{
+		Kind:  LiteralKind(lit.Kind),
+		Value: lit.Value,
+	}
+	for _, i := range lit.Items {
+		result.Items = append(result.Items, literalFromParser(i))
+	}
+	result.Fields = assignmentsFromParser(lit.Fields)
+	return result
+}
+
+func assignmentsFromParser(objs []parser.Object) []Assignment {
+	arr := []Assignment{}
+	for _, o := range objs {
+		if o.ObjectType != parser.ObjAssignment {
+			fail("invalid assignment %s", o.Name)
+		}
+		arr = append(arr, Assignment{
+			Name:  o.Name,
+			Value: literalFromParser(o.Literal),
+		})
+	}
+	return arr
+}
+
+func exampleFromParser(obj parser.Object) Example {
+	return Example{
+		Name:   obj.Name,
+		Fields: assignmentsFromParser(obj.Contents),
+	}
+}
+
 // ConvertASTPackage attempts to convert a `parser.Package` type into a
 // `models.Package` object, which has extra granular options. Please do notice
 // that instead of returning errors, this function panics if any inconsistency
@@ -312,9 +452,11 @@ func fieldFromParser(obj parser.Object) Field {
 // panic, or allowing it to crash the whole application.
 func ConvertASTPackage(ast parser.Package) *Package {
 	pkg := Package{
-		Name:    ast.Name,
-		Structs: []Struct{},
-		Fields:  []Field{},
+		Name:     ast.Name,
+		Doc:      ast.Doc,
+		Structs:  []Struct{},
+		Fields:   []Field{},
+		Examples: []Example{},
 	}
 	for _, i := range ast.Contents {
 		switch i.ObjectType {
@@ -324,6 +466,8 @@ func ConvertASTPackage(ast parser.Package) *Package {
 			pkg.Structs = append(pkg.Structs, structFromParser(i))
 		case parser.ObjID:
 			pkg.Identifier = i.Value
+		case parser.ObjExample:
+			pkg.Examples = append(pkg.Examples, exampleFromParser(i))
 		}
 	}
 	return &pkg
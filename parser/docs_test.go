@@ -0,0 +1,37 @@
+package parser
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestPackageDocs(t *testing.T) {
+	src := `/// Outer
+package sample {
+    /// Inner
+    id 0x05
+
+    uint8   count
+}
+`
+	out, err := Parse("sample.lud", []byte(src))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if doc := out.([]interface{})[0].(Package).Doc; doc != "Outer\nInner" {
+		t.Errorf("package doc %q, expected %q", doc, "Outer\nInner")
+	}
+}
+
+func TestDanglingDocs(t *testing.T) {
+	sources := []string{
+		"package sample {\n    id 0x05\n    uint8   count\n\n    /// Nothing follows\n}\n",
+		"package sample {\n    id 0x05\n    struct item {\n        uint8   count\n        /** Nothing follows */\n        // Regular comment\n    }\n}\n",
+	}
+	for _, src := range sources {
+		_, err := Parse("sample.lud", []byte(src))
+		if err == nil || !strings.Contains(err.Error(), "doc comment must precede") {
+			t.Errorf("parsing %q: error %v, expected a dangling doc comment error", src, err)
+		}
+	}
+}
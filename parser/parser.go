@@ -22,6 +22,7 @@ type Type struct {
 
 type Package struct {
 	Name     string
+	Doc      string
 	Contents []Object
 }
 
@@ -34,6 +35,15 @@ type Object struct {
 	Value      string
 	Contents   []Object
 	Attributes []string
+	Literal    Literal
+	Doc        string
+}
+
+type Literal struct {
+	Kind   string
+	Value  string
+	Items  []Literal
+	Fields []Object
 }
 
 func asString(data interface{}) string {
@@ -68,9 +78,11 @@ func strSlice(rawSlice interface{}) []string {
 func objSlice(rawSlice interface{}) []Object {
 	switch t := rawSlice.(type) {
 	case []interface{}:
-		arr := make([]Object, len(t))
-		for i, s := range t {
-			arr[i] = s.(Object)
+		arr := make([]Object, 0, len(t))
+		for _, s := range t {
+			if s != nil {
+				arr = append(arr, s.(Object))
+			}
 		}
 		return arr
 	case []Object:
@@ -79,6 +91,83 @@ func objSlice(rawSlice interface{}) []Object {
 	return []Object{}
 }
 
+func litSlice(rawSlice interface{}) []Literal {
+	switch t := rawSlice.(type) {
+	case []interface{}:
+		arr := make([]Literal, len(t))
+		for i, s := range t {
+			arr[i] = s.(Literal)
+		}
+		return arr
+	case []Literal:
+		return t
+	}
+	return []Literal{}
+}
+
+// docText extracts the text of doc comments, removing their delimiters
+// and the leading asterisks of block comments
+func docText(parts []string) string {
+	var lines []string
+	for _, p := range parts {
+		if strings.HasPrefix(p, "///") {
+			lines = append(lines, strings.TrimPrefix(p[3:], " "))
+			continue
+		}
+		block := strings.Split(p[3:len(p)-2], "\n")
+		for i, l := range block {
+			l = strings.TrimSpace(l)
+			if strings.HasPrefix(l, "*") {
+				l = strings.TrimPrefix(l[1:], " ")
+			}
+			if l != "" || (i > 0 && i < len(block)-1) {
+				lines = append(lines, l)
+			}
+		}
+	}
+	for i := range lines {
+		lines[i] = strings.TrimRight(lines[i], " \t\r")
+	}
+	return strings.TrimSpace(strings.Join(lines, "\n"))
+}
+
+// joinDocs joins the text of two doc comments
+func joinDocs(a, b string) string {
+	if a == "" || b == "" {
+		return a + b
+	}
+	return a + "\n" + b
+}
+
+// withTrailingDoc attaches a doc comment found after an object on the
+// same line
+func withTrailingDoc(val, trailing interface{}) interface{} {
+	obj, ok := val.(Object)
+	if !ok || trailing == nil || obj.ObjectType == ObjDoc {
+		return val
+	}
+	obj.Doc = joinDocs(obj.Doc, trailing.(string))
+	return obj
+}
+
+// attachDocs attaches doc comments to the objects following them,
+// removing them from the list. Dangling doc comments are rejected by the
+// danglingDoc rule.
+func attachDocs(objs []Object) []Object {
+	arr := make([]Object, 0, len(objs))
+	pending := ""
+	for _, o := range objs {
+		if o.ObjectType == ObjDoc {
+			pending = joinDocs(pending, o.Value)
+			continue
+		}
+		o.Doc = joinDocs(pending, o.Doc)
+		pending = ""
+		arr = append(arr, o)
+	}
+	return arr
+}
+
 const (
 	SourceNative        = "native"
 	SourceUser          = "user"
@@ -86,22 +175,33 @@ const (
 	ObjField            = "field"
 	ObjArray            = "array"
 	ObjStruct           = "struct"
+	ObjExample          = "example"
+	ObjAssignment       = "assignment"
+	ObjDoc              = "doc"
 	AttributeDeprecated = "deprecated"
+	LitNumber           = "number"
+	LitHex              = "hex"
+	LitString           = "string"
+	LitBool             = "bool"
+	LitNull             = "null"
+	LitUUID             = "uuid"
+	LitList             = "list"
+	LitStruct           = "struct"
 )
 
 var g = &grammar{
 	rules: []*rule{
 		{
 			name: "start",
-			pos:  position{line: 87, col: 1, offset: 1830},
+			pos:  position{line: 189, col: 1, offset: 4878},
 			expr: &actionExpr{
-				pos: position{line: 88, col: 7, offset: 1842},
+				pos: position{line: 190, col: 7, offset: 4890},
 				run: (*parser).callonstart1,
 				expr: &labeledExpr{
-					pos:   position{line: 88, col: 7, offset: 1842},
+					pos:   position{line: 190, col: 7, offset: 4890},
 					label: "val",
 					expr: &ruleRefExpr{
-						pos:  position{line: 88, col: 11, offset: 1846},
+						pos:  position{line: 190, col: 11, offset: 4894},
 						name: "contents",
 					},
 				},
@@ -109,9 +209,9 @@ var g = &grammar{
 		},
 		{
 			name: "whitespace",
-			pos:  position{line: 90, col: 1, offset: 1876},
+			pos:  position{line: 192, col: 1, offset: 4924},
 			expr: &charClassMatcher{
-				pos:        position{line: 91, col: 7, offset: 1893},
+				pos:        position{line: 193, col: 7, offset: 4941},
 				val:        "[ \\t]",
 				chars:      []rune{' ', '\t'},
 				ignoreCase: false,
@@ -120,14 +220,14 @@ var g = &grammar{
 		},
 		{
 			name: "EOL",
-			pos:  position{line: 93, col: 1, offset: 1900},
+			pos:  position{line: 195, col: 1, offset: 4948},
 			expr: &seqExpr{
-				pos: position{line: 94, col: 7, offset: 1910},
+				pos: position{line: 196, col: 7, offset: 4958},
 				exprs: []interface{}{
 					&oneOrMoreExpr{
-						pos: position{line: 94, col: 7, offset: 1910},
+						pos: position{line: 196, col: 7, offset: 4958},
 						expr: &charClassMatcher{
-							pos:        position{line: 94, col: 7, offset: 1910},
+							pos:        position{line: 196, col: 7, offset: 4958},
 							val:        "[ \\t\\r\\n]",
 							chars:      []rune{' ', '\t', '\r', '\n'},
 							ignoreCase: false,
@@ -135,9 +235,9 @@ var g = &grammar{
 						},
 					},
 					&zeroOrOneExpr{
-						pos: position{line: 94, col: 18, offset: 1921},
+						pos: position{line: 196, col: 18, offset: 4969},
 						expr: &ruleRefExpr{
-							pos:  position{line: 94, col: 18, offset: 1921},
+							pos:  position{line: 196, col: 18, offset: 4969},
 							name: "comment",
 						},
 					},
@@ -146,25 +246,25 @@ var g = &grammar{
 		},
 		{
 			name: "EOF",
-			pos:  position{line: 96, col: 1, offset: 1931},
+			pos:  position{line: 198, col: 1, offset: 4979},
 			expr: &notExpr{
-				pos: position{line: 97, col: 7, offset: 1941},
+				pos: position{line: 199, col: 7, offset: 4989},
 				expr: &anyMatcher{
-					line: 97, col: 8, offset: 1942,
+					line: 199, col: 8, offset: 4990,
 				},
 			},
 		},
 		{
 			name:        "_",
 			displayName: "\"whitespace\"",
-			pos:         position{line: 99, col: 1, offset: 1945},
+			pos:         position{line: 201, col: 1, offset: 4993},
 			expr: &actionExpr{
-				pos: position{line: 100, col: 7, offset: 1966},
+				pos: position{line: 202, col: 7, offset: 5014},
 				run: (*parser).callon_1,
 				expr: &zeroOrMoreExpr{
-					pos: position{line: 100, col: 7, offset: 1966},
+					pos: position{line: 202, col: 7, offset: 5014},
 					expr: &ruleRefExpr{
-						pos:  position{line: 100, col: 7, offset: 1966},
+						pos:  position{line: 202, col: 7, offset: 5014},
 						name: "whitespace",
 					},
 				},
@@ -173,14 +273,14 @@ var g = &grammar{
 		{
 			name:        "__",
 			displayName: "\"eol\"",
-			pos:         position{line: 102, col: 1, offset: 1999},
+			pos:         position{line: 204, col: 1, offset: 5047},
 			expr: &actionExpr{
-				pos: position{line: 103, col: 7, offset: 2014},
+				pos: position{line: 205, col: 7, offset: 5062},
 				run: (*parser).callon__1,
 				expr: &zeroOrMoreExpr{
-					pos: position{line: 103, col: 7, offset: 2014},
+					pos: position{line: 205, col: 7, offset: 5062},
 					expr: &ruleRefExpr{
-						pos:  position{line: 103, col: 7, offset: 2014},
+						pos:  position{line: 205, col: 7, offset: 5062},
 						name: "EOL",
 					},
 				},
@@ -188,9 +288,9 @@ var g = &grammar{
 		},
 		{
 			name: "digit",
-			pos:  position{line: 105, col: 1, offset: 2040},
+			pos:  position{line: 207, col: 1, offset: 5088},
 			expr: &charClassMatcher{
-				pos:        position{line: 106, col: 7, offset: 2052},
+				pos:        position{line: 208, col: 7, offset: 5100},
 				val:        "[0-9]",
 				ranges:     []rune{'0', '9'},
 				ignoreCase: false,
@@ -199,48 +299,59 @@ var g = &grammar{
 		},
 		{
 			name: "digits",
-			pos:  position{line: 108, col: 1, offset: 2059},
+			pos:  position{line: 210, col: 1, offset: 5107},
 			expr: &actionExpr{
-				pos: position{line: 109, col: 7, offset: 2072},
+				pos: position{line: 211, col: 7, offset: 5120},
 				run: (*parser).callondigits1,
 				expr: &labeledExpr{
-					pos:   position{line: 109, col: 7, offset: 2072},
+					pos:   position{line: 211, col: 7, offset: 5120},
 					label: "digits",
 					expr: &zeroOrMoreExpr{
-						pos: position{line: 109, col: 14, offset: 2079},
+						pos: position{line: 211, col: 14, offset: 5127},
 						expr: &ruleRefExpr{
-							pos:  position{line: 109, col: 14, offset: 2079},
+							pos:  position{line: 211, col: 14, offset: 5127},
 							name: "digit",
 						},
 					},
 				},
 			},
 		},
+		{
+			name: "hexDigit",
+			pos:  position{line: 213, col: 1, offset: 5168},
+			expr: &charClassMatcher{
+				pos:        position{line: 214, col: 7, offset: 5183},
+				val:        "[a-fA-F0-9]",
+				ranges:     []rune{'a', 'f', 'A', 'F', '0', '9'},
+				ignoreCase: false,
+				inverted:   false,
+			},
+		},
 		{
 			name: "hexValue",
-			pos:  position{line: 111, col: 1, offset: 2120},
+			pos:  position{line: 216, col: 1, offset: 5196},
 			expr: &actionExpr{
-				pos: position{line: 112, col: 7, offset: 2135},
+				pos: position{line: 217, col: 7, offset: 5211},
 				run: (*parser).callonhexValue1,
 				expr: &seqExpr{
-					pos: position{line: 112, col: 7, offset: 2135},
+					pos: position{line: 217, col: 7, offset: 5211},
 					exprs: []interface{}{
 						&labeledExpr{
-							pos:   position{line: 112, col: 7, offset: 2135},
+							pos:   position{line: 217, col: 7, offset: 5211},
 							label: "first",
 							expr: &litMatcher{
-								pos:        position{line: 112, col: 13, offset: 2141},
+								pos:        position{line: 217, col: 13, offset: 5217},
 								val:        "0x",
 								ignoreCase: false,
 							},
 						},
 						&labeledExpr{
-							pos:   position{line: 112, col: 18, offset: 2146},
+							pos:   position{line: 217, col: 18, offset: 5222},
 							label: "rest",
 							expr: &oneOrMoreExpr{
-								pos: position{line: 112, col: 23, offset: 2151},
+								pos: position{line: 217, col: 23, offset: 5227},
 								expr: &charClassMatcher{
-									pos:        position{line: 112, col: 23, offset: 2151},
+									pos:        position{line: 217, col: 23, offset: 5227},
 									val:        "[a-fA-F0-9]",
 									ranges:     []rune{'a', 'f', 'A', 'F', '0', '9'},
 									ignoreCase: false,
@@ -254,17 +365,17 @@ var g = &grammar{
 		},
 		{
 			name: "itemName",
-			pos:  position{line: 114, col: 1, offset: 2214},
+			pos:  position{line: 219, col: 1, offset: 5290},
 			expr: &actionExpr{
-				pos: position{line: 115, col: 7, offset: 2229},
+				pos: position{line: 220, col: 7, offset: 5305},
 				run: (*parser).callonitemName1,
 				expr: &labeledExpr{
-					pos:   position{line: 115, col: 7, offset: 2229},
+					pos:   position{line: 220, col: 7, offset: 5305},
 					label: "value",
 					expr: &oneOrMoreExpr{
-						pos: position{line: 115, col: 13, offset: 2235},
+						pos: position{line: 220, col: 13, offset: 5311},
 						expr: &charClassMatcher{
-							pos:        position{line: 115, col: 13, offset: 2235},
+							pos:        position{line: 220, col: 13, offset: 5311},
 							val:        "[a-z_]",
 							chars:      []rune{'_'},
 							ranges:     []rune{'a', 'z'},
@@ -277,58 +388,65 @@ var g = &grammar{
 		},
 		{
 			name: "openCurlyBrace",
-			pos:  position{line: 117, col: 1, offset: 2276},
+			pos:  position{line: 222, col: 1, offset: 5352},
 			expr: &litMatcher{
-				pos:        position{line: 118, col: 7, offset: 2297},
+				pos:        position{line: 223, col: 7, offset: 5373},
 				val:        "{",
 				ignoreCase: false,
 			},
 		},
 		{
 			name: "closeCurlyBrace",
-			pos:  position{line: 120, col: 1, offset: 2302},
+			pos:  position{line: 225, col: 1, offset: 5378},
 			expr: &litMatcher{
-				pos:        position{line: 121, col: 7, offset: 2324},
+				pos:        position{line: 226, col: 7, offset: 5400},
 				val:        "}",
 				ignoreCase: false,
 			},
 		},
 		{
 			name: "openSquareBrace",
-			pos:  position{line: 123, col: 1, offset: 2329},
+			pos:  position{line: 228, col: 1, offset: 5405},
 			expr: &litMatcher{
-				pos:        position{line: 124, col: 7, offset: 2351},
+				pos:        position{line: 229, col: 7, offset: 5427},
 				val:        "[",
 				ignoreCase: false,
 			},
 		},
 		{
 			name: "closeSquareBrace",
-			pos:  position{line: 126, col: 1, offset: 2356},
+			pos:  position{line: 231, col: 1, offset: 5432},
 			expr: &litMatcher{
-				pos:        position{line: 127, col: 7, offset: 2379},
+				pos:        position{line: 232, col: 7, offset: 5455},
 				val:        "]",
 				ignoreCase: false,
 			},
 		},
 		{
 			name: "comment",
-			pos:  position{line: 129, col: 1, offset: 2384},
+			pos:  position{line: 234, col: 1, offset: 5460},
 			expr: &actionExpr{
-				pos: position{line: 130, col: 7, offset: 2398},
+				pos: position{line: 235, col: 7, offset: 5474},
 				run: (*parser).calloncomment1,
 				expr: &seqExpr{
-					pos: position{line: 130, col: 7, offset: 2398},
+					pos: position{line: 235, col: 7, offset: 5474},
 					exprs: []interface{}{
+						&notExpr{
+							pos: position{line: 235, col: 7, offset: 5474},
+							expr: &ruleRefExpr{
+								pos:  position{line: 235, col: 8, offset: 5475},
+								name: "docLine",
+							},
+						},
 						&litMatcher{
-							pos:        position{line: 130, col: 7, offset: 2398},
+							pos:        position{line: 235, col: 16, offset: 5483},
 							val:        "//",
 							ignoreCase: false,
 						},
 						&zeroOrMoreExpr{
-							pos: position{line: 130, col: 12, offset: 2403},
+							pos: position{line: 235, col: 21, offset: 5488},
 							expr: &charClassMatcher{
-								pos:        position{line: 130, col: 12, offset: 2403},
+								pos:        position{line: 235, col: 21, offset: 5488},
 								val:        "[^\\n]",
 								chars:      []rune{'\n'},
 								ignoreCase: false,
@@ -336,16 +454,16 @@ var g = &grammar{
 							},
 						},
 						&zeroOrOneExpr{
-							pos: position{line: 130, col: 19, offset: 2410},
+							pos: position{line: 235, col: 28, offset: 5495},
 							expr: &choiceExpr{
-								pos: position{line: 130, col: 20, offset: 2411},
+								pos: position{line: 235, col: 29, offset: 5496},
 								alternatives: []interface{}{
 									&ruleRefExpr{
-										pos:  position{line: 130, col: 20, offset: 2411},
+										pos:  position{line: 235, col: 29, offset: 5496},
 										name: "EOL",
 									},
 									&ruleRefExpr{
-										pos:  position{line: 130, col: 24, offset: 2415},
+										pos:  position{line: 235, col: 33, offset: 5500},
 										name: "EOF",
 									},
 								},
@@ -355,29 +473,299 @@ var g = &grammar{
 				},
 			},
 		},
+		{
+			name: "docLine",
+			pos:  position{line: 240, col: 1, offset: 5621},
+			expr: &actionExpr{
+				pos: position{line: 241, col: 7, offset: 5635},
+				run: (*parser).callondocLine1,
+				expr: &seqExpr{
+					pos: position{line: 241, col: 7, offset: 5635},
+					exprs: []interface{}{
+						&litMatcher{
+							pos:        position{line: 241, col: 7, offset: 5635},
+							val:        "///",
+							ignoreCase: false,
+						},
+						&notExpr{
+							pos: position{line: 241, col: 13, offset: 5641},
+							expr: &litMatcher{
+								pos:        position{line: 241, col: 14, offset: 5642},
+								val:        "/",
+								ignoreCase: false,
+							},
+						},
+						&zeroOrMoreExpr{
+							pos: position{line: 241, col: 18, offset: 5646},
+							expr: &charClassMatcher{
+								pos:        position{line: 241, col: 18, offset: 5646},
+								val:        "[^\\n]",
+								chars:      []rune{'\n'},
+								ignoreCase: false,
+								inverted:   true,
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "docBlock",
+			pos:  position{line: 243, col: 1, offset: 5685},
+			expr: &actionExpr{
+				pos: position{line: 244, col: 7, offset: 5700},
+				run: (*parser).callondocBlock1,
+				expr: &seqExpr{
+					pos: position{line: 244, col: 7, offset: 5700},
+					exprs: []interface{}{
+						&litMatcher{
+							pos:        position{line: 244, col: 7, offset: 5700},
+							val:        "/**",
+							ignoreCase: false,
+						},
+						&notExpr{
+							pos: position{line: 244, col: 13, offset: 5706},
+							expr: &litMatcher{
+								pos:        position{line: 244, col: 14, offset: 5707},
+								val:        "/",
+								ignoreCase: false,
+							},
+						},
+						&zeroOrMoreExpr{
+							pos: position{line: 244, col: 18, offset: 5711},
+							expr: &seqExpr{
+								pos: position{line: 244, col: 19, offset: 5712},
+								exprs: []interface{}{
+									&notExpr{
+										pos: position{line: 244, col: 19, offset: 5712},
+										expr: &litMatcher{
+											pos:        position{line: 244, col: 20, offset: 5713},
+											val:        "*/",
+											ignoreCase: false,
+										},
+									},
+									&anyMatcher{
+										line: 244, col: 25, offset: 5718,
+									},
+								},
+							},
+						},
+						&litMatcher{
+							pos:        position{line: 244, col: 29, offset: 5722},
+							val:        "*/",
+							ignoreCase: false,
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "docComment",
+			pos:  position{line: 246, col: 1, offset: 5759},
+			expr: &actionExpr{
+				pos: position{line: 247, col: 7, offset: 5776},
+				run: (*parser).callondocComment1,
+				expr: &seqExpr{
+					pos: position{line: 247, col: 7, offset: 5776},
+					exprs: []interface{}{
+						&labeledExpr{
+							pos:   position{line: 247, col: 7, offset: 5776},
+							label: "first",
+							expr: &choiceExpr{
+								pos: position{line: 247, col: 14, offset: 5783},
+								alternatives: []interface{}{
+									&ruleRefExpr{
+										pos:  position{line: 247, col: 14, offset: 5783},
+										name: "docLine",
+									},
+									&ruleRefExpr{
+										pos:  position{line: 247, col: 24, offset: 5793},
+										name: "docBlock",
+									},
+								},
+							},
+						},
+						&labeledExpr{
+							pos:   position{line: 247, col: 34, offset: 5803},
+							label: "rest",
+							expr: &zeroOrMoreExpr{
+								pos: position{line: 247, col: 39, offset: 5808},
+								expr: &ruleRefExpr{
+									pos:  position{line: 247, col: 39, offset: 5808},
+									name: "docContinuation",
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "docContinuation",
+			pos:  position{line: 255, col: 1, offset: 6051},
+			expr: &actionExpr{
+				pos: position{line: 256, col: 7, offset: 6073},
+				run: (*parser).callondocContinuation1,
+				expr: &seqExpr{
+					pos: position{line: 256, col: 7, offset: 6073},
+					exprs: []interface{}{
+						&zeroOrMoreExpr{
+							pos: position{line: 256, col: 7, offset: 6073},
+							expr: &charClassMatcher{
+								pos:        position{line: 256, col: 7, offset: 6073},
+								val:        "[ \\t\\r\\n]",
+								chars:      []rune{' ', '\t', '\r', '\n'},
+								ignoreCase: false,
+								inverted:   false,
+							},
+						},
+						&labeledExpr{
+							pos:   position{line: 256, col: 18, offset: 6084},
+							label: "val",
+							expr: &choiceExpr{
+								pos: position{line: 256, col: 23, offset: 6089},
+								alternatives: []interface{}{
+									&ruleRefExpr{
+										pos:  position{line: 256, col: 23, offset: 6089},
+										name: "docLine",
+									},
+									&ruleRefExpr{
+										pos:  position{line: 256, col: 33, offset: 6099},
+										name: "docBlock",
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "trailingDoc",
+			pos:  position{line: 258, col: 1, offset: 6130},
+			expr: &actionExpr{
+				pos: position{line: 259, col: 7, offset: 6148},
+				run: (*parser).callontrailingDoc1,
+				expr: &seqExpr{
+					pos: position{line: 259, col: 7, offset: 6148},
+					exprs: []interface{}{
+						&zeroOrMoreExpr{
+							pos: position{line: 259, col: 7, offset: 6148},
+							expr: &ruleRefExpr{
+								pos:  position{line: 259, col: 7, offset: 6148},
+								name: "whitespace",
+							},
+						},
+						&labeledExpr{
+							pos:   position{line: 259, col: 19, offset: 6160},
+							label: "val",
+							expr: &ruleRefExpr{
+								pos:  position{line: 259, col: 23, offset: 6164},
+								name: "docLine",
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "danglingDoc",
+			pos:  position{line: 263, col: 1, offset: 6313},
+			expr: &actionExpr{
+				pos: position{line: 264, col: 7, offset: 6331},
+				run: (*parser).callondanglingDoc1,
+				expr: &seqExpr{
+					pos: position{line: 264, col: 7, offset: 6331},
+					exprs: []interface{}{
+						&ruleRefExpr{
+							pos:  position{line: 264, col: 7, offset: 6331},
+							name: "docComment",
+						},
+						&andExpr{
+							pos: position{line: 264, col: 18, offset: 6342},
+							expr: &seqExpr{
+								pos: position{line: 264, col: 20, offset: 6344},
+								exprs: []interface{}{
+									&zeroOrOneExpr{
+										pos: position{line: 264, col: 20, offset: 6344},
+										expr: &ruleRefExpr{
+											pos:  position{line: 264, col: 20, offset: 6344},
+											name: "__",
+										},
+									},
+									&ruleRefExpr{
+										pos:  position{line: 264, col: 24, offset: 6348},
+										name: "closeCurlyBrace",
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "literalSpace",
+			pos:  position{line: 270, col: 1, offset: 6564},
+			expr: &actionExpr{
+				pos: position{line: 271, col: 7, offset: 6583},
+				run: (*parser).callonliteralSpace1,
+				expr: &oneOrMoreExpr{
+					pos: position{line: 271, col: 7, offset: 6583},
+					expr: &choiceExpr{
+						pos: position{line: 271, col: 8, offset: 6584},
+						alternatives: []interface{}{
+							&ruleRefExpr{
+								pos:  position{line: 271, col: 8, offset: 6584},
+								name: "EOL",
+							},
+							&seqExpr{
+								pos: position{line: 271, col: 14, offset: 6590},
+								exprs: []interface{}{
+									&zeroOrMoreExpr{
+										pos: position{line: 271, col: 14, offset: 6590},
+										expr: &charClassMatcher{
+											pos:        position{line: 271, col: 14, offset: 6590},
+											val:        "[ \\t\\r\\n]",
+											chars:      []rune{' ', '\t', '\r', '\n'},
+											ignoreCase: false,
+											inverted:   false,
+										},
+									},
+									&ruleRefExpr{
+										pos:  position{line: 271, col: 25, offset: 6601},
+										name: "docComment",
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
 		{
 			name: "attribute",
-			pos:  position{line: 132, col: 1, offset: 2442},
+			pos:  position{line: 273, col: 1, offset: 6635},
 			expr: &actionExpr{
-				pos: position{line: 133, col: 7, offset: 2458},
+				pos: position{line: 274, col: 7, offset: 6651},
 				run: (*parser).callonattribute1,
 				expr: &seqExpr{
-					pos: position{line: 133, col: 7, offset: 2458},
+					pos: position{line: 274, col: 7, offset: 6651},
 					exprs: []interface{}{
 						&ruleRefExpr{
-							pos:  position{line: 133, col: 7, offset: 2458},
+							pos:  position{line: 274, col: 7, offset: 6651},
 							name: "_",
 						},
 						&litMatcher{
-							pos:        position{line: 133, col: 9, offset: 2460},
+							pos:        position{line: 274, col: 9, offset: 6653},
 							val:        "!",
 							ignoreCase: false,
 						},
 						&labeledExpr{
-							pos:   position{line: 133, col: 13, offset: 2464},
+							pos:   position{line: 274, col: 13, offset: 6657},
 							label: "flag",
 							expr: &litMatcher{
-								pos:        position{line: 133, col: 19, offset: 2470},
+								pos:        position{line: 274, col: 19, offset: 6663},
 								val:        "deprecated",
 								ignoreCase: false,
 							},
@@ -388,17 +776,17 @@ var g = &grammar{
 		},
 		{
 			name: "attributeList",
-			pos:  position{line: 135, col: 1, offset: 2516},
+			pos:  position{line: 276, col: 1, offset: 6709},
 			expr: &actionExpr{
-				pos: position{line: 136, col: 4, offset: 2533},
+				pos: position{line: 277, col: 4, offset: 6726},
 				run: (*parser).callonattributeList1,
 				expr: &labeledExpr{
-					pos:   position{line: 136, col: 4, offset: 2533},
+					pos:   position{line: 277, col: 4, offset: 6726},
 					label: "attr",
 					expr: &oneOrMoreExpr{
-						pos: position{line: 136, col: 9, offset: 2538},
+						pos: position{line: 277, col: 9, offset: 6731},
 						expr: &ruleRefExpr{
-							pos:  position{line: 136, col: 9, offset: 2538},
+							pos:  position{line: 277, col: 9, offset: 6731},
 							name: "attribute",
 						},
 					},
@@ -407,37 +795,37 @@ var g = &grammar{
 		},
 		{
 			name: "arraySize",
-			pos:  position{line: 140, col: 1, offset: 2612},
+			pos:  position{line: 281, col: 1, offset: 6805},
 			expr: &actionExpr{
-				pos: position{line: 141, col: 7, offset: 2628},
+				pos: position{line: 282, col: 7, offset: 6821},
 				run: (*parser).callonarraySize1,
 				expr: &seqExpr{
-					pos: position{line: 141, col: 7, offset: 2628},
+					pos: position{line: 282, col: 7, offset: 6821},
 					exprs: []interface{}{
 						&ruleRefExpr{
-							pos:  position{line: 141, col: 7, offset: 2628},
+							pos:  position{line: 282, col: 7, offset: 6821},
 							name: "openSquareBrace",
 						},
 						&labeledExpr{
-							pos:   position{line: 141, col: 23, offset: 2644},
+							pos:   position{line: 282, col: 23, offset: 6837},
 							label: "val",
 							expr: &choiceExpr{
-								pos: position{line: 141, col: 28, offset: 2649},
+								pos: position{line: 282, col: 28, offset: 6842},
 								alternatives: []interface{}{
 									&litMatcher{
-										pos:        position{line: 141, col: 28, offset: 2649},
+										pos:        position{line: 282, col: 28, offset: 6842},
 										val:        "*",
 										ignoreCase: false,
 									},
 									&ruleRefExpr{
-										pos:  position{line: 141, col: 34, offset: 2655},
+										pos:  position{line: 282, col: 34, offset: 6848},
 										name: "digits",
 									},
 								},
 							},
 						},
 						&ruleRefExpr{
-							pos:  position{line: 141, col: 42, offset: 2663},
+							pos:  position{line: 282, col: 42, offset: 6856},
 							name: "closeSquareBrace",
 						},
 					},
@@ -446,68 +834,68 @@ var g = &grammar{
 		},
 		{
 			name: "nativeType",
-			pos:  position{line: 143, col: 1, offset: 2711},
+			pos:  position{line: 284, col: 1, offset: 6904},
 			expr: &actionExpr{
-				pos: position{line: 144, col: 7, offset: 2728},
+				pos: position{line: 285, col: 7, offset: 6921},
 				run: (*parser).callonnativeType1,
 				expr: &labeledExpr{
-					pos:   position{line: 144, col: 7, offset: 2728},
+					pos:   position{line: 285, col: 7, offset: 6921},
 					label: "val",
 					expr: &choiceExpr{
-						pos: position{line: 144, col: 12, offset: 2733},
+						pos: position{line: 285, col: 12, offset: 6926},
 						alternatives: []interface{}{
 							&litMatcher{
-								pos:        position{line: 144, col: 12, offset: 2733},
+								pos:        position{line: 285, col: 12, offset: 6926},
 								val:        "dynint",
 								ignoreCase: false,
 							},
 							&litMatcher{
-								pos:        position{line: 144, col: 23, offset: 2744},
+								pos:        position{line: 285, col: 23, offset: 6937},
 								val:        "uint8",
 								ignoreCase: false,
 							},
 							&litMatcher{
-								pos:        position{line: 144, col: 33, offset: 2754},
+								pos:        position{line: 285, col: 33, offset: 6947},
 								val:        "uint32",
 								ignoreCase: false,
 							},
 							&litMatcher{
-								pos:        position{line: 144, col: 44, offset: 2765},
+								pos:        position{line: 285, col: 44, offset: 6958},
 								val:        "uint64",
 								ignoreCase: false,
 							},
 							&litMatcher{
-								pos:        position{line: 144, col: 55, offset: 2776},
+								pos:        position{line: 285, col: 55, offset: 6969},
 								val:        "byte",
 								ignoreCase: false,
 							},
 							&litMatcher{
-								pos:        position{line: 144, col: 64, offset: 2785},
+								pos:        position{line: 285, col: 64, offset: 6978},
 								val:        "double",
 								ignoreCase: false,
 							},
 							&litMatcher{
-								pos:        position{line: 144, col: 75, offset: 2796},
+								pos:        position{line: 285, col: 75, offset: 6989},
 								val:        "string",
 								ignoreCase: false,
 							},
 							&litMatcher{
-								pos:        position{line: 144, col: 86, offset: 2807},
+								pos:        position{line: 285, col: 86, offset: 7000},
 								val:        "blob",
 								ignoreCase: false,
 							},
 							&litMatcher{
-								pos:        position{line: 144, col: 95, offset: 2816},
+								pos:        position{line: 285, col: 95, offset: 7009},
 								val:        "bool",
 								ignoreCase: false,
 							},
 							&litMatcher{
-								pos:        position{line: 144, col: 104, offset: 2825},
+								pos:        position{line: 285, col: 104, offset: 7018},
 								val:        "uuid",
 								ignoreCase: false,
 							},
 							&litMatcher{
-								pos:        position{line: 144, col: 113, offset: 2834},
+								pos:        position{line: 285, col: 113, offset: 7027},
 								val:        "any",
 								ignoreCase: false,
 							},
@@ -518,23 +906,23 @@ var g = &grammar{
 		},
 		{
 			name: "userType",
-			pos:  position{line: 151, col: 1, offset: 2953},
+			pos:  position{line: 292, col: 1, offset: 7146},
 			expr: &actionExpr{
-				pos: position{line: 152, col: 7, offset: 2968},
+				pos: position{line: 293, col: 7, offset: 7161},
 				run: (*parser).callonuserType1,
 				expr: &seqExpr{
-					pos: position{line: 152, col: 7, offset: 2968},
+					pos: position{line: 293, col: 7, offset: 7161},
 					exprs: []interface{}{
 						&litMatcher{
-							pos:        position{line: 152, col: 7, offset: 2968},
+							pos:        position{line: 293, col: 7, offset: 7161},
 							val:        "@",
 							ignoreCase: false,
 						},
 						&labeledExpr{
-							pos:   position{line: 152, col: 11, offset: 2972},
+							pos:   position{line: 293, col: 11, offset: 7165},
 							label: "val",
 							expr: &ruleRefExpr{
-								pos:  position{line: 152, col: 15, offset: 2976},
+								pos:  position{line: 293, col: 15, offset: 7169},
 								name: "itemName",
 							},
 						},
@@ -544,27 +932,27 @@ var g = &grammar{
 		},
 		{
 			name: "idDefinition",
-			pos:  position{line: 159, col: 1, offset: 3094},
+			pos:  position{line: 300, col: 1, offset: 7287},
 			expr: &actionExpr{
-				pos: position{line: 160, col: 7, offset: 3113},
+				pos: position{line: 301, col: 7, offset: 7306},
 				run: (*parser).callonidDefinition1,
 				expr: &seqExpr{
-					pos: position{line: 160, col: 7, offset: 3113},
+					pos: position{line: 301, col: 7, offset: 7306},
 					exprs: []interface{}{
 						&litMatcher{
-							pos:        position{line: 160, col: 7, offset: 3113},
+							pos:        position{line: 301, col: 7, offset: 7306},
 							val:        "id",
 							ignoreCase: false,
 						},
 						&ruleRefExpr{
-							pos:  position{line: 160, col: 12, offset: 3118},
+							pos:  position{line: 301, col: 12, offset: 7311},
 							name: "_",
 						},
 						&labeledExpr{
-							pos:   position{line: 160, col: 14, offset: 3120},
+							pos:   position{line: 301, col: 14, offset: 7313},
 							label: "val",
 							expr: &ruleRefExpr{
-								pos:  position{line: 160, col: 18, offset: 3124},
+								pos:  position{line: 301, col: 18, offset: 7317},
 								name: "hexValue",
 							},
 						},
@@ -574,49 +962,49 @@ var g = &grammar{
 		},
 		{
 			name: "fieldDefinition",
-			pos:  position{line: 167, col: 1, offset: 3245},
+			pos:  position{line: 308, col: 1, offset: 7438},
 			expr: &actionExpr{
-				pos: position{line: 168, col: 7, offset: 3267},
+				pos: position{line: 309, col: 7, offset: 7460},
 				run: (*parser).callonfieldDefinition1,
 				expr: &seqExpr{
-					pos: position{line: 168, col: 7, offset: 3267},
+					pos: position{line: 309, col: 7, offset: 7460},
 					exprs: []interface{}{
 						&labeledExpr{
-							pos:   position{line: 168, col: 7, offset: 3267},
+							pos:   position{line: 309, col: 7, offset: 7460},
 							label: "t",
 							expr: &choiceExpr{
-								pos: position{line: 168, col: 10, offset: 3270},
+								pos: position{line: 309, col: 10, offset: 7463},
 								alternatives: []interface{}{
 									&ruleRefExpr{
-										pos:  position{line: 168, col: 10, offset: 3270},
+										pos:  position{line: 309, col: 10, offset: 7463},
 										name: "nativeType",
 									},
 									&ruleRefExpr{
-										pos:  position{line: 168, col: 23, offset: 3283},
+										pos:  position{line: 309, col: 23, offset: 7476},
 										name: "userType",
 									},
 								},
 							},
 						},
 						&ruleRefExpr{
-							pos:  position{line: 168, col: 33, offset: 3293},
+							pos:  position{line: 309, col: 33, offset: 7486},
 							name: "_",
 						},
 						&labeledExpr{
-							pos:   position{line: 168, col: 35, offset: 3295},
+							pos:   position{line: 309, col: 35, offset: 7488},
 							label: "name",
 							expr: &ruleRefExpr{
-								pos:  position{line: 168, col: 40, offset: 3300},
+								pos:  position{line: 309, col: 40, offset: 7493},
 								name: "itemName",
 							},
 						},
 						&labeledExpr{
-							pos:   position{line: 168, col: 49, offset: 3309},
+							pos:   position{line: 309, col: 49, offset: 7502},
 							label: "attributes",
 							expr: &zeroOrOneExpr{
-								pos: position{line: 168, col: 60, offset: 3320},
+								pos: position{line: 309, col: 60, offset: 7513},
 								expr: &ruleRefExpr{
-									pos:  position{line: 168, col: 60, offset: 3320},
+									pos:  position{line: 309, col: 60, offset: 7513},
 									name: "attributeList",
 								},
 							},
@@ -627,57 +1015,57 @@ var g = &grammar{
 		},
 		{
 			name: "arrayDefinition",
-			pos:  position{line: 178, col: 1, offset: 3565},
+			pos:  position{line: 319, col: 1, offset: 7758},
 			expr: &actionExpr{
-				pos: position{line: 179, col: 7, offset: 3587},
+				pos: position{line: 320, col: 7, offset: 7780},
 				run: (*parser).callonarrayDefinition1,
 				expr: &seqExpr{
-					pos: position{line: 179, col: 7, offset: 3587},
+					pos: position{line: 320, col: 7, offset: 7780},
 					exprs: []interface{}{
 						&labeledExpr{
-							pos:   position{line: 179, col: 7, offset: 3587},
+							pos:   position{line: 320, col: 7, offset: 7780},
 							label: "t",
 							expr: &choiceExpr{
-								pos: position{line: 179, col: 10, offset: 3590},
+								pos: position{line: 320, col: 10, offset: 7783},
 								alternatives: []interface{}{
 									&ruleRefExpr{
-										pos:  position{line: 179, col: 10, offset: 3590},
+										pos:  position{line: 320, col: 10, offset: 7783},
 										name: "nativeType",
 									},
 									&ruleRefExpr{
-										pos:  position{line: 179, col: 23, offset: 3603},
+										pos:  position{line: 320, col: 23, offset: 7796},
 										name: "userType",
 									},
 								},
 							},
 						},
 						&labeledExpr{
-							pos:   position{line: 179, col: 33, offset: 3613},
+							pos:   position{line: 320, col: 33, offset: 7806},
 							label: "size",
 							expr: &ruleRefExpr{
-								pos:  position{line: 179, col: 38, offset: 3618},
+								pos:  position{line: 320, col: 38, offset: 7811},
 								name: "arraySize",
 							},
 						},
 						&ruleRefExpr{
-							pos:  position{line: 179, col: 48, offset: 3628},
+							pos:  position{line: 320, col: 48, offset: 7821},
 							name: "_",
 						},
 						&labeledExpr{
-							pos:   position{line: 179, col: 50, offset: 3630},
+							pos:   position{line: 320, col: 50, offset: 7823},
 							label: "name",
 							expr: &ruleRefExpr{
-								pos:  position{line: 179, col: 55, offset: 3635},
+								pos:  position{line: 320, col: 55, offset: 7828},
 								name: "itemName",
 							},
 						},
 						&labeledExpr{
-							pos:   position{line: 179, col: 64, offset: 3644},
+							pos:   position{line: 320, col: 64, offset: 7837},
 							label: "attributes",
 							expr: &zeroOrOneExpr{
-								pos: position{line: 179, col: 75, offset: 3655},
+								pos: position{line: 320, col: 75, offset: 7848},
 								expr: &ruleRefExpr{
-									pos:  position{line: 179, col: 75, offset: 3655},
+									pos:  position{line: 320, col: 75, offset: 7848},
 									name: "attributeList",
 								},
 							},
@@ -687,125 +1075,857 @@ var g = &grammar{
 			},
 		},
 		{
-			name: "contents",
-			pos:  position{line: 192, col: 1, offset: 3957},
-			expr: &actionExpr{
-				pos: position{line: 193, col: 7, offset: 3972},
-				run: (*parser).calloncontents1,
-				expr: &labeledExpr{
-					pos:   position{line: 193, col: 7, offset: 3972},
-					label: "val",
-					expr: &oneOrMoreExpr{
-						pos: position{line: 193, col: 11, offset: 3976},
-						expr: &ruleRefExpr{
-							pos:  position{line: 193, col: 11, offset: 3976},
-							name: "fileContents",
-						},
+			name: "literal",
+			pos:  position{line: 333, col: 1, offset: 8139},
+			expr: &choiceExpr{
+				pos: position{line: 334, col: 7, offset: 8153},
+				alternatives: []interface{}{
+					&ruleRefExpr{
+						pos:  position{line: 334, col: 7, offset: 8153},
+						name: "uuidLiteral",
+					},
+					&ruleRefExpr{
+						pos:  position{line: 335, col: 7, offset: 8171},
+						name: "hexLiteral",
+					},
+					&ruleRefExpr{
+						pos:  position{line: 336, col: 7, offset: 8188},
+						name: "numberLiteral",
+					},
+					&ruleRefExpr{
+						pos:  position{line: 337, col: 7, offset: 8208},
+						name: "stringLiteral",
+					},
+					&ruleRefExpr{
+						pos:  position{line: 338, col: 7, offset: 8228},
+						name: "boolLiteral",
+					},
+					&ruleRefExpr{
+						pos:  position{line: 339, col: 7, offset: 8246},
+						name: "nullLiteral",
+					},
+					&ruleRefExpr{
+						pos:  position{line: 340, col: 7, offset: 8264},
+						name: "listLiteral",
+					},
+					&ruleRefExpr{
+						pos:  position{line: 341, col: 7, offset: 8282},
+						name: "structLiteral",
 					},
 				},
 			},
 		},
 		{
-			name: "fileContents",
-			pos:  position{line: 195, col: 1, offset: 4011},
+			name: "uuidLiteral",
+			pos:  position{line: 343, col: 1, offset: 8297},
 			expr: &actionExpr{
-				pos: position{line: 196, col: 7, offset: 4030},
-				run: (*parser).callonfileContents1,
+				pos: position{line: 344, col: 7, offset: 8315},
+				run: (*parser).callonuuidLiteral1,
 				expr: &seqExpr{
-					pos: position{line: 196, col: 7, offset: 4030},
+					pos: position{line: 344, col: 7, offset: 8315},
 					exprs: []interface{}{
-						&zeroOrOneExpr{
-							pos: position{line: 196, col: 7, offset: 4030},
-							expr: &ruleRefExpr{
-								pos:  position{line: 196, col: 7, offset: 4030},
-								name: "__",
-							},
+						&ruleRefExpr{
+							pos:  position{line: 344, col: 7, offset: 8315},
+							name: "hexDigit",
 						},
-						&zeroOrOneExpr{
-							pos: position{line: 196, col: 11, offset: 4034},
-							expr: &ruleRefExpr{
-								pos:  position{line: 196, col: 11, offset: 4034},
-								name: "comment",
-							},
+						&ruleRefExpr{
+							pos:  position{line: 344, col: 16, offset: 8324},
+							name: "hexDigit",
 						},
-						&labeledExpr{
-							pos:   position{line: 196, col: 20, offset: 4043},
-							label: "val",
-							expr: &ruleRefExpr{
-								pos:  position{line: 196, col: 24, offset: 4047},
-								name: "pkg",
-							},
+						&ruleRefExpr{
+							pos:  position{line: 344, col: 25, offset: 8333},
+							name: "hexDigit",
 						},
-						&zeroOrOneExpr{
-							pos: position{line: 196, col: 28, offset: 4051},
-							expr: &ruleRefExpr{
-								pos:  position{line: 196, col: 28, offset: 4051},
-								name: "__",
-							},
+						&ruleRefExpr{
+							pos:  position{line: 344, col: 34, offset: 8342},
+							name: "hexDigit",
 						},
-					},
-				},
-			},
-		},
-		{
-			name: "str",
-			pos:  position{line: 200, col: 1, offset: 4091},
-			expr: &actionExpr{
-				pos: position{line: 201, col: 7, offset: 4101},
-				run: (*parser).callonstr1,
-				expr: &seqExpr{
-					pos: position{line: 201, col: 7, offset: 4101},
-					exprs: []interface{}{
-						&labeledExpr{
-							pos:   position{line: 201, col: 7, offset: 4101},
-							label: "header",
-							expr: &ruleRefExpr{
-								pos:  position{line: 201, col: 14, offset: 4108},
-								name: "strHeader",
-							},
+						&ruleRefExpr{
+							pos:  position{line: 344, col: 43, offset: 8351},
+							name: "hexDigit",
 						},
-						&zeroOrOneExpr{
-							pos: position{line: 201, col: 24, offset: 4118},
-							expr: &ruleRefExpr{
-								pos:  position{line: 201, col: 24, offset: 4118},
-								name: "_",
-							},
+						&ruleRefExpr{
+							pos:  position{line: 344, col: 52, offset: 8360},
+							name: "hexDigit",
 						},
 						&ruleRefExpr{
-							pos:  position{line: 201, col: 27, offset: 4121},
-							name: "openCurlyBrace",
+							pos:  position{line: 344, col: 61, offset: 8369},
+							name: "hexDigit",
 						},
 						&ruleRefExpr{
-							pos:  position{line: 201, col: 42, offset: 4136},
-							name: "__",
+							pos:  position{line: 344, col: 70, offset: 8378},
+							name: "hexDigit",
 						},
-						&zeroOrOneExpr{
-							pos: position{line: 202, col: 5, offset: 4143},
-							expr: &ruleRefExpr{
-								pos:  position{line: 202, col: 5, offset: 4143},
-								name: "__",
-							},
+						&litMatcher{
+							pos:        position{line: 344, col: 79, offset: 8387},
+							val:        "-",
+							ignoreCase: false,
 						},
-						&labeledExpr{
-							pos:   position{line: 203, col: 5, offset: 4151},
-							label: "contents",
-							expr: &oneOrMoreExpr{
-								pos: position{line: 203, col: 14, offset: 4160},
-								expr: &ruleRefExpr{
-									pos:  position{line: 203, col: 14, offset: 4160},
-									name: "strContents",
+						&ruleRefExpr{
+							pos:  position{line: 345, col: 7, offset: 8397},
+							name: "hexDigit",
+						},
+						&ruleRefExpr{
+							pos:  position{line: 345, col: 16, offset: 8406},
+							name: "hexDigit",
+						},
+						&ruleRefExpr{
+							pos:  position{line: 345, col: 25, offset: 8415},
+							name: "hexDigit",
+						},
+						&ruleRefExpr{
+							pos:  position{line: 345, col: 34, offset: 8424},
+							name: "hexDigit",
+						},
+						&litMatcher{
+							pos:        position{line: 345, col: 43, offset: 8433},
+							val:        "-",
+							ignoreCase: false,
+						},
+						&ruleRefExpr{
+							pos:  position{line: 346, col: 7, offset: 8443},
+							name: "hexDigit",
+						},
+						&ruleRefExpr{
+							pos:  position{line: 346, col: 16, offset: 8452},
+							name: "hexDigit",
+						},
+						&ruleRefExpr{
+							pos:  position{line: 346, col: 25, offset: 8461},
+							name: "hexDigit",
+						},
+						&ruleRefExpr{
+							pos:  position{line: 346, col: 34, offset: 8470},
+							name: "hexDigit",
+						},
+						&litMatcher{
+							pos:        position{line: 346, col: 43, offset: 8479},
+							val:        "-",
+							ignoreCase: false,
+						},
+						&ruleRefExpr{
+							pos:  position{line: 347, col: 7, offset: 8489},
+							name: "hexDigit",
+						},
+						&ruleRefExpr{
+							pos:  position{line: 347, col: 16, offset: 8498},
+							name: "hexDigit",
+						},
+						&ruleRefExpr{
+							pos:  position{line: 347, col: 25, offset: 8507},
+							name: "hexDigit",
+						},
+						&ruleRefExpr{
+							pos:  position{line: 347, col: 34, offset: 8516},
+							name: "hexDigit",
+						},
+						&litMatcher{
+							pos:        position{line: 347, col: 43, offset: 8525},
+							val:        "-",
+							ignoreCase: false,
+						},
+						&ruleRefExpr{
+							pos:  position{line: 348, col: 7, offset: 8535},
+							name: "hexDigit",
+						},
+						&ruleRefExpr{
+							pos:  position{line: 348, col: 16, offset: 8544},
+							name: "hexDigit",
+						},
+						&ruleRefExpr{
+							pos:  position{line: 348, col: 25, offset: 8553},
+							name: "hexDigit",
+						},
+						&ruleRefExpr{
+							pos:  position{line: 348, col: 34, offset: 8562},
+							name: "hexDigit",
+						},
+						&ruleRefExpr{
+							pos:  position{line: 348, col: 43, offset: 8571},
+							name: "hexDigit",
+						},
+						&ruleRefExpr{
+							pos:  position{line: 348, col: 52, offset: 8580},
+							name: "hexDigit",
+						},
+						&ruleRefExpr{
+							pos:  position{line: 348, col: 61, offset: 8589},
+							name: "hexDigit",
+						},
+						&ruleRefExpr{
+							pos:  position{line: 348, col: 70, offset: 8598},
+							name: "hexDigit",
+						},
+						&ruleRefExpr{
+							pos:  position{line: 348, col: 79, offset: 8607},
+							name: "hexDigit",
+						},
+						&ruleRefExpr{
+							pos:  position{line: 348, col: 88, offset: 8616},
+							name: "hexDigit",
+						},
+						&ruleRefExpr{
+							pos:  position{line: 348, col: 97, offset: 8625},
+							name: "hexDigit",
+						},
+						&ruleRefExpr{
+							pos:  position{line: 348, col: 106, offset: 8634},
+							name: "hexDigit",
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "hexLiteral",
+			pos:  position{line: 352, col: 1, offset: 8718},
+			expr: &actionExpr{
+				pos: position{line: 353, col: 7, offset: 8735},
+				run: (*parser).callonhexLiteral1,
+				expr: &labeledExpr{
+					pos:   position{line: 353, col: 7, offset: 8735},
+					label: "val",
+					expr: &ruleRefExpr{
+						pos:  position{line: 353, col: 11, offset: 8739},
+						name: "hexValue",
+					},
+				},
+			},
+		},
+		{
+			name: "numberLiteral",
+			pos:  position{line: 355, col: 1, offset: 8808},
+			expr: &actionExpr{
+				pos: position{line: 356, col: 7, offset: 8828},
+				run: (*parser).callonnumberLiteral1,
+				expr: &seqExpr{
+					pos: position{line: 356, col: 7, offset: 8828},
+					exprs: []interface{}{
+						&zeroOrOneExpr{
+							pos: position{line: 356, col: 7, offset: 8828},
+							expr: &litMatcher{
+								pos:        position{line: 356, col: 7, offset: 8828},
+								val:        "-",
+								ignoreCase: false,
+							},
+						},
+						&oneOrMoreExpr{
+							pos: position{line: 356, col: 12, offset: 8833},
+							expr: &ruleRefExpr{
+								pos:  position{line: 356, col: 12, offset: 8833},
+								name: "digit",
+							},
+						},
+						&zeroOrOneExpr{
+							pos: position{line: 356, col: 19, offset: 8840},
+							expr: &seqExpr{
+								pos: position{line: 356, col: 20, offset: 8841},
+								exprs: []interface{}{
+									&litMatcher{
+										pos:        position{line: 356, col: 20, offset: 8841},
+										val:        ".",
+										ignoreCase: false,
+									},
+									&oneOrMoreExpr{
+										pos: position{line: 356, col: 24, offset: 8845},
+										expr: &ruleRefExpr{
+											pos:  position{line: 356, col: 24, offset: 8845},
+											name: "digit",
+										},
+									},
+								},
+							},
+						},
+						&zeroOrOneExpr{
+							pos: position{line: 356, col: 33, offset: 8854},
+							expr: &seqExpr{
+								pos: position{line: 356, col: 34, offset: 8855},
+								exprs: []interface{}{
+									&charClassMatcher{
+										pos:        position{line: 356, col: 34, offset: 8855},
+										val:        "[eE]",
+										chars:      []rune{'e', 'E'},
+										ignoreCase: false,
+										inverted:   false,
+									},
+									&zeroOrOneExpr{
+										pos: position{line: 356, col: 39, offset: 8860},
+										expr: &charClassMatcher{
+											pos:        position{line: 356, col: 39, offset: 8860},
+											val:        "[+-]",
+											chars:      []rune{'+', '-'},
+											ignoreCase: false,
+											inverted:   false,
+										},
+									},
+									&oneOrMoreExpr{
+										pos: position{line: 356, col: 45, offset: 8866},
+										expr: &ruleRefExpr{
+											pos:  position{line: 356, col: 45, offset: 8866},
+											name: "digit",
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "stringLiteral",
+			pos:  position{line: 360, col: 1, offset: 8952},
+			expr: &actionExpr{
+				pos: position{line: 361, col: 7, offset: 8972},
+				run: (*parser).callonstringLiteral1,
+				expr: &seqExpr{
+					pos: position{line: 361, col: 7, offset: 8972},
+					exprs: []interface{}{
+						&litMatcher{
+							pos:        position{line: 361, col: 7, offset: 8972},
+							val:        "\"",
+							ignoreCase: false,
+						},
+						&zeroOrMoreExpr{
+							pos: position{line: 361, col: 11, offset: 8976},
+							expr: &choiceExpr{
+								pos: position{line: 361, col: 13, offset: 8978},
+								alternatives: []interface{}{
+									&seqExpr{
+										pos: position{line: 361, col: 13, offset: 8978},
+										exprs: []interface{}{
+											&notExpr{
+												pos: position{line: 361, col: 13, offset: 8978},
+												expr: &litMatcher{
+													pos:        position{line: 361, col: 14, offset: 8979},
+													val:        "\"",
+													ignoreCase: false,
+												},
+											},
+											&notExpr{
+												pos: position{line: 361, col: 18, offset: 8983},
+												expr: &litMatcher{
+													pos:        position{line: 361, col: 19, offset: 8984},
+													val:        "\\",
+													ignoreCase: false,
+												},
+											},
+											&charClassMatcher{
+												pos:        position{line: 361, col: 24, offset: 8989},
+												val:        "[^\\n]",
+												chars:      []rune{'\n'},
+												ignoreCase: false,
+												inverted:   true,
+											},
+										},
+									},
+									&seqExpr{
+										pos: position{line: 361, col: 32, offset: 8997},
+										exprs: []interface{}{
+											&litMatcher{
+												pos:        position{line: 361, col: 32, offset: 8997},
+												val:        "\\",
+												ignoreCase: false,
+											},
+											&anyMatcher{
+												line: 361, col: 37, offset: 9002,
+											},
+										},
+									},
+								},
+							},
+						},
+						&litMatcher{
+							pos:        position{line: 361, col: 42, offset: 9007},
+							val:        "\"",
+							ignoreCase: false,
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "boolLiteral",
+			pos:  position{line: 366, col: 1, offset: 9129},
+			expr: &actionExpr{
+				pos: position{line: 367, col: 7, offset: 9147},
+				run: (*parser).callonboolLiteral1,
+				expr: &choiceExpr{
+					pos: position{line: 367, col: 8, offset: 9148},
+					alternatives: []interface{}{
+						&litMatcher{
+							pos:        position{line: 367, col: 8, offset: 9148},
+							val:        "true",
+							ignoreCase: false,
+						},
+						&litMatcher{
+							pos:        position{line: 367, col: 17, offset: 9157},
+							val:        "false",
+							ignoreCase: false,
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "nullLiteral",
+			pos:  position{line: 369, col: 1, offset: 9229},
+			expr: &actionExpr{
+				pos: position{line: 370, col: 7, offset: 9247},
+				run: (*parser).callonnullLiteral1,
+				expr: &litMatcher{
+					pos:        position{line: 370, col: 7, offset: 9247},
+					val:        "null",
+					ignoreCase: false,
+				},
+			},
+		},
+		{
+			name: "listLiteral",
+			pos:  position{line: 372, col: 1, offset: 9294},
+			expr: &actionExpr{
+				pos: position{line: 373, col: 7, offset: 9312},
+				run: (*parser).callonlistLiteral1,
+				expr: &seqExpr{
+					pos: position{line: 373, col: 7, offset: 9312},
+					exprs: []interface{}{
+						&ruleRefExpr{
+							pos:  position{line: 373, col: 7, offset: 9312},
+							name: "openSquareBrace",
+						},
+						&zeroOrOneExpr{
+							pos: position{line: 373, col: 23, offset: 9328},
+							expr: &ruleRefExpr{
+								pos:  position{line: 373, col: 23, offset: 9328},
+								name: "literalSpace",
+							},
+						},
+						&labeledExpr{
+							pos:   position{line: 373, col: 37, offset: 9342},
+							label: "items",
+							expr: &zeroOrMoreExpr{
+								pos: position{line: 373, col: 43, offset: 9348},
+								expr: &ruleRefExpr{
+									pos:  position{line: 373, col: 43, offset: 9348},
+									name: "listItem",
+								},
+							},
+						},
+						&zeroOrOneExpr{
+							pos: position{line: 373, col: 53, offset: 9358},
+							expr: &ruleRefExpr{
+								pos:  position{line: 373, col: 53, offset: 9358},
+								name: "_",
+							},
+						},
+						&ruleRefExpr{
+							pos:  position{line: 373, col: 56, offset: 9361},
+							name: "closeSquareBrace",
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "listItem",
+			pos:  position{line: 377, col: 1, offset: 9454},
+			expr: &actionExpr{
+				pos: position{line: 378, col: 7, offset: 9469},
+				run: (*parser).callonlistItem1,
+				expr: &seqExpr{
+					pos: position{line: 378, col: 7, offset: 9469},
+					exprs: []interface{}{
+						&zeroOrOneExpr{
+							pos: position{line: 378, col: 7, offset: 9469},
+							expr: &ruleRefExpr{
+								pos:  position{line: 378, col: 7, offset: 9469},
+								name: "_",
+							},
+						},
+						&labeledExpr{
+							pos:   position{line: 378, col: 10, offset: 9472},
+							label: "val",
+							expr: &ruleRefExpr{
+								pos:  position{line: 378, col: 14, offset: 9476},
+								name: "literal",
+							},
+						},
+						&zeroOrOneExpr{
+							pos: position{line: 378, col: 22, offset: 9484},
+							expr: &ruleRefExpr{
+								pos:  position{line: 378, col: 22, offset: 9484},
+								name: "_",
+							},
+						},
+						&zeroOrOneExpr{
+							pos: position{line: 378, col: 25, offset: 9487},
+							expr: &litMatcher{
+								pos:        position{line: 378, col: 25, offset: 9487},
+								val:        ",",
+								ignoreCase: false,
+							},
+						},
+						&zeroOrOneExpr{
+							pos: position{line: 378, col: 30, offset: 9492},
+							expr: &ruleRefExpr{
+								pos:  position{line: 378, col: 30, offset: 9492},
+								name: "literalSpace",
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "structLiteral",
+			pos:  position{line: 380, col: 1, offset: 9527},
+			expr: &actionExpr{
+				pos: position{line: 381, col: 7, offset: 9547},
+				run: (*parser).callonstructLiteral1,
+				expr: &seqExpr{
+					pos: position{line: 381, col: 7, offset: 9547},
+					exprs: []interface{}{
+						&ruleRefExpr{
+							pos:  position{line: 381, col: 7, offset: 9547},
+							name: "openCurlyBrace",
+						},
+						&zeroOrOneExpr{
+							pos: position{line: 381, col: 22, offset: 9562},
+							expr: &ruleRefExpr{
+								pos:  position{line: 381, col: 22, offset: 9562},
+								name: "literalSpace",
+							},
+						},
+						&labeledExpr{
+							pos:   position{line: 381, col: 36, offset: 9576},
+							label: "fields",
+							expr: &zeroOrMoreExpr{
+								pos: position{line: 381, col: 43, offset: 9583},
+								expr: &ruleRefExpr{
+									pos:  position{line: 381, col: 43, offset: 9583},
+									name: "exampleContents",
+								},
+							},
+						},
+						&zeroOrOneExpr{
+							pos: position{line: 381, col: 60, offset: 9600},
+							expr: &ruleRefExpr{
+								pos:  position{line: 381, col: 60, offset: 9600},
+								name: "_",
+							},
+						},
+						&ruleRefExpr{
+							pos:  position{line: 381, col: 63, offset: 9603},
+							name: "closeCurlyBrace",
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "contents",
+			pos:  position{line: 387, col: 1, offset: 9723},
+			expr: &actionExpr{
+				pos: position{line: 388, col: 7, offset: 9738},
+				run: (*parser).calloncontents1,
+				expr: &labeledExpr{
+					pos:   position{line: 388, col: 7, offset: 9738},
+					label: "val",
+					expr: &oneOrMoreExpr{
+						pos: position{line: 388, col: 11, offset: 9742},
+						expr: &ruleRefExpr{
+							pos:  position{line: 388, col: 11, offset: 9742},
+							name: "fileContents",
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "fileContents",
+			pos:  position{line: 390, col: 1, offset: 9777},
+			expr: &actionExpr{
+				pos: position{line: 391, col: 7, offset: 9796},
+				run: (*parser).callonfileContents1,
+				expr: &seqExpr{
+					pos: position{line: 391, col: 7, offset: 9796},
+					exprs: []interface{}{
+						&zeroOrOneExpr{
+							pos: position{line: 391, col: 7, offset: 9796},
+							expr: &ruleRefExpr{
+								pos:  position{line: 391, col: 7, offset: 9796},
+								name: "__",
+							},
+						},
+						&zeroOrOneExpr{
+							pos: position{line: 391, col: 11, offset: 9800},
+							expr: &ruleRefExpr{
+								pos:  position{line: 391, col: 11, offset: 9800},
+								name: "comment",
+							},
+						},
+						&labeledExpr{
+							pos:   position{line: 391, col: 20, offset: 9809},
+							label: "doc",
+							expr: &zeroOrOneExpr{
+								pos: position{line: 391, col: 24, offset: 9813},
+								expr: &ruleRefExpr{
+									pos:  position{line: 391, col: 24, offset: 9813},
+									name: "docComment",
+								},
+							},
+						},
+						&zeroOrOneExpr{
+							pos: position{line: 391, col: 36, offset: 9825},
+							expr: &ruleRefExpr{
+								pos:  position{line: 391, col: 36, offset: 9825},
+								name: "__",
+							},
+						},
+						&labeledExpr{
+							pos:   position{line: 391, col: 40, offset: 9829},
+							label: "val",
+							expr: &ruleRefExpr{
+								pos:  position{line: 391, col: 44, offset: 9833},
+								name: "pkg",
+							},
+						},
+						&zeroOrOneExpr{
+							pos: position{line: 391, col: 48, offset: 9837},
+							expr: &ruleRefExpr{
+								pos:  position{line: 391, col: 48, offset: 9837},
+								name: "__",
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "str",
+			pos:  position{line: 401, col: 1, offset: 10004},
+			expr: &actionExpr{
+				pos: position{line: 402, col: 7, offset: 10014},
+				run: (*parser).callonstr1,
+				expr: &seqExpr{
+					pos: position{line: 402, col: 7, offset: 10014},
+					exprs: []interface{}{
+						&labeledExpr{
+							pos:   position{line: 402, col: 7, offset: 10014},
+							label: "header",
+							expr: &ruleRefExpr{
+								pos:  position{line: 402, col: 14, offset: 10021},
+								name: "strHeader",
+							},
+						},
+						&zeroOrOneExpr{
+							pos: position{line: 402, col: 24, offset: 10031},
+							expr: &ruleRefExpr{
+								pos:  position{line: 402, col: 24, offset: 10031},
+								name: "_",
+							},
+						},
+						&ruleRefExpr{
+							pos:  position{line: 402, col: 27, offset: 10034},
+							name: "openCurlyBrace",
+						},
+						&ruleRefExpr{
+							pos:  position{line: 402, col: 42, offset: 10049},
+							name: "__",
+						},
+						&zeroOrOneExpr{
+							pos: position{line: 403, col: 5, offset: 10056},
+							expr: &ruleRefExpr{
+								pos:  position{line: 403, col: 5, offset: 10056},
+								name: "__",
+							},
+						},
+						&labeledExpr{
+							pos:   position{line: 404, col: 5, offset: 10064},
+							label: "contents",
+							expr: &oneOrMoreExpr{
+								pos: position{line: 404, col: 14, offset: 10073},
+								expr: &ruleRefExpr{
+									pos:  position{line: 404, col: 14, offset: 10073},
+									name: "strContents",
+								},
+							},
+						},
+						&zeroOrOneExpr{
+							pos: position{line: 405, col: 5, offset: 10090},
+							expr: &ruleRefExpr{
+								pos:  position{line: 405, col: 5, offset: 10090},
+								name: "__",
+							},
+						},
+						&ruleRefExpr{
+							pos:  position{line: 406, col: 5, offset: 10098},
+							name: "closeCurlyBrace",
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "strHeader",
+			pos:  position{line: 414, col: 1, offset: 10301},
+			expr: &actionExpr{
+				pos: position{line: 415, col: 7, offset: 10317},
+				run: (*parser).callonstrHeader1,
+				expr: &seqExpr{
+					pos: position{line: 415, col: 7, offset: 10317},
+					exprs: []interface{}{
+						&litMatcher{
+							pos:        position{line: 415, col: 7, offset: 10317},
+							val:        "struct",
+							ignoreCase: false,
+						},
+						&ruleRefExpr{
+							pos:  position{line: 415, col: 16, offset: 10326},
+							name: "_",
+						},
+						&labeledExpr{
+							pos:   position{line: 415, col: 18, offset: 10328},
+							label: "name",
+							expr: &ruleRefExpr{
+								pos:  position{line: 415, col: 23, offset: 10333},
+								name: "itemName",
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "strContents",
+			pos:  position{line: 417, col: 1, offset: 10364},
+			expr: &actionExpr{
+				pos: position{line: 418, col: 7, offset: 10382},
+				run: (*parser).callonstrContents1,
+				expr: &seqExpr{
+					pos: position{line: 418, col: 7, offset: 10382},
+					exprs: []interface{}{
+						&zeroOrOneExpr{
+							pos: position{line: 418, col: 7, offset: 10382},
+							expr: &ruleRefExpr{
+								pos:  position{line: 418, col: 7, offset: 10382},
+								name: "_",
+							},
+						},
+						&labeledExpr{
+							pos:   position{line: 418, col: 10, offset: 10385},
+							label: "val",
+							expr: &choiceExpr{
+								pos: position{line: 418, col: 15, offset: 10390},
+								alternatives: []interface{}{
+									&ruleRefExpr{
+										pos:  position{line: 418, col: 15, offset: 10390},
+										name: "fieldDefinition",
+									},
+									&ruleRefExpr{
+										pos:  position{line: 419, col: 19, offset: 10424},
+										name: "arrayDefinition",
+									},
+									&ruleRefExpr{
+										pos:  position{line: 420, col: 19, offset: 10458},
+										name: "danglingDoc",
+									},
+									&ruleRefExpr{
+										pos:  position{line: 421, col: 19, offset: 10488},
+										name: "docComment",
+									},
+									&ruleRefExpr{
+										pos:  position{line: 422, col: 19, offset: 10517},
+										name: "comment",
+									},
+									&ruleRefExpr{
+										pos:  position{line: 423, col: 19, offset: 10543},
+										name: "str",
+									},
+								},
+							},
+						},
+						&labeledExpr{
+							pos:   position{line: 423, col: 24, offset: 10548},
+							label: "trailing",
+							expr: &zeroOrOneExpr{
+								pos: position{line: 423, col: 33, offset: 10557},
+								expr: &ruleRefExpr{
+									pos:  position{line: 423, col: 33, offset: 10557},
+									name: "trailingDoc",
+								},
+							},
+						},
+						&zeroOrOneExpr{
+							pos: position{line: 423, col: 46, offset: 10570},
+							expr: &ruleRefExpr{
+								pos:  position{line: 423, col: 46, offset: 10570},
+								name: "__",
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "example",
+			pos:  position{line: 427, col: 1, offset: 10635},
+			expr: &actionExpr{
+				pos: position{line: 428, col: 7, offset: 10649},
+				run: (*parser).callonexample1,
+				expr: &seqExpr{
+					pos: position{line: 428, col: 7, offset: 10649},
+					exprs: []interface{}{
+						&labeledExpr{
+							pos:   position{line: 428, col: 7, offset: 10649},
+							label: "header",
+							expr: &ruleRefExpr{
+								pos:  position{line: 428, col: 14, offset: 10656},
+								name: "exampleHeader",
+							},
+						},
+						&zeroOrOneExpr{
+							pos: position{line: 428, col: 28, offset: 10670},
+							expr: &ruleRefExpr{
+								pos:  position{line: 428, col: 28, offset: 10670},
+								name: "_",
+							},
+						},
+						&ruleRefExpr{
+							pos:  position{line: 428, col: 31, offset: 10673},
+							name: "openCurlyBrace",
+						},
+						&ruleRefExpr{
+							pos:  position{line: 428, col: 46, offset: 10688},
+							name: "__",
+						},
+						&zeroOrOneExpr{
+							pos: position{line: 429, col: 5, offset: 10695},
+							expr: &ruleRefExpr{
+								pos:  position{line: 429, col: 5, offset: 10695},
+								name: "__",
+							},
+						},
+						&labeledExpr{
+							pos:   position{line: 430, col: 5, offset: 10703},
+							label: "contents",
+							expr: &zeroOrMoreExpr{
+								pos: position{line: 430, col: 14, offset: 10712},
+								expr: &ruleRefExpr{
+									pos:  position{line: 430, col: 14, offset: 10712},
+									name: "exampleContents",
 								},
 							},
 						},
 						&zeroOrOneExpr{
-							pos: position{line: 204, col: 5, offset: 4177},
+							pos: position{line: 431, col: 5, offset: 10733},
 							expr: &ruleRefExpr{
-								pos:  position{line: 204, col: 5, offset: 4177},
+								pos:  position{line: 431, col: 5, offset: 10733},
 								name: "__",
 							},
 						},
 						&ruleRefExpr{
-							pos:  position{line: 205, col: 5, offset: 4185},
+							pos:  position{line: 432, col: 5, offset: 10741},
 							name: "closeCurlyBrace",
 						},
 					},
@@ -813,28 +1933,28 @@ var g = &grammar{
 			},
 		},
 		{
-			name: "strHeader",
-			pos:  position{line: 213, col: 1, offset: 4376},
+			name: "exampleHeader",
+			pos:  position{line: 440, col: 1, offset: 10917},
 			expr: &actionExpr{
-				pos: position{line: 214, col: 7, offset: 4392},
-				run: (*parser).callonstrHeader1,
+				pos: position{line: 441, col: 7, offset: 10937},
+				run: (*parser).callonexampleHeader1,
 				expr: &seqExpr{
-					pos: position{line: 214, col: 7, offset: 4392},
+					pos: position{line: 441, col: 7, offset: 10937},
 					exprs: []interface{}{
 						&litMatcher{
-							pos:        position{line: 214, col: 7, offset: 4392},
-							val:        "struct",
+							pos:        position{line: 441, col: 7, offset: 10937},
+							val:        "example",
 							ignoreCase: false,
 						},
 						&ruleRefExpr{
-							pos:  position{line: 214, col: 16, offset: 4401},
+							pos:  position{line: 441, col: 17, offset: 10947},
 							name: "_",
 						},
 						&labeledExpr{
-							pos:   position{line: 214, col: 18, offset: 4403},
+							pos:   position{line: 441, col: 19, offset: 10949},
 							label: "name",
 							expr: &ruleRefExpr{
-								pos:  position{line: 214, col: 23, offset: 4408},
+								pos:  position{line: 441, col: 24, offset: 10954},
 								name: "itemName",
 							},
 						},
@@ -843,51 +1963,87 @@ var g = &grammar{
 			},
 		},
 		{
-			name: "strContents",
-			pos:  position{line: 216, col: 1, offset: 4439},
+			name: "exampleContents",
+			pos:  position{line: 443, col: 1, offset: 10985},
 			expr: &actionExpr{
-				pos: position{line: 217, col: 7, offset: 4457},
-				run: (*parser).callonstrContents1,
+				pos: position{line: 444, col: 7, offset: 11007},
+				run: (*parser).callonexampleContents1,
 				expr: &seqExpr{
-					pos: position{line: 217, col: 7, offset: 4457},
+					pos: position{line: 444, col: 7, offset: 11007},
 					exprs: []interface{}{
 						&zeroOrOneExpr{
-							pos: position{line: 217, col: 7, offset: 4457},
+							pos: position{line: 444, col: 7, offset: 11007},
 							expr: &ruleRefExpr{
-								pos:  position{line: 217, col: 7, offset: 4457},
+								pos:  position{line: 444, col: 7, offset: 11007},
 								name: "_",
 							},
 						},
 						&labeledExpr{
-							pos:   position{line: 217, col: 10, offset: 4460},
+							pos:   position{line: 444, col: 10, offset: 11010},
 							label: "val",
 							expr: &choiceExpr{
-								pos: position{line: 217, col: 15, offset: 4465},
+								pos: position{line: 444, col: 15, offset: 11015},
 								alternatives: []interface{}{
 									&ruleRefExpr{
-										pos:  position{line: 217, col: 15, offset: 4465},
-										name: "fieldDefinition",
+										pos:  position{line: 444, col: 15, offset: 11015},
+										name: "assignment",
 									},
 									&ruleRefExpr{
-										pos:  position{line: 218, col: 19, offset: 4499},
-										name: "arrayDefinition",
+										pos:  position{line: 445, col: 19, offset: 11044},
+										name: "docComment",
 									},
 									&ruleRefExpr{
-										pos:  position{line: 219, col: 19, offset: 4533},
+										pos:  position{line: 446, col: 19, offset: 11073},
 										name: "comment",
 									},
-									&ruleRefExpr{
-										pos:  position{line: 220, col: 19, offset: 4559},
-										name: "str",
-									},
 								},
 							},
 						},
 						&zeroOrOneExpr{
-							pos: position{line: 220, col: 24, offset: 4564},
+							pos: position{line: 446, col: 28, offset: 11082},
 							expr: &ruleRefExpr{
-								pos:  position{line: 220, col: 24, offset: 4564},
-								name: "__",
+								pos:  position{line: 446, col: 28, offset: 11082},
+								name: "trailingDoc",
+							},
+						},
+						&zeroOrOneExpr{
+							pos: position{line: 446, col: 41, offset: 11095},
+							expr: &ruleRefExpr{
+								pos:  position{line: 446, col: 41, offset: 11095},
+								name: "literalSpace",
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "assignment",
+			pos:  position{line: 453, col: 1, offset: 11249},
+			expr: &actionExpr{
+				pos: position{line: 454, col: 7, offset: 11266},
+				run: (*parser).callonassignment1,
+				expr: &seqExpr{
+					pos: position{line: 454, col: 7, offset: 11266},
+					exprs: []interface{}{
+						&labeledExpr{
+							pos:   position{line: 454, col: 7, offset: 11266},
+							label: "name",
+							expr: &ruleRefExpr{
+								pos:  position{line: 454, col: 12, offset: 11271},
+								name: "itemName",
+							},
+						},
+						&ruleRefExpr{
+							pos:  position{line: 454, col: 21, offset: 11280},
+							name: "_",
+						},
+						&labeledExpr{
+							pos:   position{line: 454, col: 23, offset: 11282},
+							label: "val",
+							expr: &ruleRefExpr{
+								pos:  position{line: 454, col: 27, offset: 11286},
+								name: "literal",
 							},
 						},
 					},
@@ -896,67 +2052,67 @@ var g = &grammar{
 		},
 		{
 			name: "pkg",
-			pos:  position{line: 224, col: 1, offset: 4602},
+			pos:  position{line: 464, col: 1, offset: 11462},
 			expr: &actionExpr{
-				pos: position{line: 225, col: 7, offset: 4612},
+				pos: position{line: 465, col: 7, offset: 11472},
 				run: (*parser).callonpkg1,
 				expr: &seqExpr{
-					pos: position{line: 225, col: 7, offset: 4612},
+					pos: position{line: 465, col: 7, offset: 11472},
 					exprs: []interface{}{
 						&zeroOrOneExpr{
-							pos: position{line: 225, col: 7, offset: 4612},
+							pos: position{line: 465, col: 7, offset: 11472},
 							expr: &ruleRefExpr{
-								pos:  position{line: 225, col: 7, offset: 4612},
+								pos:  position{line: 465, col: 7, offset: 11472},
 								name: "_",
 							},
 						},
 						&labeledExpr{
-							pos:   position{line: 225, col: 10, offset: 4615},
+							pos:   position{line: 465, col: 10, offset: 11475},
 							label: "header",
 							expr: &ruleRefExpr{
-								pos:  position{line: 225, col: 17, offset: 4622},
+								pos:  position{line: 465, col: 17, offset: 11482},
 								name: "pkgHeader",
 							},
 						},
 						&ruleRefExpr{
-							pos:  position{line: 225, col: 27, offset: 4632},
+							pos:  position{line: 465, col: 27, offset: 11492},
 							name: "_",
 						},
 						&ruleRefExpr{
-							pos:  position{line: 225, col: 29, offset: 4634},
+							pos:  position{line: 465, col: 29, offset: 11494},
 							name: "openCurlyBrace",
 						},
 						&ruleRefExpr{
-							pos:  position{line: 225, col: 44, offset: 4649},
+							pos:  position{line: 465, col: 44, offset: 11509},
 							name: "__",
 						},
 						&zeroOrOneExpr{
-							pos: position{line: 226, col: 5, offset: 4656},
+							pos: position{line: 466, col: 5, offset: 11516},
 							expr: &ruleRefExpr{
-								pos:  position{line: 226, col: 5, offset: 4656},
+								pos:  position{line: 466, col: 5, offset: 11516},
 								name: "__",
 							},
 						},
 						&labeledExpr{
-							pos:   position{line: 227, col: 5, offset: 4664},
+							pos:   position{line: 467, col: 5, offset: 11524},
 							label: "contents",
 							expr: &oneOrMoreExpr{
-								pos: position{line: 227, col: 14, offset: 4673},
+								pos: position{line: 467, col: 14, offset: 11533},
 								expr: &ruleRefExpr{
-									pos:  position{line: 227, col: 14, offset: 4673},
+									pos:  position{line: 467, col: 14, offset: 11533},
 									name: "pkgContents",
 								},
 							},
 						},
 						&zeroOrOneExpr{
-							pos: position{line: 228, col: 5, offset: 4690},
+							pos: position{line: 468, col: 5, offset: 11550},
 							expr: &ruleRefExpr{
-								pos:  position{line: 228, col: 5, offset: 4690},
+								pos:  position{line: 468, col: 5, offset: 11550},
 								name: "__",
 							},
 						},
 						&ruleRefExpr{
-							pos:  position{line: 229, col: 5, offset: 4698},
+							pos:  position{line: 469, col: 5, offset: 11558},
 							name: "closeCurlyBrace",
 						},
 					},
@@ -965,27 +2121,27 @@ var g = &grammar{
 		},
 		{
 			name: "pkgHeader",
-			pos:  position{line: 237, col: 1, offset: 4856},
+			pos:  position{line: 486, col: 1, offset: 11991},
 			expr: &actionExpr{
-				pos: position{line: 238, col: 7, offset: 4872},
+				pos: position{line: 487, col: 7, offset: 12007},
 				run: (*parser).callonpkgHeader1,
 				expr: &seqExpr{
-					pos: position{line: 238, col: 7, offset: 4872},
+					pos: position{line: 487, col: 7, offset: 12007},
 					exprs: []interface{}{
 						&litMatcher{
-							pos:        position{line: 238, col: 7, offset: 4872},
+							pos:        position{line: 487, col: 7, offset: 12007},
 							val:        "package",
 							ignoreCase: false,
 						},
 						&ruleRefExpr{
-							pos:  position{line: 238, col: 17, offset: 4882},
+							pos:  position{line: 487, col: 17, offset: 12017},
 							name: "_",
 						},
 						&labeledExpr{
-							pos:   position{line: 238, col: 19, offset: 4884},
+							pos:   position{line: 487, col: 19, offset: 12019},
 							label: "name",
 							expr: &ruleRefExpr{
-								pos:  position{line: 238, col: 24, offset: 4889},
+								pos:  position{line: 487, col: 24, offset: 12024},
 								name: "itemName",
 							},
 						},
@@ -995,53 +2151,76 @@ var g = &grammar{
 		},
 		{
 			name: "pkgContents",
-			pos:  position{line: 240, col: 1, offset: 4920},
+			pos:  position{line: 489, col: 1, offset: 12055},
 			expr: &actionExpr{
-				pos: position{line: 241, col: 7, offset: 4938},
+				pos: position{line: 490, col: 7, offset: 12073},
 				run: (*parser).callonpkgContents1,
 				expr: &seqExpr{
-					pos: position{line: 241, col: 7, offset: 4938},
+					pos: position{line: 490, col: 7, offset: 12073},
 					exprs: []interface{}{
 						&zeroOrOneExpr{
-							pos: position{line: 241, col: 7, offset: 4938},
+							pos: position{line: 490, col: 7, offset: 12073},
 							expr: &ruleRefExpr{
-								pos:  position{line: 241, col: 7, offset: 4938},
+								pos:  position{line: 490, col: 7, offset: 12073},
 								name: "_",
 							},
 						},
 						&labeledExpr{
-							pos:   position{line: 241, col: 10, offset: 4941},
+							pos:   position{line: 490, col: 10, offset: 12076},
 							label: "val",
 							expr: &choiceExpr{
-								pos: position{line: 241, col: 15, offset: 4946},
+								pos: position{line: 490, col: 15, offset: 12081},
 								alternatives: []interface{}{
 									&ruleRefExpr{
-										pos:  position{line: 241, col: 15, offset: 4946},
+										pos:  position{line: 490, col: 15, offset: 12081},
 										name: "idDefinition",
 									},
 									&ruleRefExpr{
-										pos:  position{line: 242, col: 19, offset: 4977},
+										pos:  position{line: 491, col: 19, offset: 12112},
+										name: "example",
+									},
+									&ruleRefExpr{
+										pos:  position{line: 492, col: 19, offset: 12138},
 										name: "fieldDefinition",
 									},
 									&ruleRefExpr{
-										pos:  position{line: 243, col: 19, offset: 5011},
+										pos:  position{line: 493, col: 19, offset: 12172},
 										name: "arrayDefinition",
 									},
 									&ruleRefExpr{
-										pos:  position{line: 244, col: 19, offset: 5045},
+										pos:  position{line: 494, col: 19, offset: 12206},
+										name: "danglingDoc",
+									},
+									&ruleRefExpr{
+										pos:  position{line: 495, col: 19, offset: 12236},
+										name: "docComment",
+									},
+									&ruleRefExpr{
+										pos:  position{line: 496, col: 19, offset: 12265},
 										name: "comment",
 									},
 									&ruleRefExpr{
-										pos:  position{line: 245, col: 19, offset: 5071},
+										pos:  position{line: 497, col: 19, offset: 12291},
 										name: "str",
 									},
 								},
 							},
 						},
+						&labeledExpr{
+							pos:   position{line: 497, col: 24, offset: 12296},
+							label: "trailing",
+							expr: &zeroOrOneExpr{
+								pos: position{line: 497, col: 33, offset: 12305},
+								expr: &ruleRefExpr{
+									pos:  position{line: 497, col: 33, offset: 12305},
+									name: "trailingDoc",
+								},
+							},
+						},
 						&zeroOrOneExpr{
-							pos: position{line: 245, col: 24, offset: 5076},
+							pos: position{line: 497, col: 46, offset: 12318},
 							expr: &ruleRefExpr{
-								pos:  position{line: 245, col: 24, offset: 5076},
+								pos:  position{line: 497, col: 46, offset: 12318},
 								name: "__",
 							},
 						},
@@ -1122,6 +2301,82 @@ func (p *parser) calloncomment1() (interface{}, error) {
 	return p.cur.oncomment1()
 }
 
+func (c *current) ondocLine1() (interface{}, error) {
+	return string(c.text), nil
+}
+
+func (p *parser) callondocLine1() (interface{}, error) {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.ondocLine1()
+}
+
+func (c *current) ondocBlock1() (interface{}, error) {
+	return string(c.text), nil
+}
+
+func (p *parser) callondocBlock1() (interface{}, error) {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.ondocBlock1()
+}
+
+func (c *current) ondocComment1(first, rest interface{}) (interface{}, error) {
+	parts := []string{first.(string)}
+	for _, r := range rest.([]interface{}) {
+		parts = append(parts, r.(string))
+	}
+	return Object{ObjectType: ObjDoc, Value: docText(parts)}, nil
+
+}
+
+func (p *parser) callondocComment1() (interface{}, error) {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.ondocComment1(stack["first"], stack["rest"])
+}
+
+func (c *current) ondocContinuation1(val interface{}) (interface{}, error) {
+	return val, nil
+}
+
+func (p *parser) callondocContinuation1() (interface{}, error) {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.ondocContinuation1(stack["val"])
+}
+
+func (c *current) ontrailingDoc1(val interface{}) (interface{}, error) {
+	return docText([]string{val.(string)}), nil
+}
+
+func (p *parser) callontrailingDoc1() (interface{}, error) {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.ontrailingDoc1(stack["val"])
+}
+
+func (c *current) ondanglingDoc1() (interface{}, error) {
+	return nil, errors.New("doc comment must precede a package, structure or field")
+
+}
+
+func (p *parser) callondanglingDoc1() (interface{}, error) {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.ondanglingDoc1()
+}
+
+func (c *current) onliteralSpace1() (interface{}, error) {
+	return nil, nil
+}
+
+func (p *parser) callonliteralSpace1() (interface{}, error) {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onliteralSpace1()
+}
+
 func (c *current) onattribute1(flag interface{}) (interface{}, error) {
 	return asString(flag), nil
 }
@@ -1229,6 +2484,102 @@ func (p *parser) callonarrayDefinition1() (interface{}, error) {
 	return p.cur.onarrayDefinition1(stack["t"], stack["size"], stack["name"], stack["attributes"])
 }
 
+func (c *current) onuuidLiteral1() (interface{}, error) {
+	return Literal{Kind: LitUUID, Value: string(c.text)}, nil
+
+}
+
+func (p *parser) callonuuidLiteral1() (interface{}, error) {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onuuidLiteral1()
+}
+
+func (c *current) onhexLiteral1(val interface{}) (interface{}, error) {
+	return Literal{Kind: LitHex, Value: val.(string)}, nil
+}
+
+func (p *parser) callonhexLiteral1() (interface{}, error) {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onhexLiteral1(stack["val"])
+}
+
+func (c *current) onnumberLiteral1() (interface{}, error) {
+	return Literal{Kind: LitNumber, Value: string(c.text)}, nil
+
+}
+
+func (p *parser) callonnumberLiteral1() (interface{}, error) {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onnumberLiteral1()
+}
+
+func (c *current) onstringLiteral1() (interface{}, error) {
+	val, err := strconv.Unquote(string(c.text))
+	return Literal{Kind: LitString, Value: val}, err
+
+}
+
+func (p *parser) callonstringLiteral1() (interface{}, error) {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onstringLiteral1()
+}
+
+func (c *current) onboolLiteral1() (interface{}, error) {
+	return Literal{Kind: LitBool, Value: string(c.text)}, nil
+}
+
+func (p *parser) callonboolLiteral1() (interface{}, error) {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onboolLiteral1()
+}
+
+func (c *current) onnullLiteral1() (interface{}, error) {
+	return Literal{Kind: LitNull}, nil
+}
+
+func (p *parser) callonnullLiteral1() (interface{}, error) {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onnullLiteral1()
+}
+
+func (c *current) onlistLiteral1(items interface{}) (interface{}, error) {
+	return Literal{Kind: LitList, Items: litSlice(items)}, nil
+
+}
+
+func (p *parser) callonlistLiteral1() (interface{}, error) {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onlistLiteral1(stack["items"])
+}
+
+func (c *current) onlistItem1(val interface{}) (interface{}, error) {
+	return val, nil
+}
+
+func (p *parser) callonlistItem1() (interface{}, error) {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onlistItem1(stack["val"])
+}
+
+func (c *current) onstructLiteral1(fields interface{}) (interface{}, error) {
+	return Literal{Kind: LitStruct, Fields: objSlice(fields)}, nil
+
+}
+
+func (p *parser) callonstructLiteral1() (interface{}, error) {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onstructLiteral1(stack["fields"])
+}
+
 func (c *current) oncontents1(val interface{}) (interface{}, error) {
 	return val, nil
 }
@@ -1239,21 +2590,26 @@ func (p *parser) calloncontents1() (interface{}, error) {
 	return p.cur.oncontents1(stack["val"])
 }
 
-func (c *current) onfileContents1(val interface{}) (interface{}, error) {
-	return val, nil
+func (c *current) onfileContents1(doc, val interface{}) (interface{}, error) {
+	p := val.(Package)
+	if doc != nil {
+		p.Doc = joinDocs(doc.(Object).Value, p.Doc)
+	}
+	return p, nil
+
 }
 
 func (p *parser) callonfileContents1() (interface{}, error) {
 	stack := p.vstack[len(p.vstack)-1]
 	_ = stack
-	return p.cur.onfileContents1(stack["val"])
+	return p.cur.onfileContents1(stack["doc"], stack["val"])
 }
 
 func (c *current) onstr1(header, contents interface{}) (interface{}, error) {
 	return Object{
 		ObjectType: ObjStruct,
 		Name:       header.(string),
-		Contents:   objSlice(contents.([]interface{})),
+		Contents:   attachDocs(objSlice(contents.([]interface{}))),
 	}, nil
 
 }
@@ -1274,20 +2630,83 @@ func (p *parser) callonstrHeader1() (interface{}, error) {
 	return p.cur.onstrHeader1(stack["name"])
 }
 
-func (c *current) onstrContents1(val interface{}) (interface{}, error) {
-	return val, nil
+func (c *current) onstrContents1(val, trailing interface{}) (interface{}, error) {
+	return withTrailingDoc(val, trailing), nil
 }
 
 func (p *parser) callonstrContents1() (interface{}, error) {
 	stack := p.vstack[len(p.vstack)-1]
 	_ = stack
-	return p.cur.onstrContents1(stack["val"])
+	return p.cur.onstrContents1(stack["val"], stack["trailing"])
+}
+
+func (c *current) onexample1(header, contents interface{}) (interface{}, error) {
+	return Object{
+		ObjectType: ObjExample,
+		Name:       header.(string),
+		Contents:   objSlice(contents),
+	}, nil
+
+}
+
+func (p *parser) callonexample1() (interface{}, error) {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onexample1(stack["header"], stack["contents"])
+}
+
+func (c *current) onexampleHeader1(name interface{}) (interface{}, error) {
+	return name, nil
+}
+
+func (p *parser) callonexampleHeader1() (interface{}, error) {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onexampleHeader1(stack["name"])
+}
+
+func (c *current) onexampleContents1(val interface{}) (interface{}, error) {
+	if obj, ok := val.(Object); ok && obj.ObjectType == ObjDoc {
+		return nil, nil
+	}
+	return val, nil
+
+}
+
+func (p *parser) callonexampleContents1() (interface{}, error) {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onexampleContents1(stack["val"])
+}
+
+func (c *current) onassignment1(name, val interface{}) (interface{}, error) {
+	return Object{
+		ObjectType: ObjAssignment,
+		Name:       name.(string),
+		Literal:    val.(Literal),
+	}, nil
+
+}
+
+func (p *parser) callonassignment1() (interface{}, error) {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onassignment1(stack["name"], stack["val"])
 }
 
 func (c *current) onpkg1(header, contents interface{}) (interface{}, error) {
+	objs := attachDocs(objSlice(contents.([]interface{})))
+	doc := ""
+	for _, o := range objs {
+		if o.ObjectType == ObjID {
+			// Doc comments preceding the identifier describe the package
+			doc = o.Doc
+		}
+	}
 	return Package{
 		Name:     header.(string),
-		Contents: objSlice(contents.([]interface{})),
+		Doc:      doc,
+		Contents: objs,
 	}, nil
 
 }
@@ -1308,14 +2727,14 @@ func (p *parser) callonpkgHeader1() (interface{}, error) {
 	return p.cur.onpkgHeader1(stack["name"])
 }
 
-func (c *current) onpkgContents1(val interface{}) (interface{}, error) {
-	return val, nil
+func (c *current) onpkgContents1(val, trailing interface{}) (interface{}, error) {
+	return withTrailingDoc(val, trailing), nil
 }
 
 func (p *parser) callonpkgContents1() (interface{}, error) {
 	stack := p.vstack[len(p.vstack)-1]
 	_ = stack
-	return p.cur.onpkgContents1(stack["val"])
+	return p.cur.onpkgContents1(stack["val"], stack["trailing"])
 }
 
 var (
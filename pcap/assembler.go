@@ -0,0 +1,226 @@
+package pcap
+
+import (
+	"time"
+)
+
+// maxPendingSegments limits how many out-of-order segments are kept for a
+// flow. Once exceeded, missing data is considered lost and skipped.
+const maxPendingSegments = 64
+
+// maxPendingBytes limits how much out-of-order data is kept for a flow. Once
+// exceeded, the flow is reset past its pending segments, preventing corrupted
+// captures from being buffered entirely.
+const maxPendingBytes = 16 << 20
+
+// Flow identifies one direction of a TCP connection
+type Flow struct {
+	Src, Dst Endpoint
+}
+
+func (f Flow) String() string {
+	return f.Src.String() + " → " + f.Dst.String()
+}
+
+// Handler receives data reassembled by an Assembler
+type Handler interface {
+	// Data is called with bytes delivered in order on a flow, along with the
+	// time of the packet that carried them
+	Data(f Flow, data []byte, t time.Time)
+
+	// Gap is called when n bytes of a flow were not captured, before data
+	// following them is delivered
+	Gap(f Flow, n int)
+
+	// Reset is called when a flow holds too much data out of order. Its
+	// pending segments are discarded, and the stream resumes after them, n
+	// bytes ahead.
+	Reset(f Flow, n int)
+
+	// Close is called when a flow ends, or when the Assembler is flushed
+	Close(f Flow)
+}
+
+type pendingSegment struct {
+	seq  uint32
+	data []byte
+	time time.Time
+}
+
+type stream struct {
+	flow    Flow
+	next    uint32
+	pending []pendingSegment
+	fin     bool
+	finSeq  uint32
+}
+
+// Assembler reassembles TCP segments into ordered streams of bytes,
+// discarding retransmissions and reordering segments delivered out of order
+type Assembler struct {
+	handler Handler
+	streams map[string]*stream
+	order   []*stream
+}
+
+// NewAssembler returns an Assembler delivering data to h
+func NewAssembler(h Handler) *Assembler {
+	return &Assembler{handler: h, streams: map[string]*stream{}}
+}
+
+// key returns a string identifying the flow, suitable for use in maps
+func (f Flow) key() string {
+	return f.String()
+}
+
+// Add processes a segment captured at the given time
+func (a *Assembler) Add(s *Segment, t time.Time) {
+	flow := Flow{Src: s.Src, Dst: s.Dst}
+	key := flow.key()
+	seq := s.Seq
+	if s.SYN {
+		// The SYN flag consumes a sequence number
+		seq++
+	}
+
+	st, ok := a.streams[key]
+	if ok && s.SYN && st.next != seq {
+		// A new connection reusing the same endpoints
+		a.close(st)
+		ok = false
+	}
+	if !ok {
+		if s.RST {
+			return
+		}
+		// Captures may start in the middle of a connection, in which case
+		// the first segment seen determines where the stream starts
+		st = &stream{flow: flow, next: seq}
+		a.streams[key] = st
+		a.order = append(a.order, st)
+	}
+
+	if len(s.Payload) > 0 {
+		a.insert(st, seq, s.Payload, t)
+	}
+	if s.FIN {
+		st.fin = true
+		st.finSeq = seq + uint32(len(s.Payload))
+	}
+
+	if s.RST {
+		for len(st.pending) > 0 {
+			a.skip(st)
+		}
+		a.close(st)
+	} else if st.fin && int32(st.next-st.finSeq) >= 0 {
+		a.close(st)
+	}
+}
+
+// Flush delivers data pending on all flows, skipping missing segments, and
+// closes them
+func (a *Assembler) Flush() {
+	for len(a.order) > 0 {
+		st := a.order[0]
+		for len(st.pending) > 0 {
+			a.skip(st)
+		}
+		a.close(st)
+	}
+}
+
+func (a *Assembler) close(st *stream) {
+	delete(a.streams, st.flow.key())
+	for i, s := range a.order {
+		if s == st {
+			a.order = append(a.order[:i], a.order[i+1:]...)
+			break
+		}
+	}
+	a.handler.Close(st.flow)
+}
+
+func (a *Assembler) insert(st *stream, seq uint32, data []byte, t time.Time) {
+	diff := int32(seq - st.next)
+	if diff < 0 {
+		// Retransmitted data, possibly overlapping new data
+		if int(-diff) >= len(data) {
+			return
+		}
+		data = data[-diff:]
+		diff = 0
+	}
+	if diff > 0 {
+		st.pending = append(st.pending, pendingSegment{seq: seq, data: append([]byte{}, data...), time: t})
+		if st.pendingBytes() > maxPendingBytes {
+			a.reset(st)
+		} else if len(st.pending) > maxPendingSegments {
+			a.skip(st)
+		}
+		return
+	}
+
+	a.handler.Data(st.flow, data, t)
+	st.next += uint32(len(data))
+	a.drain(st)
+}
+
+// drain delivers pending segments that became contiguous to the stream
+func (a *Assembler) drain(st *stream) {
+	for found := true; found; {
+		found = false
+		for i, p := range st.pending {
+			diff := int32(p.seq - st.next)
+			if diff > 0 {
+				continue
+			}
+			st.pending = append(st.pending[:i], st.pending[i+1:]...)
+			if int(-diff) < len(p.data) {
+				data := p.data[-diff:]
+				a.handler.Data(st.flow, data, p.time)
+				st.next += uint32(len(data))
+			}
+			found = true
+			break
+		}
+	}
+}
+
+// pendingBytes returns the amount of data held by pending segments
+func (st *stream) pendingBytes() int {
+	n := 0
+	for _, p := range st.pending {
+		n += len(p.data)
+	}
+	return n
+}
+
+// reset discards pending segments, advancing the stream past the latest of
+// them
+func (a *Assembler) reset(st *stream) {
+	end := st.next
+	for _, p := range st.pending {
+		if e := p.seq + uint32(len(p.data)); int32(e-end) > 0 {
+			end = e
+		}
+	}
+	st.pending = nil
+	a.handler.Reset(st.flow, int(int32(end-st.next)))
+	st.next = end
+}
+
+// skip advances the stream to its earliest pending segment, reporting the
+// bytes missing before it
+func (a *Assembler) skip(st *stream) {
+	earliest := 0
+	for i, p := range st.pending {
+		if int32(p.seq-st.next) < int32(st.pending[earliest].seq-st.next) {
+			earliest = i
+		}
+	}
+	p := st.pending[earliest]
+	a.handler.Gap(st.flow, int(int32(p.seq-st.next)))
+	st.next = p.seq
+	a.drain(st)
+}
@@ -0,0 +1,69 @@
+package pcap
+
+import (
+	"fmt"
+	"net"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// recorder lists calls made by an Assembler
+type recorder struct {
+	calls []string
+}
+
+func (r *recorder) Data(f Flow, data []byte, t time.Time) {
+	r.calls = append(r.calls, fmt.Sprintf("data %d", len(data)))
+}
+
+func (r *recorder) Gap(f Flow, n int) {
+	r.calls = append(r.calls, fmt.Sprintf("gap %d", n))
+}
+
+func (r *recorder) Reset(f Flow, n int) {
+	r.calls = append(r.calls, fmt.Sprintf("reset %d", n))
+}
+
+func (r *recorder) Close(f Flow) {
+	r.calls = append(r.calls, "close")
+}
+
+func TestAssembler(t *testing.T) {
+	src := Endpoint{IP: net.IPv4(10, 0, 0, 1), Port: 5000}
+	dst := Endpoint{IP: net.IPv4(10, 0, 0, 2), Port: 9000}
+	segment := func(seq uint32, size int) *Segment {
+		return &Segment{Src: src, Dst: dst, Seq: seq, Payload: make([]byte, size)}
+	}
+	const chunk = 1 << 20
+
+	tests := []struct {
+		name     string
+		segments []*Segment
+		expected []string
+	}{
+		{"ordered", []*Segment{segment(100, 10), segment(110, 5)}, []string{"data 10", "data 5", "close"}},
+		{"reordered", []*Segment{segment(100, 10), segment(115, 5), segment(110, 5)}, []string{"data 10", "data 5", "data 5", "close"}},
+		{"retransmitted", []*Segment{segment(100, 10), segment(105, 10)}, []string{"data 10", "data 5", "close"}},
+		{"missing", []*Segment{segment(100, 10), segment(120, 5)}, []string{"data 10", "gap 10", "data 5", "close"}},
+		{"reset", func() []*Segment {
+			arr := []*Segment{segment(100, 10)}
+			for i := 0; i <= maxPendingBytes/chunk; i++ {
+				arr = append(arr, segment(uint32(200+i*chunk), chunk))
+			}
+			return append(arr, segment(uint32(200+(maxPendingBytes/chunk+1)*chunk), 5))
+		}(), []string{"data 10", fmt.Sprintf("reset %d", 90+(maxPendingBytes/chunk+1)*chunk), "data 5", "close"}},
+	}
+
+	for _, tt := range tests {
+		r := &recorder{}
+		a := NewAssembler(r)
+		for _, s := range tt.segments {
+			a.Add(s, time.Time{})
+		}
+		a.Flush()
+		if !reflect.DeepEqual(r.calls, tt.expected) {
+			t.Errorf("%s: calls %v, expected %v", tt.name, r.calls, tt.expected)
+		}
+	}
+}
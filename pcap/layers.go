@@ -0,0 +1,175 @@
+package pcap
+
+import (
+	"encoding/binary"
+	"errors"
+	"net"
+	"strconv"
+)
+
+const (
+	etherTypeIPv4 = 0x0800
+	etherTypeIPv6 = 0x86DD
+	etherTypeVLAN = 0x8100
+	etherTypeQinQ = 0x88A8
+
+	protocolTCP = 6
+
+	// IPv6 extension headers skipped while looking for TCP
+	ipv6HopByHop    = 0
+	ipv6Routing     = 43
+	ipv6Destination = 60
+)
+
+// ErrNotTCP indicates that a packet does not carry a TCP segment that can be
+// reassembled
+var ErrNotTCP = errors.New("packet does not carry a TCP segment")
+
+// Endpoint identifies one side of a TCP connection
+type Endpoint struct {
+	IP   net.IP
+	Port uint16
+}
+
+func (e Endpoint) String() string {
+	return net.JoinHostPort(e.IP.String(), strconv.Itoa(int(e.Port)))
+}
+
+// Segment holds a TCP segment extracted from a packet
+type Segment struct {
+	Src, Dst Endpoint
+
+	// Seq holds the sequence number of the first payload byte
+	Seq uint32
+
+	SYN, FIN, RST bool
+
+	// Payload holds the data carried by the segment
+	Payload []byte
+}
+
+// ParseSegment extracts the TCP segment carried by a packet using the given
+// link-layer header. An error is returned for packets not carrying TCP, as
+// well as for fragmented or malformed packets.
+func ParseSegment(link LinkType, data []byte) (*Segment, error) {
+	switch link {
+	case LinkTypeEthernet:
+		if len(data) < 14 {
+			return nil, ErrNotTCP
+		}
+		etherType := binary.BigEndian.Uint16(data[12:])
+		data = data[14:]
+		for (etherType == etherTypeVLAN || etherType == etherTypeQinQ) && len(data) >= 4 {
+			etherType = binary.BigEndian.Uint16(data[2:])
+			data = data[4:]
+		}
+		return parseNetwork(etherType, data)
+	case LinkTypeLinuxSLL:
+		if len(data) < 16 {
+			return nil, ErrNotTCP
+		}
+		return parseNetwork(binary.BigEndian.Uint16(data[14:]), data[16:])
+	case LinkTypeNull:
+		if len(data) < 4 {
+			return nil, ErrNotTCP
+		}
+		// The address family is stored using the byte order of the
+		// capturing host; 2 is AF_INET, while AF_INET6 varies among
+		// systems
+		family := binary.LittleEndian.Uint32(data)
+		if family > 0xFFFF {
+			family = binary.BigEndian.Uint32(data)
+		}
+		switch family {
+		case 2:
+			return parseNetwork(etherTypeIPv4, data[4:])
+		case 10, 24, 28, 30:
+			return parseNetwork(etherTypeIPv6, data[4:])
+		}
+		return nil, ErrNotTCP
+	case LinkTypeRaw:
+		if len(data) == 0 {
+			return nil, ErrNotTCP
+		}
+		if data[0]>>4 == 6 {
+			return parseNetwork(etherTypeIPv6, data)
+		}
+		return parseNetwork(etherTypeIPv4, data)
+	}
+	return nil, errors.New("unsupported link type " + strconv.Itoa(int(link)))
+}
+
+func parseNetwork(etherType uint16, data []byte) (*Segment, error) {
+	var src, dst net.IP
+	switch etherType {
+	case etherTypeIPv4:
+		if len(data) < 20 || data[0]>>4 != 4 {
+			return nil, ErrNotTCP
+		}
+		headerLen := int(data[0]&0x0F) * 4
+		totalLen := int(binary.BigEndian.Uint16(data[2:]))
+		if headerLen < 20 || totalLen < headerLen || len(data) < headerLen {
+			return nil, ErrNotTCP
+		}
+		// Fragmented packets are not reassembled
+		if flags := binary.BigEndian.Uint16(data[6:]); flags&0x2000 != 0 || flags&0x1FFF != 0 {
+			return nil, ErrNotTCP
+		}
+		if data[9] != protocolTCP {
+			return nil, ErrNotTCP
+		}
+		src, dst = net.IP(data[12:16]), net.IP(data[16:20])
+		// Ethernet frames may carry padding after the IP packet
+		if totalLen < len(data) {
+			data = data[:totalLen]
+		}
+		data = data[headerLen:]
+	case etherTypeIPv6:
+		if len(data) < 40 || data[0]>>4 != 6 {
+			return nil, ErrNotTCP
+		}
+		payloadLen := int(binary.BigEndian.Uint16(data[4:]))
+		next := data[6]
+		src, dst = net.IP(data[8:24]), net.IP(data[24:40])
+		data = data[40:]
+		if payloadLen < len(data) {
+			data = data[:payloadLen]
+		}
+		for next != protocolTCP {
+			switch next {
+			case ipv6HopByHop, ipv6Routing, ipv6Destination:
+				if len(data) < 8 || len(data) < (int(data[1])+1)*8 {
+					return nil, ErrNotTCP
+				}
+				next = data[0]
+				data = data[(int(data[1])+1)*8:]
+			default:
+				// Includes fragments, which are not reassembled
+				return nil, ErrNotTCP
+			}
+		}
+	default:
+		return nil, ErrNotTCP
+	}
+	return parseTCP(src, dst, data)
+}
+
+func parseTCP(src, dst net.IP, data []byte) (*Segment, error) {
+	if len(data) < 20 {
+		return nil, ErrNotTCP
+	}
+	offset := int(data[12]>>4) * 4
+	if offset < 20 || len(data) < offset {
+		return nil, ErrNotTCP
+	}
+	flags := data[13]
+	return &Segment{
+		Src:     Endpoint{IP: src, Port: binary.BigEndian.Uint16(data[0:])},
+		Dst:     Endpoint{IP: dst, Port: binary.BigEndian.Uint16(data[2:])},
+		Seq:     binary.BigEndian.Uint32(data[4:]),
+		FIN:     flags&0x01 != 0,
+		SYN:     flags&0x02 != 0,
+		RST:     flags&0x04 != 0,
+		Payload: data[offset:],
+	}, nil
+}
@@ -0,0 +1,126 @@
+// Package pcap implements reading TCP traffic from pcap files, reassembling
+// the data exchanged on each connection. Only the classic pcap format is
+// supported; pcapng files must be converted beforehand.
+package pcap
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+)
+
+// LinkType identifies the link-layer header used by packets in a file
+type LinkType uint32
+
+const (
+	// LinkTypeNull indicates BSD loopback encapsulation
+	LinkTypeNull LinkType = 0
+
+	// LinkTypeEthernet indicates Ethernet II frames
+	LinkTypeEthernet LinkType = 1
+
+	// LinkTypeRaw indicates raw IPv4 or IPv6 packets
+	LinkTypeRaw LinkType = 101
+
+	// LinkTypeLinuxSLL indicates Linux "cooked" capture encapsulation
+	LinkTypeLinuxSLL LinkType = 113
+)
+
+const (
+	magicMicroseconds = 0xa1b2c3d4
+	magicNanoseconds  = 0xa1b23c4d
+	magicPcapng       = 0x0a0d0d0a
+
+	// maxSnapLen limits the length of packets accepted by Reader
+	maxSnapLen = 256 << 10
+)
+
+// ErrInvalidFile indicates that a file does not start with a pcap header
+var ErrInvalidFile = errors.New("not a pcap file")
+
+// ErrPcapng indicates that a file uses the pcapng format
+var ErrPcapng = errors.New("pcapng files are not supported; convert them to pcap using `editcap -F pcap'")
+
+// Packet holds a packet captured from the network
+type Packet struct {
+	// Time indicates when the packet was captured
+	Time time.Time
+
+	// Data holds the captured bytes, starting on the link-layer header
+	Data []byte
+
+	// Truncated indicates whether the packet was captured partially
+	Truncated bool
+}
+
+// Reader reads packets from a pcap file
+type Reader struct {
+	r     io.Reader
+	order binary.ByteOrder
+	nano  bool
+
+	// LinkType identifies the link-layer header of packets in the file
+	LinkType LinkType
+}
+
+// NewReader reads the pcap global header from r, returning a Reader for its
+// packets
+func NewReader(r io.Reader) (*Reader, error) {
+	var header [24]byte
+	if _, err := io.ReadFull(r, header[:]); err != nil {
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			return nil, ErrInvalidFile
+		}
+		return nil, err
+	}
+
+	reader := &Reader{r: r}
+	le, be := binary.LittleEndian.Uint32(header[0:]), binary.BigEndian.Uint32(header[0:])
+	switch {
+	case le == magicMicroseconds || le == magicNanoseconds:
+		reader.order = binary.LittleEndian
+	case be == magicMicroseconds || be == magicNanoseconds:
+		reader.order = binary.BigEndian
+	case le == magicPcapng:
+		return nil, ErrPcapng
+	default:
+		return nil, ErrInvalidFile
+	}
+	reader.nano = reader.order.Uint32(header[0:]) == magicNanoseconds
+	reader.LinkType = LinkType(reader.order.Uint32(header[20:]) & 0x0FFFFFFF)
+	return reader, nil
+}
+
+// Next reads the next packet from the file. io.EOF is returned after the last
+// packet, and io.ErrUnexpectedEOF when the file ends in the middle of one.
+func (r *Reader) Next() (*Packet, error) {
+	var header [16]byte
+	if _, err := io.ReadFull(r.r, header[:]); err != nil {
+		return nil, err
+	}
+	sec := r.order.Uint32(header[0:])
+	frac := r.order.Uint32(header[4:])
+	included := r.order.Uint32(header[8:])
+	original := r.order.Uint32(header[12:])
+	if included > maxSnapLen {
+		return nil, fmt.Errorf("packet length %d exceeds the limit of %d bytes", included, maxSnapLen)
+	}
+
+	if !r.nano {
+		frac *= 1000
+	}
+	p := &Packet{
+		Time:      time.Unix(int64(sec), int64(frac)),
+		Data:      make([]byte, included),
+		Truncated: original > included,
+	}
+	if _, err := io.ReadFull(r.r, p.Data); err != nil {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return nil, err
+	}
+	return p, nil
+}
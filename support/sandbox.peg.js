@@ -2,6 +2,55 @@
   Simple initial blocks and types
 **/
 
+{
+    function docText(parts) {
+        var lines = [];
+        parts.forEach(function(p) {
+            if (p.startsWith("///")) {
+                lines.push(p.slice(3).replace(/^ /, ""));
+                return;
+            }
+            var block = p.slice(3, -2).split("\n");
+            block.forEach(function(l, i) {
+                l = l.trim();
+                if (l.startsWith("*")) {
+                    l = l.slice(1).replace(/^ /, "");
+                }
+                if (l !== "" || (i > 0 && i < block.length - 1)) {
+                    lines.push(l);
+                }
+            });
+        });
+        return lines.map(function(l) { return l.replace(/[ \t\r]+$/, ""); }).join("\n").trim();
+    }
+
+    function joinDocs(a, b) {
+        return a && b ? a + "\n" + b : (a || b || "");
+    }
+
+    function withTrailingDoc(val, trailing) {
+        if (val && trailing && val.object_type !== "doc") {
+            val.doc = joinDocs(val.doc, trailing);
+        }
+        return val;
+    }
+
+    function attachDocs(objs) {
+        var pending = "";
+        return objs.filter(function(o) {
+            if (o && o.object_type === "doc") {
+                pending = joinDocs(pending, o.value);
+                return false;
+            }
+            if (o) {
+                o.doc = joinDocs(pending, o.doc);
+                pending = "";
+            }
+            return true;
+        });
+    }
+}
+
 start
     = contents
 
@@ -26,6 +75,9 @@ digit
 digits
     = digits:digit* { return digits.join('') }
 
+hex_digit
+    = [a-fA-F0-9]
+
 hex_value
     = first:"0x" rest:[a-fA-F0-9]+ { return first + rest.join('') }
 
@@ -45,7 +97,28 @@ close_square_brace
     = "]"
 
 comment
-    = "//" [^\n]* (EOL/EOF)? {}
+    = !doc_line "//" [^\n]* (EOL/EOF)? {}
+
+doc_line
+    = "///" !"/" [^\n]* { return text() }
+
+doc_block
+    = "/**" !"/" (!"*/" .)* "*/" { return text() }
+
+doc_comment
+    = first:(doc_line / doc_block) rest:doc_continuation* { return { object_type: "doc", value: docText([first].concat(rest)) } }
+
+doc_continuation
+    = [ \t\r\n]* val:(doc_line / doc_block) { return val }
+
+trailing_doc
+    = whitespace* val:doc_line { return docText([val]) }
+
+dangling_doc
+    = doc_comment &(__? close_curly_brace) { error("doc comment must precede a package, structure or field") }
+
+literal_space
+    = (EOL / [ \t\r\n]* doc_comment)+ {}
 
 attribute
     = _ "!" flag:("deprecated") { return flag }
@@ -73,13 +146,61 @@ field_definition
 array_definition
     = type:(native_type / user_type) size:array_size _ name:item_name attributes:attribute_list? { return { object_type: "array", source: type.source, kind: type.name, name: name, size: size, attributes: attributes } }
 
+// Literals
+
+literal
+    = uuid_literal
+    / hex_literal
+    / number_literal
+    / string_literal
+    / bool_literal
+    / null_literal
+    / list_literal
+    / struct_literal
+
+uuid_literal
+    = hex_digit hex_digit hex_digit hex_digit hex_digit hex_digit hex_digit hex_digit "-"
+      hex_digit hex_digit hex_digit hex_digit "-"
+      hex_digit hex_digit hex_digit hex_digit "-"
+      hex_digit hex_digit hex_digit hex_digit "-"
+      hex_digit hex_digit hex_digit hex_digit hex_digit hex_digit hex_digit hex_digit hex_digit hex_digit hex_digit hex_digit { return { kind: "uuid", value: text() } }
+
+hex_literal
+    = val:hex_value { return { kind: "hex", value: val } }
+
+number_literal
+    = "-"? digit+ ("." digit+)? ([eE] [+-]? digit+)? { return { kind: "number", value: text() } }
+
+string_literal
+    = '"' ( !'"' !'\\' [^\n] / '\\' . )* '"' { return { kind: "string", value: JSON.parse(text()) } }
+
+bool_literal
+    = ("true" / "false") { return { kind: "bool", value: text() } }
+
+null_literal
+    = "null" { return { kind: "null" } }
+
+list_literal
+    = open_square_brace literal_space? items:list_item* _? close_square_brace { return { kind: "list", items: items } }
+
+list_item
+    = _? val:literal _? ","? literal_space? { return val }
+
+struct_literal
+    = open_curly_brace literal_space? fields:example_contents* _? close_curly_brace { return { kind: "struct", fields: fields } }
+
 // Language structures
 
 contents
     = val:file_contents+ { return val }
 
 file_contents
-    = __? comment? val:pkg __? { return val }
+    = __? comment? doc:doc_comment? __? val:pkg __? {
+        if (doc) {
+            val.doc = joinDocs(doc.value, val.doc);
+        }
+        return val
+    }
 
 // Structures
 
@@ -88,7 +209,7 @@ str
     __?
     content:str_contents+
     __?
-    close_curly_brace { return { "object_type": "struct", name: header, contents: content } }
+    close_curly_brace { return { "object_type": "struct", name: header, contents: attachDocs(content) } }
 
 str_header
     = "struct" _ name:item_name { return name }
@@ -96,8 +217,30 @@ str_header
 str_contents
     = _? val:(field_definition
             / array_definition
+            / dangling_doc
+            / doc_comment
             / comment
-            / str) __? { return val }
+            / str) trailing:trailing_doc? __? { return withTrailingDoc(val, trailing) }
+
+// Examples
+
+example
+    = header:example_header _? open_curly_brace __
+    __?
+    contents:example_contents*
+    __?
+    close_curly_brace { return { object_type: "example", name: header, contents: contents } }
+
+example_header
+    = "example" _ name:item_name { return name }
+
+example_contents
+    = _? val:(assignment
+            / doc_comment
+            / comment) trailing_doc? literal_space? { return val && val.object_type === "doc" ? undefined : val }
+
+assignment
+    = name:item_name _ val:literal { return { object_type: "assignment", name: name, literal: val } }
 
 // Packages
 
@@ -106,7 +249,11 @@ pkg
         __?
         contents:pkg_contents+
         __?
-        close_curly_brace { return { name: header, contents: contents } }
+        close_curly_brace {
+            var objs = attachDocs(contents);
+            var id = objs.filter(function(o) { return o && o.object_type === "identifier"; })[0];
+            return { name: header, doc: id ? id.doc : "", contents: objs };
+        }
 
 
 pkg_header
@@ -114,7 +261,10 @@ pkg_header
 
 pkg_contents
     = _? val:(id_definition
+            / example
             / field_definition
             / array_definition
+            / dangling_doc
+            / doc_comment
             / comment
-            / str) __? { return val }
+            / str) trailing:trailing_doc? __? { return withTrailingDoc(val, trailing) }
@@ -0,0 +1,27 @@
+package validation
+
+import (
+	"fmt"
+
+	"github.com/ludwieg/ludco/codec"
+	"github.com/ludwieg/ludco/models"
+)
+
+// ValidateExamples checks examples declared on a package against its fields.
+// Differently from Validate, it operates on converted packages, since user
+// types must be resolved in order to check struct literals.
+func ValidateExamples(p *models.Package) []error {
+	errors := []error{}
+	names := map[string]bool{}
+	for _, e := range p.Examples {
+		if names[e.Name] {
+			errors = append(errors, fmt.Errorf("duplicated example definition `%s'", e.Name))
+			continue
+		}
+		names[e.Name] = true
+		if _, err := codec.FromExample(p, e); err != nil {
+			errors = append(errors, fmt.Errorf("example `%s': %s", e.Name, err))
+		}
+	}
+	return errors
+}